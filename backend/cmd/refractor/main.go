@@ -23,19 +23,27 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/joho/godotenv"
 	"github.com/sniddunc/refractor/internal/auth"
+	"github.com/sniddunc/refractor/internal/backfill"
 	"github.com/sniddunc/refractor/internal/chat"
+	"github.com/sniddunc/refractor/internal/chatfilter"
+	"github.com/sniddunc/refractor/internal/chatspam"
 	"github.com/sniddunc/refractor/internal/game"
 	"github.com/sniddunc/refractor/internal/game/minecraft"
 	"github.com/sniddunc/refractor/internal/game/mordhau"
+	"github.com/sniddunc/refractor/internal/game/squad"
 	"github.com/sniddunc/refractor/internal/gameserver"
 	"github.com/sniddunc/refractor/internal/http/api"
 	"github.com/sniddunc/refractor/internal/infraction"
+	"github.com/sniddunc/refractor/internal/matchstats"
 	"github.com/sniddunc/refractor/internal/params"
 	"github.com/sniddunc/refractor/internal/player"
+	"github.com/sniddunc/refractor/internal/playerdata"
 	"github.com/sniddunc/refractor/internal/playerinfraction"
+	"github.com/sniddunc/refractor/internal/playermerge"
 	"github.com/sniddunc/refractor/internal/rcon"
 	"github.com/sniddunc/refractor/internal/search"
 	"github.com/sniddunc/refractor/internal/server"
+	"github.com/sniddunc/refractor/internal/serverhistory"
 	"github.com/sniddunc/refractor/internal/storage/mysql"
 	"github.com/sniddunc/refractor/internal/summary"
 	"github.com/sniddunc/refractor/internal/user"
@@ -87,10 +95,20 @@ func main() {
 	infractionRepo := mysql.NewInfractionRepository(db)
 	serverRepo := mysql.NewServerRepository(db)
 	chatRepo := mysql.NewChatRepository(db)
+	playerSessionRepo := mysql.NewPlayerSessionRepository(db)
+	playerMergeRepo := mysql.NewPlayerMergeRepository(db)
+	playerDataRepo := mysql.NewPlayerDataRepository(db)
+	chatFilterRepo := mysql.NewChatFilterRepository(db)
+	chatSpamRepo := mysql.NewChatSpamRepository(db)
+	chatKeywordRepo := mysql.NewChatKeywordRepository(db)
+	backfillRepo := mysql.NewBackfillRepository(db)
+	matchStatsRepo := mysql.NewMatchStatsRepository(db)
+	serverHistoryRepo := mysql.NewServerHistoryRepository(db)
 
 	gameService := game.NewGameService()
 	gameService.AddGame(mordhau.NewMordhauGame())
 	gameService.AddGame(minecraft.NewMinecraftGame())
+	gameService.AddGame(squad.NewSquadGame())
 
 	userService := user.NewUserService(userRepo, loggerInst)
 	userHandler := api.NewUserHandler(userService)
@@ -103,7 +121,7 @@ func main() {
 	websocketService := websocket.NewWebsocketService(playerRepo, userRepo, playerInfractionService, loggerInst)
 	go websocketService.StartPool()
 
-	playerService := player.NewPlayerService(playerRepo, websocketService, loggerInst)
+	playerService := player.NewPlayerService(playerRepo, playerSessionRepo, websocketService, loggerInst)
 	playerHandler := api.NewPlayerHandler(playerService)
 
 	serverService := server.NewServerService(serverRepo, gameService, playerInfractionService, loggerInst)
@@ -125,22 +143,60 @@ func main() {
 	rconService.SubscribeOnline(websocketService.OnServerOnline)
 	rconService.SubscribeOffline(websocketService.OnServerOffline)
 	rconService.SubscribePlayerListPoll(serverService.OnPlayerListUpdate)
+	rconService.SubscribePlayerFields(serverService.OnPlayerFieldsUpdate)
+	rconService.SubscribeServerInfo(serverService.OnServerInfoUpdate)
+	rconService.SubscribeServerInfo(websocketService.OnServerInfoUpdate)
 
-	chatService := chat.NewChatService(chatRepo, playerRepo, websocketService, rconService, loggerInst)
+	infractionService := infraction.NewInfractionService(infractionRepo, playerService, serverService, userService, loggerInst)
+	infractionHandler := api.NewInfractionHandler(infractionService)
+	infractionService.SubscribeInfractionCreate(websocketService.OnInfractionCreate)
+	rconService.SubscribePunishment(infractionService.OnPunishment)
+
+	chatFilterService := chatfilter.NewChatFilterService(chatFilterRepo, serverService, gameService, infractionService,
+		websocketService, rconService, loggerInst)
+	chatFilterHandler := api.NewChatFilterHandler(chatFilterService)
+
+	chatSpamService := chatspam.NewChatSpamService(chatSpamRepo, serverService, gameService, infractionService,
+		websocketService, rconService, loggerInst)
+	chatSpamHandler := api.NewChatSpamHandler(chatSpamService)
+	rconService.SubscribeQuit(chatSpamService.OnPlayerQuit)
+	rconService.SubscribeOffline(chatSpamService.OnServerOffline)
+
+	chatService := chat.NewChatService(chatRepo, chatKeywordRepo, playerRepo, userRepo, serverService, gameService,
+		websocketService, rconService, chatFilterService, chatSpamService, infractionService, playerInfractionService,
+		loggerInst)
+	chatHandler := api.NewChatHandler(chatService)
 	rconService.SubscribeChat(chatService.OnChatReceive)
 	websocketService.SubscribeChatSend(rconService.SendChatMessage)
 	websocketService.SubscribeChatSend(chatService.OnUserSendChat)
+	websocketService.SubscribeWhisperSend(chatService.OnUserSendWhisper)
+	infractionService.SubscribeInfractionCreate(chatService.OnInfractionCreate)
 
-	infractionService := infraction.NewInfractionService(infractionRepo, playerService, serverService, userService, loggerInst)
-	infractionHandler := api.NewInfractionHandler(infractionService)
-	infractionService.SubscribeInfractionCreate(websocketService.OnInfractionCreate)
+	matchStatsService := matchstats.NewMatchStatsService(matchStatsRepo, playerService, serverService, gameService,
+		infractionService, websocketService, rconService, loggerInst)
+	matchStatsHandler := api.NewMatchStatsHandler(matchStatsService)
+	rconService.SubscribeMatchState(matchStatsService.OnMatchState)
+	rconService.SubscribeKill(matchStatsService.OnKill)
+	rconService.SubscribeOffline(matchStatsService.OnServerOffline)
 
-	summaryService := summary.NewSummaryService(playerService, infractionService, loggerInst)
+	summaryService := summary.NewSummaryService(playerService, infractionService, matchStatsService, loggerInst)
 	summaryHandler := api.NewSummaryHandler(summaryService)
 
 	searchService := search.NewSearchService(playerRepo, infractionRepo, chatRepo, loggerInst)
 	searchHandler := api.NewSearchHandler(searchService)
 
+	playerMergeService := playermerge.NewPlayerMergeService(playerMergeRepo, playerRepo, loggerInst)
+	playerMergeHandler := api.NewPlayerMergeHandler(playerMergeService)
+
+	playerDataService := playerdata.NewPlayerDataService(playerDataRepo, playerRepo, loggerInst)
+	playerDataHandler := api.NewPlayerDataHandler(playerDataService)
+
+	backfillService := backfill.NewBackfillService(backfillRepo, serverService, gameService, websocketService, loggerInst)
+	backfillHandler := api.NewBackfillHandler(backfillService)
+
+	serverHistoryService := serverhistory.NewServerHistoryService(serverHistoryRepo, serverService, loggerInst)
+	serverHistoryHandler := api.NewServerHistoryHandler(serverHistoryService)
+
 	// Set up initial user if no users currently exist
 	if count := userRepo.GetCount(); count == 0 {
 		if err := setupInitialUser(userService); err != nil {
@@ -158,16 +214,27 @@ func main() {
 	// Start RCON client watchdog
 	go watchdog.StartRCONServerWatchdog(rconService, serverService, loggerInst)
 
+	// Start sampling server status history
+	go serverHistoryService.StartSampling()
+
 	// API Setup
 	apiHandlers := &api.Handlers{
-		AuthHandler:       authHandler,
-		UserHandler:       userHandler,
-		ServerHandler:     serverHandler,
-		PlayerHandler:     playerHandler,
-		GameServerHandler: gameServerHandler,
-		InfractionHandler: infractionHandler,
-		SummaryHandler:    summaryHandler,
-		SearchHandler:     searchHandler,
+		AuthHandler:          authHandler,
+		UserHandler:          userHandler,
+		ServerHandler:        serverHandler,
+		PlayerHandler:        playerHandler,
+		GameServerHandler:    gameServerHandler,
+		InfractionHandler:    infractionHandler,
+		SummaryHandler:       summaryHandler,
+		SearchHandler:        searchHandler,
+		PlayerMergeHandler:   playerMergeHandler,
+		PlayerDataHandler:    playerDataHandler,
+		ChatFilterHandler:    chatFilterHandler,
+		ChatSpamHandler:      chatSpamHandler,
+		ChatHandler:          chatHandler,
+		BackfillHandler:      backfillHandler,
+		MatchStatsHandler:    matchStatsHandler,
+		ServerHistoryHandler: serverHistoryHandler,
 	}
 
 	// Done. Begin serving.
@@ -0,0 +1,302 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package backfill
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"github.com/sniddunc/refractor/pkg/broadcast"
+	"github.com/sniddunc/refractor/pkg/regexutils"
+	"github.com/sniddunc/refractor/refractor"
+	"time"
+)
+
+// replayer replays the lines of a server's archived log files and records the players, names, sessions and chat
+// messages found in them. Sessions are kept open across files so that logs which were rotated while players were
+// online produce a single session.
+type replayer struct {
+	repo       refractor.BackfillRepository
+	serverID   int64
+	gameConfig *refractor.GameConfig
+
+	// lastTimestamp is the time of the most recent line with a timestamp. Lines without one are assumed to have
+	// happened at this time.
+	lastTimestamp int64
+
+	// players, names, openSessions and seen are keyed by player game ID
+	players      map[string]int64
+	names        map[string]string
+	openSessions map[string]int64 // join time
+	seen         map[string]bool  // whether the player joined or quit during this backfill
+
+	// lineCounts counts how many times each chat line has been seen in the current file so that identical lines
+	// still get different keys
+	lineCounts map[[sha256.Size]byte]int
+
+	playersCreated  int
+	sessionsCreated int
+	messagesCreated int
+	duplicates      int
+}
+
+func newReplayer(repo refractor.BackfillRepository, serverID int64, gameConfig *refractor.GameConfig) *replayer {
+	return &replayer{
+		repo:         repo,
+		serverID:     serverID,
+		gameConfig:   gameConfig,
+		players:      map[string]int64{},
+		names:        map[string]string{},
+		openSessions: map[string]int64{},
+		seen:         map[string]bool{},
+		lineCounts:   map[[sha256.Size]byte]int{},
+	}
+}
+
+// startFile is called before the lines of each log file are processed.
+func (r *replayer) startFile() {
+	r.lineCounts = map[[sha256.Size]byte]int{}
+}
+
+// processLine records the event on a single log line. Lines which aren't events are ignored.
+func (r *replayer) processLine(line string) error {
+	r.updateTimestamp(line)
+
+	bcast := broadcast.MatchLogLine(line, r.gameConfig.LogLinePrefix, r.gameConfig.BroadcastPatterns)
+	if bcast == nil || r.lastTimestamp == 0 {
+		return nil
+	}
+
+	fields := bcast.Fields
+	gameID := fields[r.gameConfig.PlayerGameIDField]
+	if gameID == "" {
+		return nil
+	}
+
+	switch bcast.Type {
+	case broadcast.TYPE_JOIN:
+		return r.onJoin(gameID, fields["Name"])
+	case broadcast.TYPE_QUIT:
+		return r.onQuit(gameID)
+	case broadcast.TYPE_CHAT:
+		return r.onChat(gameID, fields, r.lineKey(line))
+	}
+
+	return nil
+}
+
+// finish closes the sessions of players who were still online when the last log file ended. If a later backfill finds
+// out when they actually quit, the session is extended.
+func (r *replayer) finish() error {
+	for gameID := range r.openSessions {
+		if err := r.closeSession(gameID, r.lastTimestamp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *replayer) onJoin(gameID string, name string) error {
+	if _, err := r.getPlayerID(gameID, name); err != nil {
+		return err
+	}
+
+	r.seen[gameID] = true
+
+	// If the player never quit (e.g. the server crashed), their previous session ends when they join again
+	if _, open := r.openSessions[gameID]; open {
+		if err := r.closeSession(gameID, r.lastTimestamp); err != nil {
+			return err
+		}
+	}
+
+	r.openSessions[gameID] = r.lastTimestamp
+
+	return nil
+}
+
+func (r *replayer) onQuit(gameID string) error {
+	if _, open := r.openSessions[gameID]; open {
+		r.seen[gameID] = true
+		return r.closeSession(gameID, r.lastTimestamp)
+	}
+
+	firstEvent := !r.seen[gameID]
+	r.seen[gameID] = true
+
+	// A player who quits before joining was already online when the logs start, so their last session (most likely
+	// closed early by a previous backfill) continues until now
+	if !firstEvent {
+		return nil
+	}
+
+	playerID := r.players[gameID]
+	if playerID == 0 {
+		var err error
+
+		playerID, err = r.repo.FindPlayerID(r.gameConfig.PlayerGameIDField, gameID)
+		if err == refractor.ErrNotFound {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("could not find player: %v", err)
+		}
+
+		r.players[gameID] = playerID
+	}
+
+	extended, err := r.repo.ExtendLastSession(playerID, r.serverID, r.lastTimestamp)
+	if err != nil {
+		return fmt.Errorf("could not extend session: %v", err)
+	}
+
+	if !extended {
+		return nil
+	}
+
+	if err := r.repo.UpdateLastSeen(playerID, r.lastTimestamp); err != nil {
+		return fmt.Errorf("could not update last seen time: %v", err)
+	}
+
+	return nil
+}
+
+func (r *replayer) onChat(gameID string, fields broadcast.Fields, key string) error {
+	playerID, err := r.getPlayerID(gameID, fields["Name"])
+	if err != nil {
+		return err
+	}
+
+	created, err := r.repo.RecordChatMessage(&refractor.ChatMessage{
+		PlayerID:     playerID,
+		ServerID:     r.serverID,
+		Message:      fields["Message"],
+		DateRecorded: r.lastTimestamp,
+		Channel:      fields["Channel"],
+		PlayerName:   fields["Name"],
+	}, key)
+	if err != nil {
+		return fmt.Errorf("could not record chat message: %v", err)
+	}
+
+	r.count(created, &r.messagesCreated)
+
+	return nil
+}
+
+func (r *replayer) closeSession(gameID string, quitTime int64) error {
+	joinTime := r.openSessions[gameID]
+	playerID := r.players[gameID]
+	delete(r.openSessions, gameID)
+
+	created, err := r.repo.RecordSession(&refractor.DBPlayerSession{
+		PlayerID: playerID,
+		ServerID: r.serverID,
+		JoinTime: joinTime,
+		QuitTime: sql.NullInt64{Int64: quitTime, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("could not record session: %v", err)
+	}
+
+	r.count(created, &r.sessionsCreated)
+
+	if err := r.repo.UpdateLastSeen(playerID, quitTime); err != nil {
+		return fmt.Errorf("could not update last seen time: %v", err)
+	}
+
+	return nil
+}
+
+// getPlayerID returns the ID of a player, creating them if they don't exist yet. If the player is using a different
+// name than the last time they were seen, the name is recorded.
+func (r *replayer) getPlayerID(gameID string, name string) (int64, error) {
+	playerID := r.players[gameID]
+
+	if playerID == 0 {
+		var err error
+
+		playerID, err = r.repo.FindPlayerID(r.gameConfig.PlayerGameIDField, gameID)
+		if err != nil && err != refractor.ErrNotFound {
+			return 0, fmt.Errorf("could not find player: %v", err)
+		}
+
+		if err == refractor.ErrNotFound {
+			playerID, err = r.repo.CreatePlayer(r.gameConfig.PlayerGameIDField, gameID, name, r.lastTimestamp)
+			if err != nil {
+				return 0, fmt.Errorf("could not create player: %v", err)
+			}
+
+			r.playersCreated++
+			r.names[gameID] = name
+		}
+
+		r.players[gameID] = playerID
+	}
+
+	if name != "" && r.names[gameID] != name {
+		if err := r.repo.RecordName(playerID, name, r.lastTimestamp); err != nil {
+			return 0, fmt.Errorf("could not record player name: %v", err)
+		}
+
+		r.names[gameID] = name
+	}
+
+	return playerID, nil
+}
+
+// updateTimestamp sets the last timestamp from the line's prefix if it has one
+func (r *replayer) updateTimestamp(line string) {
+	if r.gameConfig.LogLinePrefix == nil {
+		return
+	}
+
+	loc := r.gameConfig.LogLinePrefix.FindStringIndex(line)
+	if loc == nil || loc[0] != 0 {
+		return
+	}
+
+	fields := regexutils.MapNamedMatches(r.gameConfig.LogLinePrefix, line[:loc[1]])
+
+	timestamp, err := time.ParseInLocation(r.gameConfig.LogTimestampLayout, fields["Timestamp"], time.UTC)
+	if err != nil {
+		return
+	}
+
+	r.lastTimestamp = timestamp.Unix()
+}
+
+// lineKey returns a key which identifies a line in the current file. It is based on the line's contents rather than
+// its position so that it stays the same if the file is renamed by a log rotation.
+func (r *replayer) lineKey(line string) string {
+	sum := sha256.Sum256([]byte(line))
+	r.lineCounts[sum]++
+
+	key := sha256.Sum256([]byte(fmt.Sprintf("%x:%d", sum, r.lineCounts[sum])))
+
+	return hex.EncodeToString(key[:])
+}
+
+func (r *replayer) count(created bool, counter *int) {
+	if created {
+		*counter++
+	} else {
+		r.duplicates++
+	}
+}
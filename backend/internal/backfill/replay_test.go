@@ -0,0 +1,188 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package backfill
+
+import (
+	"github.com/sniddunc/refractor/internal/game/mordhau"
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var testLogLines = []string{
+	"[2021.01.01-12.00.00:000][  0]LogInit: Display: Starting server",
+	"[2021.01.01-12.00.05:100][  1]LogMordhauGameMode: Display: Login: 2021.01.01-12.00.05: Steve (6A5B4C3D2E1F0A9B) logged in",
+	"[2021.01.01-12.01.00:200][  2]LogMordhauPlayerController: Display: Chat: 6A5B4C3D2E1F0A9B, Steve, (ALL) hello there",
+	"[2021.01.01-12.02.00:000][  3]LogMordhauGameMode: Display: Login: 2021.01.01-12.02.00: Alex (1F2E3D4C5B6A7980) logged in",
+	"[2021.01.01-12.03.00:000][  4]LogMordhauGameMode: Display: Login: 2021.01.01-12.03.00: Steve (6A5B4C3D2E1F0A9B) logged out",
+	"[2021.01.01-12.04.00:000][  5]LogMordhauGameMode: Display: Login: 2021.01.01-12.04.00: SteveRenamed (6A5B4C3D2E1F0A9B) logged in",
+	"[2021.01.01-12.05.00:000][  6]LogNet: Display: Shutting down",
+}
+
+func unix(value string) int64 {
+	timestamp, _ := time.Parse("2006.01.02-15.04.05", value)
+	return timestamp.Unix()
+}
+
+func replayTestLines(r *replayer) error {
+	for _, line := range testLogLines {
+		if err := r.processLine(line); err != nil {
+			return err
+		}
+	}
+
+	return r.finish()
+}
+
+func Test_replayer(t *testing.T) {
+	store := mock.NewBackfillStore()
+	repo := mock.NewMockBackfillRepository(store)
+	gameConfig := mordhau.NewMordhauGame().GetConfig()
+
+	r := newReplayer(repo, 1, gameConfig)
+	assert.Nil(t, replayTestLines(r))
+
+	steveID := store.Players["PlayFabID:6A5B4C3D2E1F0A9B"]
+	alexID := store.Players["PlayFabID:1F2E3D4C5B6A7980"]
+
+	assert.Equal(t, 2, r.playersCreated)
+	assert.Equal(t, map[string]int64{
+		"Steve":        unix("2021.01.01-12.00.05"),
+		"SteveRenamed": unix("2021.01.01-12.04.00"),
+	}, store.Names[steveID])
+
+	// Players still online when the log ends have their sessions closed at the last timestamp
+	assert.Equal(t, 3, r.sessionsCreated)
+	assert.Len(t, store.Sessions, 3)
+	assert.Equal(t, steveID, store.Sessions[0].PlayerID)
+	assert.Equal(t, unix("2021.01.01-12.00.05"), store.Sessions[0].JoinTime)
+	assert.Equal(t, unix("2021.01.01-12.03.00"), store.Sessions[0].QuitTime.Int64)
+	assert.Equal(t, unix("2021.01.01-12.05.00"), store.LastSeen[alexID])
+	assert.Equal(t, unix("2021.01.01-12.05.00"), store.LastSeen[steveID])
+
+	assert.Equal(t, 1, r.messagesCreated)
+	assert.Equal(t, "hello there", store.Messages[0].Message)
+	assert.Equal(t, "ALL", store.Messages[0].Channel)
+	assert.Equal(t, "Steve", store.Messages[0].PlayerName)
+	assert.Equal(t, unix("2021.01.01-12.01.00"), store.Messages[0].DateRecorded)
+	assert.Equal(t, int64(1), store.Messages[0].ServerID)
+
+	// Replaying the same lines again should not create anything new
+	r = newReplayer(repo, 1, gameConfig)
+	assert.Nil(t, replayTestLines(r))
+
+	assert.Equal(t, 0, r.playersCreated)
+	assert.Equal(t, 0, r.sessionsCreated)
+	assert.Equal(t, 0, r.messagesCreated)
+	assert.Equal(t, 4, r.duplicates)
+	assert.Len(t, store.Players, 2)
+	assert.Len(t, store.Sessions, 3)
+	assert.Len(t, store.Messages, 1)
+}
+
+func Test_replayer_NoTimestamp(t *testing.T) {
+	store := mock.NewBackfillStore()
+	r := newReplayer(mock.NewMockBackfillRepository(store), 1, mordhau.NewMordhauGame().GetConfig())
+
+	// Events are skipped until the time they happened at is known
+	assert.Nil(t, r.processLine("Login: 2021.01.01-12.00.05: Steve (6A5B4C3D2E1F0A9B) logged in"))
+	assert.Nil(t, r.finish())
+
+	assert.Len(t, store.Players, 0)
+	assert.Len(t, store.Sessions, 0)
+}
+
+func Test_replayer_ChatMessages(t *testing.T) {
+	const chatLine = "[2021.01.01-12.01.00:200][  2]LogMordhauPlayerController: Display: Chat: 6A5B4C3D2E1F0A9B, Steve, (ALL) gg"
+
+	store := mock.NewBackfillStore()
+	repo := mock.NewMockBackfillRepository(store)
+	gameConfig := mordhau.NewMordhauGame().GetConfig()
+
+	// A message which was recorded live a few seconds after it was logged
+	store.Players["PlayFabID:6A5B4C3D2E1F0A9B"] = 1
+	store.Messages = append(store.Messages, &refractor.ChatMessage{
+		MessageID:    1,
+		PlayerID:     1,
+		ServerID:     1,
+		Message:      "gg",
+		DateRecorded: unix("2021.01.01-12.01.03"),
+	})
+
+	replay := func() *replayer {
+		r := newReplayer(repo, 1, gameConfig)
+		r.startFile()
+
+		// The same message is sent three times within the same second
+		for i := 0; i < 3; i++ {
+			assert.Nil(t, r.processLine(chatLine))
+		}
+
+		assert.Nil(t, r.finish())
+		return r
+	}
+
+	r := replay()
+	assert.Equal(t, 2, r.messagesCreated)
+	assert.Equal(t, 1, r.duplicates)
+	assert.Len(t, store.Messages, 3)
+	assert.NotEmpty(t, store.MessageKeys[1])
+
+	// Replaying the same file again should not create anything new
+	r = replay()
+	assert.Equal(t, 0, r.messagesCreated)
+	assert.Equal(t, 3, r.duplicates)
+	assert.Len(t, store.Messages, 3)
+}
+
+func Test_replayer_ExtendSession(t *testing.T) {
+	store := mock.NewBackfillStore()
+	repo := mock.NewMockBackfillRepository(store)
+	gameConfig := mordhau.NewMordhauGame().GetConfig()
+
+	r := newReplayer(repo, 1, gameConfig)
+	assert.Nil(t, replayTestLines(r))
+
+	steveID := store.Players["PlayFabID:6A5B4C3D2E1F0A9B"]
+
+	// Steve's second session was closed when the first log file ended
+	var session *refractor.DBPlayerSession
+	for _, s := range store.Sessions {
+		if s.PlayerID == steveID && s.JoinTime == unix("2021.01.01-12.04.00") {
+			session = s
+		}
+	}
+
+	assert.NotNil(t, session)
+	assert.Equal(t, unix("2021.01.01-12.05.00"), session.QuitTime.Int64)
+
+	// The next log file shows when the players who were still online actually quit
+	r = newReplayer(repo, 1, gameConfig)
+	r.startFile()
+	assert.Nil(t, r.processLine(
+		"[2021.01.01-12.10.00:000][  0]LogMordhauGameMode: Display: Login: 2021.01.01-12.10.00: SteveRenamed (6A5B4C3D2E1F0A9B) logged out"))
+	assert.Nil(t, r.processLine(
+		"[2021.01.01-12.20.00:000][  1]LogMordhauGameMode: Display: Login: 2021.01.01-12.20.00: SteveRenamed (6A5B4C3D2E1F0A9B) logged out"))
+	assert.Nil(t, r.finish())
+
+	assert.Len(t, store.Sessions, 3)
+	assert.Equal(t, unix("2021.01.01-12.10.00"), session.QuitTime.Int64)
+	assert.Equal(t, unix("2021.01.01-12.10.00"), store.LastSeen[steveID])
+}
@@ -0,0 +1,284 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package backfill
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type backfillService struct {
+	repo             refractor.BackfillRepository
+	serverService    refractor.ServerService
+	gameService      refractor.GameService
+	websocketService refractor.WebsocketService
+	log              log.Logger
+
+	// jobs holds the most recent backfill of each server
+	jobs   map[int64]*refractor.BackfillProgress
+	jobsMu sync.Mutex
+}
+
+func NewBackfillService(repo refractor.BackfillRepository, serverService refractor.ServerService,
+	gameService refractor.GameService, websocketService refractor.WebsocketService, log log.Logger) refractor.BackfillService {
+	return &backfillService{
+		repo:             repo,
+		serverService:    serverService,
+		gameService:      gameService,
+		websocketService: websocketService,
+		log:              log,
+		jobs:             map[int64]*refractor.BackfillProgress{},
+	}
+}
+
+// StartBackfill starts replaying a server's archived log files in the background. Progress can be checked with
+// GetProgress and is also broadcast over websocket as the backfill runs.
+func (s *backfillService) StartBackfill(serverID int64, body params.StartBackfillParams) (*refractor.BackfillProgress, *refractor.ServiceResponse) {
+	server, res := s.serverService.GetServerByID(serverID)
+	if server == nil {
+		return nil, res
+	}
+
+	game, _ := s.gameService.GetGame(server.Game)
+	if game == nil {
+		s.log.Error("Could not get game %s for server ID %d", server.Game, serverID)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	gameConfig := game.GetConfig()
+	if len(gameConfig.BroadcastPatterns) == 0 || gameConfig.LogTimestampLayout == "" {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    "This server's game does not support backfilling from log files",
+		}
+	}
+
+	// Make sure all of the files can be read before starting
+	var bytesTotal int64
+	for _, path := range body.Paths {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			return nil, &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+				ValidationErrors: url.Values{
+					"paths": []string{fmt.Sprintf("Could not read log file %s", path)},
+				},
+			}
+		}
+
+		bytesTotal += info.Size()
+	}
+
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+
+	if job := s.jobs[serverID]; job != nil && job.Running {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    "A backfill is already running for this server",
+		}
+	}
+
+	job := &refractor.BackfillProgress{
+		ServerID:   serverID,
+		Running:    true,
+		Files:      body.Paths,
+		BytesTotal: bytesTotal,
+		StartedBy:  body.UserMeta.UserID,
+		StartedAt:  time.Now().Unix(),
+	}
+
+	s.jobs[serverID] = job
+
+	go s.runBackfill(job, newReplayer(s.repo, serverID, gameConfig))
+
+	started := *job
+
+	return &started, &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Backfill started",
+	}
+}
+
+func (s *backfillService) GetProgress(serverID int64) (*refractor.BackfillProgress, *refractor.ServiceResponse) {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+
+	job := s.jobs[serverID]
+	if job == nil {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    "No backfill has been run for this server",
+		}
+	}
+
+	progress := *job
+
+	return &progress, &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Backfill progress fetched",
+	}
+}
+
+func (s *backfillService) runBackfill(job *refractor.BackfillProgress, r *replayer) {
+	s.log.Info("Backfill of %d log files started for server ID %d", len(job.Files), job.ServerID)
+
+	var bytesRead int64
+	var linesRead int64
+
+	err := func() error {
+		for _, path := range job.Files {
+			s.updateProgress(job, r, func(job *refractor.BackfillProgress) {
+				job.CurrentFile = path
+			})
+
+			r.startFile()
+
+			err := readLogFile(path, &bytesRead, func(line string) error {
+				linesRead++
+
+				if err := r.processLine(line); err != nil {
+					return err
+				}
+
+				if linesRead%int64(config.BackfillProgressInterval) == 0 {
+					s.updateProgress(job, r, func(job *refractor.BackfillProgress) {
+						job.BytesRead = bytesRead
+						job.LinesRead = linesRead
+					})
+				}
+
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+
+			s.updateProgress(job, r, func(job *refractor.BackfillProgress) {
+				job.FilesDone++
+			})
+		}
+
+		return r.finish()
+	}()
+
+	s.updateProgress(job, r, func(job *refractor.BackfillProgress) {
+		job.Running = false
+		job.CurrentFile = ""
+		job.BytesRead = bytesRead
+		job.LinesRead = linesRead
+		job.FinishedAt = time.Now().Unix()
+
+		if err != nil {
+			job.Error = err.Error()
+		}
+	})
+
+	if err != nil {
+		s.log.Error("Backfill for server ID %d failed. Error: %v", job.ServerID, err)
+		return
+	}
+
+	s.log.Info("Backfill for server ID %d finished. %d players, %d sessions and %d chat messages were created",
+		job.ServerID, r.playersCreated, r.sessionsCreated, r.messagesCreated)
+}
+
+// updateProgress applies an update to a job along with the replayer's counts and broadcasts the new progress
+func (s *backfillService) updateProgress(job *refractor.BackfillProgress, r *replayer, update func(job *refractor.BackfillProgress)) {
+	s.jobsMu.Lock()
+
+	update(job)
+	job.PlayersCreated = r.playersCreated
+	job.SessionsCreated = r.sessionsCreated
+	job.MessagesCreated = r.messagesCreated
+	job.Duplicates = r.duplicates
+
+	progress := *job
+
+	s.jobsMu.Unlock()
+
+	s.websocketService.Broadcast(&refractor.WebsocketMessage{
+		Type: "backfill-progress",
+		Body: progress,
+	})
+}
+
+// countingReader counts the bytes read from the underlying reader so that progress through compressed files can be
+// measured by their size on disk.
+type countingReader struct {
+	reader io.Reader
+	count  *int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	*r.count += int64(n)
+
+	return n, err
+}
+
+// readLogFile passes each line of a log file to the handler. Files ending in .gz are decompressed.
+func readLogFile(path string, bytesRead *int64, handler func(line string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	var reader io.Reader = &countingReader{reader: file, count: bytesRead}
+
+	if strings.HasSuffix(path, ".gz") {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), config.BackfillMaxLineLength)
+
+	for scanner.Scan() {
+		if err := handler(strings.TrimRight(scanner.Text(), "\r")); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
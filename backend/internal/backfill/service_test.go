@@ -0,0 +1,145 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package backfill
+
+import (
+	"compress/gzip"
+	"github.com/sniddunc/refractor/internal/game"
+	"github.com/sniddunc/refractor/internal/game/mordhau"
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/internal/server"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestBackfillService(store *mock.BackfillStore) refractor.BackfillService {
+	testLogger, _ := log.NewLogger(true, false)
+
+	gameService := game.NewGameService()
+	gameService.AddGame(mordhau.NewMordhauGame())
+	gameService.AddGame(mock.NewMockGame())
+
+	mockServerRepo := mock.NewMockServerRepository(map[int64]*refractor.Server{
+		1: {ServerID: 1, Name: "Mordhau Server", Game: "Mordhau"},
+		2: {ServerID: 2, Name: "Test Server", Game: "TestGame"},
+	})
+	serverService := server.NewServerService(mockServerRepo, gameService, nil, testLogger)
+
+	return NewBackfillService(mock.NewMockBackfillRepository(store), serverService, gameService,
+		mock.NewMockWebsocketService(), testLogger)
+}
+
+func waitForBackfill(t *testing.T, service refractor.BackfillService, serverID int64) *refractor.BackfillProgress {
+	timeout := time.After(time.Second * 2)
+
+	for {
+		progress, res := service.GetProgress(serverID)
+		assert.True(t, res.Success)
+
+		if !progress.Running {
+			return progress
+		}
+
+		select {
+		case <-timeout:
+			t.Fatal("Backfill did not finish in time")
+		case <-time.After(time.Millisecond * 5):
+		}
+	}
+}
+
+func Test_backfillService_StartBackfill(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backfill")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	// Split the test log over a plain and a gzipped file
+	plainPath := filepath.Join(dir, "Mordhau-backup.log")
+	assert.Nil(t, ioutil.WriteFile(plainPath, []byte(strings.Join(testLogLines[:3], "\r\n")+"\r\n"), 0644))
+
+	gzipPath := filepath.Join(dir, "Mordhau.log.gz")
+	gzipFile, err := os.Create(gzipPath)
+	assert.Nil(t, err)
+	gzipWriter := gzip.NewWriter(gzipFile)
+	_, err = gzipWriter.Write([]byte(strings.Join(testLogLines[3:], "\n")))
+	assert.Nil(t, err)
+	assert.Nil(t, gzipWriter.Close())
+	assert.Nil(t, gzipFile.Close())
+
+	store := mock.NewBackfillStore()
+	service := newTestBackfillService(store)
+
+	body := params.StartBackfillParams{
+		Paths:    []string{plainPath, gzipPath},
+		UserMeta: &params.UserMeta{UserID: 1},
+	}
+
+	progress, res := service.StartBackfill(1, body)
+	assert.True(t, res.Success)
+	assert.True(t, progress.Running)
+
+	progress = waitForBackfill(t, service, 1)
+	assert.Empty(t, progress.Error)
+	assert.Equal(t, 2, progress.FilesDone)
+	assert.Equal(t, int64(len(testLogLines)), progress.LinesRead)
+	assert.Equal(t, progress.BytesTotal, progress.BytesRead)
+	assert.Equal(t, 2, progress.PlayersCreated)
+	assert.Equal(t, 3, progress.SessionsCreated)
+	assert.Equal(t, 1, progress.MessagesCreated)
+
+	// Running the backfill again should only find duplicates
+	_, res = service.StartBackfill(1, body)
+	assert.True(t, res.Success)
+
+	progress = waitForBackfill(t, service, 1)
+	assert.Equal(t, 0, progress.PlayersCreated)
+	assert.Equal(t, 0, progress.SessionsCreated)
+	assert.Equal(t, 0, progress.MessagesCreated)
+	assert.Equal(t, 4, progress.Duplicates)
+}
+
+func Test_backfillService_StartBackfill_Invalid(t *testing.T) {
+	service := newTestBackfillService(mock.NewBackfillStore())
+	userMeta := &params.UserMeta{UserID: 1}
+
+	// Missing files are rejected before the backfill starts
+	_, res := service.StartBackfill(1, params.StartBackfillParams{
+		Paths:    []string{filepath.Join(os.TempDir(), "backfill-does-not-exist.log")},
+		UserMeta: userMeta,
+	})
+	assert.False(t, res.Success)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.NotEmpty(t, res.ValidationErrors.Get("paths"))
+
+	// Games without log patterns can't be backfilled
+	_, res = service.StartBackfill(2, params.StartBackfillParams{Paths: []string{os.TempDir()}, UserMeta: userMeta})
+	assert.False(t, res.Success)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	_, res = service.GetProgress(1)
+	assert.False(t, res.Success)
+}
@@ -0,0 +1,207 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chat
+
+import (
+	"fmt"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/refractor"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+type compiledKeyword struct {
+	keyword *refractor.ChatKeyword
+	regex   *regexp.Regexp
+}
+
+type chatKeywordBody struct {
+	Message  *refractor.ChatMessage `json:"message"`
+	Keywords []string               `json:"keywords"`
+}
+
+func (s *chatService) AddKeyword(body params.AddChatKeywordParams) (*refractor.ChatKeyword, *refractor.ServiceResponse) {
+	existing, err := s.keywordRepo.FindByUserID(body.UserMeta.UserID)
+	if err != nil && err != refractor.ErrNotFound {
+		s.log.Error("Could not get chat keywords of user ID %d. Error: %v", body.UserMeta.UserID, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	if len(existing) >= config.ChatKeywordMaxPerUser {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("You can not have more than %d keywords", config.ChatKeywordMaxPerUser),
+		}
+	}
+
+	for _, keyword := range existing {
+		if strings.EqualFold(keyword.Keyword, body.Keyword) {
+			return nil, &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+				ValidationErrors: url.Values{
+					"keyword": []string{"You are already subscribed to this keyword"},
+				},
+			}
+		}
+	}
+
+	keyword, err := s.keywordRepo.Create(&refractor.ChatKeyword{
+		UserID:  body.UserMeta.UserID,
+		Keyword: body.Keyword,
+	})
+	if err != nil {
+		s.log.Error("Could not create chat keyword for user ID %d. Error: %v", body.UserMeta.UserID, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	s.invalidateKeywords()
+
+	return keyword, &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Keyword added",
+	}
+}
+
+func (s *chatService) GetUserKeywords(userID int64) ([]*refractor.ChatKeyword, *refractor.ServiceResponse) {
+	keywords, err := s.keywordRepo.FindByUserID(userID)
+	if err != nil && err != refractor.ErrNotFound {
+		s.log.Error("Could not get chat keywords of user ID %d. Error: %v", userID, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	if keywords == nil {
+		keywords = []*refractor.ChatKeyword{}
+	}
+
+	return keywords, &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("Fetched %d keywords", len(keywords)),
+	}
+}
+
+// DeleteKeyword removes one of the user's keyword subscriptions. Users can only remove their own keywords.
+func (s *chatService) DeleteKeyword(id int64, user params.UserMeta) *refractor.ServiceResponse {
+	keyword, err := s.keywordRepo.FindByID(id)
+	if err != nil && err != refractor.ErrNotFound {
+		s.log.Error("Could not get chat keyword ID %d. Error: %v", id, err)
+		return refractor.InternalErrorResponse
+	}
+
+	if keyword == nil || keyword.UserID != user.UserID {
+		return &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    config.MessageInvalidIDProvided,
+		}
+	}
+
+	if err := s.keywordRepo.Delete(id); err != nil {
+		s.log.Error("Could not delete chat keyword ID %d. Error: %v", id, err)
+		return refractor.InternalErrorResponse
+	}
+
+	s.invalidateKeywords()
+
+	return &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Keyword removed",
+	}
+}
+
+// alertKeywordSubscribers sends a direct message to every user subscribed to a keyword said in the message. Each user
+// is sent at most one alert per message containing all of their keywords which matched.
+func (s *chatService) alertKeywordSubscribers(message *refractor.ChatMessage) {
+	matched := map[int64][]string{}
+	var userIDs []int64
+
+	for _, compiled := range s.getKeywords() {
+		if !compiled.regex.MatchString(message.Message) {
+			continue
+		}
+
+		userID := compiled.keyword.UserID
+		if matched[userID] == nil {
+			userIDs = append(userIDs, userID)
+		}
+
+		matched[userID] = append(matched[userID], compiled.keyword.Keyword)
+	}
+
+	for _, userID := range userIDs {
+		s.websocketService.SendDirect(userID, &refractor.WebsocketMessage{
+			Type: "chat-keyword",
+			Body: &chatKeywordBody{
+				Message:  message,
+				Keywords: matched[userID],
+			},
+		})
+	}
+}
+
+// getKeywords returns the compiled keyword subscriptions, loading them from the repository if they aren't cached.
+func (s *chatService) getKeywords() []*compiledKeyword {
+	s.keywordsMu.RLock()
+	if s.keywordsLoaded {
+		defer s.keywordsMu.RUnlock()
+		return s.keywords
+	}
+	s.keywordsMu.RUnlock()
+
+	s.keywordsMu.Lock()
+	defer s.keywordsMu.Unlock()
+
+	keywords, err := s.keywordRepo.FindAll()
+	if err != nil && err != refractor.ErrNotFound {
+		s.log.Error("Could not load chat keywords. Error: %v", err)
+		return nil
+	}
+
+	s.keywords = []*compiledKeyword{}
+
+	for _, keyword := range keywords {
+		s.keywords = append(s.keywords, &compiledKeyword{
+			keyword: keyword,
+			regex:   compileKeyword(keyword.Keyword),
+		})
+	}
+
+	s.keywordsLoaded = true
+
+	return s.keywords
+}
+
+func (s *chatService) invalidateKeywords() {
+	s.keywordsMu.Lock()
+	s.keywords = nil
+	s.keywordsLoaded = false
+	s.keywordsMu.Unlock()
+}
+
+// compileKeyword builds a case insensitive regex which matches the keyword as a whole word or phrase, so that a
+// keyword of "admin" matches "is an admin on?" but not "badminton".
+func compileKeyword(keyword string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(^|[^\pL\pN])` + regexp.QuoteMeta(keyword) + `($|[^\pL\pN])`)
+}
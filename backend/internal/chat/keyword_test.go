@@ -0,0 +1,161 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chat
+
+import (
+	"fmt"
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func Test_chatService_AddKeyword(t *testing.T) {
+	fullKeywords := map[int64]*refractor.ChatKeyword{}
+	for i := 1; i <= config.ChatKeywordMaxPerUser; i++ {
+		fullKeywords[int64(i)] = &refractor.ChatKeyword{KeywordID: int64(i), UserID: 1, Keyword: fmt.Sprintf("word%d", i)}
+	}
+
+	tests := []struct {
+		name           string
+		keywords       map[int64]*refractor.ChatKeyword
+		body           params.AddChatKeywordParams
+		wantStatusCode int
+	}{
+		{
+			name:           "chat.addkeyword.1",
+			keywords:       map[int64]*refractor.ChatKeyword{},
+			body:           params.AddChatKeywordParams{Keyword: "hacker", UserMeta: &params.UserMeta{UserID: 1}},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "chat.addkeyword.2",
+			keywords: map[int64]*refractor.ChatKeyword{
+				1: {KeywordID: 1, UserID: 1, Keyword: "hacker"},
+			},
+			body:           params.AddChatKeywordParams{Keyword: "HACKER", UserMeta: &params.UserMeta{UserID: 1}},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "chat.addkeyword.3",
+			keywords: map[int64]*refractor.ChatKeyword{
+				1: {KeywordID: 1, UserID: 2, Keyword: "hacker"},
+			},
+			body:           params.AddChatKeywordParams{Keyword: "hacker", UserMeta: &params.UserMeta{UserID: 1}},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "chat.addkeyword.4",
+			keywords:       fullKeywords,
+			body:           params.AddChatKeywordParams{Keyword: "hacker", UserMeta: &params.UserMeta{UserID: 1}},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testLogger, _ := log.NewLogger(true, false)
+
+			chatService := NewChatService(nil, mock.NewMockChatKeywordRepository(tt.keywords), nil, nil, nil, nil, nil,
+				nil, nil, nil, nil, nil, testLogger)
+
+			_, res := chatService.AddKeyword(tt.body)
+			assert.Equal(t, tt.wantStatusCode, res.StatusCode)
+		})
+	}
+}
+
+func Test_chatService_DeleteKeyword(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             int64
+		userID         int64
+		wantStatusCode int
+	}{
+		{"chat.deletekeyword.1", 1, 1, http.StatusOK},
+		{"chat.deletekeyword.2", 1, 2, http.StatusBadRequest},
+		{"chat.deletekeyword.3", 5, 1, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testLogger, _ := log.NewLogger(true, false)
+
+			mockKeywords := map[int64]*refractor.ChatKeyword{
+				1: {KeywordID: 1, UserID: 1, Keyword: "hacker"},
+			}
+
+			chatService := NewChatService(nil, mock.NewMockChatKeywordRepository(mockKeywords), nil, nil, nil, nil, nil,
+				nil, nil, nil, nil, nil, testLogger)
+
+			res := chatService.DeleteKeyword(tt.id, params.UserMeta{UserID: tt.userID})
+			assert.Equal(t, tt.wantStatusCode, res.StatusCode)
+		})
+	}
+}
+
+func Test_chatService_alertKeywordSubscribers(t *testing.T) {
+	mockKeywords := map[int64]*refractor.ChatKeyword{
+		1: {KeywordID: 1, UserID: 1, Keyword: "admin"},
+		2: {KeywordID: 2, UserID: 1, Keyword: "hacker"},
+		3: {KeywordID: 3, UserID: 2, Keyword: "Some Player"},
+		4: {KeywordID: 4, UserID: 3, Keyword: "hacker"},
+	}
+
+	tests := []struct {
+		name         string
+		message      string
+		wantKeywords map[int64][]string
+	}{
+		{"chat.alertkeywords.1", "admin there's a HACKER on red team", map[int64][]string{
+			1: {"admin", "hacker"},
+			3: {"hacker"},
+		}},
+		{"chat.alertkeywords.2", "anyone up for badminton?", map[int64][]string{}},
+		{"chat.alertkeywords.3", "gg some player, nice one", map[int64][]string{
+			2: {"Some Player"},
+		}},
+		{"chat.alertkeywords.4", "is an admin on?", map[int64][]string{
+			1: {"admin"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testLogger, _ := log.NewLogger(true, false)
+
+			mockWebsocketService := mock.NewMockWebsocketService()
+
+			chatService := NewChatService(nil, mock.NewMockChatKeywordRepository(mockKeywords), nil, nil, nil, nil,
+				mockWebsocketService, nil, nil, nil, nil, nil, testLogger).(*chatService)
+
+			chatService.alertKeywordSubscribers(&refractor.ChatMessage{MessageID: 1, ServerID: 1, Message: tt.message})
+
+			gotKeywords := map[int64][]string{}
+			for userID, messages := range mockWebsocketService.Direct {
+				assert.Len(t, messages, 1)
+				assert.Equal(t, "chat-keyword", messages[0].Type)
+
+				gotKeywords[userID] = messages[0].Body.(*chatKeywordBody).Keywords
+			}
+
+			assert.Equal(t, tt.wantKeywords, gotKeywords)
+		})
+	}
+}
@@ -0,0 +1,183 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chat
+
+import (
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/refractor"
+	"strings"
+)
+
+// muteViolationCount is the number of messages a player has sent while muted by a mute
+type muteViolationCount struct {
+	muteID int64
+	count  int
+}
+
+type muteViolationBody struct {
+	Message      *refractor.ChatMessage `json:"message"`
+	InfractionID int64                  `json:"infractionId"`
+	Action       string                 `json:"action"`
+}
+
+// enforceMute acts on a message sent by a player while mute was active on a server running a game without native mute
+// support. The action taken depends on the server's mute action. Players are reminded that they are muted by default.
+func (s *chatService) enforceMute(message *refractor.ChatMessage, playerGameID string, mute *refractor.Infraction) {
+	action := params.MuteActionRemind
+	if server, _ := s.serverService.GetServerByID(message.ServerID); server != nil && server.MuteAction != "" {
+		action = server.MuteAction
+	}
+
+	kick := false
+
+	switch action {
+	case params.MuteActionKick:
+		kick = true
+	case params.MuteActionEscalate:
+		kick = s.recordMuteViolation(message.ServerID, message.PlayerID, mute.InfractionID) >=
+			config.MuteEscalationThreshold
+	}
+
+	s.log.Info("Player ID %d chatted on server ID %d while muted by mute ID %d (%s)", message.PlayerID,
+		message.ServerID, mute.InfractionID, action)
+
+	s.websocketService.Broadcast(&refractor.WebsocketMessage{
+		Type: "mute-violation",
+		Body: &muteViolationBody{
+			Message:      message,
+			InfractionID: mute.InfractionID,
+			Action:       action,
+		},
+	})
+
+	if kick {
+		s.kickMutedPlayer(message, playerGameID, mute)
+		return
+	}
+
+	s.remindMutedPlayer(message, playerGameID, mute)
+}
+
+// remindMutedPlayer tells a player that they are muted. The reminder is whispered to the player or, if the game does
+// not support whispers, said in the server chat.
+func (s *chatService) remindMutedPlayer(message *refractor.ChatMessage, playerGameID string, mute *refractor.Infraction) {
+	game := s.getServerGame(message.ServerID)
+	if game == nil {
+		return
+	}
+
+	reason := mute.Reason
+	if reason == "" {
+		reason = "no reason given"
+	}
+
+	reminder := strings.NewReplacer(
+		"{player}", message.PlayerName,
+		"{reason}", reason,
+	).Replace(config.MuteReminderMessage)
+
+	command := game.GetWhisperCommand(refractor.CommandArgs{
+		PlayerID: playerGameID,
+		Message:  reminder,
+	})
+
+	if command == "" {
+		command = game.GetBroadcastCommand(refractor.CommandArgs{
+			Message: reminder,
+		})
+	}
+
+	if _, err := s.rconService.ExecCommand(message.ServerID, command); err != nil {
+		s.log.Error("Could not remind player ID %d of their mute on server ID %d. Error: %v", message.PlayerID,
+			message.ServerID, err)
+	}
+}
+
+// kickMutedPlayer kicks a player for chatting while muted. The kick is recorded as a system infraction.
+func (s *chatService) kickMutedPlayer(message *refractor.ChatMessage, playerGameID string, mute *refractor.Infraction) {
+	s.clearMuteViolations(message.ServerID, message.PlayerID)
+
+	_, res := s.infractionService.CreateSystemInfraction(0, message.PlayerID, message.ServerID,
+		refractor.INFRACTION_TYPE_KICK, config.MuteKickReason, 0)
+	if !res.Success {
+		s.log.Warn("Could not create automatic kick for player ID %d. %s", message.PlayerID, res.Message)
+		return
+	}
+
+	game := s.getServerGame(message.ServerID)
+	if game == nil {
+		return
+	}
+
+	command := game.GetKickCommand(refractor.CommandArgs{
+		PlayerID: playerGameID,
+		Reason:   config.MuteKickReason,
+	})
+	if command == "" {
+		return
+	}
+
+	if _, err := s.rconService.ExecCommand(message.ServerID, command); err != nil {
+		s.log.Error("Could not kick muted player ID %d from server ID %d. Error: %v", message.PlayerID,
+			message.ServerID, err)
+	}
+}
+
+// recordMuteViolation increments and returns the number of messages a muted player has sent on a server while muted
+// by a mute. The count starts over when the player is muted by a different mute so that messages sent under an
+// earlier mute don't count towards a kick.
+func (s *chatService) recordMuteViolation(serverID int64, playerID int64, muteID int64) int {
+	s.muteViolationsMu.Lock()
+	defer s.muteViolationsMu.Unlock()
+
+	if s.muteViolations[serverID] == nil {
+		s.muteViolations[serverID] = map[int64]*muteViolationCount{}
+	}
+
+	violations := s.muteViolations[serverID][playerID]
+	if violations == nil || violations.muteID != muteID {
+		violations = &muteViolationCount{muteID: muteID}
+		s.muteViolations[serverID][playerID] = violations
+	}
+
+	violations.count++
+
+	return violations.count
+}
+
+func (s *chatService) clearMuteViolations(serverID int64, playerID int64) {
+	s.muteViolationsMu.Lock()
+	defer s.muteViolationsMu.Unlock()
+
+	if s.muteViolations[serverID] != nil {
+		delete(s.muteViolations[serverID], playerID)
+	}
+}
+
+// getServerGame returns the game a server is running, or nil if it can't be found.
+func (s *chatService) getServerGame(serverID int64) refractor.Game {
+	serverData, _ := s.serverService.GetServerData(serverID)
+	if serverData == nil {
+		return nil
+	}
+
+	game, _ := s.gameService.GetGame(serverData.Game)
+
+	return game
+}
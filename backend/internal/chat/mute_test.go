@@ -0,0 +1,168 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chat
+
+import (
+	"database/sql"
+	"github.com/sniddunc/refractor/internal/game"
+	"github.com/sniddunc/refractor/internal/game/minecraft"
+	"github.com/sniddunc/refractor/internal/infraction"
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/internal/player"
+	"github.com/sniddunc/refractor/internal/server"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_chatService_enforceMute(t *testing.T) {
+	const uuid = "a6dbbd66-4e8e-4a4a-9d3f-3a1f8a7a9f2b"
+	const reminder = "tell " + uuid + " Player1, you are muted (spam). Please do not use chat until your mute expires"
+	const kick = "Kick " + uuid + " Chatting while muted"
+
+	tests := []struct {
+		name         string
+		muteAction   string
+		muteDuration int32
+		messages     int
+		wantCommands []string
+		wantKicks    int
+	}{
+		{
+			name:         "chat.enforcemute.1",
+			muteAction:   "",
+			muteDuration: 60,
+			messages:     2,
+			wantCommands: []string{reminder, reminder},
+		},
+		{
+			name:         "chat.enforcemute.2",
+			muteAction:   params.MuteActionKick,
+			muteDuration: 0,
+			messages:     1,
+			wantCommands: []string{kick},
+			wantKicks:    1,
+		},
+		{
+			name:         "chat.enforcemute.3",
+			muteAction:   params.MuteActionEscalate,
+			muteDuration: 60,
+			messages:     4,
+			wantCommands: []string{reminder, reminder, kick, reminder},
+			wantKicks:    1,
+		},
+		{
+			name:         "chat.enforcemute.4",
+			muteAction:   params.MuteActionRemind,
+			muteDuration: 1,
+			messages:     1,
+			wantCommands: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testLogger, _ := log.NewLogger(true, false)
+
+			gameService := game.NewGameService()
+			gameService.AddGame(minecraft.NewMinecraftGame())
+
+			mockServers := mock.GetMockServers()
+			mockServers[1].MuteAction = tt.muteAction
+
+			serverService := server.NewServerService(mock.NewMockServerRepository(mockServers), gameService, nil,
+				testLogger)
+			serverService.CreateServerData(1, "Minecraft")
+
+			mockPlayerRepo := mock.NewMockPlayerRepository(map[int64]*refractor.DBPlayer{
+				1: {PlayerID: 1, CurrentName: "Player1"},
+			})
+			mockInfractions := map[int64]*refractor.DBInfraction{
+				1: {
+					InfractionID: 1,
+					PlayerID:     1,
+					UserID:       1,
+					ServerID:     1,
+					Type:         refractor.INFRACTION_TYPE_MUTE,
+					Reason:       sql.NullString{String: "spam", Valid: true},
+					Duration:     sql.NullInt32{Int32: tt.muteDuration, Valid: true},
+					Timestamp:    time.Now().Unix() - 600,
+				},
+			}
+			playerService := player.NewPlayerService(mockPlayerRepo, nil, nil, testLogger)
+			infractionService := infraction.NewInfractionService(mock.NewMockInfractionRepository(mockInfractions),
+				playerService, serverService, nil, testLogger)
+			mockRCONService := mock.NewMockRCONService(1)
+
+			chatService := NewChatService(nil, nil, mockPlayerRepo, nil, serverService, gameService,
+				mock.NewMockWebsocketService(), mockRCONService, nil, nil, infractionService, nil,
+				testLogger).(*chatService)
+
+			// Messages are only enforced against while a mute is active
+			mute, _ := infractionService.GetActiveMute(1, 1)
+			if mute == nil {
+				tt.messages = 0
+			}
+
+			for i := 0; i < tt.messages; i++ {
+				chatService.enforceMute(&refractor.ChatMessage{
+					PlayerID:   1,
+					ServerID:   1,
+					Message:    "can anyone hear me",
+					PlayerName: "Player1",
+				}, uuid, mute)
+			}
+
+			kicks := 0
+			for _, i := range mockInfractions {
+				if i.Type == refractor.INFRACTION_TYPE_KICK {
+					kicks++
+
+					// Automatic kicks aren't attributed to the user who issued the mute
+					assert.Equal(t, int64(0), i.UserID)
+				}
+			}
+
+			assert.Equal(t, tt.wantCommands, mockRCONService.Commands[1])
+			assert.Equal(t, tt.wantKicks, kicks)
+		})
+	}
+}
+
+func Test_chatService_recordMuteViolation(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	chatService := NewChatService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		testLogger).(*chatService)
+
+	assert.Equal(t, 1, chatService.recordMuteViolation(1, 1, 1))
+	assert.Equal(t, 2, chatService.recordMuteViolation(1, 1, 1))
+
+	// Violations are counted per server and player
+	assert.Equal(t, 1, chatService.recordMuteViolation(2, 1, 1))
+	assert.Equal(t, 1, chatService.recordMuteViolation(1, 2, 3))
+
+	// Violations under an earlier mute don't count towards a new one
+	assert.Equal(t, 1, chatService.recordMuteViolation(1, 1, 2))
+	assert.Equal(t, 2, chatService.recordMuteViolation(1, 1, 2))
+
+	chatService.clearMuteViolations(1, 1)
+	assert.Equal(t, 1, chatService.recordMuteViolation(1, 1, 2))
+}
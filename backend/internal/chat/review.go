@@ -0,0 +1,344 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chat
+
+import (
+	"fmt"
+	"github.com/sniddunc/bitperms"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/perms"
+	"github.com/sniddunc/refractor/refractor"
+	"net/http"
+	"net/url"
+)
+
+func (s *chatService) SetMessageFlagged(id int64, flagged bool, user params.UserMeta) *refractor.ServiceResponse {
+	message, res := s.getMessage(id)
+	if res != nil {
+		return res
+	}
+
+	if flagged && message.SentByUser {
+		return &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    "Messages sent by staff cannot be flagged",
+		}
+	}
+
+	if err := s.repo.SetFlagged(id, flagged); err != nil {
+		s.log.Error("Could not set flagged = %v on chat message ID %d. Error: %v", flagged, id, err)
+		return refractor.InternalErrorResponse
+	}
+
+	s.log.Info("User ID %d set flagged = %v on chat message ID %d", user.UserID, flagged, id)
+
+	resMessage := "Chat message flagged"
+	if !flagged {
+		resMessage = "Chat message unflagged"
+	}
+
+	return &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    resMessage,
+	}
+}
+
+func (s *chatService) GetFlaggedMessages(body params.GetFlaggedMessagesParams) (int, []*refractor.FlaggedChatMessage, *refractor.ServiceResponse) {
+	count, messages, err := s.repo.FindFlagged(body.Limit, body.Offset)
+	if err != nil {
+		s.log.Error("Could not get flagged chat messages. Error: %v", err)
+		return 0, nil, refractor.InternalErrorResponse
+	}
+
+	results := []*refractor.FlaggedChatMessage{}
+
+	for _, message := range messages {
+		context, err := s.repo.GetContext(message, config.ChatReviewContextSize, config.ChatReviewContextSize)
+		if err != nil {
+			s.log.Error("Could not get context for chat message ID %d. Error: %v", message.MessageID, err)
+			return 0, nil, refractor.InternalErrorResponse
+		}
+
+		if err := s.setHistoricalPlayerNames(context); err != nil {
+			s.log.Error("Could not get player names for chat message context. Error: %v", err)
+			return 0, nil, refractor.InternalErrorResponse
+		}
+
+		infractionCount, res := s.playerInfractionService.GetPlayerInfractionCount(message.PlayerID)
+		if !res.Success {
+			return 0, nil, res
+		}
+
+		results = append(results, &refractor.FlaggedChatMessage{
+			Message:         message,
+			Context:         context,
+			InfractionCount: infractionCount,
+		})
+	}
+
+	return count, results, &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("Fetched %d flagged chat messages", len(results)),
+	}
+}
+
+func (s *chatService) DismissFlaggedMessage(id int64, user params.UserMeta) *refractor.ServiceResponse {
+	message, res := s.getMessage(id)
+	if res != nil {
+		return res
+	}
+
+	if !message.Flagged {
+		return &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    "This chat message is not flagged",
+		}
+	}
+
+	if err := s.repo.SetFlagged(id, false); err != nil {
+		s.log.Error("Could not dismiss flagged chat message ID %d. Error: %v", id, err)
+		return refractor.InternalErrorResponse
+	}
+
+	s.log.Info("User ID %d dismissed flagged chat message ID %d", user.UserID, id)
+
+	return &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Flagged chat message dismissed",
+	}
+}
+
+// infractionTypePerms holds the permission needed to create each type of infraction
+var infractionTypePerms = map[string]int64{
+	refractor.INFRACTION_TYPE_WARNING: perms.LOG_WARNING,
+	refractor.INFRACTION_TYPE_MUTE:    perms.LOG_MUTE,
+	refractor.INFRACTION_TYPE_KICK:    perms.LOG_KICK,
+	refractor.INFRACTION_TYPE_BAN:     perms.LOG_BAN,
+}
+
+// CreateInfractionFromMessage creates an infraction against the player who sent a chat message, attaches the
+// message to it and removes the message from the review queue.
+func (s *chatService) CreateInfractionFromMessage(id int64, body params.CreateChatInfractionParams) (*refractor.Infraction, *refractor.ServiceResponse) {
+	requiredPerm, ok := infractionTypePerms[body.Type]
+	if !ok {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			ValidationErrors: url.Values{
+				"type": []string{"Invalid infraction type"},
+			},
+		}
+	}
+
+	userPerms := bitperms.PermissionValue(body.UserMeta.Permissions)
+	if !userPerms.HasFlag(requiredPerm) && !perms.UserHasFullAccess(userPerms) {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    config.MessageNoPermission,
+		}
+	}
+
+	message, res := s.getMessage(id)
+	if res != nil {
+		return nil, res
+	}
+
+	if message.SentByUser {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    "Infractions cannot be created from messages sent by staff",
+		}
+	}
+
+	userID := body.UserMeta.UserID
+
+	var infraction *refractor.Infraction
+
+	switch body.Type {
+	case refractor.INFRACTION_TYPE_WARNING:
+		infraction, res = s.infractionService.CreateWarning(userID, params.CreateWarningParams{
+			PlayerID: message.PlayerID,
+			ServerID: message.ServerID,
+			Reason:   body.Reason,
+		})
+	case refractor.INFRACTION_TYPE_MUTE:
+		infraction, res = s.infractionService.CreateMute(userID, params.CreateMuteParams{
+			PlayerID: message.PlayerID,
+			ServerID: message.ServerID,
+			Reason:   body.Reason,
+			Duration: body.Duration,
+		})
+	case refractor.INFRACTION_TYPE_KICK:
+		infraction, res = s.infractionService.CreateKick(userID, params.CreateKickParams{
+			PlayerID: message.PlayerID,
+			ServerID: message.ServerID,
+			Reason:   body.Reason,
+		})
+	case refractor.INFRACTION_TYPE_BAN:
+		infraction, res = s.infractionService.CreateBan(userID, params.CreateBanParams{
+			PlayerID: message.PlayerID,
+			ServerID: message.ServerID,
+			Reason:   body.Reason,
+			Duration: body.Duration,
+		})
+	}
+
+	if !res.Success {
+		return nil, res
+	}
+
+	if err := s.repo.AttachToInfraction(message.MessageID, infraction.InfractionID); err != nil {
+		s.log.Error("Could not attach chat message ID %d to infraction ID %d. Error: %v", message.MessageID,
+			infraction.InfractionID, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	if message.Flagged {
+		if err := s.repo.SetFlagged(message.MessageID, false); err != nil {
+			s.log.Error("Could not unflag chat message ID %d. Error: %v", message.MessageID, err)
+			return nil, refractor.InternalErrorResponse
+		}
+	}
+
+	s.log.Info("User ID %d created infraction ID %d from chat message ID %d", userID, infraction.InfractionID,
+		message.MessageID)
+
+	return infraction, &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Infraction created",
+	}
+}
+
+func (s *chatService) GetInfractionMessages(infractionID int64) ([]*refractor.ChatMessage, *refractor.ServiceResponse) {
+	messages, err := s.repo.FindByInfractionID(infractionID)
+	if err != nil {
+		s.log.Error("Could not get chat messages for infraction ID %d. Error: %v", infractionID, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	if err := s.setPlayerNames(messages); err != nil {
+		s.log.Error("Could not get player names for infraction chat messages. Error: %v", err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	return messages, &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("Fetched %d chat messages", len(messages)),
+	}
+}
+
+func (s *chatService) GetMessageContext(id int64, body params.GetChatContextParams) ([]*refractor.ChatMessage, *refractor.ServiceResponse) {
+	message, res := s.getMessage(id)
+	if res != nil {
+		return nil, res
+	}
+
+	message.IsTarget = true
+
+	context, err := s.repo.GetContext(message, body.Before, body.After)
+	if err != nil {
+		s.log.Error("Could not get context for chat message ID %d. Error: %v", id, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	if err := s.setHistoricalPlayerNames(context); err != nil {
+		s.log.Error("Could not get player names for chat message context. Error: %v", err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	return context, &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("Fetched %d chat messages", len(context)),
+	}
+}
+
+// getMessage gets a chat message by its ID. If the message could not be fetched, a response to return is given back.
+func (s *chatService) getMessage(id int64) (*refractor.ChatMessage, *refractor.ServiceResponse) {
+	message, err := s.repo.FindByID(id)
+	if err != nil {
+		if err == refractor.ErrNotFound {
+			return nil, &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+				Message:    config.MessageInvalidIDProvided,
+			}
+		}
+
+		s.log.Error("Could not get chat message by ID %d. Error: %v", id, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	return message, nil
+}
+
+// setPlayerNames sets the PlayerName field of each message to the current name of the player who sent it. Messages
+// which already have the name their sender was using stored with them are left alone.
+func (s *chatService) setPlayerNames(messages []*refractor.ChatMessage) error {
+	names := map[int64]string{}
+
+	for _, message := range messages {
+		if message.PlayerName != "" || message.SentByUser {
+			continue
+		}
+
+		name, ok := names[message.PlayerID]
+		if !ok {
+			currentName, _, err := s.playerRepo.GetPlayerNames(message.PlayerID)
+			if err != nil {
+				return err
+			}
+
+			name = currentName
+			names[message.PlayerID] = name
+		}
+
+		message.PlayerName = name
+	}
+
+	return nil
+}
+
+// setHistoricalPlayerNames sets the PlayerName field of each message to the name its sender was using when the message
+// was sent. Messages which already have the name stored with them are left alone.
+func (s *chatService) setHistoricalPlayerNames(messages []*refractor.ChatMessage) error {
+	for _, message := range messages {
+		if message.PlayerName != "" || message.SentByUser {
+			continue
+		}
+
+		name, err := s.playerRepo.GetNameAt(message.PlayerID, message.DateRecorded)
+		if err != nil {
+			return err
+		}
+
+		message.PlayerName = name
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chat
+
+import (
+	"github.com/sniddunc/refractor/internal/infraction"
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/internal/player"
+	"github.com/sniddunc/refractor/internal/playerinfraction"
+	"github.com/sniddunc/refractor/internal/server"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/pkg/perms"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newTestChatService(mockMessages map[int64]*refractor.ChatMessage) (refractor.ChatService, refractor.ChatRepository) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	mockPlayerRepo := mock.NewMockPlayerRepository(map[int64]*refractor.DBPlayer{
+		1: {PlayerID: 1, CurrentName: "Player1"},
+		2: {PlayerID: 2, CurrentName: "Player2"},
+	})
+	mockInfractionRepo := mock.NewMockInfractionRepository(map[int64]*refractor.DBInfraction{
+		1: {InfractionID: 1, PlayerID: 2, Type: refractor.INFRACTION_TYPE_WARNING},
+	})
+	mockChatRepo := mock.NewMockChatRepo(mockMessages)
+
+	playerService := player.NewPlayerService(mockPlayerRepo, nil, nil, testLogger)
+	serverService := server.NewServerService(mock.NewMockServerRepository(mock.GetMockServers()), nil, nil, testLogger)
+	infractionService := infraction.NewInfractionService(mockInfractionRepo, playerService, serverService, nil, testLogger)
+	playerInfractionService := playerinfraction.NewPlayerInfractionService(mockPlayerRepo, mockInfractionRepo, testLogger)
+
+	chatService := NewChatService(mockChatRepo, nil, mockPlayerRepo, nil, serverService, nil, nil, nil, nil,
+		nil, infractionService, playerInfractionService, testLogger)
+
+	return chatService, mockChatRepo
+}
+
+func getTestMessages() map[int64]*refractor.ChatMessage {
+	return map[int64]*refractor.ChatMessage{
+		1: {MessageID: 1, PlayerID: 1, ServerID: 1, Message: "hello", DateRecorded: 100},
+		2: {MessageID: 2, PlayerID: 2, ServerID: 1, Message: "something bad", DateRecorded: 101, Flagged: true},
+		3: {MessageID: 3, PlayerID: 1, ServerID: 2, Message: "other server", DateRecorded: 102},
+		4: {MessageID: 4, PlayerID: 1, ServerID: 1, Message: "please stop", DateRecorded: 103},
+	}
+}
+
+func Test_chatService_GetFlaggedMessages(t *testing.T) {
+	chatService, _ := newTestChatService(getTestMessages())
+
+	total, flagged, res := chatService.GetFlaggedMessages(params.GetFlaggedMessagesParams{Limit: 10})
+	assert.True(t, res.Success)
+	assert.Equal(t, 1, total)
+	assert.Len(t, flagged, 1)
+
+	item := flagged[0]
+	assert.Equal(t, int64(2), item.Message.MessageID)
+	assert.Equal(t, 1, item.InfractionCount)
+
+	var contextIDs []int64
+	for _, message := range item.Context {
+		contextIDs = append(contextIDs, message.MessageID)
+	}
+
+	// Messages from other servers should not be part of the context
+	assert.Equal(t, []int64{1, 2, 4}, contextIDs)
+	assert.Equal(t, "Player1", item.Context[0].PlayerName)
+}
+
+func Test_chatService_CreateInfractionFromMessage(t *testing.T) {
+	chatService, chatRepo := newTestChatService(getTestMessages())
+
+	body := params.CreateChatInfractionParams{
+		Type:     refractor.INFRACTION_TYPE_MUTE,
+		Reason:   "Chat abuse",
+		Duration: 10,
+		UserMeta: &params.UserMeta{UserID: 1, Permissions: perms.LOG_WARNING},
+	}
+
+	// Missing the LOG_MUTE permission
+	_, res := chatService.CreateInfractionFromMessage(2, body)
+	assert.False(t, res.Success)
+	assert.Equal(t, config.MessageNoPermission, res.Message)
+
+	body.UserMeta.Permissions = perms.LOG_MUTE
+
+	infraction, res := chatService.CreateInfractionFromMessage(2, body)
+	assert.True(t, res.Success)
+	assert.Equal(t, int64(2), infraction.PlayerID)
+
+	message, _ := chatRepo.FindByID(2)
+	assert.False(t, message.Flagged)
+
+	attached, res := chatService.GetInfractionMessages(infraction.InfractionID)
+	assert.True(t, res.Success)
+	assert.Len(t, attached, 1)
+	assert.Equal(t, int64(2), attached[0].MessageID)
+	assert.Equal(t, "Player2", attached[0].PlayerName)
+}
+
+func Test_chatService_GetInfractionMessages(t *testing.T) {
+	messages := getTestMessages()
+
+	// The player was renamed to Player2 after sending the message
+	messages[2].PlayerName = "OldName"
+
+	chatService, _ := newTestChatService(messages)
+
+	infraction, res := chatService.CreateInfractionFromMessage(2, params.CreateChatInfractionParams{
+		Type:     refractor.INFRACTION_TYPE_WARNING,
+		Reason:   "Chat abuse",
+		UserMeta: &params.UserMeta{UserID: 1, Permissions: perms.LOG_WARNING},
+	})
+	assert.True(t, res.Success)
+
+	attached, res := chatService.GetInfractionMessages(infraction.InfractionID)
+	assert.True(t, res.Success)
+	assert.Len(t, attached, 1)
+	assert.Equal(t, "OldName", attached[0].PlayerName)
+}
+
+func Test_chatService_DismissFlaggedMessage(t *testing.T) {
+	chatService, _ := newTestChatService(getTestMessages())
+
+	res := chatService.DismissFlaggedMessage(1, params.UserMeta{UserID: 1})
+	assert.False(t, res.Success)
+
+	res = chatService.DismissFlaggedMessage(2, params.UserMeta{UserID: 1})
+	assert.True(t, res.Success)
+
+	total, _, _ := chatService.GetFlaggedMessages(params.GetFlaggedMessagesParams{Limit: 10})
+	assert.Equal(t, 0, total)
+}
+
+func Test_chatService_GetMessageContext(t *testing.T) {
+	chatService, _ := newTestChatService(getTestMessages())
+
+	messages, res := chatService.GetMessageContext(2, params.GetChatContextParams{Before: 1, After: 1})
+	assert.True(t, res.Success)
+
+	var ids []int64
+	var targets []int64
+	for _, message := range messages {
+		ids = append(ids, message.MessageID)
+
+		if message.IsTarget {
+			targets = append(targets, message.MessageID)
+		}
+	}
+
+	assert.Equal(t, []int64{1, 2, 4}, ids)
+	assert.Equal(t, []int64{2}, targets)
+	assert.Equal(t, "Player2", messages[1].PlayerName)
+
+	_, res = chatService.GetMessageContext(100, params.GetChatContextParams{Before: 1, After: 1})
+	assert.False(t, res.Success)
+}
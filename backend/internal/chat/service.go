@@ -18,28 +18,60 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package chat
 
 import (
-	"fmt"
 	"github.com/sniddunc/refractor/pkg/log"
 	"github.com/sniddunc/refractor/refractor"
 	"net/http"
+	"sync"
 )
 
 type chatService struct {
-	repo             refractor.ChatRepository
-	playerRepo       refractor.PlayerRepository
-	websocketService refractor.WebsocketService
-	rconService      refractor.RCONService
-	log              log.Logger
+	repo                    refractor.ChatRepository
+	keywordRepo             refractor.ChatKeywordRepository
+	playerRepo              refractor.PlayerRepository
+	userRepo                refractor.UserRepository
+	serverService           refractor.ServerService
+	gameService             refractor.GameService
+	websocketService        refractor.WebsocketService
+	rconService             refractor.RCONService
+	filterService           refractor.ChatFilterService
+	spamService             refractor.ChatSpamService
+	infractionService       refractor.InfractionService
+	playerInfractionService refractor.PlayerInfractionService
+	log                     log.Logger
+
+	// keywords is a cache of the compiled keyword subscriptions. It is cleared whenever a keyword is added or removed
+	// and is rebuilt the next time a message is checked.
+	keywords       []*compiledKeyword
+	keywordsLoaded bool
+	keywordsMu     sync.RWMutex
+
+	// muteViolations counts the messages sent by muted players on each server under their current mute. It is only
+	// used for servers running games without native mute support.
+	muteViolations   map[int64]map[int64]*muteViolationCount
+	muteViolationsMu sync.Mutex
 }
 
-func NewChatService(chatRepo refractor.ChatRepository, playerRepo refractor.PlayerRepository,
-	websocketService refractor.WebsocketService, rconService refractor.RCONService, log log.Logger) refractor.ChatService {
+func NewChatService(chatRepo refractor.ChatRepository, keywordRepo refractor.ChatKeywordRepository,
+	playerRepo refractor.PlayerRepository, userRepo refractor.UserRepository, serverService refractor.ServerService,
+	gameService refractor.GameService, websocketService refractor.WebsocketService, rconService refractor.RCONService,
+	filterService refractor.ChatFilterService, spamService refractor.ChatSpamService,
+	infractionService refractor.InfractionService, playerInfractionService refractor.PlayerInfractionService,
+	log log.Logger) refractor.ChatService {
 	return &chatService{
-		repo:             chatRepo,
-		playerRepo:       playerRepo,
-		websocketService: websocketService,
-		rconService:      rconService,
-		log:              log,
+		repo:                    chatRepo,
+		keywordRepo:             keywordRepo,
+		playerRepo:              playerRepo,
+		userRepo:                userRepo,
+		serverService:           serverService,
+		gameService:             gameService,
+		websocketService:        websocketService,
+		rconService:             rconService,
+		filterService:           filterService,
+		spamService:             spamService,
+		infractionService:       infractionService,
+		playerInfractionService: playerInfractionService,
+		log:                     log,
+		muteViolations:          map[int64]map[int64]*muteViolationCount{},
 	}
 }
 
@@ -62,12 +94,36 @@ func (s *chatService) OnChatReceive(message *refractor.ChatReceiveBody, serverID
 		return
 	}
 
+	// Check for an active mute before the chat filters run so that a mute issued by a filter in response to this message
+	// isn't enforced against the same message
+	var activeMute *refractor.Infraction
+	if !gameConfig.SupportsNativeMute {
+		activeMute, _ = s.infractionService.GetActiveMute(player.PlayerID, serverID)
+	}
+
+	// Run the message through the chat filters
+	matches := s.filterService.CheckMessage(serverID, message.Message)
+
 	// Log chat message
-	_, _ = s.LogMessage(&refractor.ChatMessage{
-		PlayerID: player.PlayerID,
-		ServerID: serverID,
-		Message:  message.Message,
+	newMessage, res := s.LogMessage(&refractor.ChatMessage{
+		PlayerID:   player.PlayerID,
+		ServerID:   serverID,
+		Message:    message.Message,
+		Flagged:    len(matches) > 0,
+		Channel:    message.Channel,
+		PlayerName: message.Name,
 	})
+	if !res.Success {
+		return
+	}
+
+	s.filterService.HandleMatches(newMessage, message.PlayerGameID, matches)
+	s.spamService.CheckMessage(newMessage, player, message.PlayerGameID)
+	s.alertKeywordSubscribers(newMessage)
+
+	if activeMute != nil {
+		s.enforceMute(newMessage, message.PlayerGameID, activeMute)
+	}
 }
 
 func (s *chatService) OnUserSendChat(msgBody *refractor.ChatSendBody) {
@@ -75,7 +131,16 @@ func (s *chatService) OnUserSendChat(msgBody *refractor.ChatSendBody) {
 		return
 	}
 
-	fmt.Println("A chat message was sent", msgBody.SentByUser)
+	// Staff messages are stored under the sender's username so that they can be told apart from player messages
+	if _, err := s.repo.Create(&refractor.ChatMessage{
+		UserID:     msgBody.UserID,
+		ServerID:   msgBody.ServerID,
+		Message:    msgBody.Message,
+		PlayerName: msgBody.Sender,
+		SentByUser: true,
+	}); err != nil {
+		s.log.Error("Could not store chat message sent by user ID %d. Error: %v", msgBody.UserID, err)
+	}
 
 	s.websocketService.Broadcast(&refractor.WebsocketMessage{
 		Type: "chat",
@@ -90,21 +155,23 @@ func (s *chatService) OnUserSendChat(msgBody *refractor.ChatSendBody) {
 }
 
 func (s *chatService) LogMessage(message *refractor.ChatMessage) (*refractor.ChatMessage, *refractor.ServiceResponse) {
-	newMessage, err := s.repo.Create(message)
-	if err != nil {
-		s.log.Error("Could not create chat message. Error: %v", err)
-		return nil, refractor.InternalErrorResponse
+	// If the name the message was sent under is unknown, the player's current name is the best we have
+	if message.PlayerName == "" {
+		currentName, _, err := s.playerRepo.GetPlayerNames(message.PlayerID)
+		if err != nil {
+			s.log.Error("Could not get player's current name. Error: %v", err)
+			return nil, refractor.InternalErrorResponse
+		}
+
+		message.PlayerName = currentName
 	}
 
-	// Get player name
-	currentName, _, err := s.playerRepo.GetPlayerNames(newMessage.PlayerID)
+	newMessage, err := s.repo.Create(message)
 	if err != nil {
-		s.log.Error("Could not get player's current name. Error: %v", err)
+		s.log.Error("Could not create chat message. Error: %v", err)
 		return nil, refractor.InternalErrorResponse
 	}
 
-	newMessage.PlayerName = currentName
-
 	return newMessage, &refractor.ServiceResponse{
 		Success:    true,
 		StatusCode: http.StatusOK,
@@ -0,0 +1,98 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chat
+
+import (
+	"bytes"
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_chatService_LogMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  *refractor.ChatMessage
+		wantName string
+	}{
+		{
+			name:     "chat.logmessage.1",
+			message:  &refractor.ChatMessage{PlayerID: 1, ServerID: 1, Message: "hi", Channel: "All", PlayerName: "OldName"},
+			wantName: "OldName",
+		},
+		{
+			name:     "chat.logmessage.2",
+			message:  &refractor.ChatMessage{PlayerID: 2, ServerID: 1, Message: "hi"},
+			wantName: "Player2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatService, chatRepo := newTestChatService(map[int64]*refractor.ChatMessage{})
+
+			message, res := chatService.LogMessage(tt.message)
+			assert.True(t, res.Success)
+			assert.Equal(t, tt.wantName, message.PlayerName)
+
+			stored, err := chatRepo.FindByID(message.MessageID)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantName, stored.PlayerName)
+			assert.Equal(t, tt.message.Channel, stored.Channel)
+		})
+	}
+}
+
+func Test_chatService_OnUserSendChat(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	mockChatRepo := mock.NewMockChatRepo(map[int64]*refractor.ChatMessage{})
+	mockWebsocketService := mock.NewMockWebsocketService()
+
+	chatService := NewChatService(mockChatRepo, nil, nil, nil, nil, nil, mockWebsocketService, nil, nil, nil, nil,
+		nil, testLogger)
+
+	chatService.OnUserSendChat(&refractor.ChatSendBody{
+		ServerID:   1,
+		UserID:     3,
+		Message:    "please stop spamming",
+		Sender:     "admin",
+		SentByUser: true,
+	})
+
+	stored, err := mockChatRepo.FindByID(1)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), stored.UserID)
+	assert.Equal(t, int64(0), stored.PlayerID)
+	assert.Equal(t, int64(1), stored.ServerID)
+	assert.Equal(t, "admin", stored.PlayerName)
+	assert.True(t, stored.SentByUser)
+	assert.Len(t, mockWebsocketService.Broadcasts, 1)
+
+	// Staff messages can be exported but not flagged
+	buf := &bytes.Buffer{}
+	res := chatService.ExportTranscript(params.ExportTranscriptParams{ServerID: 1, StartDate: 0,
+		EndDate: stored.DateRecorded + 1, Format: params.TranscriptFormatText}, buf)
+	assert.True(t, res.Success)
+	assert.Contains(t, buf.String(), "[Staff] admin: please stop spamming")
+
+	res = chatService.SetMessageFlagged(1, true, params.UserMeta{UserID: 3})
+	assert.False(t, res.Success)
+}
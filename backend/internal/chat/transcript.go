@@ -0,0 +1,184 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chat
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/refractor"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const transcriptTimeFormat = "2006-01-02 15:04:05"
+
+// transcriptWriter writes transcript entries to an underlying writer in a specific format. Entries are written as
+// they are received so that large transcripts never need to be held in memory.
+type transcriptWriter interface {
+	WriteEntry(entry *refractor.TranscriptEntry) error
+	Close() error
+}
+
+func newTranscriptWriter(format string, w io.Writer) transcriptWriter {
+	switch format {
+	case params.TranscriptFormatCSV:
+		return &csvTranscriptWriter{w: csv.NewWriter(w)}
+	case params.TranscriptFormatJSON:
+		return &jsonTranscriptWriter{w: w}
+	default:
+		return &textTranscriptWriter{w: w}
+	}
+}
+
+func (s *chatService) ExportTranscript(body params.ExportTranscriptParams, w io.Writer) *refractor.ServiceResponse {
+	tw := newTranscriptWriter(body.Format, w)
+
+	count := 0
+	err := s.repo.StreamTranscript(body.ServerID, body.StartDate, body.EndDate, func(entry *refractor.TranscriptEntry) error {
+		count++
+		return tw.WriteEntry(entry)
+	})
+	if err == nil {
+		err = tw.Close()
+	}
+
+	if err != nil {
+		s.log.Error("Could not export chat transcript for server ID %d. Error: %v", body.ServerID, err)
+		return refractor.InternalErrorResponse
+	}
+
+	return &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("Exported %d transcript entries", count),
+	}
+}
+
+func formatTranscriptTime(timestamp int64) string {
+	return time.Unix(timestamp, 0).UTC().Format(transcriptTimeFormat)
+}
+
+type textTranscriptWriter struct {
+	w io.Writer
+}
+
+func (tw *textTranscriptWriter) WriteEntry(entry *refractor.TranscriptEntry) error {
+	var line string
+
+	switch entry.Type {
+	case refractor.TRANSCRIPT_ENTRY_JOIN:
+		line = fmt.Sprintf("*** %s joined", entry.PlayerName)
+	case refractor.TRANSCRIPT_ENTRY_QUIT:
+		line = fmt.Sprintf("*** %s left", entry.PlayerName)
+	case refractor.TRANSCRIPT_ENTRY_STAFF_CHAT:
+		line = fmt.Sprintf("[Staff] %s: %s", entry.PlayerName, entry.Message)
+	case refractor.TRANSCRIPT_ENTRY_WHISPER:
+		line = fmt.Sprintf("[Staff] %s -> %s: %s", entry.PlayerName, entry.TargetName, entry.Message)
+	default:
+		line = fmt.Sprintf("%s: %s", entry.PlayerName, entry.Message)
+	}
+
+	_, err := fmt.Fprintf(tw.w, "[%s] %s\n", formatTranscriptTime(entry.Timestamp), line)
+	return err
+}
+
+func (tw *textTranscriptWriter) Close() error {
+	return nil
+}
+
+type csvTranscriptWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (tw *csvTranscriptWriter) writeHeader() error {
+	if tw.headerWritten {
+		return nil
+	}
+
+	tw.headerWritten = true
+	return tw.w.Write([]string{"timestamp", "time", "type", "playerId", "userId", "playerName", "targetPlayerId",
+		"targetName", "message"})
+}
+
+func (tw *csvTranscriptWriter) WriteEntry(entry *refractor.TranscriptEntry) error {
+	if err := tw.writeHeader(); err != nil {
+		return err
+	}
+
+	return tw.w.Write([]string{
+		strconv.FormatInt(entry.Timestamp, 10),
+		formatTranscriptTime(entry.Timestamp),
+		entry.Type,
+		strconv.FormatInt(entry.PlayerID, 10),
+		strconv.FormatInt(entry.UserID, 10),
+		entry.PlayerName,
+		strconv.FormatInt(entry.TargetPlayerID, 10),
+		entry.TargetName,
+		entry.Message,
+	})
+}
+
+func (tw *csvTranscriptWriter) Close() error {
+	if err := tw.writeHeader(); err != nil {
+		return err
+	}
+
+	tw.w.Flush()
+	return tw.w.Error()
+}
+
+// jsonTranscriptWriter writes entries as a single JSON array, one element at a time.
+type jsonTranscriptWriter struct {
+	w       io.Writer
+	started bool
+}
+
+func (tw *jsonTranscriptWriter) WriteEntry(entry *refractor.TranscriptEntry) error {
+	prefix := ","
+	if !tw.started {
+		prefix = "["
+		tw.started = true
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(tw.w, prefix); err != nil {
+		return err
+	}
+
+	_, err = tw.w.Write(data)
+	return err
+}
+
+func (tw *jsonTranscriptWriter) Close() error {
+	if !tw.started {
+		_, err := io.WriteString(tw.w, "[]")
+		return err
+	}
+
+	_, err := io.WriteString(tw.w, "]")
+	return err
+}
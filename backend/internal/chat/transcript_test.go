@@ -0,0 +1,88 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chat
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_chatService_ExportTranscript(t *testing.T) {
+	type args struct {
+		body params.ExportTranscriptParams
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "chat.exporttranscript.1",
+			args: args{
+				body: params.ExportTranscriptParams{ServerID: 1, StartDate: 0, EndDate: 200, Format: params.TranscriptFormatText},
+			},
+			want: "[1970-01-01 00:01:40] : hello\n" +
+				"[1970-01-01 00:01:41] : something bad\n" +
+				"[1970-01-01 00:01:43] : please stop\n",
+		},
+		{
+			name: "chat.exporttranscript.2",
+			args: args{
+				body: params.ExportTranscriptParams{ServerID: 1, StartDate: 101, EndDate: 102, Format: params.TranscriptFormatCSV},
+			},
+			want: "timestamp,time,type,playerId,userId,playerName,targetPlayerId,targetName,message\n" +
+				"101,1970-01-01 00:01:41,CHAT,2,0,,0,,something bad\n",
+		},
+		{
+			name: "chat.exporttranscript.3",
+			args: args{
+				body: params.ExportTranscriptParams{ServerID: 3, StartDate: 0, EndDate: 200, Format: params.TranscriptFormatJSON},
+			},
+			want: "[]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatService, _ := newTestChatService(getTestMessages())
+
+			buf := &bytes.Buffer{}
+			res := chatService.ExportTranscript(tt.args.body, buf)
+
+			assert.True(t, res.Success)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func Test_jsonTranscriptWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tw := newTranscriptWriter(params.TranscriptFormatJSON, buf)
+
+	assert.Nil(t, tw.WriteEntry(&refractor.TranscriptEntry{Type: refractor.TRANSCRIPT_ENTRY_JOIN, Timestamp: 1, PlayerID: 1, PlayerName: "Player1"}))
+	assert.Nil(t, tw.WriteEntry(&refractor.TranscriptEntry{Type: refractor.TRANSCRIPT_ENTRY_CHAT, Timestamp: 2, PlayerID: 1, PlayerName: "Player1", Message: "hi"}))
+	assert.Nil(t, tw.Close())
+
+	var entries []*refractor.TranscriptEntry
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &entries))
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "hi", entries[1].Message)
+}
@@ -0,0 +1,115 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chat
+
+import (
+	"fmt"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/refractor"
+	"strconv"
+	"strings"
+)
+
+// OnInfractionCreate delivers new warnings to the warned player in-game.
+func (s *chatService) OnInfractionCreate(infraction *refractor.Infraction) {
+	if infraction.Type != refractor.INFRACTION_TYPE_WARNING {
+		return
+	}
+
+	s.deliverWarning(infraction)
+}
+
+// deliverWarning tells a player about a warning they received using the server's warn message template. The message
+// is sent with the game's native warn command if it has one. Otherwise, it is whispered to the player or, if the game
+// does not support whispers, broadcast to the server. Warnings can only be delivered to players who are online on the
+// server they were warned on.
+func (s *chatService) deliverWarning(infraction *refractor.Infraction) {
+	playerGameID := s.serverService.GetOnlinePlayerGameID(infraction.ServerID, infraction.PlayerID)
+	if playerGameID == "" {
+		s.log.Info("Warning ID %d was not delivered in-game since player ID %d is not online on server ID %d",
+			infraction.InfractionID, infraction.PlayerID, infraction.ServerID)
+		return
+	}
+
+	serverData, _ := s.serverService.GetServerData(infraction.ServerID)
+	if serverData == nil {
+		return
+	}
+
+	game, _ := s.gameService.GetGame(serverData.Game)
+	if game == nil {
+		return
+	}
+
+	message, err := s.getWarnMessage(infraction)
+	if err != nil {
+		s.log.Error("Could not build warn message for warning ID %d. Error: %v", infraction.InfractionID, err)
+		return
+	}
+
+	command := game.GetWarnCommand(refractor.CommandArgs{
+		PlayerID: playerGameID,
+		Reason:   message,
+	})
+
+	if command == "" {
+		command = game.GetWhisperCommand(refractor.CommandArgs{
+			PlayerID: playerGameID,
+			Message:  message,
+		})
+
+		if command == "" {
+			command = game.GetBroadcastCommand(refractor.CommandArgs{
+				Message: message,
+			})
+		}
+	}
+
+	if _, err := s.rconService.ExecCommand(infraction.ServerID, command); err != nil {
+		s.log.Error("Could not deliver warning ID %d to player ID %d on server ID %d. Error: %v",
+			infraction.InfractionID, infraction.PlayerID, infraction.ServerID, err)
+	}
+}
+
+// getWarnMessage fills in the warn message template of the server a warning was issued on.
+func (s *chatService) getWarnMessage(infraction *refractor.Infraction) (string, error) {
+	messageTemplate := config.DefaultWarnMessageTemplate
+
+	server, _ := s.serverService.GetServerByID(infraction.ServerID)
+	if server != nil && server.WarnMessageTemplate != "" {
+		messageTemplate = server.WarnMessageTemplate
+	}
+
+	warnings, res := s.infractionService.GetPlayerInfractionsType(refractor.INFRACTION_TYPE_WARNING, infraction.PlayerID)
+	if !res.Success {
+		return "", fmt.Errorf("could not get warnings of player ID %d: %s", infraction.PlayerID, res.Message)
+	}
+
+	playerName := infraction.PlayerName
+	if playerName == "" {
+		playerName, _, _ = s.playerRepo.GetPlayerNames(infraction.PlayerID)
+	}
+
+	replacer := strings.NewReplacer(
+		"{player}", playerName,
+		"{reason}", infraction.Reason,
+		"{count}", strconv.Itoa(len(warnings)),
+	)
+
+	return replacer.Replace(messageTemplate), nil
+}
@@ -0,0 +1,121 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chat
+
+import (
+	"github.com/sniddunc/refractor/internal/game"
+	"github.com/sniddunc/refractor/internal/game/minecraft"
+	"github.com/sniddunc/refractor/internal/game/mordhau"
+	"github.com/sniddunc/refractor/internal/game/squad"
+	"github.com/sniddunc/refractor/internal/infraction"
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/internal/player"
+	"github.com/sniddunc/refractor/internal/server"
+	"github.com/sniddunc/refractor/internal/user"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_chatService_OnInfractionCreate(t *testing.T) {
+	tests := []struct {
+		name         string
+		game         refractor.Game
+		onlinePlayer *refractor.Player
+		template     string
+		wantCommands []string
+	}{
+		{
+			name:         "chat.oninfractioncreate.1",
+			game:         mock.NewMockGame(),
+			onlinePlayer: &refractor.Player{PlayerID: 1, PlayFabID: "ABC123"},
+			wantCommands: []string{"mockwarn"},
+		},
+		{
+			name:         "chat.oninfractioncreate.2",
+			game:         minecraft.NewMinecraftGame(),
+			onlinePlayer: &refractor.Player{PlayerID: 1, MCUUID: "a6dbbd66-4e8e-4a4a-9d3f-3a1f8a7a9f2b"},
+			wantCommands: []string{"tell a6dbbd66-4e8e-4a4a-9d3f-3a1f8a7a9f2b Player1, you have been warned: spam (warning #2)"},
+		},
+		{
+			name:         "chat.oninfractioncreate.3",
+			game:         mordhau.NewMordhauGame(),
+			onlinePlayer: &refractor.Player{PlayerID: 1, PlayFabID: "ABC123"},
+			template:     "Warning {count} for {player}: {reason}",
+			wantCommands: []string{"Say Warning 2 for Player1: spam"},
+		},
+		{
+			name:         "chat.oninfractioncreate.4",
+			game:         mordhau.NewMordhauGame(),
+			onlinePlayer: &refractor.Player{PlayerID: 2, PlayFabID: "DEF456"},
+			wantCommands: nil,
+		},
+		{
+			name:         "chat.oninfractioncreate.5",
+			game:         squad.NewSquadGame(),
+			onlinePlayer: &refractor.Player{PlayerID: 1, SteamID: "76561198000000001"},
+			template:     "Warning {count} for {player}: {reason}",
+			wantCommands: []string{"AdminWarn \"76561198000000001\" Warning 2 for Player1: spam"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testLogger, _ := log.NewLogger(true, false)
+
+			gameService := game.NewGameService()
+			gameService.AddGame(tt.game)
+
+			mockServers := mock.GetMockServers()
+			mockServers[1].WarnMessageTemplate = tt.template
+
+			serverService := server.NewServerService(mock.NewMockServerRepository(mockServers), gameService, nil,
+				testLogger)
+			serverService.CreateServerData(1, tt.game.GetName())
+			serverService.OnPlayerJoin(1, tt.onlinePlayer)
+
+			mockPlayerRepo := mock.NewMockPlayerRepository(map[int64]*refractor.DBPlayer{
+				1: {PlayerID: 1, CurrentName: "Player1"},
+				2: {PlayerID: 2, CurrentName: "Player2"},
+			})
+			mockInfractionRepo := mock.NewMockInfractionRepository(map[int64]*refractor.DBInfraction{
+				1: {InfractionID: 1, PlayerID: 1, UserID: 1, ServerID: 1, Type: refractor.INFRACTION_TYPE_WARNING},
+			})
+			userService := user.NewUserService(mock.NewMockUserRepository(mock.GetMockUsers()), testLogger)
+			playerService := player.NewPlayerService(mockPlayerRepo, nil, nil, testLogger)
+			infractionService := infraction.NewInfractionService(mockInfractionRepo, playerService, serverService,
+				userService, testLogger)
+			mockRCONService := mock.NewMockRCONService(1)
+
+			chatService := NewChatService(mock.NewMockChatRepo(map[int64]*refractor.ChatMessage{}), nil, mockPlayerRepo,
+				nil, serverService, gameService, nil, mockRCONService, nil, nil, infractionService, nil,
+				testLogger)
+			infractionService.SubscribeInfractionCreate(chatService.OnInfractionCreate)
+
+			_, res := infractionService.CreateWarning(1, params.CreateWarningParams{
+				PlayerID: 1,
+				ServerID: 1,
+				Reason:   "spam",
+			})
+			assert.True(t, res.Success, res.Message)
+
+			assert.Equal(t, tt.wantCommands, mockRCONService.Commands[1])
+		})
+	}
+}
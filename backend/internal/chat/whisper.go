@@ -0,0 +1,121 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chat
+
+import (
+	"fmt"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/refractor"
+	"net/http"
+)
+
+// OnUserSendWhisper sends a whisper received from a websocket client.
+func (s *chatService) OnUserSendWhisper(msgBody *refractor.WhisperSendBody) {
+	_, res := s.SendWhisper(params.SendWhisperParams{
+		ServerID: msgBody.ServerID,
+		PlayerID: msgBody.PlayerID,
+		Message:  msgBody.Message,
+		UserMeta: &params.UserMeta{
+			UserID: msgBody.UserID,
+		},
+	})
+	if !res.Success {
+		s.log.Warn("Could not send whisper from user ID %d to player ID %d. %s", msgBody.UserID, msgBody.PlayerID,
+			res.Message)
+	}
+}
+
+// SendWhisper privately messages a player who is online on a server and logs the whisper to the chat history.
+func (s *chatService) SendWhisper(body params.SendWhisperParams) (*refractor.ChatMessage, *refractor.ServiceResponse) {
+	serverData, _ := s.serverService.GetServerData(body.ServerID)
+	if serverData == nil {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    config.MessageInvalidIDProvided,
+		}
+	}
+
+	game, _ := s.gameService.GetGame(serverData.Game)
+	if game == nil {
+		s.log.Error("Could not get game %s for server ID %d", serverData.Game, body.ServerID)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	// The player's game ID is needed to address them, and we only have it for players who are online
+	playerGameID := s.serverService.GetOnlinePlayerGameID(body.ServerID, body.PlayerID)
+	if playerGameID == "" {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    "This player is not online on this server",
+		}
+	}
+
+	user, err := s.userRepo.FindByID(body.UserMeta.UserID)
+	if err != nil {
+		s.log.Error("Could not get user by ID %d. Error: %v", body.UserMeta.UserID, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	command := game.GetWhisperCommand(refractor.CommandArgs{
+		PlayerID: playerGameID,
+		Message:  fmt.Sprintf("[%s]: %s", user.Username, body.Message),
+	})
+	if command == "" {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("%s does not support whispers", game.GetName()),
+		}
+	}
+
+	if _, err := s.rconService.ExecCommand(body.ServerID, command); err != nil {
+		s.log.Error("Could not send whisper to player ID %d on server ID %d. Error: %v", body.PlayerID, body.ServerID, err)
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    "The whisper could not be sent to the server",
+		}
+	}
+
+	message, err := s.repo.Create(&refractor.ChatMessage{
+		UserID:         user.UserID,
+		TargetPlayerID: body.PlayerID,
+		ServerID:       body.ServerID,
+		Message:        body.Message,
+		PlayerName:     user.Username,
+		SentByUser:     true,
+	})
+	if err != nil {
+		s.log.Error("Could not store whisper sent by user ID %d. Error: %v", user.UserID, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	s.websocketService.Broadcast(&refractor.WebsocketMessage{
+		Type: "whisper",
+		Body: message,
+	})
+
+	return message, &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Whisper sent",
+	}
+}
@@ -0,0 +1,108 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chat
+
+import (
+	"github.com/sniddunc/refractor/internal/game"
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/internal/server"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func Test_chatService_SendWhisper(t *testing.T) {
+	type args struct {
+		body params.SendWhisperParams
+	}
+	tests := []struct {
+		name           string
+		args           args
+		wantStatusCode int
+		wantCommand    string
+	}{
+		{
+			name: "chat.sendwhisper.1",
+			args: args{
+				body: params.SendWhisperParams{ServerID: 1, PlayerID: 2, Message: "please stop",
+					UserMeta: &params.UserMeta{UserID: 1}},
+			},
+			wantStatusCode: http.StatusOK,
+			wantCommand:    "mockwhisper ABC123 [tester]: please stop",
+		},
+		{
+			name: "chat.sendwhisper.2",
+			args: args{
+				body: params.SendWhisperParams{ServerID: 1, PlayerID: 1, Message: "offline player",
+					UserMeta: &params.UserMeta{UserID: 1}},
+			},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "chat.sendwhisper.3",
+			args: args{
+				body: params.SendWhisperParams{ServerID: 5, PlayerID: 2, Message: "unknown server",
+					UserMeta: &params.UserMeta{UserID: 1}},
+			},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testLogger, _ := log.NewLogger(true, false)
+
+			gameService := game.NewGameService()
+			gameService.AddGame(mock.NewMockGame())
+
+			serverService := server.NewServerService(mock.NewMockServerRepository(mock.GetMockServers()), gameService,
+				nil, testLogger)
+			serverService.CreateServerData(1, mock.NewMockGame().GetName())
+			serverService.OnPlayerJoin(1, &refractor.Player{PlayerID: 2, PlayFabID: "ABC123", CurrentName: "Player2"})
+
+			mockChatRepo := mock.NewMockChatRepo(map[int64]*refractor.ChatMessage{})
+			mockRCONService := mock.NewMockRCONService(1)
+			mockWebsocketService := mock.NewMockWebsocketService()
+
+			chatService := NewChatService(mockChatRepo, nil, nil, mock.NewMockUserRepository(mock.GetMockUsers()),
+				serverService, gameService, mockWebsocketService, mockRCONService, nil, nil, nil, nil,
+				testLogger)
+
+			message, res := chatService.SendWhisper(tt.args.body)
+			assert.Equal(t, tt.wantStatusCode, res.StatusCode, res.Message)
+
+			if tt.wantStatusCode != http.StatusOK {
+				assert.Nil(t, message)
+				assert.Empty(t, mockRCONService.Commands[tt.args.body.ServerID])
+				return
+			}
+
+			assert.Equal(t, []string{tt.wantCommand}, mockRCONService.Commands[tt.args.body.ServerID])
+			assert.Equal(t, tt.args.body.PlayerID, message.TargetPlayerID)
+			assert.Equal(t, int64(1), message.UserID)
+			assert.Equal(t, "tester", message.PlayerName)
+			assert.Len(t, mockWebsocketService.Broadcasts, 1)
+
+			stored, err := mockChatRepo.FindByID(message.MessageID)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.args.body.PlayerID, stored.TargetPlayerID)
+		})
+	}
+}
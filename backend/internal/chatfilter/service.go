@@ -0,0 +1,403 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chatfilter
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/chatfilter"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+type chatFilterService struct {
+	repo              refractor.ChatFilterRepository
+	serverService     refractor.ServerService
+	gameService       refractor.GameService
+	infractionService refractor.InfractionService
+	websocketService  refractor.WebsocketService
+	rconService       refractor.RCONService
+	log               log.Logger
+
+	// rules is a cache of the compiled rules. It is cleared whenever a rule is changed and is rebuilt the next time
+	// a message is checked.
+	rules   []*compiledRule
+	loaded  bool
+	rulesMu sync.RWMutex
+}
+
+type compiledRule struct {
+	rule    *refractor.ChatFilterRule
+	matcher *chatfilter.Matcher
+}
+
+func NewChatFilterService(repo refractor.ChatFilterRepository, serverService refractor.ServerService,
+	gameService refractor.GameService, infractionService refractor.InfractionService,
+	websocketService refractor.WebsocketService, rconService refractor.RCONService, log log.Logger) refractor.ChatFilterService {
+	return &chatFilterService{
+		repo:              repo,
+		serverService:     serverService,
+		gameService:       gameService,
+		infractionService: infractionService,
+		websocketService:  websocketService,
+		rconService:       rconService,
+		log:               log,
+	}
+}
+
+func (s *chatFilterService) CreateRule(body params.CreateChatFilterRuleParams) (*refractor.ChatFilterRule, *refractor.ServiceResponse) {
+	if body.ServerID != 0 {
+		server, _ := s.serverService.GetServerByID(body.ServerID)
+		if server == nil {
+			return nil, &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+				ValidationErrors: url.Values{
+					"serverId": []string{"Invalid server ID"},
+				},
+			}
+		}
+	}
+
+	if body.Game != "" {
+		if exists, _ := s.gameService.GameExists(body.Game); !exists {
+			return nil, &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+				ValidationErrors: url.Values{
+					"game": []string{"Invalid game"},
+				},
+			}
+		}
+	}
+
+	reason := body.Reason
+	if reason == "" {
+		reason = config.ChatFilterDefaultReason
+	}
+
+	newRule := &refractor.DBChatFilterRule{
+		Game:      body.Game,
+		ServerID:  sql.NullInt64{Int64: body.ServerID, Valid: body.ServerID != 0},
+		Type:      body.Type,
+		Pattern:   body.Pattern,
+		Action:    body.Action,
+		Reason:    reason,
+		Duration:  body.Duration,
+		Enabled:   true,
+		CreatedBy: body.UserMeta.UserID,
+	}
+
+	rule, err := s.repo.Create(newRule)
+	if err != nil {
+		s.log.Error("Could not create chat filter rule. Error: %v", err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	s.invalidateRules()
+
+	s.log.Info("User ID %d created chat filter rule ID %d", body.UserMeta.UserID, rule.RuleID)
+
+	return rule, &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Chat filter rule created",
+	}
+}
+
+func (s *chatFilterService) GetAllRules() ([]*refractor.ChatFilterRule, *refractor.ServiceResponse) {
+	rules, err := s.repo.FindAll()
+	if err != nil && err != refractor.ErrNotFound {
+		s.log.Error("Could not get all chat filter rules. Error: %v", err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	if rules == nil {
+		rules = []*refractor.ChatFilterRule{}
+	}
+
+	return rules, &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("Fetched %d chat filter rules", len(rules)),
+	}
+}
+
+func (s *chatFilterService) UpdateRule(id int64, body params.UpdateChatFilterRuleParams) (*refractor.ChatFilterRule, *refractor.ServiceResponse) {
+	rule, err := s.repo.FindByID(id)
+	if err != nil {
+		if err == refractor.ErrNotFound {
+			return nil, &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+				Message:    config.MessageInvalidIDProvided,
+			}
+		}
+
+		s.log.Error("Could not get chat filter rule by ID %d. Error: %v", id, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	updateArgs := refractor.UpdateArgs{}
+
+	if body.Type != nil {
+		updateArgs["Type"] = *body.Type
+		rule.Type = *body.Type
+	}
+
+	if body.Pattern != nil {
+		updateArgs["Pattern"] = *body.Pattern
+		rule.Pattern = *body.Pattern
+	}
+
+	if body.Action != nil {
+		updateArgs["Action"] = *body.Action
+	}
+
+	if body.Reason != nil {
+		reason := *body.Reason
+		if reason == "" {
+			reason = config.ChatFilterDefaultReason
+		}
+
+		updateArgs["Reason"] = reason
+	}
+
+	if body.Duration != nil {
+		updateArgs["Duration"] = *body.Duration
+	}
+
+	if body.Enabled != nil {
+		updateArgs["Enabled"] = *body.Enabled
+	}
+
+	if len(updateArgs) == 0 {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    "No update fields provided",
+		}
+	}
+
+	// The pattern must still be valid for the rule's type once the update is applied
+	if _, err := chatfilter.Compile(rule.Type, rule.Pattern); err != nil {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			ValidationErrors: url.Values{
+				"pattern": []string{"Invalid pattern"},
+			},
+		}
+	}
+
+	updatedRule, err := s.repo.Update(id, updateArgs)
+	if err != nil {
+		s.log.Error("Could not update chat filter rule ID %d. Error: %v", id, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	s.invalidateRules()
+
+	return updatedRule, &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Chat filter rule updated",
+	}
+}
+
+func (s *chatFilterService) DeleteRule(id int64) *refractor.ServiceResponse {
+	if err := s.repo.Delete(id); err != nil {
+		if err == refractor.ErrNotFound {
+			return &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+				Message:    config.MessageInvalidIDProvided,
+			}
+		}
+
+		s.log.Error("Could not delete chat filter rule ID %d. Error: %v", id, err)
+		return refractor.InternalErrorResponse
+	}
+
+	s.invalidateRules()
+
+	return &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Chat filter rule deleted",
+	}
+}
+
+// CheckMessage returns every enabled rule which applies to the server and matches the message.
+func (s *chatFilterService) CheckMessage(serverID int64, message string) []*refractor.ChatFilterRule {
+	var game string
+	if serverData, _ := s.serverService.GetServerData(serverID); serverData != nil {
+		game = serverData.Game
+	}
+
+	var matches []*refractor.ChatFilterRule
+
+	for _, compiled := range s.getRules() {
+		rule := compiled.rule
+
+		if rule.ServerID != 0 && rule.ServerID != serverID {
+			continue
+		}
+
+		if rule.ServerID == 0 && rule.Game != "" && rule.Game != game {
+			continue
+		}
+
+		if compiled.matcher.Match(message) {
+			matches = append(matches, rule)
+		}
+	}
+
+	return matches
+}
+
+type chatFlaggedBody struct {
+	Message *refractor.ChatMessage `json:"message"`
+	RuleID  int64                  `json:"ruleId"`
+	Action  string                 `json:"action"`
+}
+
+// HandleMatches takes the action of the most severe matched rule against the player who sent the message. Only one
+// action is taken per message so that a message matching several rules doesn't result in several infractions.
+func (s *chatFilterService) HandleMatches(message *refractor.ChatMessage, playerGameID string, matches []*refractor.ChatFilterRule) {
+	if len(matches) == 0 {
+		return
+	}
+
+	rule := matches[0]
+	for _, match := range matches[1:] {
+		if chatfilter.Severity(match.Action) > chatfilter.Severity(rule.Action) {
+			rule = match
+		}
+	}
+
+	s.log.Info("Chat message ID %d from player ID %d matched chat filter rule ID %d (%s)", message.MessageID,
+		message.PlayerID, rule.RuleID, rule.Action)
+
+	if chatfilter.Severity(rule.Action) >= chatfilter.Severity(chatfilter.ACTION_ALERT) {
+		s.websocketService.Broadcast(&refractor.WebsocketMessage{
+			Type: "chat-flagged",
+			Body: &chatFlaggedBody{
+				Message: message,
+				RuleID:  rule.RuleID,
+				Action:  rule.Action,
+			},
+		})
+	}
+
+	switch rule.Action {
+	case chatfilter.ACTION_WARN:
+		_, res := s.infractionService.CreateSystemInfraction(0, message.PlayerID, message.ServerID,
+			refractor.INFRACTION_TYPE_WARNING, rule.Reason, 0)
+		if !res.Success {
+			s.log.Warn("Could not create automatic warning for player ID %d. %s", message.PlayerID, res.Message)
+		}
+	case chatfilter.ACTION_MUTE:
+		_, res := s.infractionService.CreateSystemInfraction(0, message.PlayerID, message.ServerID,
+			refractor.INFRACTION_TYPE_MUTE, rule.Reason, rule.Duration)
+		if !res.Success {
+			s.log.Warn("Could not create automatic mute for player ID %d. %s", message.PlayerID, res.Message)
+			return
+		}
+
+		s.mutePlayer(message.ServerID, playerGameID, rule.Duration)
+	}
+}
+
+// mutePlayer runs the server's game mute command against a player
+func (s *chatFilterService) mutePlayer(serverID int64, playerGameID string, duration int) {
+	serverData, _ := s.serverService.GetServerData(serverID)
+	if serverData == nil {
+		return
+	}
+
+	game, _ := s.gameService.GetGame(serverData.Game)
+	if game == nil {
+		return
+	}
+
+	command := game.GetMuteCommand(refractor.CommandArgs{
+		PlayerID: playerGameID,
+		Duration: duration,
+	})
+	if command == "" {
+		return
+	}
+
+	if _, err := s.rconService.ExecCommand(serverID, command); err != nil {
+		s.log.Error("Could not mute player %s on server ID %d. Error: %v", playerGameID, serverID, err)
+	}
+}
+
+// getRules returns the compiled enabled rules, loading them from the repository if they aren't cached.
+func (s *chatFilterService) getRules() []*compiledRule {
+	s.rulesMu.RLock()
+	if s.loaded {
+		defer s.rulesMu.RUnlock()
+		return s.rules
+	}
+	s.rulesMu.RUnlock()
+
+	s.rulesMu.Lock()
+	defer s.rulesMu.Unlock()
+
+	rules, err := s.repo.FindAll()
+	if err != nil && err != refractor.ErrNotFound {
+		s.log.Error("Could not load chat filter rules. Error: %v", err)
+		return nil
+	}
+
+	s.rules = []*compiledRule{}
+
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+
+		matcher, err := chatfilter.Compile(rule.Type, rule.Pattern)
+		if err != nil {
+			s.log.Warn("Skipping chat filter rule ID %d as it could not be compiled. Error: %v", rule.RuleID, err)
+			continue
+		}
+
+		s.rules = append(s.rules, &compiledRule{
+			rule:    rule,
+			matcher: matcher,
+		})
+	}
+
+	s.loaded = true
+
+	return s.rules
+}
+
+func (s *chatFilterService) invalidateRules() {
+	s.rulesMu.Lock()
+	s.loaded = false
+	s.rulesMu.Unlock()
+}
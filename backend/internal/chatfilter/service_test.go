@@ -0,0 +1,182 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chatfilter
+
+import (
+	"database/sql"
+	"github.com/sniddunc/refractor/internal/game"
+	"github.com/sniddunc/refractor/internal/infraction"
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/internal/player"
+	"github.com/sniddunc/refractor/internal/server"
+	"github.com/sniddunc/refractor/pkg/chatfilter"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_chatFilterService_CheckMessage(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	mockRules := map[int64]*refractor.DBChatFilterRule{
+		1: {RuleID: 1, Type: chatfilter.TYPE_WORDLIST, Pattern: "global", Action: chatfilter.ACTION_FLAG, Enabled: true},
+		2: {RuleID: 2, Game: "Mordhau", Type: chatfilter.TYPE_WORDLIST, Pattern: "mordhau", Action: chatfilter.ACTION_FLAG, Enabled: true},
+		3: {RuleID: 3, ServerID: sql.NullInt64{Int64: 2, Valid: true}, Type: chatfilter.TYPE_WORDLIST, Pattern: "server",
+			Action: chatfilter.ACTION_FLAG, Enabled: true},
+		4: {RuleID: 4, Type: chatfilter.TYPE_WORDLIST, Pattern: "disabled", Action: chatfilter.ACTION_FLAG, Enabled: false},
+	}
+
+	serverService := server.NewServerService(mock.NewMockServerRepository(mock.GetMockServers()), game.NewGameService(),
+		nil, testLogger)
+	serverService.CreateServerData(1, "Mordhau")
+	serverService.CreateServerData(2, "Minecraft")
+
+	filterService := NewChatFilterService(mock.NewMockChatFilterRepository(mockRules), serverService, nil, nil, nil,
+		nil, testLogger)
+
+	tests := []struct {
+		name        string
+		serverID    int64
+		message     string
+		wantRuleIDs []int64
+	}{
+		{"chatfilter.checkmessage.1", 1, "a global message", []int64{1}},
+		{"chatfilter.checkmessage.2", 2, "a global message", []int64{1}},
+		{"chatfilter.checkmessage.3", 1, "mordhau", []int64{2}},
+		{"chatfilter.checkmessage.4", 2, "mordhau", nil},
+		{"chatfilter.checkmessage.5", 1, "server", nil},
+		{"chatfilter.checkmessage.6", 2, "server", []int64{3}},
+		{"chatfilter.checkmessage.7", 1, "disabled", nil},
+		{"chatfilter.checkmessage.8", 1, "nothing to see here", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ruleIDs []int64
+			for _, rule := range filterService.CheckMessage(tt.serverID, tt.message) {
+				ruleIDs = append(ruleIDs, rule.RuleID)
+			}
+
+			assert.Equal(t, tt.wantRuleIDs, ruleIDs)
+		})
+	}
+}
+
+func Test_chatFilterService_HandleMatches(t *testing.T) {
+	warnRule := &refractor.ChatFilterRule{RuleID: 1, Action: chatfilter.ACTION_WARN, Reason: "language", CreatedBy: 2}
+	muteRule := &refractor.ChatFilterRule{RuleID: 2, Action: chatfilter.ACTION_MUTE, Reason: "slurs", Duration: 30,
+		CreatedBy: 2}
+	flagRule := &refractor.ChatFilterRule{RuleID: 3, Action: chatfilter.ACTION_FLAG, CreatedBy: 2}
+
+	tests := []struct {
+		name            string
+		matches         []*refractor.ChatFilterRule
+		wantCommands    []string
+		wantInfractions []string
+	}{
+		{
+			name:            "chatfilter.handlematches.1",
+			matches:         []*refractor.ChatFilterRule{warnRule},
+			wantInfractions: []string{refractor.INFRACTION_TYPE_WARNING},
+		},
+		{
+			name:            "chatfilter.handlematches.2",
+			matches:         []*refractor.ChatFilterRule{flagRule, muteRule, warnRule},
+			wantCommands:    []string{"mockmute"},
+			wantInfractions: []string{refractor.INFRACTION_TYPE_MUTE},
+		},
+		{
+			name:    "chatfilter.handlematches.3",
+			matches: []*refractor.ChatFilterRule{flagRule},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testLogger, _ := log.NewLogger(true, false)
+
+			gameService := game.NewGameService()
+			gameService.AddGame(mock.NewMockGame())
+
+			serverService := server.NewServerService(mock.NewMockServerRepository(mock.GetMockServers()), gameService,
+				nil, testLogger)
+			serverService.CreateServerData(1, mock.NewMockGame().GetName())
+
+			players := map[int64]*refractor.DBPlayer{
+				1: {PlayerID: 1, PlayFabID: sql.NullString{String: "ABC123", Valid: true}, CurrentName: "Player1"},
+			}
+			mockInfractions := map[int64]*refractor.DBInfraction{}
+			playerService := player.NewPlayerService(mock.NewMockPlayerRepository(players), nil, nil, testLogger)
+			infractionService := infraction.NewInfractionService(mock.NewMockInfractionRepository(mockInfractions),
+				playerService, serverService, nil, testLogger)
+
+			mockRCONService := mock.NewMockRCONService(1)
+
+			filterService := NewChatFilterService(mock.NewMockChatFilterRepository(map[int64]*refractor.DBChatFilterRule{}),
+				serverService, gameService, infractionService, mock.NewMockWebsocketService(), mockRCONService,
+				testLogger)
+
+			filterService.HandleMatches(&refractor.ChatMessage{
+				MessageID: 1,
+				PlayerID:  1,
+				ServerID:  1,
+				Message:   "bad words",
+			}, "ABC123", tt.matches)
+
+			var infractionTypes []string
+			for _, i := range mockInfractions {
+				infractionTypes = append(infractionTypes, i.Type)
+
+				// Automatic infractions are never attributed to a user, including the rule's creator
+				assert.Equal(t, int64(0), i.UserID)
+			}
+
+			assert.Equal(t, tt.wantCommands, mockRCONService.Commands[1])
+			assert.Equal(t, tt.wantInfractions, infractionTypes)
+		})
+	}
+}
+
+func Test_chatFilterService_UpdateRule(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	mockRules := map[int64]*refractor.DBChatFilterRule{
+		1: {RuleID: 1, Type: chatfilter.TYPE_WORDLIST, Pattern: "word", Action: chatfilter.ACTION_FLAG, Enabled: true},
+	}
+
+	serverService := server.NewServerService(mock.NewMockServerRepository(mock.GetMockServers()), game.NewGameService(),
+		nil, testLogger)
+
+	filterService := NewChatFilterService(mock.NewMockChatFilterRepository(mockRules), serverService, nil, nil, nil,
+		nil, testLogger)
+
+	// Changing the type to REGEX leaves an invalid pattern behind so it should be rejected
+	regexType := chatfilter.TYPE_REGEX
+	badPattern := "([a-z"
+	_, res := filterService.UpdateRule(1, params.UpdateChatFilterRuleParams{Type: &regexType, Pattern: &badPattern})
+	assert.False(t, res.Success)
+
+	// Rules should be reloaded after an update
+	assert.Len(t, filterService.CheckMessage(1, "word"), 1)
+
+	newPattern := "other"
+	_, res = filterService.UpdateRule(1, params.UpdateChatFilterRuleParams{Pattern: &newPattern})
+	assert.True(t, res.Success)
+	assert.Len(t, filterService.CheckMessage(1, "word"), 0)
+	assert.Len(t, filterService.CheckMessage(1, "other"), 1)
+}
@@ -0,0 +1,351 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chatspam
+
+import (
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/broadcast"
+	"github.com/sniddunc/refractor/pkg/chatspam"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/pkg/settingscache"
+	"github.com/sniddunc/refractor/refractor"
+	"net/http"
+	"time"
+)
+
+type chatSpamService struct {
+	repo              refractor.ChatSpamRepository
+	serverService     refractor.ServerService
+	gameService       refractor.GameService
+	infractionService refractor.InfractionService
+	websocketService  refractor.WebsocketService
+	rconService       refractor.RCONService
+	log               log.Logger
+
+	detector *chatspam.Detector
+
+	// settings is a cache of each server's spam settings. Servers without stored settings are cached with the defaults.
+	settings *settingscache.Cache
+}
+
+func NewChatSpamService(repo refractor.ChatSpamRepository, serverService refractor.ServerService,
+	gameService refractor.GameService, infractionService refractor.InfractionService,
+	websocketService refractor.WebsocketService, rconService refractor.RCONService, log log.Logger) refractor.ChatSpamService {
+	s := &chatSpamService{
+		repo:              repo,
+		serverService:     serverService,
+		gameService:       gameService,
+		infractionService: infractionService,
+		websocketService:  websocketService,
+		rconService:       rconService,
+		log:               log,
+		detector:          chatspam.NewDetector(),
+	}
+
+	s.settings = settingscache.New(s.loadSettings)
+
+	return s
+}
+
+func (s *chatSpamService) GetSettings(serverID int64) (*refractor.ChatSpamSettings, *refractor.ServiceResponse) {
+	if server, _ := s.serverService.GetServerByID(serverID); server == nil {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    config.MessageInvalidIDProvided,
+		}
+	}
+
+	settings, err := s.getSettings(serverID)
+	if err != nil {
+		s.log.Error("Could not get chat spam settings for server ID %d. Error: %v", serverID, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	return settings, &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Fetched chat spam settings",
+	}
+}
+
+func (s *chatSpamService) UpdateSettings(serverID int64, body params.UpdateChatSpamSettingsParams) (*refractor.ChatSpamSettings, *refractor.ServiceResponse) {
+	if server, _ := s.serverService.GetServerByID(serverID); server == nil {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    config.MessageInvalidIDProvided,
+		}
+	}
+
+	updated, err := s.settings.Update(serverID, func(current interface{}) (interface{}, error) {
+		settings := *current.(*refractor.ChatSpamSettings)
+
+		if body.Enabled != nil {
+			settings.Enabled = *body.Enabled
+		}
+
+		if body.FloodMessages != nil {
+			settings.FloodMessages = *body.FloodMessages
+		}
+
+		if body.FloodWindow != nil {
+			settings.FloodWindow = *body.FloodWindow
+		}
+
+		if body.FloodAction != nil {
+			settings.FloodAction = *body.FloodAction
+		}
+
+		if body.RepeatCount != nil {
+			settings.RepeatCount = *body.RepeatCount
+		}
+
+		if body.RepeatAction != nil {
+			settings.RepeatAction = *body.RepeatAction
+		}
+
+		if body.CapsPercent != nil {
+			settings.CapsPercent = *body.CapsPercent
+		}
+
+		if body.CapsMinLength != nil {
+			settings.CapsMinLength = *body.CapsMinLength
+		}
+
+		if body.CapsAction != nil {
+			settings.CapsAction = *body.CapsAction
+		}
+
+		if body.MuteDuration != nil {
+			settings.MuteDuration = *body.MuteDuration
+		}
+
+		settings.UpdatedBy = body.UserMeta.UserID
+
+		if err := s.repo.Save(&settings); err != nil {
+			return nil, err
+		}
+
+		return &settings, nil
+	})
+	if err != nil {
+		s.log.Error("Could not update chat spam settings for server ID %d. Error: %v", serverID, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	s.log.Info("User ID %d updated the chat spam settings of server ID %d", body.UserMeta.UserID, serverID)
+
+	return updated.(*refractor.ChatSpamSettings), &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Chat spam settings updated",
+	}
+}
+
+type chatSpamBody struct {
+	Message    *refractor.ChatMessage `json:"message"`
+	Detections []string               `json:"detections"`
+	Action     string                 `json:"action"`
+}
+
+// CheckMessage runs a message through spam detection and takes the most severe configured action for the types of
+// spam it was detected as. Messages sent by staff and messages sent by trusted players are never checked. The
+// detected spam types are returned.
+func (s *chatSpamService) CheckMessage(message *refractor.ChatMessage, player *refractor.Player, playerGameID string) []string {
+	if message.SentByUser || player == nil || player.Trusted {
+		return nil
+	}
+
+	settings, err := s.getSettings(message.ServerID)
+	if err != nil {
+		s.log.Error("Could not get chat spam settings for server ID %d. Error: %v", message.ServerID, err)
+		return nil
+	}
+
+	if !settings.Enabled {
+		return nil
+	}
+
+	detections := s.detector.Check(message.ServerID, playerGameID, message.Message, time.Now(), chatspam.Settings{
+		FloodMessages: settings.FloodMessages,
+		FloodWindow:   time.Duration(settings.FloodWindow) * time.Second,
+		RepeatCount:   settings.RepeatCount,
+		CapsPercent:   settings.CapsPercent,
+		CapsMinLength: settings.CapsMinLength,
+	})
+	if len(detections) == 0 {
+		return nil
+	}
+
+	// Only the most severe action is taken so that a message detected as several types of spam doesn't result in
+	// several infractions.
+	action := chatspam.ACTION_NONE
+	reason := ""
+
+	for _, detection := range detections {
+		detectionAction, detectionReason := getDetectionAction(settings, detection)
+
+		if chatspam.Severity(detectionAction) > chatspam.Severity(action) {
+			action = detectionAction
+			reason = detectionReason
+		}
+	}
+
+	s.log.Info("Chat message ID %d from player ID %d was detected as spam %v (%s)", message.MessageID,
+		message.PlayerID, detections, action)
+
+	if chatspam.Severity(action) >= chatspam.Severity(chatspam.ACTION_ALERT) {
+		s.websocketService.Broadcast(&refractor.WebsocketMessage{
+			Type: "chat-spam",
+			Body: &chatSpamBody{
+				Message:    message,
+				Detections: detections,
+				Action:     action,
+			},
+		})
+	}
+
+	if chatspam.Severity(action) <= chatspam.Severity(chatspam.ACTION_ALERT) {
+		return detections
+	}
+
+	switch action {
+	case chatspam.ACTION_WARN:
+		_, res := s.infractionService.CreateSystemInfraction(0, message.PlayerID, message.ServerID,
+			refractor.INFRACTION_TYPE_WARNING, reason, 0)
+		if !res.Success {
+			s.log.Warn("Could not create automatic warning for player ID %d. %s", message.PlayerID, res.Message)
+		}
+	case chatspam.ACTION_MUTE:
+		_, res := s.infractionService.CreateSystemInfraction(0, message.PlayerID, message.ServerID,
+			refractor.INFRACTION_TYPE_MUTE, reason, settings.MuteDuration)
+		if !res.Success {
+			s.log.Warn("Could not create automatic mute for player ID %d. %s", message.PlayerID, res.Message)
+			return detections
+		}
+
+		s.execGameCommand(message.ServerID, func(game refractor.Game) string {
+			return game.GetMuteCommand(refractor.CommandArgs{
+				PlayerID: playerGameID,
+				Duration: settings.MuteDuration,
+			})
+		})
+	case chatspam.ACTION_KICK:
+		_, res := s.infractionService.CreateSystemInfraction(0, message.PlayerID, message.ServerID,
+			refractor.INFRACTION_TYPE_KICK, reason, 0)
+		if !res.Success {
+			s.log.Warn("Could not create automatic kick for player ID %d. %s", message.PlayerID, res.Message)
+			return detections
+		}
+
+		s.execGameCommand(message.ServerID, func(game refractor.Game) string {
+			return game.GetKickCommand(refractor.CommandArgs{
+				PlayerID: playerGameID,
+				Reason:   reason,
+			})
+		})
+	}
+
+	return detections
+}
+
+// OnPlayerQuit clears the spam history of a player who left a server
+func (s *chatSpamService) OnPlayerQuit(fields broadcast.Fields, serverID int64, gameConfig *refractor.GameConfig) {
+	s.detector.Forget(serverID, fields[gameConfig.PlayerGameIDField])
+}
+
+// OnServerOffline clears the spam history of every player on a server which went offline
+func (s *chatSpamService) OnServerOffline(serverID int64) {
+	s.detector.ForgetServer(serverID)
+}
+
+// getSettings returns a server's spam settings, loading them from the repository if they aren't cached.
+func (s *chatSpamService) getSettings(serverID int64) (*refractor.ChatSpamSettings, error) {
+	settings, err := s.settings.Get(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	return settings.(*refractor.ChatSpamSettings), nil
+}
+
+// loadSettings loads a server's spam settings from the repository. The defaults are used if none are stored.
+func (s *chatSpamService) loadSettings(serverID int64) (interface{}, error) {
+	settings, err := s.repo.FindByServerID(serverID)
+	if err == refractor.ErrNotFound {
+		return getDefaultSettings(serverID), nil
+	}
+
+	return settings, err
+}
+
+// execGameCommand runs the command built by getCommand for the game a server is running. Nothing is run if the game
+// doesn't support the command.
+func (s *chatSpamService) execGameCommand(serverID int64, getCommand func(game refractor.Game) string) {
+	serverData, _ := s.serverService.GetServerData(serverID)
+	if serverData == nil {
+		return
+	}
+
+	game, _ := s.gameService.GetGame(serverData.Game)
+	if game == nil {
+		return
+	}
+
+	command := getCommand(game)
+	if command == "" {
+		return
+	}
+
+	if _, err := s.rconService.ExecCommand(serverID, command); err != nil {
+		s.log.Error("Could not run chat spam command on server ID %d. Error: %v", serverID, err)
+	}
+}
+
+// getDefaultSettings returns the settings used for servers which haven't had their spam settings changed. Staff are
+// only alerted by default so that players aren't punished until a server's staff choose to enable it.
+func getDefaultSettings(serverID int64) *refractor.ChatSpamSettings {
+	return &refractor.ChatSpamSettings{
+		ServerID:      serverID,
+		Enabled:       true,
+		FloodMessages: config.ChatSpamDefaultFloodMessages,
+		FloodWindow:   config.ChatSpamDefaultFloodWindow,
+		FloodAction:   chatspam.ACTION_ALERT,
+		RepeatCount:   config.ChatSpamDefaultRepeatCount,
+		RepeatAction:  chatspam.ACTION_ALERT,
+		CapsPercent:   config.ChatSpamDefaultCapsPercent,
+		CapsMinLength: config.ChatSpamDefaultCapsMinLength,
+		CapsAction:    chatspam.ACTION_ALERT,
+		MuteDuration:  config.ChatSpamDefaultMuteDuration,
+	}
+}
+
+func getDetectionAction(settings *refractor.ChatSpamSettings, detection string) (string, string) {
+	switch detection {
+	case chatspam.DETECTION_FLOOD:
+		return settings.FloodAction, config.ChatSpamFloodReason
+	case chatspam.DETECTION_REPEAT:
+		return settings.RepeatAction, config.ChatSpamRepeatReason
+	case chatspam.DETECTION_CAPS:
+		return settings.CapsAction, config.ChatSpamCapsReason
+	}
+
+	return chatspam.ACTION_NONE, ""
+}
@@ -0,0 +1,254 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chatspam
+
+import (
+	"database/sql"
+	"github.com/sniddunc/refractor/internal/game"
+	"github.com/sniddunc/refractor/internal/infraction"
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/internal/player"
+	"github.com/sniddunc/refractor/internal/server"
+	"github.com/sniddunc/refractor/pkg/chatspam"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func Test_chatSpamService_CheckMessage(t *testing.T) {
+	type message struct {
+		playerID   int64
+		text       string
+		sentByUser bool
+	}
+
+	spamSettings := &refractor.ChatSpamSettings{
+		ServerID:      1,
+		Enabled:       true,
+		FloodMessages: 3,
+		FloodWindow:   60,
+		FloodAction:   chatspam.ACTION_KICK,
+		RepeatCount:   2,
+		RepeatAction:  chatspam.ACTION_MUTE,
+		CapsPercent:   80,
+		CapsMinLength: 5,
+		CapsAction:    chatspam.ACTION_ALERT,
+		MuteDuration:  15,
+		UpdatedBy:     1,
+	}
+
+	// Settings which were never saved by a user
+	unsavedSettings := *spamSettings
+	unsavedSettings.UpdatedBy = 0
+
+	tests := []struct {
+		name            string
+		settings        *refractor.ChatSpamSettings
+		messages        []message
+		wantDetections  []string
+		wantCommands    []string
+		wantInfractions []string
+		wantAlerts      int
+	}{
+		{
+			name:           "chatspam.checkmessage.1",
+			settings:       spamSettings,
+			messages:       []message{{1, "hello", false}, {1, "how is everyone", false}},
+			wantDetections: nil,
+		},
+		{
+			name:            "chatspam.checkmessage.2",
+			settings:        spamSettings,
+			messages:        []message{{1, "buy gold", false}, {1, "buy gold", false}},
+			wantDetections:  []string{chatspam.DETECTION_REPEAT},
+			wantCommands:    []string{"mockmute"},
+			wantInfractions: []string{refractor.INFRACTION_TYPE_MUTE},
+			wantAlerts:      1,
+		},
+		{
+			name:            "chatspam.checkmessage.3",
+			settings:        spamSettings,
+			messages:        []message{{1, "one", false}, {1, "two", false}, {1, "three", false}, {1, "FOUR FOUR", false}},
+			wantDetections:  []string{chatspam.DETECTION_CAPS, chatspam.DETECTION_FLOOD},
+			wantCommands:    []string{"mockkick"},
+			wantInfractions: []string{refractor.INFRACTION_TYPE_KICK},
+			wantAlerts:      1,
+		},
+		{
+			name:           "chatspam.checkmessage.4",
+			settings:       spamSettings,
+			messages:       []message{{1, "STOP TEAMKILLING", false}},
+			wantDetections: []string{chatspam.DETECTION_CAPS},
+			wantAlerts:     1,
+		},
+		{
+			name:           "chatspam.checkmessage.5",
+			settings:       spamSettings,
+			messages:       []message{{2, "buy gold", false}, {2, "buy gold", false}},
+			wantDetections: nil,
+		},
+		{
+			name:           "chatspam.checkmessage.6",
+			settings:       spamSettings,
+			messages:       []message{{1, "buy gold", true}, {1, "buy gold", true}},
+			wantDetections: nil,
+		},
+		{
+			name:            "chatspam.checkmessage.7",
+			settings:        &unsavedSettings,
+			messages:        []message{{1, "buy gold", false}, {1, "buy gold", false}},
+			wantDetections:  []string{chatspam.DETECTION_REPEAT},
+			wantCommands:    []string{"mockmute"},
+			wantInfractions: []string{refractor.INFRACTION_TYPE_MUTE},
+			wantAlerts:      1,
+		},
+		{
+			name:           "chatspam.checkmessage.8",
+			settings:       nil,
+			messages:       []message{{1, "buy gold", false}, {1, "buy gold", false}, {1, "buy gold", false}},
+			wantDetections: []string{chatspam.DETECTION_REPEAT},
+			wantAlerts:     1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testLogger, _ := log.NewLogger(true, false)
+
+			gameService := game.NewGameService()
+			gameService.AddGame(mock.NewMockGame())
+
+			serverService := server.NewServerService(mock.NewMockServerRepository(mock.GetMockServers()), gameService,
+				nil, testLogger)
+			serverService.CreateServerData(1, mock.NewMockGame().GetName())
+
+			players := map[int64]*refractor.DBPlayer{
+				1: {PlayerID: 1, PlayFabID: sql.NullString{String: "ABC123", Valid: true}, CurrentName: "Player1"},
+				2: {PlayerID: 2, PlayFabID: sql.NullString{String: "DEF456", Valid: true}, CurrentName: "Player2", Trusted: true},
+			}
+			mockInfractions := map[int64]*refractor.DBInfraction{}
+			playerService := player.NewPlayerService(mock.NewMockPlayerRepository(players), nil, nil, testLogger)
+			infractionService := infraction.NewInfractionService(mock.NewMockInfractionRepository(mockInfractions),
+				playerService, serverService, nil, testLogger)
+
+			mockSettings := map[int64]*refractor.ChatSpamSettings{}
+			if tt.settings != nil {
+				mockSettings[1] = tt.settings
+			}
+
+			mockWebsocketService := mock.NewMockWebsocketService()
+			mockRCONService := mock.NewMockRCONService(1, 2)
+
+			spamService := NewChatSpamService(mock.NewMockChatSpamRepository(mockSettings), serverService,
+				gameService, infractionService, mockWebsocketService, mockRCONService, testLogger)
+
+			var detections []string
+			for _, m := range tt.messages {
+				dbPlayer := players[m.playerID]
+
+				detections = spamService.CheckMessage(&refractor.ChatMessage{
+					PlayerID:   m.playerID,
+					ServerID:   1,
+					Message:    m.text,
+					SentByUser: m.sentByUser,
+				}, dbPlayer.Player(), dbPlayer.PlayFabID.String)
+			}
+
+			var infractionTypes []string
+			for _, i := range mockInfractions {
+				infractionTypes = append(infractionTypes, i.Type)
+
+				// Automatic infractions are never attributed to a user
+				assert.Equal(t, int64(0), i.UserID)
+			}
+
+			assert.Equal(t, tt.wantDetections, detections)
+			assert.Equal(t, tt.wantCommands, mockRCONService.Commands[1])
+			assert.Equal(t, tt.wantInfractions, infractionTypes)
+			assert.Equal(t, tt.wantAlerts, len(mockWebsocketService.Broadcasts))
+		})
+	}
+}
+
+func Test_chatSpamService_UpdateSettings(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+	strPtr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name           string
+		serverID       int64
+		body           params.UpdateChatSpamSettingsParams
+		wantStatusCode int
+		wantSettings   *refractor.ChatSpamSettings
+	}{
+		{
+			name:     "chatspam.updatesettings.1",
+			serverID: 1,
+			body: params.UpdateChatSpamSettingsParams{
+				FloodAction:  strPtr(chatspam.ACTION_MUTE),
+				MuteDuration: intPtr(30),
+				UserMeta:     &params.UserMeta{UserID: 2},
+			},
+			wantStatusCode: http.StatusOK,
+			wantSettings: &refractor.ChatSpamSettings{
+				ServerID:      1,
+				Enabled:       true,
+				FloodMessages: config.ChatSpamDefaultFloodMessages,
+				FloodWindow:   config.ChatSpamDefaultFloodWindow,
+				FloodAction:   chatspam.ACTION_MUTE,
+				RepeatCount:   config.ChatSpamDefaultRepeatCount,
+				RepeatAction:  chatspam.ACTION_ALERT,
+				CapsPercent:   config.ChatSpamDefaultCapsPercent,
+				CapsMinLength: config.ChatSpamDefaultCapsMinLength,
+				CapsAction:    chatspam.ACTION_ALERT,
+				MuteDuration:  30,
+				UpdatedBy:     2,
+			},
+		},
+		{
+			name:           "chatspam.updatesettings.2",
+			serverID:       99,
+			body:           params.UpdateChatSpamSettingsParams{UserMeta: &params.UserMeta{UserID: 2}},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testLogger, _ := log.NewLogger(true, false)
+
+			serverService := server.NewServerService(mock.NewMockServerRepository(mock.GetMockServers()),
+				game.NewGameService(), nil, testLogger)
+
+			mockSettings := map[int64]*refractor.ChatSpamSettings{}
+			spamService := NewChatSpamService(mock.NewMockChatSpamRepository(mockSettings), serverService, nil, nil,
+				nil, nil, testLogger)
+
+			settings, res := spamService.UpdateSettings(tt.serverID, tt.body)
+
+			assert.Equal(t, tt.wantStatusCode, res.StatusCode)
+			assert.Equal(t, tt.wantSettings, settings)
+
+			if tt.wantSettings != nil {
+				assert.Equal(t, tt.wantSettings, mockSettings[tt.serverID])
+			}
+		})
+	}
+}
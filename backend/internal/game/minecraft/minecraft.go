@@ -19,6 +19,7 @@ package minecraft
 
 import (
 	"fmt"
+	"github.com/sniddunc/refractor/internal/rcon/transport"
 	"github.com/sniddunc/refractor/refractor"
 	"regexp"
 	"time"
@@ -32,14 +33,19 @@ func NewMinecraftGame() refractor.Game {
 	return &minecraft{
 		config: &refractor.GameConfig{
 			UseRCON:                   true,
+			RCONTransport:             transport.NewMordhauTransport,
 			SendAlivePing:             true,
 			AlivePingInterval:         time.Second * 30,
 			EnableBroadcasts:          false,
-			EnableChat:                false,
+			EnableChat:                true,
+			SupportsNativeMute:        false,
 			PlayerListPollingInterval: time.Second * 5,
+			ChatPollingInterval:       time.Second * 2,
 			BroadcastPatterns:         map[string]*regexp.Regexp{},
 			CmdOutputPatterns: map[string]*regexp.Regexp{
-				"PlayerList": regexp.MustCompile("(?P<MCUUID>[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}):(?P<Name>[\\S]+)"),
+				"PlayerList":  regexp.MustCompile("(?P<MCUUID>[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}):(?P<Name>[\\S]+)"),
+				"ChatCursor":  regexp.MustCompile("(?m)^cursor:(?P<Cursor>\\d+)\\r?$"),
+				"ChatMessage": regexp.MustCompile("(?m)^chat:(?P<MCUUID>[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}):(?P<Name>\\w+):(?P<Message>.+)$"),
 			},
 			PlayerGameIDField: "MCUUID",
 		},
@@ -59,10 +65,9 @@ func (g *minecraft) GetWarnCommand(args refractor.CommandArgs) string {
 	return ""
 }
 
-// GetMuteCommand returns a constructed mute command for Mordhau.
-// The following fields must be present on CommandArgs: PlayerID, Duration
+// GetMuteCommand returns an empty string since Minecraft does not have a mute command. Mutes are enforced by Refractor.
 func (g *minecraft) GetMuteCommand(args refractor.CommandArgs) string {
-	return fmt.Sprintf("Mute %s %d", args.PlayerID, args.Duration)
+	return ""
 }
 
 // GetKickCommand returns a constructed kick command for Mordhau.
@@ -80,3 +85,31 @@ func (g *minecraft) GetBanCommand(args refractor.CommandArgs) string {
 func (g *minecraft) GetPlayerListCommand() string {
 	return "refractormc:playerlist" // use refractor minecraft plugin's command
 }
+
+// GetWhisperCommand returns a constructed tell command for Minecraft. Player UUIDs are accepted as targets.
+// The following fields must be present on CommandArgs: PlayerID, Message
+func (g *minecraft) GetWhisperCommand(args refractor.CommandArgs) string {
+	return fmt.Sprintf("tell %s %s", args.PlayerID, args.Message)
+}
+
+// GetBroadcastCommand returns a constructed say command for Minecraft.
+// The following fields must be present on CommandArgs: Message
+func (g *minecraft) GetBroadcastCommand(args refractor.CommandArgs) string {
+	return fmt.Sprintf("say %s", args.Message)
+}
+
+// GetChatPollCommand returns a constructed chat command for the refractor minecraft plugin. The plugin buffers chat
+// messages and returns those sent after the cursor along with the latest cursor. If the cursor is empty, only the
+// latest cursor is returned so that chat from before Refractor connected is not relayed.
+func (g *minecraft) GetChatPollCommand(cursor string) string {
+	if cursor == "" {
+		return "refractormc:chat"
+	}
+
+	return fmt.Sprintf("refractormc:chat %s", cursor)
+}
+
+// GetServerInfoCommand returns an empty string since Minecraft does not have a server info command
+func (g *minecraft) GetServerInfoCommand() string {
+	return ""
+}
@@ -19,6 +19,7 @@ package mordhau
 
 import (
 	"fmt"
+	"github.com/sniddunc/refractor/internal/rcon/transport"
 	"github.com/sniddunc/refractor/pkg/broadcast"
 	"github.com/sniddunc/refractor/refractor"
 	"regexp"
@@ -32,19 +33,32 @@ type mordhau struct {
 func NewMordhauGame() refractor.Game {
 	return &mordhau{
 		config: &refractor.GameConfig{
-			UseRCON:                   true,
-			SendAlivePing:             true,
-			AlivePingInterval:         time.Second * 30,
-			EnableBroadcasts:          true,
-			PlayerListPollingInterval: time.Hour * 1,
-			EnableChat:                true,
+			UseRCON:                     true,
+			RCONTransport:               transport.NewMordhauTransport,
+			MaxRCONPort:                 transport.MordhauMaxPort,
+			SendAlivePing:               true,
+			AlivePingInterval:           time.Second * 30,
+			EnableBroadcasts:            true,
+			PlayerListPollingInterval:   time.Hour * 1,
+			PlayerFieldsPollingInterval: time.Second * 30,
+			ServerInfoPollingInterval:   time.Second * 30,
+			EnableChat:                  true,
+			SupportsNativeMute:          true,
 			BroadcastPatterns: map[string]*regexp.Regexp{
-				broadcast.TYPE_JOIN: regexp.MustCompile("^Login: (?P<Date>[0-9\\.-]+): (?P<Name>.+) \\((?P<PlayFabID>[0-9a-fA-F]+)\\) logged in$"),
-				broadcast.TYPE_QUIT: regexp.MustCompile("^Login: (?P<Date>[0-9\\.-]+): (?P<Name>.+) \\((?P<PlayFabID>[0-9a-fA-F]+)\\) logged out$"),
-				broadcast.TYPE_CHAT: regexp.MustCompile("^Chat: (?P<PlayFabID>[0-9a-fA-F]+), (?P<Name>.+), \\((?P<Channel>.+)\\) (?P<Message>.+)$"),
+				broadcast.TYPE_JOIN:       regexp.MustCompile("^Login: (?P<Date>[0-9\\.-]+): (?P<Name>.+) \\((?P<PlayFabID>[0-9a-fA-F]+)\\) logged in$"),
+				broadcast.TYPE_QUIT:       regexp.MustCompile("^Login: (?P<Date>[0-9\\.-]+): (?P<Name>.+) \\((?P<PlayFabID>[0-9a-fA-F]+)\\) logged out$"),
+				broadcast.TYPE_CHAT:       regexp.MustCompile("^Chat: (?P<PlayFabID>[0-9a-fA-F]+), (?P<Name>.+), \\((?P<Channel>.+)\\) (?P<Message>.+)$"),
+				broadcast.TYPE_PUNISHMENT: regexp.MustCompile("^Punishment: Admin (?P<AdminName>.+) \\((?P<AdminPlayFabID>[0-9a-fA-F]+)\\) (?P<Action>banned|kicked|muted|unbanned|unmuted) player (?P<PlayFabID>[0-9a-fA-F]+)(?: \\((?:Duration: (?P<Duration>\\d+))?(?:, )?(?:Reason: (?P<Reason>.*))?\\))?$"),
+				broadcast.TYPE_MATCHSTATE: regexp.MustCompile("^MatchState: (?P<State>.+)$"),
+				broadcast.TYPE_KILL:       regexp.MustCompile("^Killfeed: (?P<Date>[0-9\\.-]+): (?P<KillerPlayFabID>[0-9a-fA-F]+) \\((?P<KillerName>.+)\\) killed (?P<PlayFabID>[0-9a-fA-F]+) \\((?P<Name>.+)\\)$"),
 			},
+			BroadcastChannels:    []string{"login", "chat", "punishment", "matchstate", "killfeed"},
+			MatchInProgressState: "In progress",
+			LogLinePrefix:        regexp.MustCompile("^\\[(?P<Timestamp>[0-9\\.-]+)(?::\\d+)?\\]\\[\\s*\\d+\\]\\w+: (?:Display: )?"),
+			LogTimestampLayout:   "2006.01.02-15.04.05",
 			CmdOutputPatterns: map[string]*regexp.Regexp{
 				"PlayerList": regexp.MustCompile("(?P<PlayFabID>[0-9A-Z]+),\\s(?P<Name>[\\S ]+),\\s(?P<Ping>\\d{1,4})\\sms,\\steam\\s(?P<Team>[0-9-]+)"),
+				"ServerInfo": regexp.MustCompile("(?m)^(?:(?:Server ?)?Name|HostName): (?P<ServerName>.+?)\\r?$|^Game ?Mode: (?P<GameMode>.+?)\\r?$|^Map: (?P<Map>.+?)\\r?$|^(?:Max ?Players: |Players: \\d+ ?/ ?)(?P<MaxPlayers>\\d+)"),
 			},
 			PlayerGameIDField: "PlayFabID",
 		},
@@ -85,3 +99,23 @@ func (g *mordhau) GetBanCommand(args refractor.CommandArgs) string {
 func (g *mordhau) GetPlayerListCommand() string {
 	return "PlayerList"
 }
+
+// GetWhisperCommand returns an empty string since Mordhau does not have a private message command
+func (g *mordhau) GetWhisperCommand(args refractor.CommandArgs) string {
+	return ""
+}
+
+// GetBroadcastCommand returns a constructed say command for Mordhau.
+// The following fields must be present on CommandArgs: Message
+func (g *mordhau) GetBroadcastCommand(args refractor.CommandArgs) string {
+	return fmt.Sprintf("Say %s", args.Message)
+}
+
+// GetChatPollCommand returns an empty string since Mordhau relays chat through broadcasts
+func (g *mordhau) GetChatPollCommand(cursor string) string {
+	return ""
+}
+
+func (g *mordhau) GetServerInfoCommand() string {
+	return "Info"
+}
@@ -0,0 +1,123 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package squad
+
+import (
+	"fmt"
+	"github.com/sniddunc/refractor/internal/rcon/transport"
+	"github.com/sniddunc/refractor/pkg/broadcast"
+	"github.com/sniddunc/refractor/refractor"
+	"regexp"
+	"time"
+)
+
+type squad struct {
+	config *refractor.GameConfig
+}
+
+// NewSquadGame creates the Squad game. Squad servers speak Source RCON and push chat messages over the RCON
+// connection, but player joins and quits are only found by polling the player list. Players are identified by their
+// SteamID. Newer servers list online IDs (EOS and steam) in place of the SteamID, which the patterns also accept.
+func NewSquadGame() refractor.Game {
+	return &squad{
+		config: &refractor.GameConfig{
+			UseRCON:                   true,
+			RCONTransport:             transport.NewSourceTransport,
+			SendAlivePing:             true,
+			AlivePingInterval:         time.Second * 30,
+			EnableBroadcasts:          true,
+			PollPlayerList:            true,
+			PlayerListPollingInterval: time.Second * 10,
+			ServerInfoPollingInterval: time.Second * 30,
+			EnableChat:                true,
+			SupportsNativeMute:        false,
+			BroadcastPatterns: map[string]*regexp.Regexp{
+				broadcast.TYPE_CHAT: regexp.MustCompile("^\\[(?P<Channel>Chat\\w+)\\] \\[(?:SteamID:|Online IDs:.*?steam: ?)(?P<SteamID>\\d{17})\\] (?P<Name>.+?) : (?P<Message>.*)$"),
+			},
+			CmdOutputPatterns: map[string]*regexp.Regexp{
+				"PlayerList": regexp.MustCompile("ID: (?P<PlayerSlot>\\d+) \\| (?:SteamID: |Online IDs:[^|]*?steam: ?)(?P<SteamID>\\d{17}) \\| Name: (?P<Name>.+?) \\| Team ID: (?P<Team>\\d+|N/A) \\| Squad ID: (?P<Squad>\\d+|N/A)"),
+				"ServerInfo": regexp.MustCompile("\"ServerName_s\":\\s*\"(?P<ServerName>[^\"]*)\"|\"GameMode_s\":\\s*\"(?P<GameMode>[^\"]*)\"|\"MapName_s\":\\s*\"(?P<Map>[^\"]*)\"|\"MaxPlayers\":\\s*(?P<MaxPlayers>\\d+)"),
+			},
+			PlayerGameIDField: "SteamID",
+		},
+	}
+}
+
+func (g *squad) GetName() string {
+	return "Squad"
+}
+
+func (g *squad) GetConfig() *refractor.GameConfig {
+	return g.config
+}
+
+// GetWarnCommand returns a constructed AdminWarn command for Squad. The warning is shown on the player's screen.
+// The following fields must be present on CommandArgs: PlayerID, Reason
+func (g *squad) GetWarnCommand(args refractor.CommandArgs) string {
+	return fmt.Sprintf("AdminWarn \"%s\" %s", args.PlayerID, args.Reason)
+}
+
+// GetMuteCommand returns an empty string since Squad does not have a mute command. Mutes are enforced by Refractor.
+func (g *squad) GetMuteCommand(args refractor.CommandArgs) string {
+	return ""
+}
+
+// GetKickCommand returns a constructed kick command for Squad.
+// The following fields must be present on CommandArgs: PlayerID, Reason
+func (g *squad) GetKickCommand(args refractor.CommandArgs) string {
+	return fmt.Sprintf("AdminKick \"%s\" %s", args.PlayerID, args.Reason)
+}
+
+// GetBanCommand returns a constructed ban command for Squad. Durations are in minutes and a duration of 0 bans the
+// player permanently.
+// The following fields must be present on CommandArgs: PlayerID, Duration, Reason
+func (g *squad) GetBanCommand(args refractor.CommandArgs) string {
+	length := "0"
+	if args.Duration > 0 {
+		length = fmt.Sprintf("%dm", args.Duration)
+	}
+
+	return fmt.Sprintf("AdminBan \"%s\" %s %s", args.PlayerID, length, args.Reason)
+}
+
+func (g *squad) GetPlayerListCommand() string {
+	return "ListPlayers"
+}
+
+// GetWhisperCommand returns a constructed AdminWarn command for Squad since warnings are the only way to message a
+// single player.
+// The following fields must be present on CommandArgs: PlayerID, Message
+func (g *squad) GetWhisperCommand(args refractor.CommandArgs) string {
+	return fmt.Sprintf("AdminWarn \"%s\" %s", args.PlayerID, args.Message)
+}
+
+// GetBroadcastCommand returns a constructed AdminBroadcast command for Squad.
+// The following fields must be present on CommandArgs: Message
+func (g *squad) GetBroadcastCommand(args refractor.CommandArgs) string {
+	return fmt.Sprintf("AdminBroadcast %s", args.Message)
+}
+
+// GetChatPollCommand returns an empty string since Squad relays chat through broadcasts
+func (g *squad) GetChatPollCommand(cursor string) string {
+	return ""
+}
+
+// GetServerInfoCommand returns Squad's server info command, which outputs the server's details as JSON
+func (g *squad) GetServerInfoCommand() string {
+	return "ShowServerInfo"
+}
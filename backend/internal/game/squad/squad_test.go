@@ -0,0 +1,257 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package squad
+
+import (
+	"fmt"
+	"github.com/sniddunc/refractor/internal/game"
+	"github.com/sniddunc/refractor/internal/rcon"
+	"github.com/sniddunc/refractor/pkg/broadcast"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/pkg/regexutils"
+	"github.com/sniddunc/refractor/pkg/sourcercon"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_squad_Commands(t *testing.T) {
+	g := NewSquadGame()
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{
+			name: "squad.commands.1",
+			got:  g.GetWarnCommand(refractor.CommandArgs{PlayerID: "76561198000000001", Reason: "Stop mic spamming"}),
+			want: "AdminWarn \"76561198000000001\" Stop mic spamming",
+		},
+		{
+			name: "squad.commands.2",
+			got:  g.GetKickCommand(refractor.CommandArgs{PlayerID: "76561198000000001", Reason: "Teamkilling"}),
+			want: "AdminKick \"76561198000000001\" Teamkilling",
+		},
+		{
+			name: "squad.commands.3",
+			got:  g.GetBanCommand(refractor.CommandArgs{PlayerID: "76561198000000001", Duration: 90, Reason: "Cheating"}),
+			want: "AdminBan \"76561198000000001\" 90m Cheating",
+		},
+		{
+			name: "squad.commands.4",
+			got:  g.GetBanCommand(refractor.CommandArgs{PlayerID: "76561198000000001", Duration: 0, Reason: "Cheating"}),
+			want: "AdminBan \"76561198000000001\" 0 Cheating",
+		},
+		{
+			name: "squad.commands.5",
+			got:  g.GetMuteCommand(refractor.CommandArgs{PlayerID: "76561198000000001", Duration: 10}),
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.got)
+		})
+	}
+}
+
+func Test_squad_PlayerListPattern(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []broadcast.Fields
+	}{
+		{
+			name: "squad.playerlist.1",
+			output: "----- Active Players -----\n" +
+				"ID: 0 | SteamID: 76561198000000001 | Name: Alpha | Team ID: 1 | Squad ID: 2 | Is Leader: True | Role: USA_SL_01\n" +
+				"ID: 4 | SteamID: 76561198000000002 | Name: Bravo | Two | Team ID: 2 | Squad ID: N/A | Is Leader: False | Role: RUS_Rifleman_01\n" +
+				"----- Recently Disconnected Players [Max of 15] -----\n" +
+				"ID: 3 | SteamID: 76561198000000003 | Since Disconnect: 02m.30s | Name: Charlie\n",
+			want: []broadcast.Fields{
+				{"PlayerSlot": "0", "SteamID": "76561198000000001", "Name": "Alpha", "Team": "1", "Squad": "2"},
+				{"PlayerSlot": "4", "SteamID": "76561198000000002", "Name": "Bravo | Two", "Team": "2", "Squad": "N/A"},
+			},
+		},
+		{
+			name: "squad.playerlist.2",
+			output: "----- Active Players -----\n" +
+				"ID: 7 | Online IDs: EOS: 0002a1b2c3d4e5f60718293a4b5c6d7e steam: 76561198000000004 | Name: Delta | Team ID: 1 | Squad ID: 1 | Is Leader: False | Role: USA_Medic_01\n",
+			want: []broadcast.Fields{
+				{"PlayerSlot": "7", "SteamID": "76561198000000004", "Name": "Delta", "Team": "1", "Squad": "1"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern := NewSquadGame().GetConfig().CmdOutputPatterns["PlayerList"]
+
+			var got []broadcast.Fields
+			for _, match := range pattern.FindAllString(tt.output, -1) {
+				got = append(got, regexutils.MapNamedMatches(pattern, match))
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_squad_ChatPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    *broadcast.Broadcast
+	}{
+		{
+			name:    "squad.chat.1",
+			message: "[ChatAll] [SteamID:76561198000000001] Alpha : anyone got a shovel?",
+			want: &broadcast.Broadcast{
+				Type: broadcast.TYPE_CHAT,
+				Fields: broadcast.Fields{
+					"Channel": "ChatAll",
+					"SteamID": "76561198000000001",
+					"Name":    "Alpha",
+					"Message": "anyone got a shovel?",
+				},
+			},
+		},
+		{
+			name:    "squad.chat.2",
+			message: "[ChatSquad] [Online IDs:EOS: 0002a1b2c3d4e5f60718293a4b5c6d7e steam: 76561198000000004] Delta : rally up : now",
+			want: &broadcast.Broadcast{
+				Type: broadcast.TYPE_CHAT,
+				Fields: broadcast.Fields{
+					"Channel": "ChatSquad",
+					"SteamID": "76561198000000004",
+					"Name":    "Delta",
+					"Message": "rally up : now",
+				},
+			},
+		},
+		{
+			name:    "squad.chat.3",
+			message: "Kicked player 4. [steamid=76561198000000002] Bravo",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := broadcast.GetBroadcastType(tt.message, NewSquadGame().GetConfig().BroadcastPatterns)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// Test_squad_FakeServer connects to a local fake Squad server through the RCON service. The player list is long
+// enough to be split across several packets.
+func Test_squad_FakeServer(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	var playerList strings.Builder
+	playerList.WriteString("----- Active Players -----\n")
+	for i := 0; i < 100; i++ {
+		playerList.WriteString(fmt.Sprintf("ID: %d | SteamID: 765611980000%05d | Name: Player %d | Team ID: %d | "+
+			"Squad ID: N/A | Is Leader: False | Role: USA_Rifleman_01\n", i, i, i, i%2+1))
+	}
+	playerList.WriteString("----- Recently Disconnected Players [Max of 15] -----\n")
+	assert.Greater(t, playerList.Len(), sourcercon.MaxBodySize)
+
+	server, err := sourcercon.NewFakeServer("rconpassword", map[string]string{
+		"ListPlayers":    playerList.String(),
+		"ShowServerInfo": "{\"MaxPlayers\":100,\"GameMode_s\":\"RAAS\",\"MapName_s\":\"Narva RAAS v1\",\"ServerName_s\":\"Test Server\"}",
+	})
+	assert.Nil(t, err)
+	defer server.Close()
+
+	gameService := game.NewGameService()
+	gameService.AddGame(NewSquadGame())
+
+	rconService := rcon.NewRCONService(gameService, nil, testLogger)
+
+	var joins []string
+	rconService.SubscribeJoin(func(fields broadcast.Fields, serverID int64, gameConfig *refractor.GameConfig) {
+		joins = append(joins, fields["SteamID"])
+	})
+
+	chats := make(chan *refractor.ChatReceiveBody, 1)
+	rconService.SubscribeChat(func(msgBody *refractor.ChatReceiveBody, serverID int64, gameConfig *refractor.GameConfig) {
+		chats <- msgBody
+	})
+
+	infos := make(chan *refractor.LiveServerInfo, 1)
+	rconService.SubscribeServerInfo(func(serverID int64, info *refractor.LiveServerInfo) {
+		infos <- info
+	})
+
+	offline := make(chan int64, 1)
+	rconService.SubscribeOffline(func(serverID int64) {
+		offline <- serverID
+	})
+
+	err = rconService.CreateClient(&refractor.Server{
+		ServerID:     1,
+		Game:         "Squad",
+		Address:      server.Host(),
+		RCONPort:     strconv.Itoa(int(server.Port())),
+		RCONPassword: "rconpassword",
+	})
+	assert.Nil(t, err)
+
+	assert.Len(t, joins, 100)
+	assert.Equal(t, "76561198000000000", joins[0])
+	assert.Equal(t, "76561198000000099", joins[99])
+
+	select {
+	case info := <-infos:
+		assert.Equal(t, &refractor.LiveServerInfo{
+			Name:       "Test Server",
+			Map:        "Narva RAAS v1",
+			GameMode:   "RAAS",
+			MaxPlayers: 100,
+		}, info)
+	case <-time.After(time.Second):
+		t.Fatal("Server info was not received")
+	}
+
+	// Wait for the broadcast listener to start before broadcasting
+	time.Sleep(50 * time.Millisecond)
+	assert.Nil(t, server.Broadcast("[ChatTeam] [SteamID:76561198000000042] Player 42 : need ammo"))
+
+	select {
+	case chat := <-chats:
+		assert.Equal(t, "76561198000000042", chat.PlayerGameID)
+		assert.Equal(t, "Player 42", chat.Name)
+		assert.Equal(t, "need ammo", chat.Message)
+		assert.Equal(t, "ChatTeam", chat.Channel)
+	case <-time.After(time.Second):
+		t.Fatal("Chat message was not received")
+	}
+
+	assert.Nil(t, server.Close())
+
+	select {
+	case serverID := <-offline:
+		assert.Equal(t, int64(1), serverID)
+	case <-time.After(time.Second):
+		t.Fatal("Server did not go offline")
+	}
+}
@@ -39,14 +39,22 @@ type API struct {
 
 // Handlers holds the handlers for the various application domains
 type Handlers struct {
-	AuthHandler       refractor.AuthHandler
-	UserHandler       refractor.UserHandler
-	ServerHandler     refractor.ServerHandler
-	PlayerHandler     refractor.PlayerHandler
-	GameServerHandler refractor.GameServerHandler
-	InfractionHandler refractor.InfractionHandler
-	SummaryHandler    refractor.SummaryHandler
-	SearchHandler     refractor.SearchHandler
+	AuthHandler          refractor.AuthHandler
+	UserHandler          refractor.UserHandler
+	ServerHandler        refractor.ServerHandler
+	PlayerHandler        refractor.PlayerHandler
+	GameServerHandler    refractor.GameServerHandler
+	InfractionHandler    refractor.InfractionHandler
+	SummaryHandler       refractor.SummaryHandler
+	SearchHandler        refractor.SearchHandler
+	PlayerMergeHandler   refractor.PlayerMergeHandler
+	PlayerDataHandler    refractor.PlayerDataHandler
+	ChatFilterHandler    refractor.ChatFilterHandler
+	ChatSpamHandler      refractor.ChatSpamHandler
+	ChatHandler          refractor.ChatHandler
+	BackfillHandler      refractor.BackfillHandler
+	MatchStatsHandler    refractor.MatchStatsHandler
+	ServerHistoryHandler refractor.ServerHistoryHandler
 }
 
 type Response struct {
@@ -128,6 +136,9 @@ func (api *API) setupRoutes() {
 	serverGroup.GET("/data", api.ServerHandler.GetAllServerData)
 	serverGroup.PATCH("/:id", api.ServerHandler.UpdateServer, api.RequirePerms(perms.FULL_ACCESS))
 	serverGroup.DELETE("/:id", api.ServerHandler.DeleteServer, api.RequirePerms(perms.FULL_ACCESS))
+	serverGroup.POST("/:id/backfill", api.BackfillHandler.StartBackfill, api.RequirePerms(perms.FULL_ACCESS))
+	serverGroup.GET("/:id/backfill", api.BackfillHandler.GetProgress, api.RequirePerms(perms.FULL_ACCESS))
+	serverGroup.GET("/:id/history", api.ServerHistoryHandler.GetServerHistory)
 
 	// Infraction endpoints
 	infractionGroup := apiGroup.Group("/infractions", jwtMiddleware, AttachClaims())
@@ -149,6 +160,44 @@ func (api *API) setupRoutes() {
 	playerGroup.GET("/summary/:id", api.SummaryHandler.GetPlayerSummary)
 	playerGroup.POST("/:id/watch", api.PlayerHandler.SwitchPlayerWatch(true))
 	playerGroup.POST("/:id/unwatch", api.PlayerHandler.SwitchPlayerWatch(false))
+	playerGroup.POST("/:id/trust", api.PlayerHandler.SwitchPlayerTrusted(true), api.RequirePerms(perms.FULL_ACCESS))
+	playerGroup.POST("/:id/untrust", api.PlayerHandler.SwitchPlayerTrusted(false), api.RequirePerms(perms.FULL_ACCESS))
+	playerGroup.POST("/merge", api.PlayerMergeHandler.MergePlayers, api.RequirePerms(perms.FULL_ACCESS))
+	playerGroup.POST("/merge/:id/undo", api.PlayerMergeHandler.UndoMerge, api.RequirePerms(perms.FULL_ACCESS))
+	playerGroup.GET("/:id/export", api.PlayerDataHandler.ExportPlayerData, api.RequirePerms(perms.FULL_ACCESS))
+	playerGroup.POST("/:id/erase", api.PlayerDataHandler.ErasePlayerData, api.RequirePerms(perms.FULL_ACCESS))
+
+	// Chat endpoints
+	chatGroup := apiGroup.Group("/chat", jwtMiddleware, AttachClaims(), api.RequirePerms(perms.VIEW_CHAT_RECORDS))
+	chatGroup.GET("/flagged", api.ChatHandler.GetFlaggedMessages)
+	chatGroup.POST("/flagged/:id/dismiss", api.ChatHandler.DismissFlaggedMessage)
+	chatGroup.POST("/flagged/:id/infraction", api.ChatHandler.CreateInfractionFromMessage)
+	chatGroup.POST("/:id/flag", api.ChatHandler.SwitchMessageFlag(true))
+	chatGroup.POST("/:id/unflag", api.ChatHandler.SwitchMessageFlag(false))
+	chatGroup.GET("/infraction/:id", api.ChatHandler.GetInfractionMessages)
+	chatGroup.GET("/transcript", api.ChatHandler.ExportTranscript)
+	chatGroup.POST("/whisper", api.ChatHandler.SendWhisper)
+	chatGroup.GET("/:id/context", api.ChatHandler.GetMessageContext)
+	chatGroup.GET("/keywords", api.ChatHandler.GetKeywords)
+	chatGroup.POST("/keywords", api.ChatHandler.AddKeyword)
+	chatGroup.DELETE("/keywords/:id", api.ChatHandler.DeleteKeyword)
+
+	// Chat filter endpoints
+	chatFilterGroup := apiGroup.Group("/chatfilters", jwtMiddleware, AttachClaims())
+	chatFilterGroup.GET("/", api.ChatFilterHandler.GetAllRules, api.RequirePerms(perms.FULL_ACCESS))
+	chatFilterGroup.POST("/", api.ChatFilterHandler.CreateRule, api.RequirePerms(perms.FULL_ACCESS))
+	chatFilterGroup.PATCH("/:id", api.ChatFilterHandler.UpdateRule, api.RequirePerms(perms.FULL_ACCESS))
+	chatFilterGroup.DELETE("/:id", api.ChatFilterHandler.DeleteRule, api.RequirePerms(perms.FULL_ACCESS))
+
+	// Chat spam endpoints
+	chatSpamGroup := apiGroup.Group("/chatspam", jwtMiddleware, AttachClaims())
+	chatSpamGroup.GET("/:id", api.ChatSpamHandler.GetSettings, api.RequirePerms(perms.FULL_ACCESS))
+	chatSpamGroup.PATCH("/:id", api.ChatSpamHandler.UpdateSettings, api.RequirePerms(perms.FULL_ACCESS))
+
+	// Teamkill endpoints
+	teamkillGroup := apiGroup.Group("/teamkill", jwtMiddleware, AttachClaims())
+	teamkillGroup.GET("/:id", api.MatchStatsHandler.GetTeamkillSettings, api.RequirePerms(perms.FULL_ACCESS))
+	teamkillGroup.PATCH("/:id", api.MatchStatsHandler.UpdateTeamkillSettings, api.RequirePerms(perms.FULL_ACCESS))
 
 	// Search endpoints
 	searchGroup := apiGroup.Group("/search", jwtMiddleware, AttachClaims())
@@ -0,0 +1,351 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/jwt"
+	"github.com/sniddunc/refractor/refractor"
+	"net/http"
+	"strconv"
+)
+
+type chatHandler struct {
+	service refractor.ChatService
+}
+
+func NewChatHandler(service refractor.ChatService) refractor.ChatHandler {
+	return &chatHandler{
+		service: service,
+	}
+}
+
+func (h *chatHandler) SwitchMessageFlag(flagged bool) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		idString := c.Param("id")
+
+		messageID, err := strconv.ParseInt(idString, 10, 32)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, Response{
+				Success: false,
+				Message: config.MessageInvalidIDProvided,
+			})
+		}
+
+		claims := c.Get("claims").(*jwt.Claims)
+
+		res := h.service.SetMessageFlagged(messageID, flagged, params.UserMeta{
+			UserID:      claims.UserID,
+			Permissions: claims.Permissions,
+		})
+		return c.JSON(res.StatusCode, Response{
+			Success: res.Success,
+			Message: res.Message,
+		})
+	}
+}
+
+type flaggedMessagesRes struct {
+	Total    int                             `json:"total"`
+	Messages []*refractor.FlaggedChatMessage `json:"messages"`
+}
+
+func (h *chatHandler) GetFlaggedMessages(c echo.Context) error {
+	body := params.GetFlaggedMessagesParams{}
+	if ok := ValidateRequest(&body, c); !ok {
+		return nil
+	}
+
+	total, messages, res := h.service.GetFlaggedMessages(body)
+	if !res.Success {
+		return c.JSON(res.StatusCode, Response{
+			Success: res.Success,
+			Message: res.Message,
+		})
+	}
+
+	return c.JSON(res.StatusCode, Response{
+		Success: res.Success,
+		Message: res.Message,
+		Payload: &flaggedMessagesRes{
+			Total:    total,
+			Messages: messages,
+		},
+	})
+}
+
+func (h *chatHandler) DismissFlaggedMessage(c echo.Context) error {
+	idString := c.Param("id")
+
+	messageID, err := strconv.ParseInt(idString, 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: config.MessageInvalidIDProvided,
+		})
+	}
+
+	claims := c.Get("claims").(*jwt.Claims)
+
+	res := h.service.DismissFlaggedMessage(messageID, params.UserMeta{
+		UserID:      claims.UserID,
+		Permissions: claims.Permissions,
+	})
+	return c.JSON(res.StatusCode, Response{
+		Success: res.Success,
+		Message: res.Message,
+	})
+}
+
+func (h *chatHandler) CreateInfractionFromMessage(c echo.Context) error {
+	idString := c.Param("id")
+
+	messageID, err := strconv.ParseInt(idString, 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: config.MessageInvalidIDProvided,
+		})
+	}
+
+	body := params.CreateChatInfractionParams{}
+	if ok := ValidateRequest(&body, c); !ok {
+		return nil
+	}
+
+	claims := c.Get("claims").(*jwt.Claims)
+
+	body.UserMeta = &params.UserMeta{
+		UserID:      claims.UserID,
+		Permissions: claims.Permissions,
+	}
+
+	infraction, res := h.service.CreateInfractionFromMessage(messageID, body)
+	return c.JSON(res.StatusCode, Response{
+		Success: res.Success,
+		Message: res.Message,
+		Errors:  res.ValidationErrors,
+		Payload: infraction,
+	})
+}
+
+func (h *chatHandler) GetInfractionMessages(c echo.Context) error {
+	idString := c.Param("id")
+
+	infractionID, err := strconv.ParseInt(idString, 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: config.MessageInvalidIDProvided,
+		})
+	}
+
+	messages, res := h.service.GetInfractionMessages(infractionID)
+	return c.JSON(res.StatusCode, Response{
+		Success: res.Success,
+		Message: res.Message,
+		Payload: messages,
+	})
+}
+
+func (h *chatHandler) GetMessageContext(c echo.Context) error {
+	idString := c.Param("id")
+
+	messageID, err := strconv.ParseInt(idString, 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: config.MessageInvalidIDProvided,
+		})
+	}
+
+	body := params.GetChatContextParams{}
+	if ok := ValidateRequest(&body, c); !ok {
+		return nil
+	}
+
+	messages, res := h.service.GetMessageContext(messageID, body)
+	return c.JSON(res.StatusCode, Response{
+		Success: res.Success,
+		Message: res.Message,
+		Payload: messages,
+	})
+}
+
+// transcriptStreamWriter writes a transcript out to the response in chunks. Response headers are only sent once the
+// first chunk is written so that an error response can still be sent if the export fails before anything is written.
+type transcriptStreamWriter struct {
+	c           echo.Context
+	filename    string
+	contentType string
+	started     bool
+}
+
+func (w *transcriptStreamWriter) Write(p []byte) (int, error) {
+	res := w.c.Response()
+
+	if !w.started {
+		res.Header().Set(echo.HeaderContentType, w.contentType)
+		res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s\"", w.filename))
+		res.WriteHeader(http.StatusOK)
+		w.started = true
+	}
+
+	n, err := res.Write(p)
+	if err != nil {
+		return n, err
+	}
+
+	res.Flush()
+	return n, nil
+}
+
+var transcriptFormats = map[string]struct {
+	extension   string
+	contentType string
+}{
+	params.TranscriptFormatText: {"txt", echo.MIMETextPlainCharsetUTF8},
+	params.TranscriptFormatCSV:  {"csv", "text/csv; charset=UTF-8"},
+	params.TranscriptFormatJSON: {"json", echo.MIMEApplicationJSONCharsetUTF8},
+}
+
+func (h *chatHandler) ExportTranscript(c echo.Context) error {
+	body := params.ExportTranscriptParams{}
+	if ok := ValidateRequest(&body, c); !ok {
+		return nil
+	}
+
+	format := transcriptFormats[body.Format]
+
+	sw := &transcriptStreamWriter{
+		c:           c,
+		filename:    fmt.Sprintf("transcript-%d-%d-%d.%s", body.ServerID, body.StartDate, body.EndDate, format.extension),
+		contentType: format.contentType,
+	}
+
+	// Writes to the response are buffered so that it is flushed to the client in chunks rather than once per entry
+	bw := bufio.NewWriterSize(sw, config.ChatTranscriptChunkSize)
+
+	res := h.service.ExportTranscript(body, bw)
+	if res.Success {
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if sw.started && !res.Success {
+		// Part of the transcript has already been sent with a 200 status, so abort the connection to make sure the
+		// download fails instead of leaving the client with a truncated file which looks complete.
+		panic(http.ErrAbortHandler)
+	}
+
+	if !sw.started {
+		if !res.Success {
+			return c.JSON(res.StatusCode, Response{
+				Success: res.Success,
+				Message: res.Message,
+			})
+		}
+
+		// Nothing was written, so send the empty transcript's headers on their own
+		_, _ = sw.Write(nil)
+	}
+
+	return nil
+}
+
+func (h *chatHandler) SendWhisper(c echo.Context) error {
+	body := params.SendWhisperParams{}
+	if ok := ValidateRequest(&body, c); !ok {
+		return nil
+	}
+
+	claims := c.Get("claims").(*jwt.Claims)
+
+	body.UserMeta = &params.UserMeta{
+		UserID:      claims.UserID,
+		Permissions: claims.Permissions,
+	}
+
+	message, res := h.service.SendWhisper(body)
+	return c.JSON(res.StatusCode, Response{
+		Success: res.Success,
+		Message: res.Message,
+		Payload: message,
+	})
+}
+
+func (h *chatHandler) AddKeyword(c echo.Context) error {
+	body := params.AddChatKeywordParams{}
+	if ok := ValidateRequest(&body, c); !ok {
+		return nil
+	}
+
+	claims := c.Get("claims").(*jwt.Claims)
+
+	body.UserMeta = &params.UserMeta{
+		UserID:      claims.UserID,
+		Permissions: claims.Permissions,
+	}
+
+	keyword, res := h.service.AddKeyword(body)
+	return c.JSON(res.StatusCode, Response{
+		Success: res.Success,
+		Message: res.Message,
+		Errors:  res.ValidationErrors,
+		Payload: keyword,
+	})
+}
+
+func (h *chatHandler) GetKeywords(c echo.Context) error {
+	claims := c.Get("claims").(*jwt.Claims)
+
+	keywords, res := h.service.GetUserKeywords(claims.UserID)
+	return c.JSON(res.StatusCode, Response{
+		Success: res.Success,
+		Message: res.Message,
+		Payload: keywords,
+	})
+}
+
+func (h *chatHandler) DeleteKeyword(c echo.Context) error {
+	idString := c.Param("id")
+
+	keywordID, err := strconv.ParseInt(idString, 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: config.MessageInvalidIDProvided,
+		})
+	}
+
+	claims := c.Get("claims").(*jwt.Claims)
+
+	res := h.service.DeleteKeyword(keywordID, params.UserMeta{
+		UserID:      claims.UserID,
+		Permissions: claims.Permissions,
+	})
+	return c.JSON(res.StatusCode, Response{
+		Success: res.Success,
+		Message: res.Message,
+	})
+}
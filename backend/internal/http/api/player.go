@@ -19,6 +19,7 @@ package api
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/sniddunc/refractor/internal/params"
 	"github.com/sniddunc/refractor/pkg/broadcast"
 	"github.com/sniddunc/refractor/pkg/config"
 	"github.com/sniddunc/refractor/refractor"
@@ -37,7 +38,12 @@ func NewPlayerHandler(service refractor.PlayerService) refractor.PlayerHandler {
 }
 
 func (h *playerHandler) GetRecentPlayers(c echo.Context) error {
-	recentPlayers, res := h.service.GetRecentPlayers()
+	body := params.GetRecentPlayersParams{}
+	if ok := ValidateRequest(&body, c); !ok {
+		return nil
+	}
+
+	recentPlayers, res := h.service.GetRecentPlayers(body)
 	return c.JSON(res.StatusCode, Response{
 		Success: res.Success,
 		Message: res.Message,
@@ -65,6 +71,26 @@ func (h *playerHandler) SwitchPlayerWatch(watch bool) echo.HandlerFunc {
 	}
 }
 
+func (h *playerHandler) SwitchPlayerTrusted(trusted bool) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		idString := c.Param("id")
+
+		playerID, err := strconv.ParseInt(idString, 10, 32)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, Response{
+				Success: false,
+				Message: config.MessageInvalidIDProvided,
+			})
+		}
+
+		res := h.service.SetPlayerTrusted(playerID, trusted)
+		return c.JSON(res.StatusCode, Response{
+			Success: res.Success,
+			Message: res.Message,
+		})
+	}
+}
+
 func (h *playerHandler) OnPlayerJoin(fields broadcast.Fields, serverID int64, gameConfig *refractor.GameConfig) {
 	h.service.OnPlayerJoin(serverID, fields[gameConfig.PlayerGameIDField], fields["Name"], gameConfig)
 }
@@ -0,0 +1,93 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/jwt"
+	"github.com/sniddunc/refractor/refractor"
+	"net/http"
+	"strconv"
+)
+
+type playerDataHandler struct {
+	service refractor.PlayerDataService
+}
+
+func NewPlayerDataHandler(service refractor.PlayerDataService) refractor.PlayerDataHandler {
+	return &playerDataHandler{
+		service: service,
+	}
+}
+
+func (h *playerDataHandler) ExportPlayerData(c echo.Context) error {
+	idString := c.Param("id")
+
+	playerID, err := strconv.ParseInt(idString, 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: config.MessageInvalidIDProvided,
+		})
+	}
+
+	claims := c.Get("claims").(*jwt.Claims)
+
+	export, res := h.service.ExportPlayerData(playerID, params.UserMeta{
+		UserID:      claims.UserID,
+		Permissions: claims.Permissions,
+	})
+	if !res.Success {
+		return c.JSON(res.StatusCode, Response{
+			Success: res.Success,
+			Message: res.Message,
+		})
+	}
+
+	// The archive is sent on its own rather than wrapped in a Response so that it can be saved as is
+	c.Response().Header().Set(echo.HeaderContentDisposition,
+		fmt.Sprintf("attachment; filename=\"player-%d.json\"", playerID))
+
+	return c.JSON(res.StatusCode, export)
+}
+
+func (h *playerDataHandler) ErasePlayerData(c echo.Context) error {
+	idString := c.Param("id")
+
+	playerID, err := strconv.ParseInt(idString, 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: config.MessageInvalidIDProvided,
+		})
+	}
+
+	claims := c.Get("claims").(*jwt.Claims)
+
+	res := h.service.ErasePlayerData(playerID, params.UserMeta{
+		UserID:      claims.UserID,
+		Permissions: claims.Permissions,
+	})
+	return c.JSON(res.StatusCode, Response{
+		Success: res.Success,
+		Message: res.Message,
+	})
+}
@@ -0,0 +1,84 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/jwt"
+	"github.com/sniddunc/refractor/refractor"
+	"net/http"
+	"strconv"
+)
+
+type playerMergeHandler struct {
+	service refractor.PlayerMergeService
+}
+
+func NewPlayerMergeHandler(service refractor.PlayerMergeService) refractor.PlayerMergeHandler {
+	return &playerMergeHandler{
+		service: service,
+	}
+}
+
+func (h *playerMergeHandler) MergePlayers(c echo.Context) error {
+	body := params.MergePlayersParams{}
+	if ok := ValidateRequest(&body, c); !ok {
+		return nil
+	}
+
+	claims := c.Get("claims").(*jwt.Claims)
+
+	body.UserMeta = &params.UserMeta{
+		UserID:      claims.UserID,
+		Permissions: claims.Permissions,
+	}
+
+	merge, res := h.service.MergePlayers(body)
+	return c.JSON(res.StatusCode, Response{
+		Success: res.Success,
+		Message: res.Message,
+		Errors:  res.ValidationErrors,
+		Payload: merge,
+	})
+}
+
+func (h *playerMergeHandler) UndoMerge(c echo.Context) error {
+	idString := c.Param("id")
+
+	mergeID, err := strconv.ParseInt(idString, 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: config.MessageInvalidIDProvided,
+		})
+	}
+
+	claims := c.Get("claims").(*jwt.Claims)
+
+	merge, res := h.service.UndoMerge(mergeID, params.UserMeta{
+		UserID:      claims.UserID,
+		Permissions: claims.Permissions,
+	})
+	return c.JSON(res.StatusCode, Response{
+		Success: res.Success,
+		Message: res.Message,
+		Payload: merge,
+	})
+}
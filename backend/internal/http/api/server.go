@@ -75,6 +75,9 @@ type serverDataRes struct {
 	Online      bool                `json:"online"`
 	PlayerCount int                 `json:"playerCount"`
 	Players     []*refractor.Player `json:"players"`
+
+	// Info is the latest info polled from the server if its game supports it
+	Info *refractor.LiveServerInfo `json:"info,omitempty"`
 }
 
 func (h *serverHandler) GetAllServerData(c echo.Context) error {
@@ -134,6 +137,7 @@ func (h *serverHandler) GetAllServerData(c echo.Context) error {
 			Online:      serverData.Online,
 			PlayerCount: serverData.PlayerCount,
 			Players:     players,
+			Info:        serverData.Info,
 		})
 	}
 
@@ -200,6 +204,14 @@ func (h *serverHandler) OnPlayerJoin(fields broadcast.Fields, serverID int64, ga
 		return
 	}
 
+	if player == nil {
+		h.log.Warn("Could not add unknown player %v = %v to the data of server ID %d", playerGameID,
+			fields[playerGameID], serverID)
+		return
+	}
+
+	player.Fields = gameConfig.GetPlayerListFields(fields)
+
 	h.service.OnPlayerJoin(serverID, player)
 }
 
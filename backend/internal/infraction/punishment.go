@@ -0,0 +1,139 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package infraction
+
+import (
+	"database/sql"
+	"github.com/sniddunc/refractor/pkg/broadcast"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/refractor"
+	"strconv"
+	"time"
+)
+
+// punishmentTypes maps the actions reported in punishment broadcasts to the infraction types they are recorded as.
+// Actions which lift a punishment (e.g. unbans) are not recorded.
+var punishmentTypes = map[string]string{
+	"banned": refractor.INFRACTION_TYPE_BAN,
+	"kicked": refractor.INFRACTION_TYPE_KICK,
+	"muted":  refractor.INFRACTION_TYPE_MUTE,
+}
+
+// OnPunishment records a punishment issued by an admin in-game as a system infraction. The in-game admin is linked
+// to the infraction as its issuer if they are a known player. Punishments which were just recorded through Refractor
+// are skipped so that commands run by Refractor itself are not recorded twice.
+func (s *infractionService) OnPunishment(fields broadcast.Fields, serverID int64, gameConfig *refractor.GameConfig) {
+	infractionType := punishmentTypes[fields["Action"]]
+	if infractionType == "" {
+		s.log.Info("Ignoring in-game punishment action %s on server ID %d", fields["Action"], serverID)
+		return
+	}
+
+	playerGameID := fields[gameConfig.PlayerGameIDField]
+
+	player, _ := s.playerService.GetPlayer(refractor.FindArgs{
+		gameConfig.PlayerGameIDField: playerGameID,
+	})
+	if player == nil {
+		s.log.Warn("Could not record in-game %s of unknown player %s on server ID %d", infractionType, playerGameID,
+			serverID)
+		return
+	}
+
+	now := time.Now().Unix()
+
+	if s.recentlyRecorded(player.PlayerID, serverID, infractionType, now) {
+		return
+	}
+
+	// Identify the in-game admin if they are a known player
+	var issuerPlayerID int64
+	if adminGameID := fields["Admin"+gameConfig.PlayerGameIDField]; adminGameID != "" {
+		admin, _ := s.playerService.GetPlayer(refractor.FindArgs{
+			gameConfig.PlayerGameIDField: adminGameID,
+		})
+
+		if admin != nil {
+			issuerPlayerID = admin.PlayerID
+		}
+	}
+
+	reason := fields["Reason"]
+	if reason == "" {
+		reason = config.InGamePunishmentDefaultReason
+	}
+
+	duration := sql.NullInt32{}
+	if infractionType == refractor.INFRACTION_TYPE_MUTE || infractionType == refractor.INFRACTION_TYPE_BAN {
+		minutes, _ := strconv.Atoi(fields["Duration"])
+		duration = sql.NullInt32{Int32: int32(minutes), Valid: true}
+	}
+
+	infraction, res := s.createInfraction(player.PlayerID, 0, serverID, infractionType,
+		sql.NullString{String: reason, Valid: true}, duration, now, true, issuerPlayerID)
+	if infraction == nil {
+		s.log.Error("Could not record in-game %s of player ID %d on server ID %d: %s", infractionType,
+			player.PlayerID, serverID, res.Message)
+		return
+	}
+
+	s.log.Info("Recorded in-game %s of player ID %d by %s on server ID %d", infractionType, player.PlayerID,
+		fields["AdminName"], serverID)
+}
+
+// recentlyRecorded checks if an infraction of the same type was recorded for the player on the server within
+// config.InGamePunishmentDedupWindow seconds.
+func (s *infractionService) recentlyRecorded(playerID int64, serverID int64, infractionType string, now int64) bool {
+	infractions, err := s.repo.FindMany(refractor.FindArgs{
+		"PlayerID": playerID,
+		"ServerID": serverID,
+		"Type":     infractionType,
+	})
+	if err != nil && err != refractor.ErrNotFound {
+		s.log.Error("Could not find infractions of player ID %d. Error: %v", playerID, err)
+		return false
+	}
+
+	for _, infraction := range infractions {
+		if now-infraction.Timestamp <= config.InGamePunishmentDedupWindow {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setStaffName sets the name of whoever issued an infraction. This is the Refractor user's username for infractions
+// created through Refractor and the in-game admin's current name for infractions issued in-game.
+func (s *infractionService) setStaffName(infraction *refractor.Infraction) {
+	if infraction.UserID != 0 {
+		user, _ := s.userService.GetUserByID(infraction.UserID)
+		if user != nil {
+			infraction.StaffName = user.Username
+		}
+
+		return
+	}
+
+	if infraction.IssuerPlayerID != 0 {
+		issuer, _ := s.playerService.GetPlayerByID(infraction.IssuerPlayerID)
+		if issuer != nil {
+			infraction.StaffName = issuer.CurrentName
+		}
+	}
+}
@@ -0,0 +1,189 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package infraction
+
+import (
+	"database/sql"
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/internal/player"
+	"github.com/sniddunc/refractor/internal/server"
+	"github.com/sniddunc/refractor/pkg/broadcast"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_infractionService_OnPunishment(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	gameConfig := &refractor.GameConfig{
+		PlayerGameIDField: "PlayFabID",
+	}
+
+	type fields struct {
+		mockInfractions map[int64]*refractor.DBInfraction
+	}
+	type args struct {
+		fields broadcast.Fields
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		args           args
+		wantInfraction *refractor.DBInfraction
+	}{
+		{
+			name: "infraction.onpunishment.1",
+			fields: fields{
+				mockInfractions: map[int64]*refractor.DBInfraction{},
+			},
+			args: args{
+				fields: broadcast.Fields{
+					"AdminName":      "Admin",
+					"AdminPlayFabID": "ADMIN",
+					"Action":         "banned",
+					"PlayFabID":      "TARGET",
+					"Duration":       "60",
+					"Reason":         "Teamkilling",
+				},
+			},
+			wantInfraction: &refractor.DBInfraction{
+				InfractionID:   1,
+				PlayerID:       1,
+				ServerID:       1,
+				Type:           refractor.INFRACTION_TYPE_BAN,
+				Reason:         sql.NullString{String: "Teamkilling", Valid: true},
+				Duration:       sql.NullInt32{Int32: 60, Valid: true},
+				SystemAction:   true,
+				IssuerPlayerID: 2,
+			},
+		},
+		{
+			name: "infraction.onpunishment.2",
+			fields: fields{
+				mockInfractions: map[int64]*refractor.DBInfraction{},
+			},
+			args: args{
+				fields: broadcast.Fields{
+					"AdminName":      "Unknown",
+					"AdminPlayFabID": "UNKNOWN",
+					"Action":         "kicked",
+					"PlayFabID":      "TARGET",
+				},
+			},
+			wantInfraction: &refractor.DBInfraction{
+				InfractionID: 1,
+				PlayerID:     1,
+				ServerID:     1,
+				Type:         refractor.INFRACTION_TYPE_KICK,
+				Reason:       sql.NullString{String: config.InGamePunishmentDefaultReason, Valid: true},
+				SystemAction: true,
+			},
+		},
+		{
+			name: "infraction.onpunishment.3",
+			fields: fields{
+				mockInfractions: map[int64]*refractor.DBInfraction{
+					1: {
+						InfractionID: 1,
+						PlayerID:     1,
+						ServerID:     1,
+						Type:         refractor.INFRACTION_TYPE_KICK,
+						Timestamp:    time.Now().Unix() - 10,
+					},
+				},
+			},
+			args: args{
+				fields: broadcast.Fields{
+					"Action":    "kicked",
+					"PlayFabID": "TARGET",
+				},
+			},
+			wantInfraction: nil,
+		},
+		{
+			name: "infraction.onpunishment.4",
+			fields: fields{
+				mockInfractions: map[int64]*refractor.DBInfraction{},
+			},
+			args: args{
+				fields: broadcast.Fields{
+					"Action":    "unbanned",
+					"PlayFabID": "TARGET",
+				},
+			},
+			wantInfraction: nil,
+		},
+		{
+			name: "infraction.onpunishment.5",
+			fields: fields{
+				mockInfractions: map[int64]*refractor.DBInfraction{},
+			},
+			args: args{
+				fields: broadcast.Fields{
+					"Action":    "banned",
+					"PlayFabID": "NOBODY",
+				},
+			},
+			wantInfraction: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := len(tt.fields.mockInfractions)
+
+			mockPlayerRepo := mock.NewMockPlayerRepository(map[int64]*refractor.DBPlayer{
+				1: {
+					PlayerID:  1,
+					PlayFabID: sql.NullString{String: "TARGET", Valid: true},
+				},
+				2: {
+					PlayerID:  2,
+					PlayFabID: sql.NullString{String: "ADMIN", Valid: true},
+				},
+			})
+			playerService := player.NewPlayerService(mockPlayerRepo, nil, nil, testLogger)
+			mockServerRepo := mock.NewMockServerRepository(map[int64]*refractor.Server{
+				1: {
+					ServerID: 1,
+				},
+			})
+			serverService := server.NewServerService(mockServerRepo, nil, nil, testLogger)
+			mockInfractionRepo := mock.NewMockInfractionRepository(tt.fields.mockInfractions)
+			infractionService := NewInfractionService(mockInfractionRepo, playerService, serverService, nil, testLogger)
+
+			infractionService.OnPunishment(tt.args.fields, 1, gameConfig)
+
+			if tt.wantInfraction == nil {
+				assert.Equal(t, existing, len(tt.fields.mockInfractions), "No infraction should have been created")
+				return
+			}
+
+			created := tt.fields.mockInfractions[tt.wantInfraction.InfractionID]
+			assert.NotNil(t, created, "Infraction should have been created")
+
+			// Timestamps are set at creation so we copy it over before comparing
+			tt.wantInfraction.Timestamp = created.Timestamp
+
+			assert.Equal(t, tt.wantInfraction, created, "Infractions should be equal")
+		})
+	}
+}
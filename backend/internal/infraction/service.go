@@ -58,7 +58,7 @@ func (s *infractionService) CreateWarning(userID int64, body params.CreateWarnin
 	reason := sql.NullString{String: body.Reason, Valid: true}
 
 	warning, res := s.createInfraction(body.PlayerID, userID, body.ServerID, refractor.INFRACTION_TYPE_WARNING, reason,
-		duration, time.Now().Unix(), false)
+		duration, time.Now().Unix(), false, 0)
 
 	return warning, res
 }
@@ -69,7 +69,7 @@ func (s *infractionService) CreateMute(userID int64, body params.CreateMuteParam
 	reason := sql.NullString{String: body.Reason, Valid: true}
 
 	mute, res := s.createInfraction(body.PlayerID, userID, body.ServerID, refractor.INFRACTION_TYPE_MUTE, reason,
-		duration, time.Now().Unix(), false)
+		duration, time.Now().Unix(), false, 0)
 
 	return mute, res
 }
@@ -80,7 +80,7 @@ func (s *infractionService) CreateKick(userID int64, body params.CreateKickParam
 	reason := sql.NullString{String: body.Reason, Valid: true}
 
 	kick, res := s.createInfraction(body.PlayerID, userID, body.ServerID, refractor.INFRACTION_TYPE_KICK, reason,
-		duration, time.Now().Unix(), false)
+		duration, time.Now().Unix(), false, 0)
 
 	return kick, res
 }
@@ -91,15 +91,30 @@ func (s *infractionService) CreateBan(userID int64, body params.CreateBanParams)
 	reason := sql.NullString{String: body.Reason, Valid: true}
 
 	ban, res := s.createInfraction(body.PlayerID, userID, body.ServerID, refractor.INFRACTION_TYPE_BAN, reason,
-		duration, time.Now().Unix(), false)
+		duration, time.Now().Unix(), false, 0)
 
 	return ban, res
 }
 
+// CreateSystemInfraction creates an infraction which was issued automatically by Refractor rather than by a user.
+// userID should be the user responsible for the automated action, such as the creator of the rule which triggered it,
+// or 0 if no user is responsible, in which case the infraction is stored without one.
+func (s *infractionService) CreateSystemInfraction(userID int64, playerID int64, serverID int64, infractionType string,
+	reason string, duration int) (*refractor.Infraction, *refractor.ServiceResponse) {
+	nullDuration := sql.NullInt32{}
+	if infractionType == refractor.INFRACTION_TYPE_MUTE || infractionType == refractor.INFRACTION_TYPE_BAN {
+		nullDuration = sql.NullInt32{Int32: int32(duration), Valid: true}
+	}
+
+	return s.createInfraction(playerID, userID, serverID, infractionType, sql.NullString{String: reason, Valid: true},
+		nullDuration, time.Now().Unix(), true, 0)
+}
+
 // We don't just make this function a member of the infraction service interface because there is a good chance we'll need to wrap
 // other code around this logic in the future. To avoid code repetition, the creation logic was moved into this function.
 func (s *infractionService) createInfraction(playerID int64, userID int64, serverID int64, infractionType string,
-	reason sql.NullString, duration sql.NullInt32, timestamp int64, systemAction bool) (*refractor.Infraction, *refractor.ServiceResponse) {
+	reason sql.NullString, duration sql.NullInt32, timestamp int64, systemAction bool,
+	issuerPlayerID int64) (*refractor.Infraction, *refractor.ServiceResponse) {
 
 	// Make sure player exists
 	player, _ := s.playerService.GetPlayerByID(playerID)
@@ -134,6 +149,8 @@ func (s *infractionService) createInfraction(playerID int64, userID int64, serve
 		Duration:     duration,
 		Timestamp:    timestamp,
 		SystemAction: systemAction,
+
+		IssuerPlayerID: issuerPlayerID,
 	}
 
 	infraction, err := s.repo.Create(newInfraction)
@@ -145,11 +162,7 @@ func (s *infractionService) createInfraction(playerID int64, userID int64, serve
 	// Notify subscribers
 	if len(s.infractionCreateSubscribers) > 0 {
 		infraction.PlayerName = player.CurrentName
-
-		user, _ := s.userService.GetUserByID(userID)
-		if user != nil {
-			infraction.StaffName = user.Username
-		}
+		s.setStaffName(infraction)
 
 		for _, subscriber := range s.infractionCreateSubscribers {
 			subscriber(infraction)
@@ -294,6 +307,46 @@ func (s *infractionService) UpdateInfraction(id int64, body params.UpdateInfract
 	}
 }
 
+// GetActiveMute returns the player's active mute on a server. If the player has several active mutes, the one which
+// expires last is returned. A nil infraction is returned if the player isn't muted.
+func (s *infractionService) GetActiveMute(playerID int64, serverID int64) (*refractor.Infraction, *refractor.ServiceResponse) {
+	mutes, err := s.repo.FindMany(refractor.FindArgs{
+		"PlayerID": playerID,
+		"ServerID": serverID,
+		"Type":     refractor.INFRACTION_TYPE_MUTE,
+	})
+	if err != nil && err != refractor.ErrNotFound {
+		s.log.Error("Could not find mutes of player ID %d. Error: %v", playerID, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	now := time.Now().Unix()
+
+	var activeMute *refractor.Infraction
+	for _, mute := range mutes {
+		if !mute.IsActive(now) {
+			continue
+		}
+
+		if activeMute == nil || mute.ExpiresAt() == 0 ||
+			(activeMute.ExpiresAt() != 0 && mute.ExpiresAt() > activeMute.ExpiresAt()) {
+			activeMute = mute
+		}
+	}
+
+	res := &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Player is muted",
+	}
+
+	if activeMute == nil {
+		res.Message = "Player is not muted"
+	}
+
+	return activeMute, res
+}
+
 func (s *infractionService) GetPlayerInfractionsType(infractionType string, playerID int64) ([]*refractor.Infraction, *refractor.ServiceResponse) {
 	infractions, err := s.repo.FindMany(refractor.FindArgs{
 		"PlayerID": playerID,
@@ -314,14 +367,7 @@ func (s *infractionService) GetPlayerInfractionsType(infractionType string, play
 
 	// Get staff names
 	for _, infraction := range infractions {
-		user, err := s.userService.GetUserByID(infraction.UserID)
-		if err != nil {
-			s.log.Error("Could not get infraction user by ID. Error: %v", err)
-			continue
-		}
-
-		// Set StaffName to contain the staff member's username
-		infraction.StaffName = user.Username
+		s.setStaffName(infraction)
 	}
 
 	return infractions, &refractor.ServiceResponse{
@@ -348,14 +394,7 @@ func (s *infractionService) GetPlayerInfractions(playerID int64) ([]*refractor.I
 
 	// Get staff names
 	for _, infraction := range infractions {
-		user, err := s.userService.GetUserByID(infraction.UserID)
-		if err != nil {
-			s.log.Error("Could not get infraction user by ID. Error: %v", err)
-			continue
-		}
-
-		// Set StaffName to contain the staff member's username
-		infraction.StaffName = user.Username
+		s.setStaffName(infraction)
 	}
 
 	return infractions, &refractor.ServiceResponse{
@@ -32,6 +32,7 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 )
 
 func Test_infractionService_CreateWarning(t *testing.T) {
@@ -92,7 +93,7 @@ func Test_infractionService_CreateWarning(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockPlayerRepo := mock.NewMockPlayerRepository(tt.fields.mockPlayers)
-			playerService := player.NewPlayerService(mockPlayerRepo, testLogger)
+			playerService := player.NewPlayerService(mockPlayerRepo, nil, nil, testLogger)
 			mockServerRepo := mock.NewMockServerRepository(tt.fields.mockServers)
 			serverService := server.NewServerService(mockServerRepo, nil, nil, testLogger)
 			mockInfractionRepo := mock.NewMockInfractionRepository(map[int64]*refractor.DBInfraction{})
@@ -753,3 +754,95 @@ func infractionsAreEqual(infraction1 *refractor.Infraction, infraction2 *refract
 //		})
 //	}
 //}
+
+func Test_infractionService_GetActiveMute(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	now := time.Now().Unix()
+
+	mute := func(id int64, playerID int64, serverID int64, duration int32, timestamp int64) *refractor.DBInfraction {
+		return &refractor.DBInfraction{
+			InfractionID: id,
+			PlayerID:     playerID,
+			UserID:       1,
+			ServerID:     serverID,
+			Type:         refractor.INFRACTION_TYPE_MUTE,
+			Duration:     sql.NullInt32{Int32: duration, Valid: true},
+			Timestamp:    timestamp,
+		}
+	}
+
+	tests := []struct {
+		name             string
+		mockInfractions  map[int64]*refractor.DBInfraction
+		playerID         int64
+		serverID         int64
+		wantInfractionID int64
+	}{
+		{
+			name: "infraction.getactivemute.1",
+			mockInfractions: map[int64]*refractor.DBInfraction{
+				1: mute(1, 1, 1, 60, now-600),
+			},
+			playerID:         1,
+			serverID:         1,
+			wantInfractionID: 1,
+		},
+		{
+			name: "infraction.getactivemute.2",
+			mockInfractions: map[int64]*refractor.DBInfraction{
+				1: mute(1, 1, 1, 5, now-600),
+			},
+			playerID:         1,
+			serverID:         1,
+			wantInfractionID: 0,
+		},
+		{
+			name: "infraction.getactivemute.3",
+			mockInfractions: map[int64]*refractor.DBInfraction{
+				1: mute(1, 1, 1, 60, now-600),
+				2: mute(2, 1, 1, 0, now-86400),
+				3: mute(3, 1, 1, 120, now-600),
+			},
+			playerID:         1,
+			serverID:         1,
+			wantInfractionID: 2,
+		},
+		{
+			name: "infraction.getactivemute.4",
+			mockInfractions: map[int64]*refractor.DBInfraction{
+				1: mute(1, 1, 1, 60, now-600),
+				2: mute(2, 1, 1, 120, now-600),
+			},
+			playerID:         1,
+			serverID:         1,
+			wantInfractionID: 2,
+		},
+		{
+			name: "infraction.getactivemute.5",
+			mockInfractions: map[int64]*refractor.DBInfraction{
+				1: mute(1, 1, 2, 60, now-600),
+				2: mute(2, 2, 1, 60, now-600),
+			},
+			playerID:         1,
+			serverID:         1,
+			wantInfractionID: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			infractionService := NewInfractionService(mock.NewMockInfractionRepository(tt.mockInfractions), nil, nil,
+				nil, testLogger)
+
+			activeMute, res := infractionService.GetActiveMute(tt.playerID, tt.serverID)
+			assert.True(t, res.Success)
+
+			var gotInfractionID int64
+			if activeMute != nil {
+				gotInfractionID = activeMute.InfractionID
+			}
+
+			assert.Equal(t, tt.wantInfractionID, gotInfractionID)
+		})
+	}
+}
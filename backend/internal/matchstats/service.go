@@ -0,0 +1,507 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package matchstats
+
+import (
+	"fmt"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/broadcast"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/pkg/matchstats"
+	"github.com/sniddunc/refractor/pkg/regexutils"
+	"github.com/sniddunc/refractor/pkg/settingscache"
+	"github.com/sniddunc/refractor/refractor"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type matchStatsService struct {
+	repo              refractor.MatchStatsRepository
+	playerService     refractor.PlayerService
+	serverService     refractor.ServerService
+	gameService       refractor.GameService
+	infractionService refractor.InfractionService
+	websocketService  refractor.WebsocketService
+	rconService       refractor.RCONService
+	log               log.Logger
+
+	tracker *matchstats.Tracker
+
+	// settings is a cache of each server's teamkill settings. Servers without stored settings are cached with the
+	// defaults.
+	settings *settingscache.Cache
+
+	// teams is a cache of the team each online player is on, per server
+	teams   map[int64]*serverTeams
+	teamsMu sync.Mutex
+}
+
+type serverTeams struct {
+	teams     map[string]string
+	refreshed time.Time
+}
+
+func NewMatchStatsService(repo refractor.MatchStatsRepository, playerService refractor.PlayerService,
+	serverService refractor.ServerService, gameService refractor.GameService,
+	infractionService refractor.InfractionService, websocketService refractor.WebsocketService,
+	rconService refractor.RCONService, log log.Logger) refractor.MatchStatsService {
+	s := &matchStatsService{
+		repo:              repo,
+		playerService:     playerService,
+		serverService:     serverService,
+		gameService:       gameService,
+		infractionService: infractionService,
+		websocketService:  websocketService,
+		rconService:       rconService,
+		log:               log,
+		tracker:           matchstats.NewTracker(),
+		teams:             map[int64]*serverTeams{},
+	}
+
+	s.settings = settingscache.New(s.loadSettings)
+
+	return s
+}
+
+// GetPlayerMatchStats returns the stats of a player's most recent matches, most recent first
+func (s *matchStatsService) GetPlayerMatchStats(playerID int64) ([]*refractor.MatchStats, *refractor.ServiceResponse) {
+	stats, err := s.repo.FindByPlayerID(playerID, config.PlayerSummaryMatchStatsLimit)
+	if err != nil {
+		if err == refractor.ErrNotFound {
+			return []*refractor.MatchStats{}, &refractor.ServiceResponse{
+				Success:    true,
+				StatusCode: http.StatusOK,
+				Message:    "Fetched 0 match stats",
+			}
+		}
+
+		s.log.Error("Could not find match stats of player ID %d. Error: %v", playerID, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	return stats, &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("Fetched %d match stats", len(stats)),
+	}
+}
+
+func (s *matchStatsService) GetTeamkillSettings(serverID int64) (*refractor.TeamkillSettings, *refractor.ServiceResponse) {
+	if server, _ := s.serverService.GetServerByID(serverID); server == nil {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    config.MessageInvalidIDProvided,
+		}
+	}
+
+	settings, err := s.getSettings(serverID)
+	if err != nil {
+		s.log.Error("Could not get teamkill settings for server ID %d. Error: %v", serverID, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	return settings, &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Fetched teamkill settings",
+	}
+}
+
+func (s *matchStatsService) UpdateTeamkillSettings(serverID int64, body params.UpdateTeamkillSettingsParams) (*refractor.TeamkillSettings, *refractor.ServiceResponse) {
+	if server, _ := s.serverService.GetServerByID(serverID); server == nil {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    config.MessageInvalidIDProvided,
+		}
+	}
+
+	updated, err := s.settings.Update(serverID, func(current interface{}) (interface{}, error) {
+		settings := *current.(*refractor.TeamkillSettings)
+
+		if body.Enabled != nil {
+			settings.Enabled = *body.Enabled
+		}
+
+		if body.Threshold != nil {
+			settings.Threshold = *body.Threshold
+		}
+
+		if body.Action != nil {
+			settings.Action = *body.Action
+		}
+
+		if body.BanDuration != nil {
+			settings.BanDuration = *body.BanDuration
+		}
+
+		settings.UpdatedBy = body.UserMeta.UserID
+
+		if err := s.repo.SaveTeamkillSettings(&settings); err != nil {
+			return nil, err
+		}
+
+		return &settings, nil
+	})
+	if err != nil {
+		s.log.Error("Could not update teamkill settings for server ID %d. Error: %v", serverID, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	s.log.Info("User ID %d updated the teamkill settings of server ID %d", body.UserMeta.UserID, serverID)
+
+	return updated.(*refractor.TeamkillSettings), &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Teamkill settings updated",
+	}
+}
+
+// OnMatchState starts a new match when a server reports that a match is in progress and ends the current match on
+// any other state. The stats of ended matches are stored.
+func (s *matchStatsService) OnMatchState(fields broadcast.Fields, serverID int64, gameConfig *refractor.GameConfig) {
+	now := time.Now()
+
+	var ended *matchstats.Match
+
+	if gameConfig.MatchInProgressState != "" && fields["State"] == gameConfig.MatchInProgressState {
+		ended = s.tracker.StartMatch(serverID, now)
+
+		// Teams are usually shuffled between matches
+		s.teamsMu.Lock()
+		delete(s.teams, serverID)
+		s.teamsMu.Unlock()
+	} else {
+		ended = s.tracker.EndMatch(serverID, now)
+	}
+
+	s.saveMatch(serverID, ended, gameConfig.PlayerGameIDField)
+}
+
+type teamkillAlertBody struct {
+	ServerID  int64             `json:"serverId"`
+	Player    *refractor.Player `json:"player"`
+	Teamkills int               `json:"teamkills"`
+	Action    string            `json:"action"`
+}
+
+// OnKill records a kill in the current match of a server. A kill is a teamkill if the killer and victim are on the
+// same team. When a player's teamkills in a match reach the server's threshold, staff are alerted and the configured
+// action is taken against them.
+func (s *matchStatsService) OnKill(fields broadcast.Fields, serverID int64, gameConfig *refractor.GameConfig) {
+	killerGameID := fields["Killer"+gameConfig.PlayerGameIDField]
+	victimGameID := fields[gameConfig.PlayerGameIDField]
+
+	teamkill := false
+	if killerGameID != "" && killerGameID != victimGameID {
+		teamkill = s.onSameTeam(serverID, killerGameID, victimGameID)
+	}
+
+	killerStats := s.tracker.RecordKill(serverID, killerGameID, victimGameID, teamkill, time.Now())
+
+	if teamkill {
+		s.checkTeamkills(serverID, killerGameID, killerStats.Teamkills, gameConfig)
+	}
+}
+
+// OnServerOffline ends and stores the current match of a server which went offline
+func (s *matchStatsService) OnServerOffline(serverID int64) {
+	s.teamsMu.Lock()
+	delete(s.teams, serverID)
+	s.teamsMu.Unlock()
+
+	ended := s.tracker.EndMatch(serverID, time.Now())
+	if ended == nil {
+		return
+	}
+
+	game := s.getGame(serverID)
+	if game == nil {
+		s.log.Warn("Could not store the match stats of server ID %d as its game could not be found", serverID)
+		return
+	}
+
+	s.saveMatch(serverID, ended, game.GetConfig().PlayerGameIDField)
+}
+
+// checkTeamkills alerts staff and takes the configured action against a player once their teamkills in the current
+// match reach the threshold. Trusted players are never acted against automatically, but staff are still alerted.
+func (s *matchStatsService) checkTeamkills(serverID int64, playerGameID string, teamkills int,
+	gameConfig *refractor.GameConfig) {
+	settings, err := s.getSettings(serverID)
+	if err != nil {
+		s.log.Error("Could not get teamkill settings for server ID %d. Error: %v", serverID, err)
+		return
+	}
+
+	if !settings.Enabled || teamkills != settings.Threshold {
+		return
+	}
+
+	player, _ := s.playerService.GetPlayer(refractor.FindArgs{
+		gameConfig.PlayerGameIDField: playerGameID,
+	})
+	if player == nil {
+		s.log.Warn("Could not act on teamkills of unknown player %s on server ID %d", playerGameID, serverID)
+		return
+	}
+
+	action := settings.Action
+	if player.Trusted {
+		action = matchstats.ACTION_ALERT
+	}
+
+	s.log.Info("Player ID %d reached %d teamkills on server ID %d (%s)", player.PlayerID, teamkills, serverID, action)
+
+	s.websocketService.Broadcast(&refractor.WebsocketMessage{
+		Type: "teamkill-alert",
+		Body: &teamkillAlertBody{
+			ServerID:  serverID,
+			Player:    player,
+			Teamkills: teamkills,
+			Action:    action,
+		},
+	})
+
+	if action == matchstats.ACTION_ALERT {
+		return
+	}
+
+	switch action {
+	case matchstats.ACTION_WARN:
+		_, res := s.infractionService.CreateSystemInfraction(0, player.PlayerID, serverID,
+			refractor.INFRACTION_TYPE_WARNING, config.TeamkillReason, 0)
+		if !res.Success {
+			s.log.Warn("Could not create automatic warning for player ID %d. %s", player.PlayerID, res.Message)
+		}
+	case matchstats.ACTION_KICK:
+		_, res := s.infractionService.CreateSystemInfraction(0, player.PlayerID, serverID,
+			refractor.INFRACTION_TYPE_KICK, config.TeamkillReason, 0)
+		if !res.Success {
+			s.log.Warn("Could not create automatic kick for player ID %d. %s", player.PlayerID, res.Message)
+			return
+		}
+
+		s.execGameCommand(serverID, func(game refractor.Game) string {
+			return game.GetKickCommand(refractor.CommandArgs{
+				PlayerID: playerGameID,
+				Reason:   config.TeamkillReason,
+			})
+		})
+	case matchstats.ACTION_BAN:
+		_, res := s.infractionService.CreateSystemInfraction(0, player.PlayerID, serverID,
+			refractor.INFRACTION_TYPE_BAN, config.TeamkillReason, settings.BanDuration)
+		if !res.Success {
+			s.log.Warn("Could not create automatic ban for player ID %d. %s", player.PlayerID, res.Message)
+			return
+		}
+
+		s.execGameCommand(serverID, func(game refractor.Game) string {
+			return game.GetBanCommand(refractor.CommandArgs{
+				PlayerID: playerGameID,
+				Duration: settings.BanDuration,
+				Reason:   config.TeamkillReason,
+			})
+		})
+	}
+}
+
+// onSameTeam checks if two players are on the same team. Teams are cached for config.MatchTeamCacheTTL. If either
+// player isn't in the cache (e.g. they just joined), it is refreshed early but no more often than
+// config.MatchTeamMinRefreshInterval. Players whose team is unknown or negative (unassigned) are never on the same
+// team as anyone.
+func (s *matchStatsService) onSameTeam(serverID int64, playerGameID1 string, playerGameID2 string) bool {
+	now := time.Now()
+
+	s.teamsMu.Lock()
+	cached := s.teams[serverID]
+	s.teamsMu.Unlock()
+
+	refresh := cached == nil || now.Sub(cached.refreshed) >= config.MatchTeamCacheTTL
+	if !refresh && (cached.teams[playerGameID1] == "" || cached.teams[playerGameID2] == "") {
+		refresh = now.Sub(cached.refreshed) >= config.MatchTeamMinRefreshInterval
+	}
+
+	if refresh {
+		teams, err := s.fetchTeams(serverID)
+		if err != nil {
+			s.log.Warn("Could not get the teams of players on server ID %d. Error: %v", serverID, err)
+
+			// Keep the previous teams but don't try again until the next refresh is due
+			teams = map[string]string{}
+			if cached != nil {
+				teams = cached.teams
+			}
+		}
+
+		cached = &serverTeams{
+			teams:     teams,
+			refreshed: now,
+		}
+
+		s.teamsMu.Lock()
+		s.teams[serverID] = cached
+		s.teamsMu.Unlock()
+	}
+
+	team1 := cached.teams[playerGameID1]
+	team2 := cached.teams[playerGameID2]
+
+	return team1 != "" && team1 == team2 && !strings.HasPrefix(team1, "-")
+}
+
+// fetchTeams gets the team of each online player from the Team named group of the game's player list pattern.
+func (s *matchStatsService) fetchTeams(serverID int64) (map[string]string, error) {
+	game := s.getGame(serverID)
+	if game == nil {
+		return nil, fmt.Errorf("could not get the game of server ID %d", serverID)
+	}
+
+	gameConfig := game.GetConfig()
+
+	playerListPattern := gameConfig.CmdOutputPatterns["PlayerList"]
+	if playerListPattern == nil {
+		return nil, fmt.Errorf("game %s has no player list pattern", game.GetName())
+	}
+
+	output, err := s.rconService.ExecCommand(serverID, game.GetPlayerListCommand())
+	if err != nil {
+		return nil, err
+	}
+
+	teams := map[string]string{}
+
+	for _, player := range playerListPattern.FindAllString(output, -1) {
+		fields := regexutils.MapNamedMatches(playerListPattern, player)
+
+		if fields["Team"] != "" {
+			teams[fields[gameConfig.PlayerGameIDField]] = fields["Team"]
+		}
+	}
+
+	return teams, nil
+}
+
+// saveMatch stores the stats of every known player in an ended match. Nothing is stored if match is nil.
+func (s *matchStatsService) saveMatch(serverID int64, match *matchstats.Match, playerGameIDField string) {
+	if match == nil {
+		return
+	}
+
+	var stats []*refractor.MatchStats
+
+	for playerGameID, playerStats := range match.Players {
+		player, _ := s.playerService.GetPlayer(refractor.FindArgs{
+			playerGameIDField: playerGameID,
+		})
+		if player == nil {
+			continue
+		}
+
+		stats = append(stats, &refractor.MatchStats{
+			ServerID:   serverID,
+			PlayerID:   player.PlayerID,
+			MatchStart: match.Start.Unix(),
+			MatchEnd:   match.End.Unix(),
+			Kills:      playerStats.Kills,
+			Deaths:     playerStats.Deaths,
+			Teamkills:  playerStats.Teamkills,
+		})
+	}
+
+	if len(stats) == 0 {
+		return
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].PlayerID < stats[j].PlayerID
+	})
+
+	if err := s.repo.Create(stats); err != nil {
+		s.log.Error("Could not store the match stats of server ID %d. Error: %v", serverID, err)
+		return
+	}
+
+	s.log.Info("Stored the match stats of %d players on server ID %d", len(stats), serverID)
+}
+
+// getSettings returns a server's teamkill settings, loading them from the repository if they aren't cached.
+func (s *matchStatsService) getSettings(serverID int64) (*refractor.TeamkillSettings, error) {
+	settings, err := s.settings.Get(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	return settings.(*refractor.TeamkillSettings), nil
+}
+
+// loadSettings loads a server's teamkill settings from the repository. The defaults are used if none are stored.
+func (s *matchStatsService) loadSettings(serverID int64) (interface{}, error) {
+	settings, err := s.repo.FindTeamkillSettings(serverID)
+	if err == refractor.ErrNotFound {
+		return getDefaultSettings(serverID), nil
+	}
+
+	return settings, err
+}
+
+// getGame returns the game a server is running, or nil if it couldn't be found.
+func (s *matchStatsService) getGame(serverID int64) refractor.Game {
+	serverData, _ := s.serverService.GetServerData(serverID)
+	if serverData == nil {
+		return nil
+	}
+
+	game, _ := s.gameService.GetGame(serverData.Game)
+	return game
+}
+
+// execGameCommand runs the command built by getCommand for the game a server is running. Nothing is run if the game
+// doesn't support the command.
+func (s *matchStatsService) execGameCommand(serverID int64, getCommand func(game refractor.Game) string) {
+	game := s.getGame(serverID)
+	if game == nil {
+		return
+	}
+
+	command := getCommand(game)
+	if command == "" {
+		return
+	}
+
+	if _, err := s.rconService.ExecCommand(serverID, command); err != nil {
+		s.log.Error("Could not run teamkill command on server ID %d. Error: %v", serverID, err)
+	}
+}
+
+// getDefaultSettings returns the settings used for servers which haven't had their teamkill settings changed. Staff
+// are only alerted by default so that players aren't punished until a server's staff choose to enable it.
+func getDefaultSettings(serverID int64) *refractor.TeamkillSettings {
+	return &refractor.TeamkillSettings{
+		ServerID:    serverID,
+		Enabled:     true,
+		Threshold:   config.TeamkillDefaultThreshold,
+		Action:      matchstats.ACTION_ALERT,
+		BanDuration: config.TeamkillDefaultBanDuration,
+	}
+}
@@ -0,0 +1,264 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package matchstats
+
+import (
+	"database/sql"
+	"github.com/sniddunc/refractor/internal/game"
+	"github.com/sniddunc/refractor/internal/infraction"
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/internal/player"
+	"github.com/sniddunc/refractor/internal/server"
+	"github.com/sniddunc/refractor/pkg/broadcast"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/pkg/matchstats"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func Test_matchStatsService_OnKill(t *testing.T) {
+	type kill struct {
+		killer string
+		victim string
+	}
+
+	// AAA and BBB are on the same team, CCC is on the other team and DDD hasn't picked a team yet
+	playerList := "AAA, Killer, team 0\nBBB, Teammate, team 0\nCCC, Enemy, team 1\nDDD, Spectator, team -1"
+
+	tests := []struct {
+		name            string
+		settings        *refractor.TeamkillSettings
+		trusted         bool
+		kills           []kill
+		wantCommands    []string
+		wantInfractions []string
+		wantAlerts      int
+		wantStats       []*refractor.MatchStats
+	}{
+		{
+			name: "matchstats.onkill.1",
+			settings: &refractor.TeamkillSettings{
+				ServerID:  1,
+				Enabled:   true,
+				Threshold: 2,
+				Action:    matchstats.ACTION_KICK,
+				UpdatedBy: 1,
+			},
+			kills:           []kill{{"AAA", "BBB"}, {"AAA", "CCC"}, {"AAA", "BBB"}, {"AAA", "BBB"}},
+			wantCommands:    []string{"mocklist", "mockkick"},
+			wantInfractions: []string{refractor.INFRACTION_TYPE_KICK},
+			wantAlerts:      1,
+			wantStats: []*refractor.MatchStats{
+				{MatchStatsID: 1, ServerID: 1, PlayerID: 1, Kills: 1, Teamkills: 3},
+				{MatchStatsID: 2, ServerID: 1, PlayerID: 2, Deaths: 3},
+				{MatchStatsID: 3, ServerID: 1, PlayerID: 3, Deaths: 1},
+			},
+		},
+		{
+			name: "matchstats.onkill.2",
+			settings: &refractor.TeamkillSettings{
+				ServerID:    1,
+				Enabled:     true,
+				Threshold:   1,
+				Action:      matchstats.ACTION_BAN,
+				BanDuration: 30,
+				UpdatedBy:   1,
+			},
+			trusted:      true,
+			kills:        []kill{{"AAA", "BBB"}},
+			wantCommands: []string{"mocklist"},
+			wantAlerts:   1,
+			wantStats: []*refractor.MatchStats{
+				{MatchStatsID: 1, ServerID: 1, PlayerID: 1, Teamkills: 1},
+				{MatchStatsID: 2, ServerID: 1, PlayerID: 2, Deaths: 1},
+			},
+		},
+		{
+			name:         "matchstats.onkill.3",
+			settings:     nil,
+			kills:        []kill{{"AAA", "BBB"}, {"AAA", "BBB"}, {"AAA", "BBB"}},
+			wantCommands: []string{"mocklist"},
+			wantAlerts:   1,
+			wantStats: []*refractor.MatchStats{
+				{MatchStatsID: 1, ServerID: 1, PlayerID: 1, Teamkills: 3},
+				{MatchStatsID: 2, ServerID: 1, PlayerID: 2, Deaths: 3},
+			},
+		},
+		{
+			name: "matchstats.onkill.4",
+			settings: &refractor.TeamkillSettings{
+				ServerID:  1,
+				Enabled:   true,
+				Threshold: 1,
+				Action:    matchstats.ACTION_KICK,
+			},
+			kills:           []kill{{"AAA", "BBB"}},
+			wantCommands:    []string{"mocklist", "mockkick"},
+			wantInfractions: []string{refractor.INFRACTION_TYPE_KICK},
+			wantAlerts:      1,
+			wantStats: []*refractor.MatchStats{
+				{MatchStatsID: 1, ServerID: 1, PlayerID: 1, Teamkills: 1},
+				{MatchStatsID: 2, ServerID: 1, PlayerID: 2, Deaths: 1},
+			},
+		},
+		{
+			name:         "matchstats.onkill.5",
+			settings:     nil,
+			kills:        []kill{{"DDD", "AAA"}, {"BBB", "BBB"}},
+			wantCommands: []string{"mocklist"},
+			wantStats: []*refractor.MatchStats{
+				{MatchStatsID: 1, ServerID: 1, PlayerID: 1, Deaths: 1},
+				{MatchStatsID: 2, ServerID: 1, PlayerID: 2, Deaths: 1},
+				{MatchStatsID: 3, ServerID: 1, PlayerID: 4, Kills: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testLogger, _ := log.NewLogger(true, false)
+
+			gameService := game.NewGameService()
+			gameService.AddGame(mock.NewMockGame())
+			gameConfig := mock.NewMockGame().GetConfig()
+
+			serverService := server.NewServerService(mock.NewMockServerRepository(mock.GetMockServers()), gameService,
+				nil, testLogger)
+			serverService.CreateServerData(1, mock.NewMockGame().GetName())
+
+			players := map[int64]*refractor.DBPlayer{
+				1: {PlayerID: 1, PlayFabID: sql.NullString{String: "AAA", Valid: true}, Trusted: tt.trusted},
+				2: {PlayerID: 2, PlayFabID: sql.NullString{String: "BBB", Valid: true}},
+				3: {PlayerID: 3, PlayFabID: sql.NullString{String: "CCC", Valid: true}},
+				4: {PlayerID: 4, PlayFabID: sql.NullString{String: "DDD", Valid: true}},
+			}
+			mockInfractions := map[int64]*refractor.DBInfraction{}
+			playerService := player.NewPlayerService(mock.NewMockPlayerRepository(players), nil, nil, testLogger)
+			infractionService := infraction.NewInfractionService(mock.NewMockInfractionRepository(mockInfractions),
+				playerService, serverService, nil, testLogger)
+
+			mockSettings := map[int64]*refractor.TeamkillSettings{}
+			if tt.settings != nil {
+				mockSettings[1] = tt.settings
+			}
+
+			mockStats := map[int64]*refractor.MatchStats{}
+			mockWebsocketService := mock.NewMockWebsocketService()
+			mockRCONService := mock.NewMockRCONService(1)
+			mockRCONService.Outputs["mocklist"] = playerList
+
+			matchStatsService := NewMatchStatsService(mock.NewMockMatchStatsRepository(mockStats, mockSettings),
+				playerService, serverService, gameService, infractionService, mockWebsocketService, mockRCONService,
+				testLogger)
+
+			matchStatsService.OnMatchState(broadcast.Fields{"State": "In progress"}, 1, gameConfig)
+
+			for _, k := range tt.kills {
+				matchStatsService.OnKill(broadcast.Fields{
+					"KillerPlayFabID": k.killer,
+					"PlayFabID":       k.victim,
+				}, 1, gameConfig)
+			}
+
+			matchStatsService.OnMatchState(broadcast.Fields{"State": "Leaving map"}, 1, gameConfig)
+
+			var infractionTypes []string
+			for _, i := range mockInfractions {
+				infractionTypes = append(infractionTypes, i.Type)
+
+				// Automatic infractions are never attributed to a user
+				assert.Equal(t, int64(0), i.UserID)
+			}
+
+			var stats []*refractor.MatchStats
+			for id := int64(1); id <= int64(len(mockStats)); id++ {
+				// Match times depend on when the test ran so they are left out of the comparison
+				s := *mockStats[id]
+				s.MatchStart, s.MatchEnd = 0, 0
+				stats = append(stats, &s)
+			}
+
+			assert.Equal(t, tt.wantCommands, mockRCONService.Commands[1])
+			assert.Equal(t, tt.wantInfractions, infractionTypes)
+			assert.Equal(t, tt.wantAlerts, len(mockWebsocketService.Broadcasts))
+			assert.Equal(t, tt.wantStats, stats)
+		})
+	}
+}
+
+func Test_matchStatsService_UpdateTeamkillSettings(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+	strPtr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name           string
+		serverID       int64
+		body           params.UpdateTeamkillSettingsParams
+		wantStatusCode int
+		wantSettings   *refractor.TeamkillSettings
+	}{
+		{
+			name:     "matchstats.updateteamkillsettings.1",
+			serverID: 1,
+			body: params.UpdateTeamkillSettingsParams{
+				Threshold: intPtr(5),
+				Action:    strPtr(matchstats.ACTION_BAN),
+				UserMeta:  &params.UserMeta{UserID: 2},
+			},
+			wantStatusCode: http.StatusOK,
+			wantSettings: &refractor.TeamkillSettings{
+				ServerID:    1,
+				Enabled:     true,
+				Threshold:   5,
+				Action:      matchstats.ACTION_BAN,
+				BanDuration: config.TeamkillDefaultBanDuration,
+				UpdatedBy:   2,
+			},
+		},
+		{
+			name:           "matchstats.updateteamkillsettings.2",
+			serverID:       99,
+			body:           params.UpdateTeamkillSettingsParams{UserMeta: &params.UserMeta{UserID: 2}},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testLogger, _ := log.NewLogger(true, false)
+
+			serverService := server.NewServerService(mock.NewMockServerRepository(mock.GetMockServers()),
+				game.NewGameService(), nil, testLogger)
+
+			mockSettings := map[int64]*refractor.TeamkillSettings{}
+			matchStatsService := NewMatchStatsService(mock.NewMockMatchStatsRepository(map[int64]*refractor.MatchStats{},
+				mockSettings), nil, serverService, nil, nil, nil, nil, testLogger)
+
+			settings, res := matchStatsService.UpdateTeamkillSettings(tt.serverID, tt.body)
+
+			assert.Equal(t, tt.wantStatusCode, res.StatusCode)
+			assert.Equal(t, tt.wantSettings, settings)
+
+			if tt.wantSettings != nil {
+				assert.Equal(t, tt.wantSettings, mockSettings[tt.serverID])
+			}
+		})
+	}
+}
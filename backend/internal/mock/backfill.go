@@ -0,0 +1,179 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mock
+
+import (
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/refractor"
+)
+
+// BackfillStore holds the records created by the mock backfill repository so that tests can inspect them
+type BackfillStore struct {
+	Players  map[string]int64           // Players[gameIDField + ":" + gameID] = playerID
+	Names    map[int64]map[string]int64 // Names[playerID][name] = timestamp
+	Sessions []*refractor.DBPlayerSession
+	Messages []*refractor.ChatMessage
+	LastSeen map[int64]int64
+
+	// MessageKeys[messageID] = backfill key. Messages without a key were recorded live.
+	MessageKeys map[int64]string
+}
+
+func NewBackfillStore() *BackfillStore {
+	return &BackfillStore{
+		Players:     map[string]int64{},
+		Names:       map[int64]map[string]int64{},
+		Sessions:    []*refractor.DBPlayerSession{},
+		Messages:    []*refractor.ChatMessage{},
+		LastSeen:    map[int64]int64{},
+		MessageKeys: map[int64]string{},
+	}
+}
+
+type mockBackfillRepo struct {
+	store *BackfillStore
+}
+
+func NewMockBackfillRepository(store *BackfillStore) refractor.BackfillRepository {
+	return &mockBackfillRepo{
+		store: store,
+	}
+}
+
+func (r *mockBackfillRepo) FindPlayerID(gameIDField string, gameID string) (int64, error) {
+	playerID := r.store.Players[gameIDField+":"+gameID]
+	if playerID == 0 {
+		return 0, refractor.ErrNotFound
+	}
+
+	return playerID, nil
+}
+
+func (r *mockBackfillRepo) CreatePlayer(gameIDField string, gameID string, name string, timestamp int64) (int64, error) {
+	playerID := int64(len(r.store.Players) + 1)
+
+	r.store.Players[gameIDField+":"+gameID] = playerID
+	r.store.Names[playerID] = map[string]int64{name: timestamp}
+	r.store.LastSeen[playerID] = timestamp
+
+	return playerID, nil
+}
+
+func (r *mockBackfillRepo) RecordName(playerID int64, name string, timestamp int64) error {
+	if r.store.Names[playerID] == nil {
+		r.store.Names[playerID] = map[string]int64{}
+	}
+
+	if timestamp > r.store.Names[playerID][name] {
+		r.store.Names[playerID][name] = timestamp
+	}
+
+	return nil
+}
+
+func (r *mockBackfillRepo) RecordSession(session *refractor.DBPlayerSession) (bool, error) {
+	for _, existing := range r.store.Sessions {
+		if existing.PlayerID == session.PlayerID && existing.ServerID == session.ServerID &&
+			existing.JoinTime == session.JoinTime {
+			if existing.QuitTime.Int64 < session.QuitTime.Int64 {
+				existing.QuitTime = session.QuitTime
+			}
+
+			return false, nil
+		}
+	}
+
+	recorded := *session
+	recorded.SessionID = int64(len(r.store.Sessions) + 1)
+	r.store.Sessions = append(r.store.Sessions, &recorded)
+
+	return true, nil
+}
+
+func (r *mockBackfillRepo) ExtendLastSession(playerID int64, serverID int64, quitTime int64) (bool, error) {
+	var latest *refractor.DBPlayerSession
+
+	for _, existing := range r.store.Sessions {
+		if existing.PlayerID != playerID || existing.ServerID != serverID || existing.JoinTime >= quitTime {
+			continue
+		}
+
+		if latest == nil || existing.JoinTime > latest.JoinTime {
+			latest = existing
+		}
+	}
+
+	if latest == nil || !latest.QuitTime.Valid || latest.QuitTime.Int64 >= quitTime {
+		return false, nil
+	}
+
+	latest.QuitTime.Int64 = quitTime
+
+	return true, nil
+}
+
+func (r *mockBackfillRepo) RecordChatMessage(message *refractor.ChatMessage, key string) (bool, error) {
+	for _, existing := range r.store.Messages {
+		if r.store.MessageKeys[existing.MessageID] == key {
+			return false, nil
+		}
+	}
+
+	var closest *refractor.ChatMessage
+	window := int64(config.BackfillChatMatchWindow)
+
+	for _, existing := range r.store.Messages {
+		if r.store.MessageKeys[existing.MessageID] != "" || existing.PlayerID != message.PlayerID ||
+			existing.ServerID != message.ServerID || existing.Message != message.Message {
+			continue
+		}
+
+		distance := abs(existing.DateRecorded - message.DateRecorded)
+		if distance <= window && (closest == nil || distance < abs(closest.DateRecorded-message.DateRecorded)) {
+			closest = existing
+		}
+	}
+
+	if closest != nil {
+		r.store.MessageKeys[closest.MessageID] = key
+		return false, nil
+	}
+
+	recorded := *message
+	recorded.MessageID = int64(len(r.store.Messages) + 1)
+	r.store.Messages = append(r.store.Messages, &recorded)
+	r.store.MessageKeys[recorded.MessageID] = key
+
+	return true, nil
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+func (r *mockBackfillRepo) UpdateLastSeen(playerID int64, timestamp int64) error {
+	if timestamp > r.store.LastSeen[playerID] {
+		r.store.LastSeen[playerID] = timestamp
+	}
+
+	return nil
+}
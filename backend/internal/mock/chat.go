@@ -2,15 +2,18 @@ package mock
 
 import (
 	"github.com/sniddunc/refractor/refractor"
+	"sort"
 )
 
 type mockChatRepo struct {
-	messages map[int64]*refractor.ChatMessage
+	messages    map[int64]*refractor.ChatMessage
+	attachments map[int64][]int64
 }
 
 func NewMockChatRepo(mockMessages map[int64]*refractor.ChatMessage) refractor.ChatRepository {
 	return &mockChatRepo{
-		messages: mockMessages,
+		messages:    mockMessages,
+		attachments: map[int64][]int64{},
 	}
 }
 
@@ -80,3 +83,131 @@ func (r *mockChatRepo) FindMany(args refractor.FindArgs) ([]*refractor.ChatMessa
 func (r *mockChatRepo) Search(args refractor.FindArgs, limit int, offset int, getPlayerName refractor.PlayerNameGetter) (int, []*refractor.ChatMessage, error) {
 	panic("implement me")
 }
+
+func (r *mockChatRepo) SetFlagged(id int64, flagged bool) error {
+	message := r.messages[id]
+	if message == nil {
+		return refractor.ErrNotFound
+	}
+
+	message.Flagged = flagged
+
+	return nil
+}
+
+func (r *mockChatRepo) FindFlagged(limit int, offset int) (int, []*refractor.ChatMessage, error) {
+	flagged := []*refractor.ChatMessage{}
+
+	for _, message := range r.sortedMessages() {
+		if message.Flagged {
+			flagged = append(flagged, message)
+		}
+	}
+
+	total := len(flagged)
+
+	if offset > total {
+		offset = total
+	}
+
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	return total, flagged[offset:end], nil
+}
+
+func (r *mockChatRepo) GetContext(message *refractor.ChatMessage, before int, after int) ([]*refractor.ChatMessage, error) {
+	var serverMessages []*refractor.ChatMessage
+
+	for _, m := range r.sortedMessages() {
+		if m.ServerID == message.ServerID {
+			serverMessages = append(serverMessages, m)
+		}
+	}
+
+	for i, m := range serverMessages {
+		if m.MessageID != message.MessageID {
+			continue
+		}
+
+		start := i - before
+		if start < 0 {
+			start = 0
+		}
+
+		end := i + after + 1
+		if end > len(serverMessages) {
+			end = len(serverMessages)
+		}
+
+		return serverMessages[start:end], nil
+	}
+
+	return nil, refractor.ErrNotFound
+}
+
+func (r *mockChatRepo) AttachToInfraction(messageID int64, infractionID int64) error {
+	r.attachments[infractionID] = append(r.attachments[infractionID], messageID)
+
+	return nil
+}
+
+func (r *mockChatRepo) FindByInfractionID(infractionID int64) ([]*refractor.ChatMessage, error) {
+	messages := []*refractor.ChatMessage{}
+
+	for _, messageID := range r.attachments[infractionID] {
+		messages = append(messages, r.messages[messageID])
+	}
+
+	return messages, nil
+}
+
+func (r *mockChatRepo) StreamTranscript(serverID int64, startDate int64, endDate int64, handler refractor.TranscriptEntryHandler) error {
+	for _, message := range r.sortedMessages() {
+		if message.ServerID != serverID || message.DateRecorded < startDate || message.DateRecorded > endDate {
+			continue
+		}
+
+		entryType := refractor.TRANSCRIPT_ENTRY_CHAT
+		if message.TargetPlayerID != 0 {
+			entryType = refractor.TRANSCRIPT_ENTRY_WHISPER
+		} else if message.SentByUser {
+			entryType = refractor.TRANSCRIPT_ENTRY_STAFF_CHAT
+		}
+
+		if err := handler(&refractor.TranscriptEntry{
+			Type:           entryType,
+			Timestamp:      message.DateRecorded,
+			PlayerID:       message.PlayerID,
+			UserID:         message.UserID,
+			TargetPlayerID: message.TargetPlayerID,
+			PlayerName:     message.PlayerName,
+			Message:        message.Message,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sortedMessages returns all messages ordered the way they were sent
+func (r *mockChatRepo) sortedMessages() []*refractor.ChatMessage {
+	var messages []*refractor.ChatMessage
+
+	for _, message := range r.messages {
+		messages = append(messages, message)
+	}
+
+	sort.Slice(messages, func(i, j int) bool {
+		if messages[i].DateRecorded != messages[j].DateRecorded {
+			return messages[i].DateRecorded < messages[j].DateRecorded
+		}
+
+		return messages[i].MessageID < messages[j].MessageID
+	})
+
+	return messages
+}
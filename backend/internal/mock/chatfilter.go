@@ -0,0 +1,107 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mock
+
+import (
+	"github.com/sniddunc/refractor/refractor"
+)
+
+type mockChatFilterRepo struct {
+	rules map[int64]*refractor.DBChatFilterRule
+}
+
+func NewMockChatFilterRepository(mockRules map[int64]*refractor.DBChatFilterRule) refractor.ChatFilterRepository {
+	return &mockChatFilterRepo{
+		rules: mockRules,
+	}
+}
+
+func (r *mockChatFilterRepo) Create(rule *refractor.DBChatFilterRule) (*refractor.ChatFilterRule, error) {
+	newID := int64(len(r.rules) + 1)
+	rule.RuleID = newID
+
+	r.rules[newID] = rule
+
+	return rule.ChatFilterRule(), nil
+}
+
+func (r *mockChatFilterRepo) FindByID(id int64) (*refractor.ChatFilterRule, error) {
+	rule := r.rules[id]
+	if rule == nil {
+		return nil, refractor.ErrNotFound
+	}
+
+	return rule.ChatFilterRule(), nil
+}
+
+func (r *mockChatFilterRepo) FindAll() ([]*refractor.ChatFilterRule, error) {
+	var foundRules []*refractor.ChatFilterRule
+
+	for _, rule := range r.rules {
+		foundRules = append(foundRules, rule.ChatFilterRule())
+	}
+
+	if len(foundRules) == 0 {
+		return nil, refractor.ErrNotFound
+	}
+
+	return foundRules, nil
+}
+
+func (r *mockChatFilterRepo) Update(id int64, args refractor.UpdateArgs) (*refractor.ChatFilterRule, error) {
+	rule := r.rules[id]
+	if rule == nil {
+		return nil, refractor.ErrNotFound
+	}
+
+	if args["Type"] != nil {
+		rule.Type = args["Type"].(string)
+	}
+
+	if args["Pattern"] != nil {
+		rule.Pattern = args["Pattern"].(string)
+	}
+
+	if args["Action"] != nil {
+		rule.Action = args["Action"].(string)
+	}
+
+	if args["Reason"] != nil {
+		rule.Reason = args["Reason"].(string)
+	}
+
+	if args["Duration"] != nil {
+		rule.Duration = args["Duration"].(int)
+	}
+
+	if args["Enabled"] != nil {
+		rule.Enabled = args["Enabled"].(bool)
+	}
+
+	return rule.ChatFilterRule(), nil
+}
+
+func (r *mockChatFilterRepo) Delete(id int64) error {
+	if r.rules[id] == nil {
+		return refractor.ErrNotFound
+	}
+
+	delete(r.rules, id)
+
+	return nil
+}
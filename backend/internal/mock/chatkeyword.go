@@ -0,0 +1,89 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mock
+
+import (
+	"github.com/sniddunc/refractor/refractor"
+	"sort"
+)
+
+type mockChatKeywordRepo struct {
+	keywords map[int64]*refractor.ChatKeyword
+}
+
+func NewMockChatKeywordRepository(mockKeywords map[int64]*refractor.ChatKeyword) refractor.ChatKeywordRepository {
+	return &mockChatKeywordRepo{
+		keywords: mockKeywords,
+	}
+}
+
+func (r *mockChatKeywordRepo) Create(keyword *refractor.ChatKeyword) (*refractor.ChatKeyword, error) {
+	keyword.KeywordID = int64(len(r.keywords) + 1)
+	r.keywords[keyword.KeywordID] = keyword
+
+	return keyword, nil
+}
+
+func (r *mockChatKeywordRepo) FindByID(id int64) (*refractor.ChatKeyword, error) {
+	keyword := r.keywords[id]
+	if keyword == nil {
+		return nil, refractor.ErrNotFound
+	}
+
+	return keyword, nil
+}
+
+func (r *mockChatKeywordRepo) FindByUserID(userID int64) ([]*refractor.ChatKeyword, error) {
+	var foundKeywords []*refractor.ChatKeyword
+
+	for _, keyword := range r.sorted() {
+		if keyword.UserID == userID {
+			foundKeywords = append(foundKeywords, keyword)
+		}
+	}
+
+	return foundKeywords, nil
+}
+
+func (r *mockChatKeywordRepo) FindAll() ([]*refractor.ChatKeyword, error) {
+	return r.sorted(), nil
+}
+
+func (r *mockChatKeywordRepo) Delete(id int64) error {
+	if r.keywords[id] == nil {
+		return refractor.ErrNotFound
+	}
+
+	delete(r.keywords, id)
+
+	return nil
+}
+
+func (r *mockChatKeywordRepo) sorted() []*refractor.ChatKeyword {
+	var keywords []*refractor.ChatKeyword
+
+	for _, keyword := range r.keywords {
+		keywords = append(keywords, keyword)
+	}
+
+	sort.Slice(keywords, func(i, j int) bool {
+		return keywords[i].KeywordID < keywords[j].KeywordID
+	})
+
+	return keywords
+}
@@ -0,0 +1,49 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mock
+
+import (
+	"github.com/sniddunc/refractor/refractor"
+)
+
+type mockChatSpamRepo struct {
+	settings map[int64]*refractor.ChatSpamSettings
+}
+
+func NewMockChatSpamRepository(mockSettings map[int64]*refractor.ChatSpamSettings) refractor.ChatSpamRepository {
+	return &mockChatSpamRepo{
+		settings: mockSettings,
+	}
+}
+
+func (r *mockChatSpamRepo) FindByServerID(serverID int64) (*refractor.ChatSpamSettings, error) {
+	settings := r.settings[serverID]
+	if settings == nil {
+		return nil, refractor.ErrNotFound
+	}
+
+	found := *settings
+	return &found, nil
+}
+
+func (r *mockChatSpamRepo) Save(settings *refractor.ChatSpamSettings) error {
+	saved := *settings
+	r.settings[settings.ServerID] = &saved
+
+	return nil
+}
@@ -18,6 +18,7 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package mock
 
 import (
+	"fmt"
 	"github.com/sniddunc/refractor/pkg/broadcast"
 	"github.com/sniddunc/refractor/refractor"
 	"regexp"
@@ -31,14 +32,20 @@ type mockGame struct {
 func NewMockGame() refractor.Game {
 	return &mockGame{
 		config: &refractor.GameConfig{
-			UseRCON:           true,
-			SendAlivePing:     true,
-			AlivePingInterval: time.Second * 30,
-			EnableBroadcasts:  true,
+			UseRCON:            true,
+			SendAlivePing:      true,
+			AlivePingInterval:  time.Second * 30,
+			EnableBroadcasts:   true,
+			SupportsNativeMute: true,
+			PlayerGameIDField:  "PlayFabID",
 			BroadcastPatterns: map[string]*regexp.Regexp{
 				broadcast.TYPE_JOIN: regexp.MustCompile("^(?P<name>.+) joined the game$"),
 				broadcast.TYPE_QUIT: regexp.MustCompile("^(?P<name>.+) quit the game$"),
 			},
+			CmdOutputPatterns: map[string]*regexp.Regexp{
+				"PlayerList": regexp.MustCompile("(?P<PlayFabID>[0-9A-Z]+), (?P<Name>[\\S ]+), team (?P<Team>[0-9-]+)"),
+			},
+			MatchInProgressState: "In progress",
 		},
 	}
 }
@@ -70,3 +77,19 @@ func (g *mockGame) GetBanCommand(args refractor.CommandArgs) string {
 func (g *mockGame) GetPlayerListCommand() string {
 	return "mocklist"
 }
+
+func (g *mockGame) GetWhisperCommand(args refractor.CommandArgs) string {
+	return fmt.Sprintf("mockwhisper %s %s", args.PlayerID, args.Message)
+}
+
+func (g *mockGame) GetBroadcastCommand(args refractor.CommandArgs) string {
+	return fmt.Sprintf("mockbroadcast %s", args.Message)
+}
+
+func (g *mockGame) GetChatPollCommand(cursor string) string {
+	return ""
+}
+
+func (g *mockGame) GetServerInfoCommand() string {
+	return ""
+}
@@ -20,6 +20,7 @@ package mock
 import (
 	"database/sql"
 	"github.com/sniddunc/refractor/refractor"
+	"sort"
 )
 
 type mockInfractionsRepo struct {
@@ -194,7 +195,11 @@ func (r *mockInfractionsRepo) FindMany(args refractor.FindArgs) ([]*refractor.In
 		return nil, refractor.ErrNotFound
 	}
 
-	// Otherwise return the matches
+	// Otherwise return the matches in a stable order since map iteration order is random
+	sort.Slice(infractions, func(i, j int) bool {
+		return infractions[i].InfractionID < infractions[j].InfractionID
+	})
+
 	return infractions, nil
 }
 
@@ -0,0 +1,89 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mock
+
+import (
+	"github.com/sniddunc/refractor/refractor"
+	"sort"
+)
+
+type mockMatchStatsRepo struct {
+	stats    map[int64]*refractor.MatchStats
+	settings map[int64]*refractor.TeamkillSettings
+}
+
+func NewMockMatchStatsRepository(mockStats map[int64]*refractor.MatchStats,
+	mockSettings map[int64]*refractor.TeamkillSettings) refractor.MatchStatsRepository {
+	return &mockMatchStatsRepo{
+		stats:    mockStats,
+		settings: mockSettings,
+	}
+}
+
+func (r *mockMatchStatsRepo) Create(stats []*refractor.MatchStats) error {
+	for _, s := range stats {
+		s.MatchStatsID = int64(len(r.stats) + 1)
+
+		created := *s
+		r.stats[s.MatchStatsID] = &created
+	}
+
+	return nil
+}
+
+func (r *mockMatchStatsRepo) FindByPlayerID(playerID int64, limit int) ([]*refractor.MatchStats, error) {
+	var foundStats []*refractor.MatchStats
+
+	for _, s := range r.stats {
+		if s.PlayerID == playerID {
+			found := *s
+			foundStats = append(foundStats, &found)
+		}
+	}
+
+	if len(foundStats) == 0 {
+		return nil, refractor.ErrNotFound
+	}
+
+	sort.Slice(foundStats, func(i, j int) bool {
+		return foundStats[i].MatchEnd > foundStats[j].MatchEnd
+	})
+
+	if len(foundStats) > limit {
+		foundStats = foundStats[:limit]
+	}
+
+	return foundStats, nil
+}
+
+func (r *mockMatchStatsRepo) FindTeamkillSettings(serverID int64) (*refractor.TeamkillSettings, error) {
+	settings := r.settings[serverID]
+	if settings == nil {
+		return nil, refractor.ErrNotFound
+	}
+
+	found := *settings
+	return &found, nil
+}
+
+func (r *mockMatchStatsRepo) SaveTeamkillSettings(settings *refractor.TeamkillSettings) error {
+	saved := *settings
+	r.settings[settings.ServerID] = &saved
+
+	return nil
+}
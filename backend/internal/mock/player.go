@@ -20,6 +20,7 @@ package mock
 import (
 	"database/sql"
 	"github.com/sniddunc/refractor/refractor"
+	"sort"
 	"strings"
 )
 
@@ -86,6 +87,10 @@ func (r *mockPlayerRepo) FindOne(args refractor.FindArgs) (*refractor.Player, er
 			continue
 		}
 
+		if args["SteamID"] != nil && args["SteamID"].(string) != player.SteamID.String {
+			continue
+		}
+
 		if args["LastSeen"] != nil && args["LastSeen"].(int64) != player.LastSeen {
 			continue
 		}
@@ -110,6 +115,10 @@ func (r *mockPlayerRepo) Exists(args refractor.FindArgs) (bool, error) {
 			continue
 		}
 
+		if args["SteamID"] != nil && args["SteamID"].(string) != player.SteamID.String {
+			continue
+		}
+
 		if args["LastSeen"] != nil && args["LastSeen"].(int64) != player.LastSeen {
 			continue
 		}
@@ -141,18 +150,76 @@ func (r *mockPlayerRepo) Update(id int64, args refractor.UpdateArgs) (*refractor
 		r.players[id].PlayFabID = sql.NullString{String: args["MCUUID"].(string), Valid: true}
 	}
 
+	if args["SteamID"] != nil {
+		r.players[id].SteamID = sql.NullString{String: args["SteamID"].(string), Valid: true}
+	}
+
 	if args["LastSeen"] != nil {
 		r.players[id].LastSeen = args["LastSeen"].(int64)
 	}
 
+	if args["Watched"] != nil {
+		r.players[id].Watched = args["Watched"].(bool)
+	}
+
+	if args["Trusted"] != nil {
+		r.players[id].Trusted = args["Trusted"].(bool)
+	}
+
 	return r.players[id].Player(), nil
 }
 
-func (r *mockPlayerRepo) SearchByName(name string, offset int, limit int) (int, []*refractor.Player, error) {
+func (r *mockPlayerRepo) FindNameCandidates(name string, max int) ([]*refractor.PlayerNameMatch, error) {
+	var candidates []*refractor.PlayerNameMatch
+
+	for _, player := range r.players {
+		candidates = append(candidates, &refractor.PlayerNameMatch{
+			PlayerID: player.PlayerID,
+			Name:     player.CurrentName,
+			Current:  true,
+			LastSeen: player.LastSeen,
+		})
+
+		for _, previousName := range player.PreviousNames {
+			candidates = append(candidates, &refractor.PlayerNameMatch{
+				PlayerID: player.PlayerID,
+				Name:     previousName,
+				Current:  false,
+				LastSeen: player.LastSeen,
+			})
+		}
+	}
+
+	// Return names containing the search term first so that they are never cut off by max, like the real repository
+	lowerName := strings.ToLower(name)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return strings.Contains(strings.ToLower(candidates[i].Name), lowerName) &&
+			!strings.Contains(strings.ToLower(candidates[j].Name), lowerName)
+	})
+
+	if len(candidates) > max {
+		candidates = candidates[:max]
+	}
+
+	return candidates, nil
+}
+
+func (r *mockPlayerRepo) SearchByGameID(field string, term string, limit int, offset int) (int, []*refractor.Player, error) {
 	var foundPlayers []*refractor.Player
 
 	for _, player := range r.players {
-		if strings.Contains(player.CurrentName, name) {
+		var id string
+
+		switch field {
+		case "PlayFabID":
+			id = player.PlayFabID.String
+		case "MCUUID":
+			id = player.MCUUID.String
+		case "SteamID":
+			id = player.SteamID.String
+		}
+
+		if strings.Contains(id, term) {
 			foundPlayers = append(foundPlayers, player.Player())
 		}
 	}
@@ -161,5 +228,19 @@ func (r *mockPlayerRepo) SearchByName(name string, offset int, limit int) (int,
 }
 
 func (r *mockPlayerRepo) GetPlayerNames(id int64) (string, []string, error) {
-	panic("implement me")
+	player := r.players[id]
+	if player == nil {
+		return "", nil, refractor.ErrNotFound
+	}
+
+	return player.CurrentName, player.PreviousNames, nil
+}
+
+func (r *mockPlayerRepo) GetNameAt(playerID int64, timestamp int64) (string, error) {
+	player := r.players[playerID]
+	if player == nil {
+		return "", refractor.ErrNotFound
+	}
+
+	return player.CurrentName, nil
 }
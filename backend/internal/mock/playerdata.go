@@ -0,0 +1,75 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mock
+
+import (
+	"github.com/sniddunc/refractor/refractor"
+	"time"
+)
+
+type mockPlayerDataRepo struct {
+	players  map[int64]*refractor.DBPlayer
+	requests []*refractor.PlayerDataRequest
+}
+
+func NewMockPlayerDataRepository(mockPlayers map[int64]*refractor.DBPlayer) refractor.PlayerDataRepository {
+	return &mockPlayerDataRepo{
+		players: mockPlayers,
+	}
+}
+
+func (r *mockPlayerDataRepo) Export(playerID int64) (*refractor.PlayerDataExport, error) {
+	player := r.players[playerID]
+	if player == nil {
+		return nil, refractor.ErrNotFound
+	}
+
+	return &refractor.PlayerDataExport{
+		ExportedAt: time.Now().Unix(),
+		PlayerID:   player.PlayerID,
+		PlayFabID:  player.PlayFabID.String,
+		MCUUID:     player.MCUUID.String,
+		SteamID:    player.SteamID.String,
+		LastSeen:   player.LastSeen,
+	}, nil
+}
+
+func (r *mockPlayerDataRepo) Erase(playerID int64, pseudonym string) error {
+	player := r.players[playerID]
+	if player == nil {
+		return refractor.ErrNotFound
+	}
+
+	player.PlayFabID.Valid = false
+	player.PlayFabID.String = ""
+	player.MCUUID.Valid = false
+	player.MCUUID.String = ""
+	player.SteamID.Valid = false
+	player.SteamID.String = ""
+	player.CurrentName = pseudonym
+	player.PreviousNames = []string{}
+
+	return nil
+}
+
+func (r *mockPlayerDataRepo) LogRequest(request *refractor.PlayerDataRequest) error {
+	request.RequestID = int64(len(r.requests) + 1)
+	r.requests = append(r.requests, request)
+
+	return nil
+}
@@ -0,0 +1,76 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mock
+
+import (
+	"github.com/sniddunc/refractor/refractor"
+	"time"
+)
+
+type mockPlayerMergeRepo struct {
+	merges map[int64]*refractor.PlayerMerge
+}
+
+func NewMockPlayerMergeRepository(mockMerges map[int64]*refractor.PlayerMerge) refractor.PlayerMergeRepository {
+	return &mockPlayerMergeRepo{
+		merges: mockMerges,
+	}
+}
+
+func (r *mockPlayerMergeRepo) Merge(sourceID int64, targetID int64, userID int64) (*refractor.PlayerMerge, error) {
+	newID := int64(len(r.merges) + 1)
+
+	merge := &refractor.PlayerMerge{
+		MergeID:        newID,
+		SourcePlayerID: sourceID,
+		TargetPlayerID: targetID,
+		UserID:         userID,
+		Timestamp:      time.Now().Unix(),
+		Snapshot:       &refractor.PlayerMergeSnapshot{},
+	}
+
+	r.merges[newID] = merge
+
+	return merge, nil
+}
+
+func (r *mockPlayerMergeRepo) FindByID(id int64) (*refractor.PlayerMerge, error) {
+	merge := r.merges[id]
+	if merge == nil {
+		return nil, refractor.ErrNotFound
+	}
+
+	return merge, nil
+}
+
+func (r *mockPlayerMergeRepo) Undo(id int64, userID int64) (*refractor.PlayerMerge, error) {
+	merge := r.merges[id]
+	if merge == nil {
+		return nil, refractor.ErrNotFound
+	}
+
+	if merge.Undone {
+		return nil, refractor.ErrMergeUndone
+	}
+
+	merge.Undone = true
+	merge.UndoneBy = userID
+	merge.UndoneAt = time.Now().Unix()
+
+	return merge, nil
+}
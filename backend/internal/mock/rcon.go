@@ -0,0 +1,139 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mock
+
+import (
+	"fmt"
+	"github.com/sniddunc/refractor/refractor"
+)
+
+// MockRCONService records the commands executed through it instead of sending them to a server. Commands sent to a
+// server ID without an entry in Online fail as if the server had no RCON client. Commands with an entry in Outputs
+// return it as their output.
+type MockRCONService struct {
+	Online   map[int64]bool
+	Commands map[int64][]string
+	Outputs  map[string]string
+}
+
+func NewMockRCONService(onlineServerIDs ...int64) *MockRCONService {
+	online := map[int64]bool{}
+	for _, id := range onlineServerIDs {
+		online[id] = true
+	}
+
+	return &MockRCONService{
+		Online:   online,
+		Commands: map[int64][]string{},
+		Outputs:  map[string]string{},
+	}
+}
+
+func (s *MockRCONService) CreateClient(server *refractor.Server) error {
+	s.Online[server.ServerID] = true
+	return nil
+}
+
+func (s *MockRCONService) GetClients() map[int64]*refractor.RCONClient {
+	return map[int64]*refractor.RCONClient{}
+}
+
+func (s *MockRCONService) DeleteClient(serverID int64) {
+	delete(s.Online, serverID)
+}
+
+func (s *MockRCONService) SendChatMessage(msgBody *refractor.ChatSendBody) {
+	_, _ = s.ExecCommand(msgBody.ServerID, fmt.Sprintf("Say [%s]: %s", msgBody.Sender, msgBody.Message))
+}
+
+func (s *MockRCONService) ExecCommand(serverID int64, command string) (string, error) {
+	if !s.Online[serverID] {
+		return "", fmt.Errorf("no RCON client for server ID %d", serverID)
+	}
+
+	s.Commands[serverID] = append(s.Commands[serverID], command)
+	return s.Outputs[command], nil
+}
+
+func (s *MockRCONService) SubscribeJoin(subscriber refractor.BroadcastSubscriber) {}
+
+func (s *MockRCONService) SubscribeQuit(subscriber refractor.BroadcastSubscriber) {}
+
+func (s *MockRCONService) SubscribeOnline(subscriber refractor.StatusSubscriber) {}
+
+func (s *MockRCONService) SubscribeOffline(subscriber refractor.StatusSubscriber) {}
+
+func (s *MockRCONService) SubscribeChat(subscriber refractor.ChatReceiveSubscriber) {}
+
+func (s *MockRCONService) SubscribePlayerListPoll(subscriber refractor.PlayerListPollSubscriber) {}
+
+func (s *MockRCONService) SubscribePlayerFields(subscriber refractor.PlayerFieldsSubscriber) {}
+
+func (s *MockRCONService) SubscribeServerInfo(subscriber refractor.ServerInfoSubscriber) {}
+
+func (s *MockRCONService) SubscribePunishment(subscriber refractor.BroadcastSubscriber) {}
+
+func (s *MockRCONService) SubscribeMatchState(subscriber refractor.BroadcastSubscriber) {}
+
+func (s *MockRCONService) SubscribeKill(subscriber refractor.BroadcastSubscriber) {}
+
+// MockRCONTransport records the commands executed through it instead of sending them to a server. Commands with an
+// entry in Outputs return it as their output. Broadcasts and disconnects can be simulated through the handlers in
+// Config.
+type MockRCONTransport struct {
+	Config            *refractor.RCONTransportConfig
+	Connected         bool
+	BroadcastChannels []string
+	Commands          []string
+	Outputs           map[string]string
+}
+
+func NewMockRCONTransport(config *refractor.RCONTransportConfig) refractor.RCONTransport {
+	return &MockRCONTransport{
+		Config:  config,
+		Outputs: map[string]string{},
+	}
+}
+
+func (t *MockRCONTransport) Connect() error {
+	t.Connected = true
+	return nil
+}
+
+func (t *MockRCONTransport) ExecCommand(command string) (string, error) {
+	if !t.Connected {
+		return "", fmt.Errorf("not connected")
+	}
+
+	t.Commands = append(t.Commands, command)
+	return t.Outputs[command], nil
+}
+
+func (t *MockRCONTransport) ListenForBroadcasts(channels []string, errors chan error) {
+	t.BroadcastChannels = channels
+}
+
+func (t *MockRCONTransport) Disconnect() error {
+	t.Connected = false
+
+	if t.Config.DisconnectHandler != nil {
+		t.Config.DisconnectHandler(nil, true)
+	}
+
+	return nil
+}
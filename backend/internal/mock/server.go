@@ -150,6 +150,22 @@ func (r *mockServerRepo) Update(id int64, args refractor.UpdateArgs) (*refractor
 		r.servers[id].RCONPassword = args["RCONPassword"].(string)
 	}
 
+	if args["WarnMessageTemplate"] != nil {
+		r.servers[id].WarnMessageTemplate = args["WarnMessageTemplate"].(string)
+	}
+
+	if args["MuteAction"] != nil {
+		r.servers[id].MuteAction = args["MuteAction"].(string)
+	}
+
+	if args["EventSource"] != nil {
+		r.servers[id].EventSource = args["EventSource"].(string)
+	}
+
+	if args["LogFilePath"] != nil {
+		r.servers[id].LogFilePath = args["LogFilePath"].(string)
+	}
+
 	return r.servers[id], nil
 }
 
@@ -0,0 +1,88 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mock
+
+import (
+	"github.com/sniddunc/refractor/refractor"
+	"sort"
+)
+
+type mockServerHistoryRepo struct {
+	samples map[int64]map[int64]*refractor.ServerStatusSample
+}
+
+// NewMockServerHistoryRepository creates a mock repository of the given samples, which are keyed by server ID and
+// then timestamp.
+func NewMockServerHistoryRepository(mockSamples map[int64]map[int64]*refractor.ServerStatusSample) refractor.ServerHistoryRepository {
+	return &mockServerHistoryRepo{
+		samples: mockSamples,
+	}
+}
+
+func (r *mockServerHistoryRepo) Create(sample *refractor.ServerStatusSample) error {
+	if r.samples[sample.ServerID] == nil {
+		r.samples[sample.ServerID] = map[int64]*refractor.ServerStatusSample{}
+	}
+
+	created := *sample
+	r.samples[sample.ServerID][sample.Timestamp] = &created
+
+	return nil
+}
+
+func (r *mockServerHistoryRepo) FindByServerID(serverID int64, from int64, to int64) ([]*refractor.ServerStatusSample, error) {
+	var foundSamples []*refractor.ServerStatusSample
+
+	for timestamp, sample := range r.samples[serverID] {
+		if timestamp >= from && timestamp < to {
+			found := *sample
+			foundSamples = append(foundSamples, &found)
+		}
+	}
+
+	if len(foundSamples) == 0 {
+		return nil, refractor.ErrNotFound
+	}
+
+	sort.Slice(foundSamples, func(i, j int) bool {
+		return foundSamples[i].Timestamp < foundSamples[j].Timestamp
+	})
+
+	return foundSamples, nil
+}
+
+func (r *mockServerHistoryRepo) ReplaceSamples(serverID int64, before int64, downsampled []*refractor.ServerStatusSample) error {
+	for timestamp, sample := range r.samples[serverID] {
+		if timestamp < before && !sample.Downsampled {
+			delete(r.samples[serverID], timestamp)
+		}
+	}
+
+	for _, sample := range downsampled {
+		if r.samples[serverID] == nil {
+			r.samples[serverID] = map[int64]*refractor.ServerStatusSample{}
+		}
+
+		created := *sample
+		created.ServerID = serverID
+		created.Downsampled = true
+		r.samples[serverID][sample.Timestamp] = &created
+	}
+
+	return nil
+}
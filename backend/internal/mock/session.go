@@ -0,0 +1,113 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mock
+
+import (
+	"github.com/sniddunc/refractor/refractor"
+	"sort"
+)
+
+type mockPlayerSessionRepo struct {
+	sessions map[int64]*refractor.DBPlayerSession
+}
+
+func NewMockPlayerSessionRepository(mockSessions map[int64]*refractor.DBPlayerSession) refractor.PlayerSessionRepository {
+	return &mockPlayerSessionRepo{
+		sessions: mockSessions,
+	}
+}
+
+func (r *mockPlayerSessionRepo) Create(session *refractor.DBPlayerSession) (*refractor.PlayerSession, error) {
+	newID := int64(len(r.sessions) + 1)
+	r.sessions[newID] = session
+
+	session.SessionID = newID
+
+	return session.PlayerSession(), nil
+}
+
+func (r *mockPlayerSessionRepo) FindOpen(playerID int64, serverID int64) (*refractor.PlayerSession, error) {
+	for _, session := range r.sessions {
+		if session.PlayerID == playerID && session.ServerID == serverID && !session.QuitTime.Valid {
+			return session.PlayerSession(), nil
+		}
+	}
+
+	return nil, refractor.ErrNotFound
+}
+
+func (r *mockPlayerSessionRepo) Close(sessionID int64, quitTime int64) error {
+	session := r.sessions[sessionID]
+	if session == nil {
+		return refractor.ErrNotFound
+	}
+
+	session.QuitTime.Int64 = quitTime
+	session.QuitTime.Valid = true
+
+	return nil
+}
+
+func (r *mockPlayerSessionRepo) GetRecent(args refractor.FindArgs, limit int) ([]*refractor.PlayerSession, error) {
+	type sessionKey struct {
+		playerID int64
+		serverID int64
+	}
+
+	// Only keep the latest session for each player and server pair, like the GROUP BY in the real repository
+	latest := map[sessionKey]*refractor.DBPlayerSession{}
+
+	for _, session := range r.sessions {
+		if !session.QuitTime.Valid {
+			continue
+		}
+
+		if args["ServerID"] != nil && args["ServerID"].(int64) != session.ServerID {
+			continue
+		}
+
+		if args["Since"] != nil && session.QuitTime.Int64 < args["Since"].(int64) {
+			continue
+		}
+
+		if args["Until"] != nil && session.QuitTime.Int64 > args["Until"].(int64) {
+			continue
+		}
+
+		key := sessionKey{playerID: session.PlayerID, serverID: session.ServerID}
+		if existing := latest[key]; existing == nil || session.QuitTime.Int64 > existing.QuitTime.Int64 {
+			latest[key] = session
+		}
+	}
+
+	var foundSessions []*refractor.PlayerSession
+
+	for _, session := range latest {
+		foundSessions = append(foundSessions, session.PlayerSession())
+	}
+
+	sort.Slice(foundSessions, func(i, j int) bool {
+		return foundSessions[i].QuitTime > foundSessions[j].QuitTime
+	})
+
+	if len(foundSessions) > limit {
+		foundSessions = foundSessions[:limit]
+	}
+
+	return foundSessions, nil
+}
@@ -0,0 +1,67 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mock
+
+import (
+	"github.com/sniddunc/refractor/pkg/broadcast"
+	"github.com/sniddunc/refractor/refractor"
+	"net"
+)
+
+// MockWebsocketService records the messages broadcast and sent directly through it so tests can inspect them.
+type MockWebsocketService struct {
+	Broadcasts []*refractor.WebsocketMessage
+	Direct     map[int64][]*refractor.WebsocketMessage
+}
+
+func NewMockWebsocketService() *MockWebsocketService {
+	return &MockWebsocketService{
+		Broadcasts: []*refractor.WebsocketMessage{},
+		Direct:     map[int64][]*refractor.WebsocketMessage{},
+	}
+}
+
+func (s *MockWebsocketService) Broadcast(message *refractor.WebsocketMessage) {
+	s.Broadcasts = append(s.Broadcasts, message)
+}
+
+func (s *MockWebsocketService) SendDirect(userID int64, message *refractor.WebsocketMessage) {
+	s.Direct[userID] = append(s.Direct[userID], message)
+}
+
+func (s *MockWebsocketService) CreateClient(userID int64, conn net.Conn) {}
+
+func (s *MockWebsocketService) StartPool() {}
+
+func (s *MockWebsocketService) OnPlayerJoin(fields broadcast.Fields, serverID int64, gameConfig *refractor.GameConfig) {
+}
+
+func (s *MockWebsocketService) OnPlayerQuit(fields broadcast.Fields, serverID int64, gameConfig *refractor.GameConfig) {
+}
+
+func (s *MockWebsocketService) OnServerOnline(serverID int64) {}
+
+func (s *MockWebsocketService) OnServerOffline(serverID int64) {}
+
+func (s *MockWebsocketService) OnServerInfoUpdate(serverID int64, info *refractor.LiveServerInfo) {}
+
+func (s *MockWebsocketService) OnInfractionCreate(infraction *refractor.Infraction) {}
+
+func (s *MockWebsocketService) SubscribeChatSend(subscriber refractor.ChatSendSubscriber) {}
+
+func (s *MockWebsocketService) SubscribeWhisperSend(subscriber refractor.WhisperSendSubscriber) {}
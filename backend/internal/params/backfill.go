@@ -0,0 +1,49 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package params
+
+import (
+	"fmt"
+	"github.com/sniddunc/refractor/pkg/config"
+	"net/url"
+)
+
+// StartBackfillParams holds the data we expect when starting a backfill. Paths are log files on the machine running
+// Refractor and should be ordered from oldest to newest. Gzipped files are supported.
+type StartBackfillParams struct {
+	Paths []string `json:"paths" form:"paths"`
+	*UserMeta
+}
+
+func (body *StartBackfillParams) Validate() (bool, url.Values) {
+	errors := url.Values{}
+
+	if len(body.Paths) < 1 || len(body.Paths) > config.BackfillMaxFiles {
+		errors.Set("paths", fmt.Sprintf("Between 1 and %d log files must be provided", config.BackfillMaxFiles))
+	}
+
+	for _, path := range body.Paths {
+		if path == "" || len(path) > config.ServerLogPathMaxLen {
+			errors.Set("paths", fmt.Sprintf("Log file paths must be between 1 and %d characters in length",
+				config.ServerLogPathMaxLen))
+			break
+		}
+	}
+
+	return len(errors) == 0, errors
+}
@@ -0,0 +1,69 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package params
+
+import (
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestStartBackfillParams_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []string
+		want  bool
+	}{
+		{
+			name:  "params.startbackfill.1",
+			paths: []string{"/srv/mordhau/Mordhau-backup-2021.01.01.log", "/srv/mordhau/Mordhau.log.gz"},
+			want:  true,
+		},
+		{
+			name:  "params.startbackfill.2",
+			paths: nil,
+			want:  false,
+		},
+		{
+			name:  "params.startbackfill.3",
+			paths: []string{"/srv/mordhau/Mordhau.log", ""},
+			want:  false,
+		},
+		{
+			name:  "params.startbackfill.4",
+			paths: []string{strings.Repeat("a", config.ServerLogPathMaxLen+1)},
+			want:  false,
+		},
+		{
+			name:  "params.startbackfill.5",
+			paths: make([]string, config.BackfillMaxFiles+1),
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &StartBackfillParams{
+				Paths: tt.paths,
+			}
+
+			got, errors := body.Validate()
+			assert.Equal(t, tt.want, got, "Validate returned the wrong values. Errors: %v", errors)
+		})
+	}
+}
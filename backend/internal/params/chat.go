@@ -0,0 +1,154 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package params
+
+import (
+	"fmt"
+	"github.com/sniddunc/refractor/pkg/config"
+	"net/url"
+	"strings"
+)
+
+// GetFlaggedMessagesParams holds the data we expect when fetching a page of the flagged chat review queue.
+type GetFlaggedMessagesParams struct {
+	Offset int `query:"offset" json:"offset" form:"offset"`
+	Limit  int `query:"limit" json:"limit" form:"limit"`
+}
+
+func (body *GetFlaggedMessagesParams) Validate() (bool, url.Values) {
+	pagination := &SearchParams{Offset: body.Offset, Limit: body.Limit}
+
+	return pagination.Validate()
+}
+
+// GetChatContextParams holds the data we expect when fetching the messages around a chat message. A value of 0 for
+// Before or After means the default context size is used.
+type GetChatContextParams struct {
+	Before int `query:"before" json:"before" form:"before"`
+	After  int `query:"after" json:"after" form:"after"`
+}
+
+func (body *GetChatContextParams) Validate() (bool, url.Values) {
+	errors := url.Values{}
+
+	if body.Before < 0 || body.Before > config.ChatContextMaxSize {
+		errors.Set("before", fmt.Sprintf("Before must be between 0 and %d", config.ChatContextMaxSize))
+	}
+
+	if body.After < 0 || body.After > config.ChatContextMaxSize {
+		errors.Set("after", fmt.Sprintf("After must be between 0 and %d", config.ChatContextMaxSize))
+	}
+
+	if body.Before == 0 {
+		body.Before = config.ChatContextDefaultSize
+	}
+
+	if body.After == 0 {
+		body.After = config.ChatContextDefaultSize
+	}
+
+	return len(errors) == 0, errors
+}
+
+const (
+	TranscriptFormatText = "text"
+	TranscriptFormatCSV  = "csv"
+	TranscriptFormatJSON = "json"
+)
+
+// ExportTranscriptParams holds the data we expect when exporting a server's chat transcript. StartDate and EndDate
+// are unix timestamps. Format defaults to plain text.
+type ExportTranscriptParams struct {
+	ServerID  int64  `query:"serverId" json:"serverId" form:"serverId"`
+	StartDate int64  `query:"startDate" json:"startDate" form:"startDate"`
+	EndDate   int64  `query:"endDate" json:"endDate" form:"endDate"`
+	Format    string `query:"format" json:"format" form:"format"`
+}
+
+func (body *ExportTranscriptParams) Validate() (bool, url.Values) {
+	errors := url.Values{}
+
+	if body.ServerID < 1 {
+		errors.Set("serverId", "Invalid server ID")
+	}
+
+	if body.StartDate < 0 {
+		errors.Set("startDate", "Invalid start date")
+	}
+
+	if body.EndDate <= body.StartDate {
+		errors.Set("endDate", "End date must be after the start date")
+	}
+
+	switch body.Format {
+	case "":
+		body.Format = TranscriptFormatText
+	case TranscriptFormatText, TranscriptFormatCSV, TranscriptFormatJSON:
+	default:
+		errors.Set("format", "Format must be one of text, csv or json")
+	}
+
+	return len(errors) == 0, errors
+}
+
+// SendWhisperParams holds the data we expect when privately messaging a player on a server
+type SendWhisperParams struct {
+	ServerID int64  `json:"serverId" form:"serverId"`
+	PlayerID int64  `json:"playerId" form:"playerId"`
+	Message  string `json:"message" form:"message"`
+	*UserMeta
+}
+
+func (body *SendWhisperParams) Validate() (bool, url.Values) {
+	errors := url.Values{}
+
+	if body.ServerID < 1 {
+		errors.Set("serverId", "Invalid server ID")
+	}
+
+	if body.PlayerID < 1 {
+		errors.Set("playerId", "Invalid player ID")
+	}
+
+	body.Message = strings.TrimSpace(body.Message)
+	if body.Message == "" {
+		errors.Set("message", "Message is a required field")
+	} else if len(body.Message) > config.WhisperMaxLen {
+		errors.Set("message", fmt.Sprintf("Message must be no longer than %d characters", config.WhisperMaxLen))
+	}
+
+	return len(errors) == 0, errors
+}
+
+// AddChatKeywordParams holds the data we expect when a user subscribes to a chat keyword
+type AddChatKeywordParams struct {
+	Keyword string `json:"keyword" form:"keyword"`
+	*UserMeta
+}
+
+func (body *AddChatKeywordParams) Validate() (bool, url.Values) {
+	errors := url.Values{}
+
+	body.Keyword = strings.TrimSpace(body.Keyword)
+	if len(body.Keyword) < config.ChatKeywordMinLen || len(body.Keyword) > config.ChatKeywordMaxLen {
+		errors.Set("keyword", fmt.Sprintf("Keyword must be between %d and %d characters in length",
+			config.ChatKeywordMinLen, config.ChatKeywordMaxLen))
+	}
+
+	return len(errors) == 0, errors
+}
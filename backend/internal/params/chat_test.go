@@ -0,0 +1,96 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package params
+
+import (
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestGetChatContextParams_Validate(t *testing.T) {
+	body := &GetChatContextParams{}
+	valid, _ := body.Validate()
+	assert.True(t, valid)
+	assert.Equal(t, config.ChatContextDefaultSize, body.Before)
+	assert.Equal(t, config.ChatContextDefaultSize, body.After)
+
+	body = &GetChatContextParams{Before: 5, After: config.ChatContextMaxSize + 1}
+	valid, errors := body.Validate()
+	assert.False(t, valid)
+	assert.NotEmpty(t, errors.Get("after"))
+
+	body = &GetChatContextParams{Before: -1, After: 2}
+	valid, _ = body.Validate()
+	assert.False(t, valid)
+}
+
+func TestExportTranscriptParams_Validate(t *testing.T) {
+	body := &ExportTranscriptParams{ServerID: 1, StartDate: 100, EndDate: 200}
+	valid, _ := body.Validate()
+	assert.True(t, valid)
+	assert.Equal(t, TranscriptFormatText, body.Format)
+
+	body = &ExportTranscriptParams{ServerID: 1, StartDate: 200, EndDate: 100, Format: "xml"}
+	valid, errors := body.Validate()
+	assert.False(t, valid)
+	assert.NotEmpty(t, errors.Get("endDate"))
+	assert.NotEmpty(t, errors.Get("format"))
+
+	body = &ExportTranscriptParams{StartDate: 100, EndDate: 200, Format: TranscriptFormatCSV}
+	valid, errors = body.Validate()
+	assert.False(t, valid)
+	assert.NotEmpty(t, errors.Get("serverId"))
+}
+
+func TestSendWhisperParams_Validate(t *testing.T) {
+	body := &SendWhisperParams{ServerID: 1, PlayerID: 1, Message: "  hello  "}
+	valid, _ := body.Validate()
+	assert.True(t, valid)
+	assert.Equal(t, "hello", body.Message)
+
+	body = &SendWhisperParams{ServerID: 1, PlayerID: 1, Message: "   "}
+	valid, errors := body.Validate()
+	assert.False(t, valid)
+	assert.NotEmpty(t, errors.Get("message"))
+
+	body = &SendWhisperParams{Message: strings.Repeat("a", config.WhisperMaxLen+1)}
+	valid, errors = body.Validate()
+	assert.False(t, valid)
+	assert.NotEmpty(t, errors.Get("serverId"))
+	assert.NotEmpty(t, errors.Get("playerId"))
+	assert.NotEmpty(t, errors.Get("message"))
+}
+
+func TestAddChatKeywordParams_Validate(t *testing.T) {
+	body := &AddChatKeywordParams{Keyword: "  hacker  "}
+	valid, _ := body.Validate()
+	assert.True(t, valid)
+	assert.Equal(t, "hacker", body.Keyword)
+
+	body = &AddChatKeywordParams{Keyword: " a "}
+	valid, errors := body.Validate()
+	assert.False(t, valid)
+	assert.NotEmpty(t, errors.Get("keyword"))
+
+	body = &AddChatKeywordParams{Keyword: strings.Repeat("a", config.ChatKeywordMaxLen+1)}
+	valid, errors = body.Validate()
+	assert.False(t, valid)
+	assert.NotEmpty(t, errors.Get("keyword"))
+}
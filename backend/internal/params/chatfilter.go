@@ -0,0 +1,127 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package params
+
+import (
+	"fmt"
+	"github.com/sniddunc/refractor/pkg/chatfilter"
+	"github.com/sniddunc/refractor/pkg/config"
+	"net/url"
+)
+
+// CreateChatFilterRuleParams holds the data we expect when creating a chat filter rule
+type CreateChatFilterRuleParams struct {
+	Game     string `json:"game" form:"game"`
+	ServerID int64  `json:"serverId" form:"serverId"`
+	Type     string `json:"type" form:"type"`
+	Pattern  string `json:"pattern" form:"pattern"`
+	Action   string `json:"action" form:"action"`
+	Reason   string `json:"reason" form:"reason"`
+	Duration int    `json:"duration" form:"duration"`
+	*UserMeta
+}
+
+func (body *CreateChatFilterRuleParams) Validate() (bool, url.Values) {
+	errors := url.Values{}
+
+	if len(body.Game) > config.ServerGameMaxLen {
+		errors.Set("game", fmt.Sprintf("Game name must be no longer than %d characters", config.ServerGameMaxLen))
+	}
+
+	if body.ServerID < 0 {
+		errors.Set("serverId", "Invalid server ID")
+	}
+
+	validateChatFilterRule(errors, body.Type, body.Pattern, body.Action, body.Reason, body.Duration)
+
+	return len(errors) == 0, errors
+}
+
+// UpdateChatFilterRuleParams holds the data we expect when updating a chat filter rule
+type UpdateChatFilterRuleParams struct {
+	Type     *string `json:"type" form:"type"`
+	Pattern  *string `json:"pattern" form:"pattern"`
+	Action   *string `json:"action" form:"action"`
+	Reason   *string `json:"reason" form:"reason"`
+	Duration *int    `json:"duration" form:"duration"`
+	Enabled  *bool   `json:"enabled" form:"enabled"`
+}
+
+// Validate validates the fields which were provided. Whether the pattern is valid for the rule's type can only be
+// checked once the update is applied to the existing rule, so that check is done by the service.
+func (body *UpdateChatFilterRuleParams) Validate() (bool, url.Values) {
+	errors := url.Values{}
+
+	if body.Type != nil && !isOneOf(*body.Type, chatfilter.Types) {
+		errors.Set("type", "Invalid rule type")
+	}
+
+	if body.Pattern != nil && (*body.Pattern == "" || len(*body.Pattern) > config.ChatFilterPatternMaxLen) {
+		errors.Set("pattern", fmt.Sprintf("Pattern must be between 1 and %d characters in length",
+			config.ChatFilterPatternMaxLen))
+	}
+
+	if body.Action != nil && !isOneOf(*body.Action, chatfilter.Actions) {
+		errors.Set("action", "Invalid rule action")
+	}
+
+	if body.Reason != nil && len(*body.Reason) > config.InfractionReasonMaxLen {
+		errors.Set("reason", fmt.Sprintf("Reason must be no longer than %d characters", config.InfractionReasonMaxLen))
+	}
+
+	if body.Duration != nil && (*body.Duration < 0 || *body.Duration > config.InfractionDurationMax) {
+		errors.Set("duration", "Invalid duration")
+	}
+
+	return len(errors) == 0, errors
+}
+
+func validateChatFilterRule(errors url.Values, ruleType string, pattern string, action string, reason string, duration int) {
+	if !isOneOf(ruleType, chatfilter.Types) {
+		errors.Set("type", "Invalid rule type")
+	}
+
+	if pattern == "" || len(pattern) > config.ChatFilterPatternMaxLen {
+		errors.Set("pattern", fmt.Sprintf("Pattern must be between 1 and %d characters in length",
+			config.ChatFilterPatternMaxLen))
+	} else if _, err := chatfilter.Compile(ruleType, pattern); err != nil && errors.Get("type") == "" {
+		errors.Set("pattern", "Invalid pattern")
+	}
+
+	if !isOneOf(action, chatfilter.Actions) {
+		errors.Set("action", "Invalid rule action")
+	}
+
+	if len(reason) > config.InfractionReasonMaxLen {
+		errors.Set("reason", fmt.Sprintf("Reason must be no longer than %d characters", config.InfractionReasonMaxLen))
+	}
+
+	if duration < 0 || duration > config.InfractionDurationMax {
+		errors.Set("duration", "Invalid duration")
+	}
+}
+
+func isOneOf(value string, allowed []string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+
+	return false
+}
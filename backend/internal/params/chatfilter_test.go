@@ -0,0 +1,70 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package params
+
+import (
+	"github.com/sniddunc/refractor/pkg/chatfilter"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCreateChatFilterRuleParams_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      CreateChatFilterRuleParams
+		wantValid bool
+	}{
+		{
+			name:      "params.chatfilter.create.1",
+			body:      CreateChatFilterRuleParams{Type: chatfilter.TYPE_WORDLIST, Pattern: "a, b", Action: chatfilter.ACTION_FLAG},
+			wantValid: true,
+		},
+		{
+			name:      "params.chatfilter.create.2",
+			body:      CreateChatFilterRuleParams{Type: chatfilter.TYPE_REGEX, Pattern: "([a-z", Action: chatfilter.ACTION_FLAG},
+			wantValid: false,
+		},
+		{
+			name:      "params.chatfilter.create.3",
+			body:      CreateChatFilterRuleParams{Type: "UNKNOWN", Pattern: "a", Action: chatfilter.ACTION_FLAG},
+			wantValid: false,
+		},
+		{
+			name:      "params.chatfilter.create.4",
+			body:      CreateChatFilterRuleParams{Type: chatfilter.TYPE_LEETSPEAK, Pattern: "a", Action: "BAN"},
+			wantValid: false,
+		},
+		{
+			name: "params.chatfilter.create.5",
+			body: CreateChatFilterRuleParams{Type: chatfilter.TYPE_LEETSPEAK, Pattern: "a", Action: chatfilter.ACTION_MUTE,
+				Duration: -1},
+			wantValid: false,
+		},
+		{
+			name:      "params.chatfilter.create.6",
+			body:      CreateChatFilterRuleParams{Type: chatfilter.TYPE_WORDLIST, Pattern: "", Action: chatfilter.ACTION_FLAG},
+			wantValid: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, _ := tt.body.Validate()
+			assert.Equal(t, tt.wantValid, valid)
+		})
+	}
+}
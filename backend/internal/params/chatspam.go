@@ -0,0 +1,83 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package params
+
+import (
+	"fmt"
+	"github.com/sniddunc/refractor/pkg/chatspam"
+	"github.com/sniddunc/refractor/pkg/config"
+	"net/url"
+)
+
+// UpdateChatSpamSettingsParams holds the data we expect when updating a server's chat spam settings
+type UpdateChatSpamSettingsParams struct {
+	Enabled       *bool   `json:"enabled" form:"enabled"`
+	FloodMessages *int    `json:"floodMessages" form:"floodMessages"`
+	FloodWindow   *int    `json:"floodWindow" form:"floodWindow"`
+	FloodAction   *string `json:"floodAction" form:"floodAction"`
+	RepeatCount   *int    `json:"repeatCount" form:"repeatCount"`
+	RepeatAction  *string `json:"repeatAction" form:"repeatAction"`
+	CapsPercent   *int    `json:"capsPercent" form:"capsPercent"`
+	CapsMinLength *int    `json:"capsMinLength" form:"capsMinLength"`
+	CapsAction    *string `json:"capsAction" form:"capsAction"`
+	MuteDuration  *int    `json:"muteDuration" form:"muteDuration"`
+	*UserMeta
+}
+
+func (body *UpdateChatSpamSettingsParams) Validate() (bool, url.Values) {
+	errors := url.Values{}
+
+	thresholds := []struct {
+		field string
+		value *int
+		max   int
+	}{
+		{"floodMessages", body.FloodMessages, config.ChatSpamThresholdMax},
+		{"floodWindow", body.FloodWindow, config.ChatSpamFloodWindowMax},
+		{"repeatCount", body.RepeatCount, config.ChatSpamThresholdMax},
+		{"capsPercent", body.CapsPercent, 100},
+		{"capsMinLength", body.CapsMinLength, config.ChatSpamThresholdMax},
+	}
+
+	for _, t := range thresholds {
+		if t.value != nil && (*t.value < 0 || *t.value > t.max) {
+			errors.Set(t.field, fmt.Sprintf("Must be between 0 and %d", t.max))
+		}
+	}
+
+	actions := []struct {
+		field string
+		value *string
+	}{
+		{"floodAction", body.FloodAction},
+		{"repeatAction", body.RepeatAction},
+		{"capsAction", body.CapsAction},
+	}
+
+	for _, a := range actions {
+		if a.value != nil && !isOneOf(*a.value, chatspam.Actions) {
+			errors.Set(a.field, "Invalid action")
+		}
+	}
+
+	if body.MuteDuration != nil && (*body.MuteDuration < 0 || *body.MuteDuration > config.InfractionDurationMax) {
+		errors.Set("muteDuration", "Invalid duration")
+	}
+
+	return len(errors) == 0, errors
+}
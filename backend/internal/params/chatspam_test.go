@@ -0,0 +1,72 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package params
+
+import (
+	"github.com/sniddunc/refractor/pkg/chatspam"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUpdateChatSpamSettingsParams_Validate(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+	strPtr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name      string
+		body      UpdateChatSpamSettingsParams
+		wantValid bool
+	}{
+		{
+			name:      "params.chatspam.update.1",
+			body:      UpdateChatSpamSettingsParams{FloodMessages: intPtr(5), FloodWindow: intPtr(10), FloodAction: strPtr(chatspam.ACTION_MUTE)},
+			wantValid: true,
+		},
+		{
+			name:      "params.chatspam.update.2",
+			body:      UpdateChatSpamSettingsParams{},
+			wantValid: true,
+		},
+		{
+			name:      "params.chatspam.update.3",
+			body:      UpdateChatSpamSettingsParams{CapsPercent: intPtr(101)},
+			wantValid: false,
+		},
+		{
+			name:      "params.chatspam.update.4",
+			body:      UpdateChatSpamSettingsParams{RepeatCount: intPtr(-1)},
+			wantValid: false,
+		},
+		{
+			name:      "params.chatspam.update.5",
+			body:      UpdateChatSpamSettingsParams{CapsAction: strPtr("BAN")},
+			wantValid: false,
+		},
+		{
+			name:      "params.chatspam.update.6",
+			body:      UpdateChatSpamSettingsParams{MuteDuration: intPtr(-5)},
+			wantValid: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, _ := tt.body.Validate()
+			assert.Equal(t, tt.wantValid, valid)
+		})
+	}
+}
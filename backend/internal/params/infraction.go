@@ -181,3 +181,33 @@ func (body *UpdateInfractionParams) Validate() (bool, url.Values) {
 
 	return len(errors) == 0, errors
 }
+
+// CreateChatInfractionParams holds the data we expect when turning a flagged chat message into an infraction
+type CreateChatInfractionParams struct {
+	Type     string `json:"type" form:"type"`
+	Reason   string `json:"reason" form:"reason"`
+	Duration int    `json:"duration" form:"duration"`
+	*UserMeta
+}
+
+// Validate validates the reason and duration. The type is checked by the service since the valid infraction types
+// are defined in the refractor package.
+func (body *CreateChatInfractionParams) Validate() (bool, url.Values) {
+	errors := url.Values{}
+
+	if body.Type == "" {
+		errors.Set("type", "Type is a required field")
+	}
+
+	if body.Reason == "" {
+		errors.Set("reason", "Reason is a required field")
+	} else if len(body.Reason) < config.InfractionReasonMinLen || len(body.Reason) > config.InfractionReasonMaxLen {
+		errors.Set("reason", fmt.Sprintf("Reason must be between %d and %d characters in length", config.InfractionReasonMinLen, config.InfractionReasonMaxLen))
+	}
+
+	if body.Duration < 0 || body.Duration > config.InfractionDurationMax {
+		errors.Set("duration", "Invalid duration")
+	}
+
+	return len(errors) == 0, errors
+}
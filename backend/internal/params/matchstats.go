@@ -0,0 +1,52 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package params
+
+import (
+	"fmt"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/matchstats"
+	"net/url"
+)
+
+// UpdateTeamkillSettingsParams holds the data we expect when updating a server's teamkill settings
+type UpdateTeamkillSettingsParams struct {
+	Enabled     *bool   `json:"enabled" form:"enabled"`
+	Threshold   *int    `json:"threshold" form:"threshold"`
+	Action      *string `json:"action" form:"action"`
+	BanDuration *int    `json:"banDuration" form:"banDuration"`
+	*UserMeta
+}
+
+func (body *UpdateTeamkillSettingsParams) Validate() (bool, url.Values) {
+	errors := url.Values{}
+
+	if body.Threshold != nil && (*body.Threshold < 1 || *body.Threshold > config.TeamkillThresholdMax) {
+		errors.Set("threshold", fmt.Sprintf("Must be between 1 and %d", config.TeamkillThresholdMax))
+	}
+
+	if body.Action != nil && !isOneOf(*body.Action, matchstats.Actions) {
+		errors.Set("action", "Invalid action")
+	}
+
+	if body.BanDuration != nil && (*body.BanDuration < 0 || *body.BanDuration > config.InfractionDurationMax) {
+		errors.Set("banDuration", "Invalid duration")
+	}
+
+	return len(errors) == 0, errors
+}
@@ -0,0 +1,67 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package params
+
+import (
+	"github.com/sniddunc/refractor/pkg/matchstats"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUpdateTeamkillSettingsParams_Validate(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+	strPtr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name      string
+		body      UpdateTeamkillSettingsParams
+		wantValid bool
+	}{
+		{
+			name:      "params.teamkill.update.1",
+			body:      UpdateTeamkillSettingsParams{Threshold: intPtr(3), Action: strPtr(matchstats.ACTION_BAN), BanDuration: intPtr(60)},
+			wantValid: true,
+		},
+		{
+			name:      "params.teamkill.update.2",
+			body:      UpdateTeamkillSettingsParams{},
+			wantValid: true,
+		},
+		{
+			name:      "params.teamkill.update.3",
+			body:      UpdateTeamkillSettingsParams{Threshold: intPtr(0)},
+			wantValid: false,
+		},
+		{
+			name:      "params.teamkill.update.4",
+			body:      UpdateTeamkillSettingsParams{Action: strPtr("MUTE")},
+			wantValid: false,
+		},
+		{
+			name:      "params.teamkill.update.5",
+			body:      UpdateTeamkillSettingsParams{BanDuration: intPtr(-1)},
+			wantValid: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, _ := tt.body.Validate()
+			assert.Equal(t, tt.wantValid, valid)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package params
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// MergePlayersParams holds the data we expect when merging a duplicate player (source) into another player (target).
+type MergePlayersParams struct {
+	SourceID int64 `json:"sourceId" form:"sourceId"`
+	TargetID int64 `json:"targetId" form:"targetId"`
+	*UserMeta
+}
+
+func (body *MergePlayersParams) Validate() (bool, url.Values) {
+	errors := url.Values{}
+
+	if body.SourceID < 1 {
+		errors.Set("sourceId", "Invalid player ID")
+	}
+
+	if body.TargetID < 1 {
+		errors.Set("targetId", "Invalid player ID")
+	}
+
+	if body.SourceID == body.TargetID {
+		errors.Set("targetId", "A player cannot be merged into itself")
+	}
+
+	return len(errors) == 0, errors
+}
+
+// GetRecentPlayersParams holds the data we expect when fetching recent players.
+// All fields are optional. An unset Until means "up to now".
+type GetRecentPlayersParams struct {
+	ServerID string `query:"serverId" json:"serverId" form:"serverId"`
+	Since    int64  `query:"since" json:"since" form:"since"`
+	Until    int64  `query:"until" json:"until" form:"until"`
+	*ParsedRecentPlayersIDs
+}
+
+type ParsedRecentPlayersIDs struct {
+	ServerID int64
+}
+
+func (body *GetRecentPlayersParams) Validate() (bool, url.Values) {
+	body.ParsedRecentPlayersIDs = &ParsedRecentPlayersIDs{}
+
+	errors := url.Values{}
+
+	// Validate and parse ServerID
+	if body.ServerID != "" {
+		serverID, err := strconv.ParseInt(body.ServerID, 10, 64)
+		if err != nil || serverID < 1 {
+			errors.Set("serverId", "Invalid server ID")
+		} else {
+			body.ParsedRecentPlayersIDs.ServerID = serverID
+		}
+	}
+
+	if body.Since < 0 {
+		errors.Set("since", "Invalid start time provided")
+	}
+
+	if body.Until < 0 {
+		errors.Set("until", "Invalid end time provided")
+	} else if body.Until != 0 && body.Until < body.Since {
+		errors.Set("until", "End time must not be before the start time")
+	}
+
+	return len(errors) == 0, errors
+}
@@ -0,0 +1,137 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package params
+
+import "testing"
+
+func TestGetRecentPlayersParams_Validate(t *testing.T) {
+	type fields struct {
+		ServerID string
+		Since    int64
+		Until    int64
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   bool
+	}{
+		{
+			name:   "params.recentplayers.validate.1",
+			fields: fields{},
+			want:   true,
+		},
+		{
+			name: "params.recentplayers.validate.2",
+			fields: fields{
+				ServerID: "3",
+				Since:    1610000000,
+				Until:    1610086400,
+			},
+			want: true,
+		},
+		{
+			name: "params.recentplayers.validate.3",
+			fields: fields{
+				ServerID: "not a number",
+			},
+			want: false,
+		},
+		{
+			name: "params.recentplayers.validate.4",
+			fields: fields{
+				ServerID: "0",
+			},
+			want: false,
+		},
+		{
+			name: "params.recentplayers.validate.5",
+			fields: fields{
+				Since: -1,
+			},
+			want: false,
+		},
+		{
+			name: "params.recentplayers.validate.6",
+			fields: fields{
+				Since: 1610086400,
+				Until: 1610000000,
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &GetRecentPlayersParams{
+				ServerID: tt.fields.ServerID,
+				Since:    tt.fields.Since,
+				Until:    tt.fields.Until,
+			}
+
+			got, errors := body.Validate()
+			if got != tt.want {
+				t.Errorf("Validate() got = %v, want %v\nErrors: %v", got, tt.want, errors)
+			}
+		})
+	}
+}
+
+func TestMergePlayersParams_Validate(t *testing.T) {
+	type fields struct {
+		SourceID int64
+		TargetID int64
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   bool
+	}{
+		{
+			name:   "params.mergeplayers.validate.1",
+			fields: fields{SourceID: 1, TargetID: 2},
+			want:   true,
+		},
+		{
+			name:   "params.mergeplayers.validate.2",
+			fields: fields{SourceID: 2, TargetID: 2},
+			want:   false,
+		},
+		{
+			name:   "params.mergeplayers.validate.3",
+			fields: fields{SourceID: 0, TargetID: 2},
+			want:   false,
+		},
+		{
+			name:   "params.mergeplayers.validate.4",
+			fields: fields{SourceID: 1, TargetID: -1},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &MergePlayersParams{
+				SourceID: tt.fields.SourceID,
+				TargetID: tt.fields.TargetID,
+			}
+
+			got, errors := body.Validate()
+			if got != tt.want {
+				t.Errorf("Validate() got = %v, want %v\nErrors: %v", got, tt.want, errors)
+			}
+		})
+	}
+}
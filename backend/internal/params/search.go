@@ -54,7 +54,7 @@ type SearchPlayersParams struct {
 	SearchParams
 }
 
-var validPlayerSearchTypes = []string{"name", "id", "playfabid", "mcuuid"}
+var validPlayerSearchTypes = []string{"name", "id", "playfabid", "mcuuid", "steamid"}
 
 func (body *SearchPlayersParams) Validate() (bool, url.Values) {
 	if ok, errors := body.SearchParams.Validate(); !ok {
@@ -186,6 +186,8 @@ type SearchChatMessagesParams struct {
 	EndDate   int64  `json:"endDate" form:"endDate"`
 	PlayerID  string `json:"playerId" form:"playerId"`
 	ServerID  string `json:"serverId" form:"serverId"`
+	Channel   string `json:"channel" form:"channel"`
+	Name      string `json:"name" form:"name"`
 	*ParsedChatMessageIDs
 	SearchParams
 }
@@ -228,5 +230,15 @@ func (body *SearchChatMessagesParams) Validate() (bool, url.Values) {
 		errors.Set("endDate", "Invalid end date provided")
 	}
 
+	body.Channel = strings.TrimSpace(body.Channel)
+	if len(body.Channel) > config.ChatChannelMaxLen {
+		errors.Set("channel", fmt.Sprintf("Channel must be no longer than %d characters", config.ChatChannelMaxLen))
+	}
+
+	body.Name = strings.TrimSpace(body.Name)
+	if len(body.Name) > config.SearchTermMaxLen {
+		errors.Set("name", fmt.Sprintf("Name must be no longer than %d characters", config.SearchTermMaxLen))
+	}
+
 	return len(errors) == 0, errors
 }
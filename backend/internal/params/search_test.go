@@ -229,6 +229,8 @@ func TestSearchChatMessagesParams_Validate(t *testing.T) {
 		Message      string
 		PlayerID     string
 		ServerID     string
+		Channel      string
+		Name         string
 		StartDate    int64
 		EndDate      int64
 		SearchParams SearchParams
@@ -322,12 +324,48 @@ func TestSearchChatMessagesParams_Validate(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "params.search.infractions.validate.5",
+			fields: fields{
+				Channel: "Team",
+				Name:    "Player",
+				SearchParams: SearchParams{
+					Offset: config.SearchOffsetMin,
+					Limit:  config.SearchLimitMin,
+				},
+			},
+			want: true,
+		},
+		{
+			name: "params.search.infractions.validate.6",
+			fields: fields{
+				Channel: strings.Repeat("a", config.ChatChannelMaxLen+1),
+				SearchParams: SearchParams{
+					Offset: config.SearchOffsetMin,
+					Limit:  config.SearchLimitMin,
+				},
+			},
+			want: false,
+		},
+		{
+			name: "params.search.infractions.validate.7",
+			fields: fields{
+				Name: strings.Repeat("a", config.SearchTermMaxLen+1),
+				SearchParams: SearchParams{
+					Offset: config.SearchOffsetMin,
+					Limit:  config.SearchLimitMin,
+				},
+			},
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			body := &SearchChatMessagesParams{
 				PlayerID:     tt.fields.PlayerID,
 				ServerID:     tt.fields.ServerID,
+				Channel:      tt.fields.Channel,
+				Name:         tt.fields.Name,
 				StartDate:    tt.fields.StartDate,
 				EndDate:      tt.fields.EndDate,
 				SearchParams: tt.fields.SearchParams,
@@ -25,13 +25,35 @@ import (
 	"strconv"
 )
 
+// Actions taken when a muted player chats on a server running a game without native mute support
+const (
+	MuteActionRemind   = "REMIND"
+	MuteActionKick     = "KICK"
+	MuteActionEscalate = "ESCALATE"
+)
+
+var MuteActions = []string{MuteActionRemind, MuteActionKick, MuteActionEscalate}
+
+// Sources of player join, quit and chat events for a server
+const (
+	EventSourceBroadcast = "BROADCAST"
+	EventSourcePolling   = "POLLING"
+	EventSourceLog       = "LOG"
+)
+
+var EventSources = []string{EventSourceBroadcast, EventSourcePolling, EventSourceLog}
+
 // CreateServerParams holds the data we expect when creating a server
 type CreateServerParams struct {
-	Name         string `form:"name"`
-	Game         string `form:"game"`
-	Address      string `form:"address"`
-	RCONPort     string `form:"rconPort"`
-	RCONPassword string `form:"rconPassword"`
+	Name                string `form:"name"`
+	Game                string `form:"game"`
+	Address             string `form:"address"`
+	RCONPort            string `form:"rconPort"`
+	RCONPassword        string `form:"rconPassword"`
+	WarnMessageTemplate string `form:"warnMessageTemplate"`
+	MuteAction          string `form:"muteAction"`
+	EventSource         string `form:"eventSource"`
+	LogFilePath         string `form:"logFilePath"`
 }
 
 // Validate validates the data inside the attached struct
@@ -63,6 +85,26 @@ func (body *CreateServerParams) Validate() (bool, url.Values) {
 			config.ServerPasswordMinLen, config.ServerPasswordMaxLen))
 	}
 
+	if len(body.WarnMessageTemplate) > config.WarnMessageTemplateMaxLen {
+		errors.Set("warnMessageTemplate", fmt.Sprintf("Warn message template must be no longer than %d characters",
+			config.WarnMessageTemplateMaxLen))
+	}
+
+	if body.MuteAction != "" && !isOneOf(body.MuteAction, MuteActions) {
+		errors.Set("muteAction", "Invalid mute action")
+	}
+
+	if body.EventSource != "" && !isOneOf(body.EventSource, EventSources) {
+		errors.Set("eventSource", "Invalid event source")
+	}
+
+	if len(body.LogFilePath) > config.ServerLogPathMaxLen {
+		errors.Set("logFilePath", fmt.Sprintf("Log file path must be no longer than %d characters",
+			config.ServerLogPathMaxLen))
+	} else if body.EventSource == EventSourceLog && body.LogFilePath == "" {
+		errors.Set("logFilePath", "A log file path is required to use the log event source")
+	}
+
 	return len(errors) == 0, errors
 }
 
@@ -71,6 +113,12 @@ type UpdateServerParams struct {
 	Address      string `form:"address"`
 	RCONPort     string `form:"rconPort"`
 	RCONPassword string `form:"rconPassword"`
+
+	// WarnMessageTemplate is a pointer so that the template can be reset to the default by setting it to ""
+	WarnMessageTemplate *string `json:"warnMessageTemplate" form:"warnMessageTemplate"`
+	MuteAction          string  `json:"muteAction" form:"muteAction"`
+	EventSource         string  `json:"eventSource" form:"eventSource"`
+	LogFilePath         string  `json:"logFilePath" form:"logFilePath"`
 }
 
 func (body *UpdateServerParams) Validate() (bool, url.Values) {
@@ -90,8 +138,8 @@ func (body *UpdateServerParams) Validate() (bool, url.Values) {
 	}
 
 	if body.RCONPort != "" {
-		// Since port numbers are 16 bit ints, we can check if the provided port is valid by
-		// trying to parse it to an int16.
+		// Since port numbers are 16 bit unsigned ints, we can check if the provided port is valid by
+		// trying to parse it to a uint16.
 		if _, err := strconv.ParseUint(body.RCONPort, 10, 16); err != nil {
 			errors.Set("rconPort", "The provided RCON port was not a valid port number")
 		}
@@ -104,5 +152,23 @@ func (body *UpdateServerParams) Validate() (bool, url.Values) {
 		}
 	}
 
+	if body.WarnMessageTemplate != nil && len(*body.WarnMessageTemplate) > config.WarnMessageTemplateMaxLen {
+		errors.Set("warnMessageTemplate", fmt.Sprintf("Warn message template must be no longer than %d characters",
+			config.WarnMessageTemplateMaxLen))
+	}
+
+	if body.MuteAction != "" && !isOneOf(body.MuteAction, MuteActions) {
+		errors.Set("muteAction", "Invalid mute action")
+	}
+
+	if body.EventSource != "" && !isOneOf(body.EventSource, EventSources) {
+		errors.Set("eventSource", "Invalid event source")
+	}
+
+	if len(body.LogFilePath) > config.ServerLogPathMaxLen {
+		errors.Set("logFilePath", fmt.Sprintf("Log file path must be no longer than %d characters",
+			config.ServerLogPathMaxLen))
+	}
+
 	return len(errors) == 0, errors
 }
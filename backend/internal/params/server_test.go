@@ -298,3 +298,50 @@ func TestUpdateServerParams_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateServerParams_Validate_WarnMessageTemplate(t *testing.T) {
+	template := ""
+	body := &UpdateServerParams{WarnMessageTemplate: &template}
+	valid, _ := body.Validate()
+	assert.True(t, valid)
+
+	template = strings.Repeat("a", config.WarnMessageTemplateMaxLen+1)
+	valid, errors := body.Validate()
+	assert.False(t, valid)
+	assert.NotEmpty(t, errors.Get("warnMessageTemplate"))
+}
+
+func TestUpdateServerParams_Validate_MuteAction(t *testing.T) {
+	body := &UpdateServerParams{MuteAction: MuteActionEscalate}
+	valid, _ := body.Validate()
+	assert.True(t, valid)
+
+	body = &UpdateServerParams{MuteAction: "BAN"}
+	valid, errors := body.Validate()
+	assert.False(t, valid)
+	assert.NotEmpty(t, errors.Get("muteAction"))
+}
+
+func TestCreateServerParams_Validate_EventSource(t *testing.T) {
+	body := &CreateServerParams{
+		Game:         "Mordhau",
+		Name:         "valid name",
+		Address:      "192.168.1.2",
+		RCONPort:     "4322",
+		RCONPassword: "password",
+		EventSource:  EventSourceLog,
+		LogFilePath:  "/srv/mordhau/Mordhau.log",
+	}
+	valid, _ := body.Validate()
+	assert.True(t, valid)
+
+	body.LogFilePath = ""
+	valid, errors := body.Validate()
+	assert.False(t, valid)
+	assert.NotEmpty(t, errors.Get("logFilePath"))
+
+	body.EventSource = "SOCKET"
+	valid, errors = body.Validate()
+	assert.False(t, valid)
+	assert.NotEmpty(t, errors.Get("eventSource"))
+}
@@ -0,0 +1,61 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package params
+
+import (
+	"net/url"
+)
+
+// Sizes of the buckets server status history can be grouped into
+const (
+	HistoryBucketMinute = "minute"
+	HistoryBucketHour   = "hour"
+	HistoryBucketDay    = "day"
+)
+
+var HistoryBuckets = []string{HistoryBucketMinute, HistoryBucketHour, HistoryBucketDay}
+
+// GetServerHistoryParams holds the data we expect when fetching a server's status history. From and To are unix
+// timestamps. If they are left out, the most recent history is returned.
+type GetServerHistoryParams struct {
+	Bucket string `query:"bucket"`
+	From   int64  `query:"from"`
+	To     int64  `query:"to"`
+}
+
+func (body *GetServerHistoryParams) Validate() (bool, url.Values) {
+	errors := url.Values{}
+
+	if !isOneOf(body.Bucket, HistoryBuckets) {
+		errors.Set("bucket", "Must be one of minute, hour or day")
+	}
+
+	if body.From < 0 {
+		errors.Set("from", "Invalid timestamp")
+	}
+
+	if body.To < 0 {
+		errors.Set("to", "Invalid timestamp")
+	}
+
+	if body.From > 0 && body.To > 0 && body.From >= body.To {
+		errors.Set("to", "Must be after from")
+	}
+
+	return len(errors) == 0, errors
+}
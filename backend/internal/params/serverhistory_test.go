@@ -0,0 +1,63 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package params
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetServerHistoryParams_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		body GetServerHistoryParams
+		want bool
+	}{
+		{
+			name: "params.getserverhistory.1",
+			body: GetServerHistoryParams{Bucket: HistoryBucketHour},
+			want: true,
+		},
+		{
+			name: "params.getserverhistory.2",
+			body: GetServerHistoryParams{Bucket: HistoryBucketDay, From: 1610000000, To: 1610086400},
+			want: true,
+		},
+		{
+			name: "params.getserverhistory.3",
+			body: GetServerHistoryParams{Bucket: "week"},
+			want: false,
+		},
+		{
+			name: "params.getserverhistory.4",
+			body: GetServerHistoryParams{Bucket: HistoryBucketMinute, From: 1610086400, To: 1610000000},
+			want: false,
+		},
+		{
+			name: "params.getserverhistory.5",
+			body: GetServerHistoryParams{Bucket: HistoryBucketMinute, From: -1},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errors := tt.body.Validate()
+			assert.Equal(t, tt.want, got, "Validate returned the wrong values. Errors: %v", errors)
+		})
+	}
+}
@@ -19,6 +19,8 @@ package player
 
 import (
 	"database/sql"
+	"fmt"
+	"github.com/sniddunc/refractor/internal/params"
 	"github.com/sniddunc/refractor/pkg/config"
 	"github.com/sniddunc/refractor/pkg/log"
 	"github.com/sniddunc/refractor/refractor"
@@ -29,17 +31,18 @@ import (
 
 type playerService struct {
 	repo              refractor.PlayerRepository
+	sessionRepo       refractor.PlayerSessionRepository
 	log               log.Logger
-	recentPlayers     *recentPlayers
 	updateSubscribers []refractor.PlayerUpdateSubscriber
 	websocketService  refractor.WebsocketService
 }
 
-func NewPlayerService(repo refractor.PlayerRepository, ws refractor.WebsocketService, log log.Logger) refractor.PlayerService {
+func NewPlayerService(repo refractor.PlayerRepository, sessionRepo refractor.PlayerSessionRepository,
+	ws refractor.WebsocketService, log log.Logger) refractor.PlayerService {
 	return &playerService{
 		repo:             repo,
+		sessionRepo:      sessionRepo,
 		log:              log,
-		recentPlayers:    newRecentPlayers(config.RecentPlayersMaxSize),
 		websocketService: ws,
 	}
 }
@@ -79,11 +82,48 @@ func (s *playerService) GetPlayerByID(id int64) (*refractor.Player, *refractor.S
 	}
 }
 
-func (s *playerService) GetRecentPlayers() ([]*refractor.Player, *refractor.ServiceResponse) {
-	return s.recentPlayers.getAll(), &refractor.ServiceResponse{
+func (s *playerService) GetRecentPlayers(body params.GetRecentPlayersParams) ([]*refractor.RecentPlayer, *refractor.ServiceResponse) {
+	args := refractor.FindArgs{
+		"ServerID": nil,
+		"Since":    body.Since,
+		"Until":    body.Until,
+	}
+
+	if body.ParsedRecentPlayersIDs != nil && body.ParsedRecentPlayersIDs.ServerID != 0 {
+		args["ServerID"] = body.ParsedRecentPlayersIDs.ServerID
+	}
+
+	if body.Until == 0 {
+		args["Until"] = time.Now().Unix()
+	}
+
+	sessions, err := s.sessionRepo.GetRecent(args, config.RecentPlayersMaxSize)
+	if err != nil && err != refractor.ErrNotFound {
+		s.log.Error("Could not get recent player sessions. Error: %v", err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	// Explicitly define the slice so that an empty result is returned as an empty array rather than null
+	recentPlayers := []*refractor.RecentPlayer{}
+
+	for _, session := range sessions {
+		player, err := s.repo.FindByID(session.PlayerID)
+		if err != nil {
+			s.log.Error("Could not get recent player by ID %d. Error: %v", session.PlayerID, err)
+			continue
+		}
+
+		recentPlayers = append(recentPlayers, &refractor.RecentPlayer{
+			ServerID: session.ServerID,
+			QuitTime: session.QuitTime,
+			Player:   player,
+		})
+	}
+
+	return recentPlayers, &refractor.ServiceResponse{
 		Success:    true,
 		StatusCode: http.StatusOK,
-		Message:    "Recent players fetched",
+		Message:    fmt.Sprintf("Fetched %d recent players", len(recentPlayers)),
 	}
 }
 
@@ -121,6 +161,41 @@ func (s *playerService) SetPlayerWatch(id int64, watch bool) *refractor.ServiceR
 	return res
 }
 
+// SetPlayerTrusted marks a player as trusted or untrusted. Trusted players are exempt from chat spam detection.
+func (s *playerService) SetPlayerTrusted(id int64, trusted bool) *refractor.ServiceResponse {
+	updated, err := s.repo.Update(id, refractor.UpdateArgs{
+		"Trusted": trusted,
+	})
+	if err != nil {
+		if err == refractor.ErrNotFound {
+			return &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+				Message:    config.MessageInvalidIDProvided,
+			}
+		}
+
+		s.log.Error("Could not updated player. Error: %v", err)
+		return refractor.InternalErrorResponse
+	}
+
+	s.log.Info("Player ID %d has had their trusted field set to %v", id, trusted)
+
+	res := &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Player marked as trusted",
+	}
+
+	if !trusted {
+		res.Message = "Player no longer marked as trusted"
+	}
+
+	s.notifyPlayerUpdate(updated)
+
+	return res
+}
+
 func (s *playerService) OnPlayerJoin(serverID int64, playerGameID string, currentName string, gameConfig *refractor.GameConfig) (*refractor.Player, *refractor.ServiceResponse) {
 	// Check if the player is recorded in storage
 	foundPlayer, err := s.repo.FindOne(refractor.FindArgs{
@@ -154,6 +229,8 @@ func (s *playerService) OnPlayerJoin(serverID int64, playerGameID string, curren
 			return nil, refractor.InternalErrorResponse
 		}
 
+		s.openSession(newPlayer.PlayerID, serverID)
+
 		return newPlayer, &refractor.ServiceResponse{
 			Success:    true,
 			StatusCode: http.StatusOK,
@@ -171,6 +248,8 @@ func (s *playerService) OnPlayerJoin(serverID int64, playerGameID string, curren
 		}
 	}
 
+	s.openSession(foundPlayer.PlayerID, serverID)
+
 	return foundPlayer, &refractor.ServiceResponse{
 		Success:    true,
 		StatusCode: http.StatusOK,
@@ -194,16 +273,18 @@ func (s *playerService) OnPlayerQuit(serverID int64, playerGameID string, gameCo
 		return nil, refractor.InternalErrorResponse
 	}
 
+	quitTime := time.Now().Unix()
+
 	// Update player's last seen field
 	if _, err := s.repo.Update(foundPlayer.PlayerID, refractor.UpdateArgs{
-		"LastSeen": time.Now().Unix(),
+		"LastSeen": quitTime,
 	}); err != nil {
 		s.log.Error("Could not update LastSeen field for player with PlayFabID: %s. Error: %v", playerGameID, err)
 		return nil, refractor.InternalErrorResponse
 	}
 
-	// Add player to recent players
-	s.recentPlayers.push(foundPlayer)
+	// Close the player's session on this server so they show up in recent players
+	s.closeSession(foundPlayer.PlayerID, serverID, quitTime)
 
 	return foundPlayer, &refractor.ServiceResponse{
 		Success:    true,
@@ -234,6 +315,55 @@ func (s *playerService) GetPlayer(args refractor.FindArgs) (*refractor.Player, *
 	}
 }
 
+// openSession records the start of a player's session on a server. If the player already has an open session on the
+// server (e.g. Refractor restarted while they were online) it is reused instead of creating a new one.
+func (s *playerService) openSession(playerID int64, serverID int64) {
+	existing, err := s.sessionRepo.FindOpen(playerID, serverID)
+	if err != nil && err != refractor.ErrNotFound {
+		s.log.Error("Could not check for an open session for player ID %d on server ID %d. Error: %v", playerID, serverID, err)
+		return
+	}
+
+	if existing != nil {
+		return
+	}
+
+	if _, err := s.sessionRepo.Create(&refractor.DBPlayerSession{
+		PlayerID: playerID,
+		ServerID: serverID,
+		JoinTime: time.Now().Unix(),
+	}); err != nil {
+		s.log.Error("Could not create session for player ID %d on server ID %d. Error: %v", playerID, serverID, err)
+	}
+}
+
+// closeSession sets the quit time on a player's open session on a server. If no open session exists (e.g. the join
+// was missed) a session starting and ending at the quit time is recorded instead.
+func (s *playerService) closeSession(playerID int64, serverID int64, quitTime int64) {
+	existing, err := s.sessionRepo.FindOpen(playerID, serverID)
+	if err != nil && err != refractor.ErrNotFound {
+		s.log.Error("Could not get open session for player ID %d on server ID %d. Error: %v", playerID, serverID, err)
+		return
+	}
+
+	if existing != nil {
+		if err := s.sessionRepo.Close(existing.SessionID, quitTime); err != nil {
+			s.log.Error("Could not close session ID %d. Error: %v", existing.SessionID, err)
+		}
+
+		return
+	}
+
+	if _, err := s.sessionRepo.Create(&refractor.DBPlayerSession{
+		PlayerID: playerID,
+		ServerID: serverID,
+		JoinTime: quitTime,
+		QuitTime: sql.NullInt64{Int64: quitTime, Valid: true},
+	}); err != nil {
+		s.log.Error("Could not create closed session for player ID %d on server ID %d. Error: %v", playerID, serverID, err)
+	}
+}
+
 func (s *playerService) SubscribeUpdate(sub refractor.PlayerUpdateSubscriber) {
 	s.updateSubscribers = append(s.updateSubscribers, sub)
 }
@@ -0,0 +1,99 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package player
+
+import (
+	"database/sql"
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_playerService_GetRecentPlayers(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	mockPlayers := map[int64]*refractor.DBPlayer{
+		1: {PlayerID: 1, CurrentName: "player1"},
+		2: {PlayerID: 2, CurrentName: "player2"},
+		3: {PlayerID: 3, CurrentName: "player3"},
+	}
+
+	mockSessions := map[int64]*refractor.DBPlayerSession{
+		1: {SessionID: 1, PlayerID: 1, ServerID: 1, JoinTime: 100, QuitTime: sql.NullInt64{Int64: 200, Valid: true}},
+		2: {SessionID: 2, PlayerID: 2, ServerID: 2, JoinTime: 100, QuitTime: sql.NullInt64{Int64: 300, Valid: true}},
+		3: {SessionID: 3, PlayerID: 3, ServerID: 1, JoinTime: 100, QuitTime: sql.NullInt64{Int64: 400, Valid: true}},
+		4: {SessionID: 4, PlayerID: 1, ServerID: 2, JoinTime: 500},
+		5: {SessionID: 5, PlayerID: 3, ServerID: 1, JoinTime: 310, QuitTime: sql.NullInt64{Int64: 320, Valid: true}},
+	}
+
+	type args struct {
+		body params.GetRecentPlayersParams
+	}
+	tests := []struct {
+		name          string
+		args          args
+		wantPlayerIDs []int64
+		wantQuitTimes []int64
+	}{
+		{
+			name:          "player.getrecentplayers.1",
+			args:          args{body: params.GetRecentPlayersParams{}},
+			wantPlayerIDs: []int64{3, 2, 1},
+			wantQuitTimes: []int64{400, 300, 200},
+		},
+		{
+			name: "player.getrecentplayers.2",
+			args: args{body: params.GetRecentPlayersParams{
+				ParsedRecentPlayersIDs: &params.ParsedRecentPlayersIDs{ServerID: 1},
+			}},
+			wantPlayerIDs: []int64{3, 1},
+			wantQuitTimes: []int64{400, 200},
+		},
+		{
+			name: "player.getrecentplayers.3",
+			args: args{body: params.GetRecentPlayersParams{
+				Since: 250,
+				Until: 350,
+			}},
+			wantPlayerIDs: []int64{3, 2},
+			wantQuitTimes: []int64{320, 300},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			playerRepo := mock.NewMockPlayerRepository(mockPlayers)
+			sessionRepo := mock.NewMockPlayerSessionRepository(mockSessions)
+			playerService := NewPlayerService(playerRepo, sessionRepo, nil, testLogger)
+
+			recentPlayers, res := playerService.GetRecentPlayers(tt.args.body)
+			assert.True(t, res.Success)
+
+			var playerIDs, quitTimes []int64
+			for _, recent := range recentPlayers {
+				playerIDs = append(playerIDs, recent.PlayerID)
+				quitTimes = append(quitTimes, recent.QuitTime)
+			}
+
+			assert.Equal(t, tt.wantPlayerIDs, playerIDs)
+			assert.Equal(t, tt.wantQuitTimes, quitTimes)
+		})
+	}
+}
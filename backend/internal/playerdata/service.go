@@ -0,0 +1,140 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package playerdata
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"net/http"
+	"time"
+)
+
+type playerDataService struct {
+	repo       refractor.PlayerDataRepository
+	playerRepo refractor.PlayerRepository
+	log        log.Logger
+}
+
+func NewPlayerDataService(repo refractor.PlayerDataRepository, playerRepo refractor.PlayerRepository,
+	log log.Logger) refractor.PlayerDataService {
+	return &playerDataService{
+		repo:       repo,
+		playerRepo: playerRepo,
+		log:        log,
+	}
+}
+
+func (s *playerDataService) ExportPlayerData(playerID int64, user params.UserMeta) (*refractor.PlayerDataExport, *refractor.ServiceResponse) {
+	export, err := s.repo.Export(playerID)
+	if err != nil {
+		if err == refractor.ErrNotFound {
+			return nil, &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+				Message:    config.MessageInvalidIDProvided,
+			}
+		}
+
+		s.log.Error("Could not export data for player ID %d. Error: %v", playerID, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	if res := s.logRequest(playerID, user.UserID, refractor.PLAYER_DATA_REQUEST_EXPORT); res != nil {
+		return nil, res
+	}
+
+	return export, &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Player data exported",
+	}
+}
+
+func (s *playerDataService) ErasePlayerData(playerID int64, user params.UserMeta) *refractor.ServiceResponse {
+	exists, err := s.playerRepo.Exists(refractor.FindArgs{
+		"PlayerID": playerID,
+	})
+	if err != nil {
+		s.log.Error("Could not check if player ID %d exists. Error: %v", playerID, err)
+		return refractor.InternalErrorResponse
+	}
+
+	if !exists {
+		return &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    config.MessageInvalidIDProvided,
+		}
+	}
+
+	pseudonym, err := generatePseudonym()
+	if err != nil {
+		s.log.Error("Could not generate pseudonym for player ID %d. Error: %v", playerID, err)
+		return refractor.InternalErrorResponse
+	}
+
+	if err := s.repo.Erase(playerID, pseudonym); err != nil {
+		s.log.Error("Could not erase data for player ID %d. Error: %v", playerID, err)
+		return refractor.InternalErrorResponse
+	}
+
+	if res := s.logRequest(playerID, user.UserID, refractor.PLAYER_DATA_REQUEST_ERASURE); res != nil {
+		return res
+	}
+
+	return &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Player data erased",
+	}
+}
+
+func (s *playerDataService) logRequest(playerID int64, userID int64, requestType string) *refractor.ServiceResponse {
+	request := &refractor.PlayerDataRequest{
+		PlayerID:  playerID,
+		UserID:    userID,
+		Type:      requestType,
+		Timestamp: time.Now().Unix(),
+	}
+
+	if err := s.repo.LogRequest(request); err != nil {
+		s.log.Error("Could not log %s request for player ID %d by user ID %d. Error: %v", requestType, playerID,
+			userID, err)
+		return refractor.InternalErrorResponse
+	}
+
+	s.log.Info("User ID %d made a player data %s request for player ID %d (request ID %d)", userID, requestType,
+		playerID, request.RequestID)
+
+	return nil
+}
+
+// generatePseudonym creates a random name to replace an erased player's names with.
+func generatePseudonym() (string, error) {
+	buf := make([]byte, 6)
+
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return config.ErasedPlayerNamePrefix + hex.EncodeToString(buf), nil
+}
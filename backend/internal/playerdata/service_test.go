@@ -0,0 +1,131 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package playerdata
+
+import (
+	"database/sql"
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func Test_playerDataService_ExportPlayerData(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	tests := []struct {
+		name     string
+		playerID int64
+		wantRes  *refractor.ServiceResponse
+	}{
+		{
+			name:     "playerdata.exportplayerdata.1",
+			playerID: 1,
+			wantRes: &refractor.ServiceResponse{
+				Success:    true,
+				StatusCode: http.StatusOK,
+				Message:    "Player data exported",
+			},
+		},
+		{
+			name:     "playerdata.exportplayerdata.2",
+			playerID: 2,
+			wantRes: &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+				Message:    config.MessageInvalidIDProvided,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPlayers := map[int64]*refractor.DBPlayer{
+				1: {PlayerID: 1, PlayFabID: sql.NullString{String: "playfabid", Valid: true}},
+			}
+			dataRepo := mock.NewMockPlayerDataRepository(mockPlayers)
+			playerRepo := mock.NewMockPlayerRepository(mockPlayers)
+			dataService := NewPlayerDataService(dataRepo, playerRepo, testLogger)
+
+			export, res := dataService.ExportPlayerData(tt.playerID, params.UserMeta{UserID: 1})
+			assert.True(t, tt.wantRes.Equals(res), "tt.wantRes = %v and res = %v should be equal", tt.wantRes, res)
+
+			if res.Success {
+				assert.Equal(t, "playfabid", export.PlayFabID)
+			}
+		})
+	}
+}
+
+func Test_playerDataService_ErasePlayerData(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	tests := []struct {
+		name     string
+		playerID int64
+		wantRes  *refractor.ServiceResponse
+	}{
+		{
+			name:     "playerdata.eraseplayerdata.1",
+			playerID: 1,
+			wantRes: &refractor.ServiceResponse{
+				Success:    true,
+				StatusCode: http.StatusOK,
+				Message:    "Player data erased",
+			},
+		},
+		{
+			name:     "playerdata.eraseplayerdata.2",
+			playerID: 2,
+			wantRes: &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+				Message:    config.MessageInvalidIDProvided,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPlayers := map[int64]*refractor.DBPlayer{
+				1: {
+					PlayerID:      1,
+					PlayFabID:     sql.NullString{String: "playfabid", Valid: true},
+					CurrentName:   "Name",
+					PreviousNames: []string{"OldName"},
+				},
+			}
+			dataRepo := mock.NewMockPlayerDataRepository(mockPlayers)
+			playerRepo := mock.NewMockPlayerRepository(mockPlayers)
+			dataService := NewPlayerDataService(dataRepo, playerRepo, testLogger)
+
+			res := dataService.ErasePlayerData(tt.playerID, params.UserMeta{UserID: 1})
+			assert.True(t, tt.wantRes.Equals(res), "tt.wantRes = %v and res = %v should be equal", tt.wantRes, res)
+
+			if res.Success {
+				player := mockPlayers[tt.playerID]
+				assert.False(t, player.PlayFabID.Valid)
+				assert.Empty(t, player.PreviousNames)
+				assert.True(t, strings.HasPrefix(player.CurrentName, config.ErasedPlayerNamePrefix))
+			}
+		})
+	}
+}
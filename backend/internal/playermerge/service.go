@@ -0,0 +1,145 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package playermerge
+
+import (
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type playerMergeService struct {
+	repo       refractor.PlayerMergeRepository
+	playerRepo refractor.PlayerRepository
+	log        log.Logger
+}
+
+func NewPlayerMergeService(repo refractor.PlayerMergeRepository, playerRepo refractor.PlayerRepository,
+	log log.Logger) refractor.PlayerMergeService {
+	return &playerMergeService{
+		repo:       repo,
+		playerRepo: playerRepo,
+		log:        log,
+	}
+}
+
+func (s *playerMergeService) MergePlayers(body params.MergePlayersParams) (*refractor.PlayerMerge, *refractor.ServiceResponse) {
+	// Make sure both players exist
+	for field, id := range map[string]int64{"sourceId": body.SourceID, "targetId": body.TargetID} {
+		exists, err := s.playerRepo.Exists(refractor.FindArgs{
+			"PlayerID": id,
+		})
+		if err != nil {
+			s.log.Error("Could not check if player ID %d exists. Error: %v", id, err)
+			return nil, refractor.InternalErrorResponse
+		}
+
+		if !exists {
+			return nil, &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+				ValidationErrors: url.Values{
+					field: []string{"Invalid player ID"},
+				},
+			}
+		}
+	}
+
+	merge, err := s.repo.Merge(body.SourceID, body.TargetID, body.UserMeta.UserID)
+	if err != nil {
+		s.log.Error("Could not merge player ID %d into player ID %d. Error: %v", body.SourceID, body.TargetID, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	s.log.Info("User ID %d merged player ID %d into player ID %d (merge ID %d)", body.UserMeta.UserID, body.SourceID,
+		body.TargetID, merge.MergeID)
+
+	return merge, &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Players merged",
+	}
+}
+
+func (s *playerMergeService) UndoMerge(id int64, user params.UserMeta) (*refractor.PlayerMerge, *refractor.ServiceResponse) {
+	merge, err := s.repo.FindByID(id)
+	if err != nil {
+		if err == refractor.ErrNotFound {
+			return nil, &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+				Message:    config.MessageInvalidIDProvided,
+			}
+		}
+
+		s.log.Error("Could not get player merge by ID %d. Error: %v", id, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	if merge.Undone {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    "This merge has already been undone",
+		}
+	}
+
+	// Snapshots are cleared when a player involved in the merge has their data erased
+	if merge.Snapshot == nil || merge.Snapshot.Source.PlayerID == 0 {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    "This merge can no longer be undone",
+		}
+	}
+
+	if time.Now().Unix()-merge.Timestamp > config.PlayerMergeUndoWindow {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    "This merge is too old to be undone",
+		}
+	}
+
+	merge, err = s.repo.Undo(id, user.UserID)
+	if err != nil {
+		// The merge may have been undone by someone else since it was checked above
+		if err == refractor.ErrMergeUndone {
+			return nil, &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+				Message:    "This merge has already been undone",
+			}
+		}
+
+		s.log.Error("Could not undo player merge ID %d. Error: %v", id, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	s.log.Info("User ID %d undid player merge ID %d", user.UserID, id)
+
+	return merge, &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    "Merge undone",
+	}
+}
@@ -0,0 +1,136 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package playermerge
+
+import (
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_playerMergeService_MergePlayers(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	tests := []struct {
+		name    string
+		body    params.MergePlayersParams
+		wantRes *refractor.ServiceResponse
+	}{
+		{
+			name: "playermerge.mergeplayers.1",
+			body: params.MergePlayersParams{SourceID: 1, TargetID: 2, UserMeta: &params.UserMeta{UserID: 1}},
+			wantRes: &refractor.ServiceResponse{
+				Success:    true,
+				StatusCode: http.StatusOK,
+				Message:    "Players merged",
+			},
+		},
+		{
+			name: "playermerge.mergeplayers.2",
+			body: params.MergePlayersParams{SourceID: 1, TargetID: 3, UserMeta: &params.UserMeta{UserID: 1}},
+			wantRes: &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			playerRepo := mock.NewMockPlayerRepository(map[int64]*refractor.DBPlayer{
+				1: {PlayerID: 1},
+				2: {PlayerID: 2},
+			})
+			mergeRepo := mock.NewMockPlayerMergeRepository(map[int64]*refractor.PlayerMerge{})
+			mergeService := NewPlayerMergeService(mergeRepo, playerRepo, testLogger)
+
+			_, res := mergeService.MergePlayers(tt.body)
+			assert.True(t, tt.wantRes.Equals(res), "tt.wantRes = %v and res = %v should be equal", tt.wantRes, res)
+		})
+	}
+}
+
+func Test_playerMergeService_UndoMerge(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	now := time.Now().Unix()
+	snapshot := &refractor.PlayerMergeSnapshot{Source: refractor.DBPlayer{PlayerID: 2}}
+
+	tests := []struct {
+		name    string
+		merge   *refractor.PlayerMerge
+		wantRes *refractor.ServiceResponse
+	}{
+		{
+			name:  "playermerge.undomerge.1",
+			merge: &refractor.PlayerMerge{MergeID: 1, Timestamp: now - 60, Snapshot: snapshot},
+			wantRes: &refractor.ServiceResponse{
+				Success:    true,
+				StatusCode: http.StatusOK,
+				Message:    "Merge undone",
+			},
+		},
+		{
+			name:  "playermerge.undomerge.2",
+			merge: &refractor.PlayerMerge{MergeID: 1, Timestamp: now - config.PlayerMergeUndoWindow - 60, Snapshot: snapshot},
+			wantRes: &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+				Message:    "This merge is too old to be undone",
+			},
+		},
+		{
+			name:  "playermerge.undomerge.3",
+			merge: &refractor.PlayerMerge{MergeID: 1, Timestamp: now - 60, Undone: true},
+			wantRes: &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+				Message:    "This merge has already been undone",
+			},
+		},
+		{
+			name:  "playermerge.undomerge.4",
+			merge: &refractor.PlayerMerge{MergeID: 1, Timestamp: now - 60, Snapshot: &refractor.PlayerMergeSnapshot{}},
+			wantRes: &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+				Message:    "This merge can no longer be undone",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mergeRepo := mock.NewMockPlayerMergeRepository(map[int64]*refractor.PlayerMerge{1: tt.merge})
+			mergeService := NewPlayerMergeService(mergeRepo, nil, testLogger)
+
+			merge, res := mergeService.UndoMerge(1, params.UserMeta{UserID: 3})
+			assert.True(t, tt.wantRes.Equals(res), "tt.wantRes = %v and res = %v should be equal", tt.wantRes, res)
+
+			if res.Success {
+				assert.True(t, merge.Undone)
+				assert.Equal(t, int64(3), merge.UndoneBy)
+				assert.NotZero(t, merge.UndoneAt)
+			}
+		})
+	}
+}
@@ -34,6 +34,24 @@ func (s *rconService) HandleQuitBroadcast(bcast *broadcast.Broadcast, serverID i
 	}
 }
 
+func (s *rconService) HandlePunishmentBroadcast(bcast *broadcast.Broadcast, serverID int64, gameConfig *refractor.GameConfig) {
+	for _, sub := range s.punishmentSubscribers {
+		sub(bcast.Fields, serverID, gameConfig)
+	}
+}
+
+func (s *rconService) HandleMatchStateBroadcast(bcast *broadcast.Broadcast, serverID int64, gameConfig *refractor.GameConfig) {
+	for _, sub := range s.matchStateSubscribers {
+		sub(bcast.Fields, serverID, gameConfig)
+	}
+}
+
+func (s *rconService) HandleKillBroadcast(bcast *broadcast.Broadcast, serverID int64, gameConfig *refractor.GameConfig) {
+	for _, sub := range s.killSubscribers {
+		sub(bcast.Fields, serverID, gameConfig)
+	}
+}
+
 func (s *rconService) HandleChatBroadcast(bcast *broadcast.Broadcast, serverID int64, gameConfig *refractor.GameConfig) {
 	fields := bcast.Fields
 
@@ -43,6 +61,7 @@ func (s *rconService) HandleChatBroadcast(bcast *broadcast.Broadcast, serverID i
 			PlayerGameID: fields[gameConfig.PlayerGameIDField],
 			Name:         fields["Name"],
 			Message:      fields["Message"],
+			Channel:      fields["Channel"],
 			SentByUser:   false,
 		}
 
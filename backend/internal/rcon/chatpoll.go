@@ -0,0 +1,123 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package rcon
+
+import (
+	"github.com/sniddunc/refractor/pkg/broadcast"
+	"github.com/sniddunc/refractor/pkg/regexutils"
+	"github.com/sniddunc/refractor/refractor"
+	"strings"
+)
+
+// startChatPolling is used for games which relay chat through a polled command rather than through broadcasts.
+// Each poll fetches the chat messages sent since the last cursor and publishes them to the chat subscribers.
+// Cursors are kept across reconnects so that messages buffered while the connection was down are not lost. It runs
+// until the stop channel is closed.
+func (s *rconService) startChatPolling(serverID int64, game refractor.Game, stop chan struct{}) {
+	gameConfig := game.GetConfig()
+
+	for {
+		if !waitForNextPoll(gameConfig.ChatPollingInterval, stop) {
+			return
+		}
+
+		client := s.clients[serverID]
+		if client == nil {
+			s.log.Warn("Chat polling routine could not get the client for server ID %d", serverID)
+			s.log.Warn("Exiting chat polling routine for server ID %d", serverID)
+			return
+		}
+
+		lastCursor := s.getChatCursor(serverID)
+		command := game.GetChatPollCommand(lastCursor)
+
+		res, err := client.ExecCommand(command)
+		if err != nil {
+			s.log.Error("RCON ExecCommand %s failed with error: %v", command, err)
+			continue
+		}
+
+		cursor, messages := parseChatPollOutput(res, gameConfig)
+		if cursor == "" {
+			s.log.Warn("Chat poll response for server ID %d did not contain a cursor", serverID)
+			continue
+		}
+
+		// If this is the first poll for this server, the cursor is only being initialized so no messages are relayed
+		s.setChatCursor(serverID, cursor)
+
+		if lastCursor == "" {
+			continue
+		}
+
+		for _, fields := range messages {
+			s.HandleChatBroadcast(&broadcast.Broadcast{
+				Type:   broadcast.TYPE_CHAT,
+				Fields: fields,
+			}, serverID, gameConfig)
+		}
+	}
+}
+
+func (s *rconService) getChatCursor(serverID int64) string {
+	s.chatCursorsMu.Lock()
+	defer s.chatCursorsMu.Unlock()
+
+	return s.chatCursors[serverID]
+}
+
+func (s *rconService) setChatCursor(serverID int64, cursor string) {
+	s.chatCursorsMu.Lock()
+	defer s.chatCursorsMu.Unlock()
+
+	s.chatCursors[serverID] = cursor
+}
+
+// parseChatPollOutput extracts the latest cursor and the chat messages from the output of a chat poll command using
+// the game's ChatCursor and ChatMessage output patterns. Messages are returned in the order they were sent.
+func parseChatPollOutput(output string, gameConfig *refractor.GameConfig) (string, []broadcast.Fields) {
+	cursorPattern := gameConfig.CmdOutputPatterns["ChatCursor"]
+	messagePattern := gameConfig.CmdOutputPatterns["ChatMessage"]
+
+	if cursorPattern == nil || messagePattern == nil {
+		return "", nil
+	}
+
+	cursorFields := regexutils.MapNamedMatches(cursorPattern, output)
+	if cursorFields == nil {
+		return "", nil
+	}
+
+	var messages []broadcast.Fields
+
+	for _, line := range messagePattern.FindAllString(output, -1) {
+		fields := regexutils.MapNamedMatches(messagePattern, line)
+		if fields == nil {
+			continue
+		}
+
+		fields["Message"] = strings.TrimSpace(fields["Message"])
+		if fields["Message"] == "" {
+			continue
+		}
+
+		messages = append(messages, fields)
+	}
+
+	return cursorFields["Cursor"], messages
+}
@@ -0,0 +1,130 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package rcon
+
+import (
+	"github.com/sniddunc/refractor/internal/game"
+	"github.com/sniddunc/refractor/internal/game/minecraft"
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/pkg/broadcast"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_parseChatPollOutput(t *testing.T) {
+	type args struct {
+		output     string
+		gameConfig *refractor.GameConfig
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantCursor   string
+		wantMessages []broadcast.Fields
+	}{
+		{
+			name: "rcon.parsechatpolloutput.1",
+			args: args{
+				output:     "cursor:42\nchat:0a4bd5f6-f3ae-4d67-9f2c-3d5c2bd8e0a1:Steve:hello there\nchat:5b1e6c5f-1c4e-4a3f-8d1c-9e0f2a3b4c5d:Alex:hi: how are you?\r\n",
+				gameConfig: minecraft.NewMinecraftGame().GetConfig(),
+			},
+			wantCursor: "42",
+			wantMessages: []broadcast.Fields{
+				{
+					"MCUUID":  "0a4bd5f6-f3ae-4d67-9f2c-3d5c2bd8e0a1",
+					"Name":    "Steve",
+					"Message": "hello there",
+				},
+				{
+					"MCUUID":  "5b1e6c5f-1c4e-4a3f-8d1c-9e0f2a3b4c5d",
+					"Name":    "Alex",
+					"Message": "hi: how are you?",
+				},
+			},
+		},
+		{
+			name: "rcon.parsechatpolloutput.2",
+			args: args{
+				output:     "cursor:7\r\n",
+				gameConfig: minecraft.NewMinecraftGame().GetConfig(),
+			},
+			wantCursor:   "7",
+			wantMessages: nil,
+		},
+		{
+			name: "rcon.parsechatpolloutput.3",
+			args: args{
+				output:     "Unknown command. Type \"/help\" for help.",
+				gameConfig: minecraft.NewMinecraftGame().GetConfig(),
+			},
+			wantCursor:   "",
+			wantMessages: nil,
+		},
+		{
+			name: "rcon.parsechatpolloutput.4",
+			args: args{
+				output:     "cursor:3\nchat:0a4bd5f6-f3ae-4d67-9f2c-3d5c2bd8e0a1:Steve:hello",
+				gameConfig: mock.NewMockGame().GetConfig(),
+			},
+			wantCursor:   "",
+			wantMessages: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, messages := parseChatPollOutput(tt.args.output, tt.args.gameConfig)
+
+			assert.Equal(t, tt.wantCursor, cursor)
+			assert.Equal(t, tt.wantMessages, messages)
+		})
+	}
+}
+
+func Test_rconService_startChatPolling(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	minecraftGame := minecraft.NewMinecraftGame()
+	minecraftGame.GetConfig().ChatPollingInterval = time.Millisecond * 10
+
+	transport := mock.NewMockRCONTransport(&refractor.RCONTransportConfig{}).(*mock.MockRCONTransport)
+	transport.Outputs["refractormc:chat"] = "cursor:1"
+	_ = transport.Connect()
+
+	rconService := NewRCONService(game.NewGameService(), nil, testLogger).(*rconService)
+	rconService.clients[1] = &refractor.RCONClient{RCONTransport: transport}
+
+	stop := rconService.newClientStop(1)
+	done := make(chan struct{})
+
+	go func() {
+		rconService.startChatPolling(1, minecraftGame, stop)
+		close(done)
+	}()
+
+	// The routine should exit once it is stopped, even though a client for the server still exists
+	rconService.stopClientRoutines(1)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("chat polling routine did not exit")
+	}
+}
@@ -0,0 +1,78 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package rcon
+
+import (
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/broadcast"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/logtail"
+	"github.com/sniddunc/refractor/refractor"
+	"os"
+)
+
+// getEventSource returns the source of join, quit and chat events for a server. If the server's chosen source can't
+// be used with its game, the game's default source is used instead.
+func (s *rconService) getEventSource(server *refractor.Server, gameConfig *refractor.GameConfig) string {
+	defaultSource := params.EventSourcePolling
+	if gameConfig.EnableBroadcasts {
+		defaultSource = params.EventSourceBroadcast
+	}
+
+	switch server.EventSource {
+	case params.EventSourcePolling:
+		return params.EventSourcePolling
+	case params.EventSourceBroadcast:
+		if !gameConfig.EnableBroadcasts {
+			s.log.Warn("Server ID %d uses the broadcast event source but its game does not support broadcasts", server.ServerID)
+			return defaultSource
+		}
+
+		return params.EventSourceBroadcast
+	case params.EventSourceLog:
+		if server.LogFilePath == "" || len(gameConfig.BroadcastPatterns) == 0 {
+			s.log.Warn("Server ID %d uses the log event source but has no log file path or its game has no broadcast patterns",
+				server.ServerID)
+			return defaultSource
+		}
+
+		return params.EventSourceLog
+	}
+
+	return defaultSource
+}
+
+// startLogTailing follows a server's log file and publishes lines matching the game's broadcast patterns to the
+// same subscribers as RCON broadcasts. It runs until the stop channel is closed.
+func (s *rconService) startLogTailing(serverID int64, path string, gameConfig *refractor.GameConfig, stop chan struct{}) {
+	s.log.Info("Following log file %s for server ID %d", path, serverID)
+
+	tailer := logtail.NewTailer(path, config.LogTailPollInterval)
+
+	if _, err := os.Stat(path); err != nil {
+		s.log.Warn("Log file %s for server ID %d can not be opened yet and will be retried. Error: %v", path,
+			serverID, err)
+	}
+
+	tailer.Follow(stop, func(line string) {
+		bcast := broadcast.MatchLogLine(line, gameConfig.LogLinePrefix, gameConfig.BroadcastPatterns)
+		s.handleBroadcast(bcast, serverID, gameConfig)
+	})
+
+	s.log.Info("Stopped following log file %s for server ID %d", path, serverID)
+}
@@ -0,0 +1,98 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package rcon
+
+import (
+	"github.com/sniddunc/refractor/internal/game/minecraft"
+	"github.com/sniddunc/refractor/internal/game/mordhau"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_rconService_getEventSource(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	type args struct {
+		server     *refractor.Server
+		gameConfig *refractor.GameConfig
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "rcon.geteventsource.1",
+			args: args{
+				server:     &refractor.Server{ServerID: 1},
+				gameConfig: mordhau.NewMordhauGame().GetConfig(),
+			},
+			want: params.EventSourceBroadcast,
+		},
+		{
+			name: "rcon.geteventsource.2",
+			args: args{
+				server:     &refractor.Server{ServerID: 1},
+				gameConfig: minecraft.NewMinecraftGame().GetConfig(),
+			},
+			want: params.EventSourcePolling,
+		},
+		{
+			name: "rcon.geteventsource.3",
+			args: args{
+				server:     &refractor.Server{ServerID: 1, EventSource: params.EventSourceLog, LogFilePath: "/srv/mordhau/Mordhau.log"},
+				gameConfig: mordhau.NewMordhauGame().GetConfig(),
+			},
+			want: params.EventSourceLog,
+		},
+		{
+			name: "rcon.geteventsource.4",
+			args: args{
+				server:     &refractor.Server{ServerID: 1, EventSource: params.EventSourceLog},
+				gameConfig: mordhau.NewMordhauGame().GetConfig(),
+			},
+			want: params.EventSourceBroadcast,
+		},
+		{
+			name: "rcon.geteventsource.5",
+			args: args{
+				server:     &refractor.Server{ServerID: 1, EventSource: params.EventSourcePolling},
+				gameConfig: mordhau.NewMordhauGame().GetConfig(),
+			},
+			want: params.EventSourcePolling,
+		},
+		{
+			name: "rcon.geteventsource.6",
+			args: args{
+				server:     &refractor.Server{ServerID: 1, EventSource: params.EventSourceBroadcast},
+				gameConfig: minecraft.NewMinecraftGame().GetConfig(),
+			},
+			want: params.EventSourcePolling,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &rconService{log: testLogger}
+
+			assert.Equal(t, tt.want, s.getEventSource(tt.args.server, tt.args.gameConfig))
+		})
+	}
+}
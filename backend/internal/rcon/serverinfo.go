@@ -0,0 +1,83 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package rcon
+
+import (
+	"github.com/sniddunc/refractor/pkg/regexutils"
+	"github.com/sniddunc/refractor/refractor"
+	"regexp"
+	"strconv"
+)
+
+// startServerInfoPolling periodically runs the game's server info command on a server and publishes the parsed info
+// to the server info subscribers whenever it changes. The server is polled once right away so that its info is
+// available as soon as it comes online. It runs until the stop channel is closed.
+func (s *rconService) startServerInfoPolling(serverID int64, game refractor.Game, stop chan struct{}) {
+	gameConfig := game.GetConfig()
+	command := game.GetServerInfoCommand()
+
+	var prevInfo *refractor.LiveServerInfo
+
+	for {
+		client := s.clients[serverID]
+		if client == nil {
+			s.log.Warn("Server info polling routine could not get the client for server ID %d", serverID)
+			s.log.Warn("Exiting server info polling routine for server ID %d", serverID)
+			return
+		}
+
+		res, err := client.ExecCommand(command)
+		if err != nil {
+			s.log.Error("RCON ExecCommand %s failed with error: %v", command, err)
+		} else if info := parseServerInfo(res, gameConfig.CmdOutputPatterns["ServerInfo"]); prevInfo == nil || *info != *prevInfo {
+			prevInfo = info
+
+			for _, sub := range s.serverInfoSubscribers {
+				sub(serverID, info)
+			}
+		}
+
+		if !waitForNextPoll(gameConfig.ServerInfoPollingInterval, stop) {
+			return
+		}
+	}
+}
+
+// parseServerInfo extracts server info from the output of a server info command. The named groups of every match of
+// the pattern are collected so that games can match each detail on its own line. Details which weren't found are
+// left empty.
+func parseServerInfo(output string, pattern *regexp.Regexp) *refractor.LiveServerInfo {
+	fields := map[string]string{}
+
+	for _, match := range pattern.FindAllString(output, -1) {
+		for name, value := range regexutils.MapNamedMatches(pattern, match) {
+			if value != "" {
+				fields[name] = value
+			}
+		}
+	}
+
+	maxPlayers, _ := strconv.Atoi(fields["MaxPlayers"])
+
+	return &refractor.LiveServerInfo{
+		Name:       fields["ServerName"],
+		Map:        fields["Map"],
+		GameMode:   fields["GameMode"],
+		MaxPlayers: maxPlayers,
+	}
+}
@@ -0,0 +1,111 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package rcon
+
+import (
+	"github.com/sniddunc/refractor/internal/game"
+	"github.com/sniddunc/refractor/internal/game/mordhau"
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_parseServerInfo(t *testing.T) {
+	pattern := mordhau.NewMordhauGame().GetConfig().CmdOutputPatterns["ServerInfo"]
+
+	tests := []struct {
+		name   string
+		output string
+		want   *refractor.LiveServerInfo
+	}{
+		{
+			name:   "rcon.parseserverinfo.1",
+			output: "HostName: My Mordhau Server\r\nServerVersion: 1.0\r\nGameMode: Frontline\r\nMap: FL_Camp\r\nPlayers: 12 / 64\r\n",
+			want: &refractor.LiveServerInfo{
+				Name:       "My Mordhau Server",
+				Map:        "FL_Camp",
+				GameMode:   "Frontline",
+				MaxPlayers: 64,
+			},
+		},
+		{
+			name:   "rcon.parseserverinfo.2",
+			output: "Server Name: Duels: EU\nMap: ArenaMap\nMaxPlayers: 16",
+			want: &refractor.LiveServerInfo{
+				Name:       "Duels: EU",
+				Map:        "ArenaMap",
+				MaxPlayers: 16,
+			},
+		},
+		{
+			name:   "rcon.parseserverinfo.3",
+			output: "Unknown command",
+			want:   &refractor.LiveServerInfo{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseServerInfo(tt.output, pattern))
+		})
+	}
+}
+
+func Test_rconService_startServerInfoPolling(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	mordhauGame := mordhau.NewMordhauGame()
+
+	transport := mock.NewMockRCONTransport(&refractor.RCONTransportConfig{}).(*mock.MockRCONTransport)
+	transport.Outputs["Info"] = "HostName: Test Server\nGame Mode: Skirmish\nMap: Grad\nMax Players: 48"
+	_ = transport.Connect()
+
+	rconService := NewRCONService(game.NewGameService(), nil, testLogger).(*rconService)
+	rconService.clients[1] = &refractor.RCONClient{RCONTransport: transport}
+
+	published := make(chan *refractor.LiveServerInfo, 1)
+	rconService.SubscribeServerInfo(func(serverID int64, info *refractor.LiveServerInfo) {
+		published <- info
+	})
+
+	stop := rconService.newClientStop(1)
+	done := make(chan struct{})
+
+	go func() {
+		rconService.startServerInfoPolling(1, mordhauGame, stop)
+		close(done)
+	}()
+
+	select {
+	case info := <-published:
+		assert.Equal(t, "Grad", info.Map)
+	case <-time.After(time.Second):
+		t.Fatal("server info was not published")
+	}
+
+	// The routine should exit once it is stopped, even though a client for the server still exists
+	rconService.stopClientRoutines(1)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("server info polling routine did not exit")
+	}
+}
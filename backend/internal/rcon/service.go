@@ -19,12 +19,13 @@ package rcon
 
 import (
 	"fmt"
-	rcon "github.com/sniddunc/mordhau-rcon"
+	"github.com/sniddunc/refractor/internal/params"
 	"github.com/sniddunc/refractor/pkg/broadcast"
 	"github.com/sniddunc/refractor/pkg/log"
 	"github.com/sniddunc/refractor/pkg/regexutils"
 	"github.com/sniddunc/refractor/refractor"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -36,13 +37,31 @@ type rconService struct {
 	joinSubscribers           []refractor.BroadcastSubscriber
 	quitSubscribers           []refractor.BroadcastSubscriber
 	chatSubscribers           []refractor.ChatReceiveSubscriber
+	punishmentSubscribers     []refractor.BroadcastSubscriber
+	matchStateSubscribers     []refractor.BroadcastSubscriber
+	killSubscribers           []refractor.BroadcastSubscriber
 	onlineSubscribers         []refractor.StatusSubscriber
 	offlineSubscribers        []refractor.StatusSubscriber
 	playerListPollSubscribers []refractor.PlayerListPollSubscriber
+	playerFieldsSubscribers   []refractor.PlayerFieldsSubscriber
+	serverInfoSubscribers     []refractor.ServerInfoSubscriber
 
 	// used to store players for future comparison if broadcasts are not enabled
 	// prevPlayers[serverId][playerGameID] = onlinePlayer
 	prevPlayers map[int64]map[string]*onlinePlayer
+
+	// used to store the last chat cursor of servers which relay chat through polling
+	// chatCursors[serverId] = cursor
+	// Each server's chat polling routine uses the map, so access is guarded by chatCursorsMu
+	chatCursors   map[int64]string
+	chatCursorsMu sync.Mutex
+
+	// used to stop the routines started for a server's client, such as log tailing and polling, once the client is
+	// gone. Otherwise a reconnected client would have the routines of every previous client running alongside its own.
+	// clientStops[serverId] = stop channel
+	// Clients are created and removed from several goroutines, so access is guarded by clientStopsMu
+	clientStops   map[int64]chan struct{}
+	clientStopsMu sync.Mutex
 }
 
 func NewRCONService(gameService refractor.GameService, playerService refractor.PlayerService, log log.Logger) refractor.RCONService {
@@ -54,15 +73,22 @@ func NewRCONService(gameService refractor.GameService, playerService refractor.P
 		joinSubscribers:           []refractor.BroadcastSubscriber{},
 		quitSubscribers:           []refractor.BroadcastSubscriber{},
 		chatSubscribers:           []refractor.ChatReceiveSubscriber{},
+		punishmentSubscribers:     []refractor.BroadcastSubscriber{},
+		matchStateSubscribers:     []refractor.BroadcastSubscriber{},
+		killSubscribers:           []refractor.BroadcastSubscriber{},
 		onlineSubscribers:         []refractor.StatusSubscriber{},
 		offlineSubscribers:        []refractor.StatusSubscriber{},
 		playerListPollSubscribers: []refractor.PlayerListPollSubscriber{},
+		playerFieldsSubscribers:   []refractor.PlayerFieldsSubscriber{},
+		serverInfoSubscribers:     []refractor.ServerInfoSubscriber{},
 		prevPlayers:               map[int64]map[string]*onlinePlayer{},
+		chatCursors:               map[int64]string{},
+		clientStops:               map[int64]chan struct{}{},
 	}
 }
 
 func (s *rconService) CreateClient(server *refractor.Server) error {
-	port, err := strconv.ParseInt(server.RCONPort, 10, 16)
+	port, err := strconv.ParseUint(server.RCONPort, 10, 16)
 	if err != nil {
 		return err
 	}
@@ -74,18 +100,27 @@ func (s *rconService) CreateClient(server *refractor.Server) error {
 	}
 
 	gameConfig := game.GetConfig()
+	eventSource := s.getEventSource(server, gameConfig)
+
+	if gameConfig.RCONTransport == nil {
+		return fmt.Errorf("game %s has no RCON transport", game.GetName())
+	}
 
-	// Create client
-	client := rcon.NewClient(&rcon.ClientConfig{
-		Host:                     server.Address,
-		Port:                     int16(port),
-		Password:                 server.RCONPassword,
-		SendHeartbeatCommand:     gameConfig.SendAlivePing,
-		HeartbeatCommandInterval: gameConfig.AlivePingInterval,
-		AttemptReconnect:         false,
-		EnableBroadcasts:         gameConfig.EnableBroadcasts,
-		BroadcastHandler:         s.getBroadcastListener(server.ServerID, gameConfig),
-		DisconnectHandler:        s.getDisconnectHandler(server.ServerID),
+	if gameConfig.MaxRCONPort != 0 && port > uint64(gameConfig.MaxRCONPort) {
+		return fmt.Errorf("the RCON transport of game %s can not connect to ports above %d", game.GetName(),
+			gameConfig.MaxRCONPort)
+	}
+
+	// Create client using the game's transport
+	client := gameConfig.RCONTransport(&refractor.RCONTransportConfig{
+		Host:              server.Address,
+		Port:              uint16(port),
+		Password:          server.RCONPassword,
+		SendHeartbeat:     gameConfig.SendAlivePing,
+		HeartbeatInterval: gameConfig.AlivePingInterval,
+		EnableBroadcasts:  eventSource == params.EventSourceBroadcast,
+		BroadcastHandler:  s.getBroadcastListener(server.ServerID, gameConfig),
+		DisconnectHandler: s.getDisconnectHandler(server.ServerID),
 	})
 
 	// Connect the main socket
@@ -93,10 +128,13 @@ func (s *rconService) CreateClient(server *refractor.Server) error {
 		return err
 	}
 
-	// Connect broadcast socket
-	if gameConfig.EnableBroadcasts {
+	stop := s.newClientStop(server.ServerID)
+
+	switch eventSource {
+	case params.EventSourceBroadcast:
+		// Connect broadcast socket
 		errorChan := make(chan error)
-		go client.ListenForBroadcasts([]string{"login", "chat"}, errorChan)
+		go client.ListenForBroadcasts(gameConfig.BroadcastChannels, errorChan)
 
 		go func() {
 			select {
@@ -106,18 +144,45 @@ func (s *rconService) CreateClient(server *refractor.Server) error {
 			}
 		}()
 
-		// If broadcasts were enabled and a polling interval was set, we start the player list refresh routine
+		// Games which don't broadcast joins and quits detect them by polling the player list. Otherwise, if a polling
+		// interval was set, we start the player list refresh routine
+		if gameConfig.PollPlayerList {
+			go s.startPlayerListPolling(server.ServerID, game)
+		} else {
+			if gameConfig.PlayerListPollingInterval != 0 {
+				go s.startPlayerListRefreshPoll(server.ServerID, game)
+			}
+
+			if gameConfig.PlayerFieldsPollingInterval != 0 {
+				go s.startPlayerFieldsPolling(server.ServerID, game, stop)
+			}
+		}
+	case params.EventSourceLog:
+		go s.startLogTailing(server.ServerID, server.LogFilePath, gameConfig, stop)
+
+		// Joins and quits come from the log file, so the player list is only refreshed like it is for broadcasts
 		if gameConfig.PlayerListPollingInterval != 0 {
 			go s.startPlayerListRefreshPoll(server.ServerID, game)
 		}
-	} else {
+
+		if gameConfig.PlayerFieldsPollingInterval != 0 {
+			go s.startPlayerFieldsPolling(server.ServerID, game, stop)
+		}
+	default:
 		go s.startPlayerListPolling(server.ServerID, game)
 	}
 
+	// If the game relays chat through a polled command, start the chat polling routine. Servers using the log event
+	// source already receive chat from their log file.
+	if gameConfig.EnableChat && gameConfig.ChatPollingInterval != 0 && game.GetChatPollCommand("") != "" &&
+		eventSource != params.EventSourceLog {
+		go s.startChatPolling(server.ServerID, game, stop)
+	}
+
 	// Add to list of clients
 	s.clients[server.ServerID] = &refractor.RCONClient{
-		Server: server,
-		Client: client,
+		Server:        server,
+		RCONTransport: client,
 	}
 
 	// Get players currently on the server
@@ -125,13 +190,16 @@ func (s *rconService) CreateClient(server *refractor.Server) error {
 
 	for _, onlinePlayer := range onlinePlayers {
 		for _, sub := range s.joinSubscribers {
-			sub(broadcast.Fields{
-				game.GetConfig().PlayerGameIDField: onlinePlayer.PlayerGameID,
-				"Name":                             onlinePlayer.Name,
-			}, server.ServerID, game.GetConfig())
+			sub(onlinePlayer.getJoinFields(gameConfig), server.ServerID, gameConfig)
 		}
 	}
 
+	// If the game has a server info command, start the server info polling routine
+	if gameConfig.ServerInfoPollingInterval != 0 && game.GetServerInfoCommand() != "" &&
+		gameConfig.CmdOutputPatterns["ServerInfo"] != nil {
+		go s.startServerInfoPolling(server.ServerID, game, stop)
+	}
+
 	// If this point was reached, we know the RCON connection was successful so we notify server online subscribers
 	// of this server online event.
 	for _, sub := range s.onlineSubscribers {
@@ -177,10 +245,7 @@ func (s *rconService) startPlayerListPolling(serverID int64, game refractor.Game
 
 				// Player was not online previously so broadcast join
 				for _, sub := range s.joinSubscribers {
-					sub(broadcast.Fields{
-						game.GetConfig().PlayerGameIDField: player.PlayerGameID,
-						"Name":                             player.Name,
-					}, serverID, game.GetConfig())
+					sub(player.getJoinFields(game.GetConfig()), serverID, game.GetConfig())
 				}
 			}
 		}
@@ -203,6 +268,8 @@ func (s *rconService) startPlayerListPolling(serverID int64, game refractor.Game
 
 		// Update prevPlayers for this server
 		s.prevPlayers[serverID] = prevPlayers
+
+		s.publishPlayerFields(serverID, game.GetConfig(), players)
 	}
 }
 
@@ -246,6 +313,31 @@ func (s *rconService) startPlayerListRefreshPoll(serverID int64, game refractor.
 		for _, sub := range s.playerListPollSubscribers {
 			sub(serverID, gameConfig, onlinePlayers)
 		}
+
+		s.publishPlayerFields(serverID, gameConfig, players)
+	}
+}
+
+// startPlayerFieldsPolling periodically fetches the player list of a server and publishes the player list fields to
+// the player fields subscribers. It is used by games which don't poll the player list to detect joins and quits so
+// that fields such as ping and team don't go stale in between player list refresh polls. It runs until the stop
+// channel is closed.
+func (s *rconService) startPlayerFieldsPolling(serverID int64, game refractor.Game, stop chan struct{}) {
+	gameConfig := game.GetConfig()
+
+	for {
+		if !waitForNextPoll(gameConfig.PlayerFieldsPollingInterval, stop) {
+			return
+		}
+
+		client := s.clients[serverID]
+		if client == nil {
+			s.log.Warn("Player fields polling routine could not get the client for server ID %d", serverID)
+			s.log.Warn("Exiting player fields polling routine for server ID %d", serverID)
+			return
+		}
+
+		s.publishPlayerFields(serverID, gameConfig, s.getOnlinePlayers(serverID, game))
 	}
 }
 
@@ -255,16 +347,41 @@ func (s *rconService) GetClients() map[int64]*refractor.RCONClient {
 
 func (s *rconService) DeleteClient(serverID int64) {
 	delete(s.clients, serverID)
+	s.stopClientRoutines(serverID)
 }
 
 func (s *rconService) SendChatMessage(msgBody *refractor.ChatSendBody) {
 	client := s.clients[msgBody.ServerID]
+	if client == nil {
+		s.log.Warn("Could not send chat message to server %d. No RCON client found.", msgBody.ServerID)
+		return
+	}
+
+	game, _ := s.gameService.GetGame(client.Server.Game)
+	if game == nil {
+		s.log.Warn("Could not send chat message to server %d. Game %s not found.", msgBody.ServerID, client.Server.Game)
+		return
+	}
+
+	command := game.GetBroadcastCommand(refractor.CommandArgs{
+		Message: fmt.Sprintf("[%s]: %s", msgBody.Sender, msgBody.Message),
+	})
 
-	if _, err := client.ExecCommand(fmt.Sprintf("Say [%s]: %s", msgBody.Sender, msgBody.Message)); err != nil {
+	if _, err := client.ExecCommand(command); err != nil {
 		s.log.Error("Could not send chat message to server %d. Error: %v", msgBody.ServerID, err)
 	}
 }
 
+// ExecCommand runs a command on a server and returns its output
+func (s *rconService) ExecCommand(serverID int64, command string) (string, error) {
+	client := s.clients[serverID]
+	if client == nil {
+		return "", fmt.Errorf("no RCON client for server ID %d", serverID)
+	}
+
+	return client.ExecCommand(command)
+}
+
 // SubscribeJoin adds a function to a slice of functions to be called when a player joins a server
 func (s *rconService) SubscribeJoin(subscriber refractor.BroadcastSubscriber) {
 	s.joinSubscribers = append(s.joinSubscribers, subscriber)
@@ -293,6 +410,32 @@ func (s *rconService) SubscribePlayerListPoll(subscriber refractor.PlayerListPol
 	s.playerListPollSubscribers = append(s.playerListPollSubscribers, subscriber)
 }
 
+// SubscribePlayerFields adds a function to a slice of functions to be called with the player list fields of the
+// players on a server each time its player list is polled
+func (s *rconService) SubscribePlayerFields(subscriber refractor.PlayerFieldsSubscriber) {
+	s.playerFieldsSubscribers = append(s.playerFieldsSubscribers, subscriber)
+}
+
+// SubscribeServerInfo adds a function to a slice of functions to be called when the polled info of a server changes
+func (s *rconService) SubscribeServerInfo(subscriber refractor.ServerInfoSubscriber) {
+	s.serverInfoSubscribers = append(s.serverInfoSubscribers, subscriber)
+}
+
+// SubscribePunishment adds a function to a slice of functions to be called when an admin punishes a player in-game
+func (s *rconService) SubscribePunishment(subscriber refractor.BroadcastSubscriber) {
+	s.punishmentSubscribers = append(s.punishmentSubscribers, subscriber)
+}
+
+// SubscribeMatchState adds a function to a slice of functions to be called when the match state of a server changes
+func (s *rconService) SubscribeMatchState(subscriber refractor.BroadcastSubscriber) {
+	s.matchStateSubscribers = append(s.matchStateSubscribers, subscriber)
+}
+
+// SubscribeKill adds a function to a slice of functions to be called when a player is killed on a server
+func (s *rconService) SubscribeKill(subscriber refractor.BroadcastSubscriber) {
+	s.killSubscribers = append(s.killSubscribers, subscriber)
+}
+
 func (s *rconService) getBroadcastListener(serverID int64, gameConfig *refractor.GameConfig) func(string) {
 	// We wrap this in a parent function so that we can pass in the server IDs which each client belongs to.
 	// This allows us to uniquely identify which server a broadcast came from.
@@ -300,24 +443,43 @@ func (s *rconService) getBroadcastListener(serverID int64, gameConfig *refractor
 		s.log.Info("Received broadcast from server ID %d: %v", serverID, message)
 
 		bcast := broadcast.GetBroadcastType(message, gameConfig.BroadcastPatterns)
+		s.handleBroadcast(bcast, serverID, gameConfig)
+	}
+}
 
-		switch bcast.Type {
-		case broadcast.TYPE_JOIN:
-			s.HandleJoinBroadcast(bcast, serverID, gameConfig)
-			break
-		case broadcast.TYPE_QUIT:
-			s.HandleQuitBroadcast(bcast, serverID, gameConfig)
-			break
-		case broadcast.TYPE_CHAT:
-			s.HandleChatBroadcast(bcast, serverID, gameConfig)
-			break
-		}
+// handleBroadcast publishes a broadcast to the subscribers of its type. Broadcasts which didn't match any of the
+// game's patterns are ignored.
+func (s *rconService) handleBroadcast(bcast *broadcast.Broadcast, serverID int64, gameConfig *refractor.GameConfig) {
+	if bcast == nil {
+		return
+	}
+
+	switch bcast.Type {
+	case broadcast.TYPE_JOIN:
+		s.HandleJoinBroadcast(bcast, serverID, gameConfig)
+		break
+	case broadcast.TYPE_QUIT:
+		s.HandleQuitBroadcast(bcast, serverID, gameConfig)
+		break
+	case broadcast.TYPE_CHAT:
+		s.HandleChatBroadcast(bcast, serverID, gameConfig)
+		break
+	case broadcast.TYPE_PUNISHMENT:
+		s.HandlePunishmentBroadcast(bcast, serverID, gameConfig)
+		break
+	case broadcast.TYPE_MATCHSTATE:
+		s.HandleMatchStateBroadcast(bcast, serverID, gameConfig)
+		break
+	case broadcast.TYPE_KILL:
+		s.HandleKillBroadcast(bcast, serverID, gameConfig)
+		break
 	}
 }
 
 func (s *rconService) getDisconnectHandler(serverID int64) func(error, bool) {
 	return func(err error, expected bool) {
 		delete(s.clients, serverID)
+		s.stopClientRoutines(serverID)
 
 		// Notify all subscribers of a server offline event
 		for _, sub := range s.offlineSubscribers {
@@ -329,6 +491,34 @@ func (s *rconService) getDisconnectHandler(serverID int64) func(error, bool) {
 type onlinePlayer struct {
 	PlayerGameID string
 	Name         string
+
+	// Fields holds every named group captured by the game's player list pattern
+	Fields broadcast.Fields
+}
+
+// getJoinFields returns the fields published to join subscribers when a player is found online by polling
+func (p *onlinePlayer) getJoinFields(gameConfig *refractor.GameConfig) broadcast.Fields {
+	fields := broadcast.Fields{}
+	for name, value := range p.Fields {
+		fields[name] = value
+	}
+
+	fields[gameConfig.PlayerGameIDField] = p.PlayerGameID
+	fields["Name"] = p.Name
+
+	return fields
+}
+
+// publishPlayerFields publishes the player list fields of the players on a server to the player fields subscribers
+func (s *rconService) publishPlayerFields(serverID int64, gameConfig *refractor.GameConfig, players []*onlinePlayer) {
+	fields := map[string]broadcast.Fields{}
+	for _, player := range players {
+		fields[player.PlayerGameID] = player.Fields
+	}
+
+	for _, sub := range s.playerFieldsSubscribers {
+		sub(serverID, gameConfig, fields)
+	}
 }
 
 func (s *rconService) getOnlinePlayers(serverID int64, game refractor.Game) []*onlinePlayer {
@@ -355,8 +545,51 @@ func (s *rconService) getOnlinePlayers(serverID int64, game refractor.Game) []*o
 		onlinePlayers = append(onlinePlayers, &onlinePlayer{
 			PlayerGameID: playerGameID,
 			Name:         name,
+			Fields:       fields,
 		})
 	}
 
 	return onlinePlayers
 }
+
+// newClientStop creates the stop channel of the routines started for a server's client. Routines still running for a
+// previous client of the server are stopped first.
+func (s *rconService) newClientStop(serverID int64) chan struct{} {
+	s.clientStopsMu.Lock()
+	defer s.clientStopsMu.Unlock()
+
+	if stop := s.clientStops[serverID]; stop != nil {
+		close(stop)
+	}
+
+	stop := make(chan struct{})
+	s.clientStops[serverID] = stop
+
+	return stop
+}
+
+// stopClientRoutines stops the routines started for a server's client. They are only stopped once, even if the client
+// is deleted and disconnected at the same time.
+func (s *rconService) stopClientRoutines(serverID int64) {
+	s.clientStopsMu.Lock()
+	defer s.clientStopsMu.Unlock()
+
+	stop := s.clientStops[serverID]
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	delete(s.clientStops, serverID)
+}
+
+// waitForNextPoll waits for the polling interval of a polling routine to pass. It returns false if the routine should
+// exit instead because the client it was started for is gone.
+func waitForNextPoll(interval time.Duration, stop chan struct{}) bool {
+	select {
+	case <-stop:
+		return false
+	case <-time.After(interval):
+		return true
+	}
+}
@@ -0,0 +1,230 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package rcon
+
+import (
+	"errors"
+	"github.com/sniddunc/refractor/internal/game"
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/pkg/broadcast"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_rconService_CreateClient(t *testing.T) {
+	tests := []struct {
+		name          string
+		noTransport   bool
+		port          string
+		maxPort       uint16
+		wantErr       bool
+		wantJoins     []broadcast.Fields
+		wantStatusLog []string
+	}{
+		{
+			name: "rcon.createclient.1",
+			wantJoins: []broadcast.Fields{
+				{"PlayFabID": "AAA", "Name": "Player One", "Team": "0"},
+				{"PlayFabID": "BBB", "Name": "Player Two", "Team": "1"},
+			},
+			wantStatusLog: []string{"online", "offline"},
+		},
+		{
+			name:        "rcon.createclient.2",
+			noTransport: true,
+			wantErr:     true,
+		},
+		{
+			name:    "rcon.createclient.3",
+			port:    "40000",
+			maxPort: 32767,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testLogger, _ := log.NewLogger(true, false)
+
+			mockGame := mock.NewMockGame()
+			mockGame.GetConfig().MaxRCONPort = tt.maxPort
+
+			var transport *mock.MockRCONTransport
+			if tt.noTransport {
+				mockGame.GetConfig().RCONTransport = nil
+			} else {
+				mockGame.GetConfig().RCONTransport = func(config *refractor.RCONTransportConfig) refractor.RCONTransport {
+					transport = mock.NewMockRCONTransport(config).(*mock.MockRCONTransport)
+					transport.Outputs["mocklist"] = "AAA, Player One, team 0\nBBB, Player Two, team 1"
+					return transport
+				}
+			}
+
+			gameService := game.NewGameService()
+			gameService.AddGame(mockGame)
+
+			rconService := NewRCONService(gameService, nil, testLogger)
+
+			var joins []broadcast.Fields
+			var statusLog []string
+			rconService.SubscribeJoin(func(fields broadcast.Fields, serverID int64, gameConfig *refractor.GameConfig) {
+				joins = append(joins, fields)
+			})
+			rconService.SubscribeOnline(func(serverID int64) {
+				statusLog = append(statusLog, "online")
+			})
+			rconService.SubscribeOffline(func(serverID int64) {
+				statusLog = append(statusLog, "offline")
+			})
+
+			server := &refractor.Server{
+				ServerID:     1,
+				Game:         mockGame.GetName(),
+				Address:      "127.0.0.1",
+				RCONPort:     "7778",
+				RCONPassword: "rconpassword",
+			}
+
+			if tt.port != "" {
+				server.RCONPort = tt.port
+			}
+
+			err := rconService.CreateClient(server)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, rconService.GetClients()[1])
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.True(t, transport.Connected)
+			assert.Equal(t, "127.0.0.1", transport.Config.Host)
+			assert.Equal(t, uint16(7778), transport.Config.Port)
+			assert.Equal(t, "rconpassword", transport.Config.Password)
+			assert.True(t, transport.Config.EnableBroadcasts)
+			assert.Equal(t, []string{"mocklist"}, transport.Commands)
+			assert.Equal(t, transport, rconService.GetClients()[1].RCONTransport)
+
+			// Losing the connection should remove the client and take the server offline
+			transport.Config.DisconnectHandler(errors.New("connection reset"), false)
+
+			assert.Nil(t, rconService.GetClients()[1])
+			assert.Equal(t, tt.wantJoins, joins)
+			assert.Equal(t, tt.wantStatusLog, statusLog)
+		})
+	}
+}
+
+func Test_rconService_startPlayerFieldsPolling(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	mockGame := mock.NewMockGame()
+	mockGame.GetConfig().PlayerFieldsPollingInterval = time.Millisecond * 10
+
+	transport := mock.NewMockRCONTransport(&refractor.RCONTransportConfig{}).(*mock.MockRCONTransport)
+	transport.Outputs["mocklist"] = "AAA, Player One, team 0\nBBB, Player Two, team 1"
+	_ = transport.Connect()
+
+	rconService := NewRCONService(game.NewGameService(), nil, testLogger).(*rconService)
+	rconService.clients[1] = &refractor.RCONClient{RCONTransport: transport}
+
+	published := make(chan map[string]broadcast.Fields, 1)
+	rconService.SubscribePlayerFields(func(serverID int64, gameConfig *refractor.GameConfig, fields map[string]broadcast.Fields) {
+		select {
+		case published <- fields:
+		default:
+		}
+	})
+
+	stop := rconService.newClientStop(1)
+	done := make(chan struct{})
+
+	go func() {
+		rconService.startPlayerFieldsPolling(1, mockGame, stop)
+		close(done)
+	}()
+
+	select {
+	case fields := <-published:
+		assert.Equal(t, "0", fields["AAA"]["Team"])
+		assert.Equal(t, "1", fields["BBB"]["Team"])
+	case <-time.After(time.Second):
+		t.Fatal("player fields were not published")
+	}
+
+	// The routine should exit once it is stopped, even though a client for the server still exists
+	rconService.stopClientRoutines(1)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("player fields polling routine did not exit")
+	}
+}
+
+func Test_rconService_SendChatMessage(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	mockGame := mock.NewMockGame()
+	gameService := game.NewGameService()
+	gameService.AddGame(mockGame)
+
+	transport := mock.NewMockRCONTransport(&refractor.RCONTransportConfig{}).(*mock.MockRCONTransport)
+	_ = transport.Connect()
+
+	rconService := NewRCONService(gameService, nil, testLogger).(*rconService)
+	rconService.clients[1] = &refractor.RCONClient{
+		Server:        &refractor.Server{ServerID: 1, Game: mockGame.GetName()},
+		RCONTransport: transport,
+	}
+
+	rconService.SendChatMessage(&refractor.ChatSendBody{ServerID: 1, Message: "hello", Sender: "Admin"})
+	rconService.SendChatMessage(&refractor.ChatSendBody{ServerID: 2, Message: "hello", Sender: "Admin"})
+
+	assert.Equal(t, []string{"mockbroadcast [Admin]: hello"}, transport.Commands)
+}
+
+func Test_rconService_stopClientRoutines(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+	s := NewRCONService(nil, nil, testLogger).(*rconService)
+
+	first := s.newClientStop(1)
+
+	// Creating a new stop channel for a server stops the routines of its previous client
+	second := s.newClientStop(1)
+	_, open := <-first
+	assert.False(t, open)
+
+	// A client being deleted and disconnecting at the same time shouldn't stop its routines twice
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.stopClientRoutines(1)
+		}()
+	}
+	wg.Wait()
+
+	_, open = <-second
+	assert.False(t, open)
+	assert.Nil(t, s.clientStops[1])
+}
@@ -0,0 +1,44 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package transport
+
+import (
+	rcon "github.com/sniddunc/mordhau-rcon"
+	"github.com/sniddunc/refractor/refractor"
+	"math"
+)
+
+// MordhauMaxPort is the highest port the mordhau-rcon client can connect to since it takes ports as an int16
+const MordhauMaxPort = math.MaxInt16
+
+// NewMordhauTransport creates a transport which uses the mordhau-rcon client. Broadcasts are received on a second
+// socket, which the heartbeat command keeps alive. Ports above MordhauMaxPort can't be connected to, so games using
+// this transport should set it as their MaxRCONPort.
+func NewMordhauTransport(config *refractor.RCONTransportConfig) refractor.RCONTransport {
+	return rcon.NewClient(&rcon.ClientConfig{
+		Host:                     config.Host,
+		Port:                     int16(config.Port),
+		Password:                 config.Password,
+		SendHeartbeatCommand:     config.SendHeartbeat,
+		HeartbeatCommandInterval: config.HeartbeatInterval,
+		AttemptReconnect:         false,
+		EnableBroadcasts:         config.EnableBroadcasts,
+		BroadcastHandler:         config.BroadcastHandler,
+		DisconnectHandler:        config.DisconnectHandler,
+	})
+}
@@ -0,0 +1,38 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package transport
+
+import (
+	"github.com/sniddunc/refractor/pkg/sourcercon"
+	"github.com/sniddunc/refractor/refractor"
+)
+
+// NewSourceTransport creates a transport which speaks Valve's Source RCON protocol. Commands and broadcasts share a
+// single connection, and responses split across several packets are joined back together.
+func NewSourceTransport(config *refractor.RCONTransportConfig) refractor.RCONTransport {
+	return sourcercon.NewClient(&sourcercon.ClientConfig{
+		Host:              config.Host,
+		Port:              config.Port,
+		Password:          config.Password,
+		SendHeartbeat:     config.SendHeartbeat,
+		HeartbeatInterval: config.HeartbeatInterval,
+		EnableBroadcasts:  config.EnableBroadcasts,
+		BroadcastHandler:  config.BroadcastHandler,
+		DisconnectHandler: config.DisconnectHandler,
+	})
+}
@@ -20,9 +20,12 @@ package search
 import (
 	"fmt"
 	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/fuzzy"
 	logger "github.com/sniddunc/refractor/pkg/log"
 	"github.com/sniddunc/refractor/refractor"
 	"net/http"
+	"sort"
 	"strconv"
 	"time"
 )
@@ -47,9 +50,11 @@ func NewSearchService(playerRepo refractor.PlayerRepository, infractionRepo refr
 func (s *searchService) SearchPlayers(body params.SearchPlayersParams) (int, []*refractor.Player, *refractor.ServiceResponse) {
 	switch body.SearchType {
 	case "playfabid":
-		return s.searchByPlayerPlayFabID(body.SearchTerm)
+		return s.searchByPlayerGameID("PlayFabID", body.SearchTerm, body.SearchParams.Limit, body.SearchParams.Offset)
 	case "mcuuid":
-		return s.searchByPlayerMCUUID(body.SearchTerm)
+		return s.searchByPlayerGameID("MCUUID", body.SearchTerm, body.SearchParams.Limit, body.SearchParams.Offset)
+	case "steamid":
+		return s.searchByPlayerGameID("SteamID", body.SearchTerm, body.SearchParams.Limit, body.SearchParams.Offset)
 	case "name":
 		return s.searchByPlayerName(body.SearchTerm, body.SearchParams.Limit, body.SearchParams.Offset)
 	case "id":
@@ -60,8 +65,9 @@ func (s *searchService) SearchPlayers(body params.SearchPlayersParams) (int, []*
 	}
 }
 
-func (s *searchService) searchByPlayerPlayFabID(playFabID string) (int, []*refractor.Player, *refractor.ServiceResponse) {
-	player, err := s.playerRepo.FindByPlayFabID(playFabID)
+// searchByPlayerGameID finds players whose game identifier (e.g. PlayFabID) contains the search term.
+func (s *searchService) searchByPlayerGameID(field string, term string, limit int, offset int) (int, []*refractor.Player, *refractor.ServiceResponse) {
+	count, players, err := s.playerRepo.SearchByGameID(field, term, limit, offset)
 	if err != nil {
 		if err == refractor.ErrNotFound {
 			return 0, []*refractor.Player{}, &refractor.ServiceResponse{
@@ -71,59 +77,111 @@ func (s *searchService) searchByPlayerPlayFabID(playFabID string) (int, []*refra
 			}
 		}
 
-		s.log.Error("Could not get player by PlayFabID. Error: %v", err)
+		s.log.Error("Could not search players by %s. Error: %v", field, err)
 		return 0, nil, refractor.InternalErrorResponse
 	}
 
-	return 1, []*refractor.Player{player}, &refractor.ServiceResponse{
+	return count, players, &refractor.ServiceResponse{
 		Success:    true,
 		StatusCode: http.StatusOK,
-		Message:    "Found 1 matching players",
+		Message:    fmt.Sprintf("Found %d matching players", count),
 	}
 }
 
-func (s *searchService) searchByPlayerMCUUID(MCUUID string) (int, []*refractor.Player, *refractor.ServiceResponse) {
-	player, err := s.playerRepo.FindByMCUUID(MCUUID)
-	if err != nil {
-		if err == refractor.ErrNotFound {
-			return 0, []*refractor.Player{}, &refractor.ServiceResponse{
-				Success:    true,
-				StatusCode: http.StatusOK,
-				Message:    "Found 0 matching players",
-			}
+// searchByPlayerName runs a typo tolerant search across current and historical player names. Each returned player has
+// MatchedName set to the name which matched the search term.
+func (s *searchService) searchByPlayerName(name string, limit int, offset int) (int, []*refractor.Player, *refractor.ServiceResponse) {
+	candidates, err := s.playerRepo.FindNameCandidates(name, config.SearchNameCandidateMax)
+	if err != nil && err != refractor.ErrNotFound {
+		s.log.Error("Could not search players by name. Error: %v", err)
+		return 0, nil, refractor.InternalErrorResponse
+	}
+
+	matches := rankNameMatches(name, candidates, time.Now().Unix())
+	count := len(matches)
+
+	// Paginate the ranked matches
+	if offset > len(matches) {
+		offset = len(matches)
+	}
+
+	matches = matches[offset:]
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	players := []*refractor.Player{}
+
+	for _, match := range matches {
+		player, err := s.playerRepo.FindByID(match.PlayerID)
+		if err != nil {
+			s.log.Error("Could not get matched player by ID %d. Error: %v", match.PlayerID, err)
+			continue
 		}
 
-		s.log.Error("Could not get player by MCUUID. Error: %v", err)
-		return 0, nil, refractor.InternalErrorResponse
+		player.MatchedName = match.Name
+		players = append(players, player)
 	}
 
-	return 1, []*refractor.Player{player}, &refractor.ServiceResponse{
+	return count, players, &refractor.ServiceResponse{
 		Success:    true,
 		StatusCode: http.StatusOK,
-		Message:    "Found 1 matching players",
+		Message:    fmt.Sprintf("Found %d matching players", count),
 	}
 }
 
-func (s *searchService) searchByPlayerName(name string, limit int, offset int) (int, []*refractor.Player, *refractor.ServiceResponse) {
-	count, players, err := s.playerRepo.SearchByName(name, limit, offset)
-	if err != nil {
-		if err == refractor.ErrNotFound {
-			return 0, []*refractor.Player{}, &refractor.ServiceResponse{
-				Success:    true,
-				StatusCode: http.StatusOK,
-				Message:    "Found 0 matching players",
-			}
+type rankedNameMatch struct {
+	*refractor.PlayerNameMatch
+	score float64
+}
+
+// rankNameMatches scores name candidates against the search term and returns the best match for each player, best
+// first. Current names and recently seen players are ranked higher than historical names and inactive players.
+func rankNameMatches(term string, candidates []*refractor.PlayerNameMatch, now int64) []*rankedNameMatch {
+	bestMatches := map[int64]*rankedNameMatch{}
+
+	for _, candidate := range candidates {
+		score := fuzzy.Similarity(term, candidate.Name)
+		if score < config.SearchNameMinSimilarity {
+			continue
 		}
 
-		s.log.Error("Could not search players by name. Error: %v", err)
-		return 0, nil, refractor.InternalErrorResponse
+		if candidate.Current {
+			score += config.SearchNameCurrentBonus
+		}
+
+		// Recency bonus decays linearly over the recency window
+		if age := now - candidate.LastSeen; age >= 0 && age < config.SearchNameRecencyWindow {
+			score += config.SearchNameRecencyBonus * (1 - float64(age)/float64(config.SearchNameRecencyWindow))
+		}
+
+		if existing := bestMatches[candidate.PlayerID]; existing == nil || score > existing.score {
+			bestMatches[candidate.PlayerID] = &rankedNameMatch{
+				PlayerNameMatch: candidate,
+				score:           score,
+			}
+		}
 	}
 
-	return count, players, &refractor.ServiceResponse{
-		Success:    true,
-		StatusCode: http.StatusOK,
-		Message:    fmt.Sprintf("Found %d matching players", len(players)),
+	ranked := make([]*rankedNameMatch, 0, len(bestMatches))
+	for _, match := range bestMatches {
+		ranked = append(ranked, match)
 	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+
+		if ranked[i].LastSeen != ranked[j].LastSeen {
+			return ranked[i].LastSeen > ranked[j].LastSeen
+		}
+
+		return ranked[i].PlayerID < ranked[j].PlayerID
+	})
+
+	return ranked
 }
 
 func (s *searchService) searchByID(idString string) (int, []*refractor.Player, *refractor.ServiceResponse) {
@@ -225,6 +283,14 @@ func (s *searchService) SearchChatMessages(body params.SearchChatMessagesParams)
 		searchArgs["ServerID"] = body.ParsedChatMessageIDs.ServerID
 	}
 
+	if body.Channel != "" {
+		searchArgs["Channel"] = body.Channel
+	}
+
+	if body.Name != "" {
+		searchArgs["PlayerName"] = body.Name
+	}
+
 	searchArgs["Message"] = body.Message
 	searchArgs["StartDate"] = body.StartDate
 
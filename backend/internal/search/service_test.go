@@ -0,0 +1,113 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package search
+
+import (
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_rankNameMatches(t *testing.T) {
+	now := int64(1610000000)
+	recent := now - 60
+	old := now - config.SearchNameRecencyWindow*2
+
+	tests := []struct {
+		name          string
+		term          string
+		candidates    []*refractor.PlayerNameMatch
+		wantPlayerIDs []int64
+		wantNames     []string
+	}{
+		{
+			name: "search.ranknamematches.1",
+			term: "sniddunc",
+			candidates: []*refractor.PlayerNameMatch{
+				{PlayerID: 1, Name: "sniddunc", Current: false, LastSeen: old},
+				{PlayerID: 2, Name: "sniddunc", Current: true, LastSeen: old},
+			},
+			wantPlayerIDs: []int64{2, 1},
+			wantNames:     []string{"sniddunc", "sniddunc"},
+		},
+		{
+			name: "search.ranknamematches.2",
+			term: "sniddunc",
+			candidates: []*refractor.PlayerNameMatch{
+				{PlayerID: 1, Name: "sniddunc", Current: true, LastSeen: old},
+				{PlayerID: 2, Name: "sniddunc", Current: true, LastSeen: recent},
+			},
+			wantPlayerIDs: []int64{2, 1},
+			wantNames:     []string{"sniddunc", "sniddunc"},
+		},
+		{
+			name: "search.ranknamematches.3",
+			term: "sniddunc",
+			candidates: []*refractor.PlayerNameMatch{
+				{PlayerID: 1, Name: "SomethingElse", Current: true, LastSeen: recent},
+				{PlayerID: 1, Name: "snidunc", Current: false, LastSeen: recent},
+				{PlayerID: 2, Name: "completely unrelated", Current: true, LastSeen: recent},
+			},
+			wantPlayerIDs: []int64{1},
+			wantNames:     []string{"snidunc"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranked := rankNameMatches(tt.term, tt.candidates, now)
+
+			var playerIDs []int64
+			var names []string
+			for _, match := range ranked {
+				playerIDs = append(playerIDs, match.PlayerID)
+				names = append(names, match.Name)
+			}
+
+			assert.Equal(t, tt.wantPlayerIDs, playerIDs)
+			assert.Equal(t, tt.wantNames, names)
+		})
+	}
+}
+
+func Test_searchService_SearchPlayers(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	mockPlayers := map[int64]*refractor.DBPlayer{
+		1: {PlayerID: 1, CurrentName: "Walter", PreviousNames: []string{"Heisenberg"}},
+		2: {PlayerID: 2, CurrentName: "Jesse"},
+	}
+
+	playerRepo := mock.NewMockPlayerRepository(mockPlayers)
+	searchService := NewSearchService(playerRepo, nil, nil, testLogger)
+
+	count, players, res := searchService.SearchPlayers(params.SearchPlayersParams{
+		SearchTerm:   "heisenburg",
+		SearchType:   "name",
+		SearchParams: params.SearchParams{Limit: 10},
+	})
+
+	assert.True(t, res.Success)
+	assert.Equal(t, 1, count)
+	assert.Len(t, players, 1)
+	assert.Equal(t, int64(1), players[0].PlayerID)
+	assert.Equal(t, "Heisenberg", players[0].MatchedName)
+}
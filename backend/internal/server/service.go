@@ -20,6 +20,7 @@ package server
 import (
 	"fmt"
 	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/broadcast"
 	"github.com/sniddunc/refractor/pkg/config"
 	"github.com/sniddunc/refractor/pkg/log"
 	"github.com/sniddunc/refractor/refractor"
@@ -87,11 +88,15 @@ func (s *serverService) CreateServer(body params.CreateServerParams) (*refractor
 
 	// Create the new server
 	newServer := &refractor.Server{
-		Game:         body.Game,
-		Name:         body.Name,
-		Address:      body.Address,
-		RCONPort:     body.RCONPort,
-		RCONPassword: body.RCONPassword,
+		Game:                body.Game,
+		Name:                body.Name,
+		Address:             body.Address,
+		RCONPort:            body.RCONPort,
+		RCONPassword:        body.RCONPassword,
+		WarnMessageTemplate: body.WarnMessageTemplate,
+		MuteAction:          body.MuteAction,
+		EventSource:         body.EventSource,
+		LogFilePath:         body.LogFilePath,
 	}
 
 	if err := s.repo.Create(newServer); err != nil {
@@ -209,6 +214,49 @@ func (s *serverService) UpdateServer(id int64, body params.UpdateServerParams) (
 		updateArgs["RCONPassword"] = body.RCONPassword
 	}
 
+	if body.WarnMessageTemplate != nil {
+		updateArgs["WarnMessageTemplate"] = *body.WarnMessageTemplate
+	}
+
+	if body.MuteAction != "" {
+		updateArgs["MuteAction"] = body.MuteAction
+	}
+
+	if body.EventSource != "" {
+		updateArgs["EventSource"] = body.EventSource
+	}
+
+	if body.LogFilePath != "" {
+		updateArgs["LogFilePath"] = body.LogFilePath
+	}
+
+	// Switching to the log event source without providing a path is only allowed if the server already has one
+	if body.EventSource == params.EventSourceLog && body.LogFilePath == "" {
+		server, err := s.repo.FindByID(id)
+		if err != nil {
+			if err == refractor.ErrNotFound {
+				return nil, &refractor.ServiceResponse{
+					Success:    false,
+					StatusCode: http.StatusNotFound,
+					Message:    config.MessageServerNotFound,
+				}
+			}
+
+			s.log.Error("Could not find server of ID %d in repo. Error: %v", id, err)
+			return nil, refractor.InternalErrorResponse
+		}
+
+		if server.LogFilePath == "" {
+			return nil, &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+				ValidationErrors: url.Values{
+					"logFilePath": []string{"A log file path is required to use the log event source"},
+				},
+			}
+		}
+	}
+
 	if len(updateArgs) < 1 {
 		return nil, &refractor.ServiceResponse{
 			Success:    false,
@@ -269,6 +317,7 @@ func (s *serverService) OnPlayerJoin(serverID int64, player *refractor.Player) {
 
 	// Add the player to the server data
 	s.serverData[serverID].OnlinePlayers[field] = player
+	s.serverData[serverID].PlayerCount = len(s.serverData[serverID].OnlinePlayers)
 }
 
 func (s *serverService) OnPlayerQuit(serverID int64, player *refractor.Player) {
@@ -281,6 +330,7 @@ func (s *serverService) OnPlayerQuit(serverID int64, player *refractor.Player) {
 
 	// Remove the player from the server data
 	delete(s.serverData[serverID].OnlinePlayers, field)
+	s.serverData[serverID].PlayerCount = len(s.serverData[serverID].OnlinePlayers)
 }
 
 func (s *serverService) OnServerOnline(serverID int64) {
@@ -299,6 +349,7 @@ func (s *serverService) OnServerOffline(serverID int64) {
 	}
 
 	s.serverData[serverID].Online = false
+	s.serverData[serverID].Info = nil
 
 	s.log.Warn("Server with ID %d has gone offline", serverID)
 }
@@ -318,8 +369,12 @@ func (s *serverService) OnPlayerUpdate(updated *refractor.Player) {
 				r := reflect.ValueOf(player)
 				field := reflect.Indirect(r).FieldByName(game.GetConfig().PlayerGameIDField).String()
 
-				// Replace their entry with the updated player struct
-				data.OnlinePlayers[field] = updated
+				// Replace their entry with the updated player struct. The updated player is copied so that each
+				// server keeps its own player list fields.
+				updatedCopy := *updated
+				updatedCopy.Fields = player.Fields
+
+				data.OnlinePlayers[field] = &updatedCopy
 			}
 		}
 	}
@@ -336,4 +391,48 @@ func (s *serverService) OnPlayerListUpdate(serverID int64, gameConfig *refractor
 	}
 
 	s.serverData[serverID].OnlinePlayers = onlinePlayerMap
+	s.serverData[serverID].PlayerCount = len(onlinePlayerMap)
+}
+
+// OnPlayerFieldsUpdate sets the player list fields (e.g. ping and team) of the players online on a server. Players
+// without fields in the update keep their previous fields.
+func (s *serverService) OnPlayerFieldsUpdate(serverID int64, gameConfig *refractor.GameConfig, fields map[string]broadcast.Fields) {
+	serverData := s.serverData[serverID]
+	if serverData == nil {
+		s.log.Warn("OnPlayerFieldsUpdate was called with an invalid serverID of %d", serverID)
+		return
+	}
+
+	for gameID, player := range serverData.OnlinePlayers {
+		if playerFields := fields[gameID]; playerFields != nil {
+			player.Fields = playerFields
+		}
+	}
+}
+
+// OnServerInfoUpdate sets the latest polled info of a server
+func (s *serverService) OnServerInfoUpdate(serverID int64, info *refractor.LiveServerInfo) {
+	if s.serverData[serverID] == nil {
+		s.log.Warn("OnServerInfoUpdate was called with an invalid serverID of %d", serverID)
+		return
+	}
+
+	s.serverData[serverID].Info = info
+}
+
+// GetOnlinePlayerGameID returns the game ID of a player who is online on a server. If the player is not online, an
+// empty string is returned.
+func (s *serverService) GetOnlinePlayerGameID(serverID int64, playerID int64) string {
+	serverData := s.serverData[serverID]
+	if serverData == nil {
+		return ""
+	}
+
+	for gameID, player := range serverData.OnlinePlayers {
+		if player.PlayerID == playerID {
+			return gameID
+		}
+	}
+
+	return ""
 }
@@ -21,6 +21,7 @@ import (
 	"github.com/sniddunc/refractor/internal/game"
 	"github.com/sniddunc/refractor/internal/mock"
 	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/broadcast"
 	"github.com/sniddunc/refractor/pkg/log"
 	"github.com/sniddunc/refractor/refractor"
 	"github.com/stretchr/testify/assert"
@@ -166,6 +167,50 @@ func Test_serverService_EditServer(t *testing.T) {
 				Message:    "Server updated. RCON changes will come into effect the next time Refractor is restarted.",
 			},
 		},
+		{
+			name: "server.editserver.2",
+			fields: fields{
+				mockServers: map[int64]*refractor.Server{
+					1: {ServerID: 1, Name: "Test Server", Game: "Test Game"},
+				},
+			},
+			args: args{
+				id:   1,
+				body: params.UpdateServerParams{EventSource: params.EventSourceLog},
+			},
+			want: nil,
+			wantRes: &refractor.ServiceResponse{
+				Success:    false,
+				StatusCode: http.StatusBadRequest,
+				ValidationErrors: url.Values{
+					"logFilePath": []string{"A log file path is required to use the log event source"},
+				},
+			},
+		},
+		{
+			name: "server.editserver.3",
+			fields: fields{
+				mockServers: map[int64]*refractor.Server{
+					1: {ServerID: 1, Name: "Test Server", Game: "Test Game", LogFilePath: "/srv/game/server.log"},
+				},
+			},
+			args: args{
+				id:   1,
+				body: params.UpdateServerParams{EventSource: params.EventSourceLog},
+			},
+			want: &refractor.Server{
+				ServerID:    1,
+				Name:        "Test Server",
+				Game:        "Test Game",
+				EventSource: params.EventSourceLog,
+				LogFilePath: "/srv/game/server.log",
+			},
+			wantRes: &refractor.ServiceResponse{
+				Success:    true,
+				StatusCode: http.StatusOK,
+				Message:    "Server updated. RCON changes will come into effect the next time Refractor is restarted.",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -179,3 +224,39 @@ func Test_serverService_EditServer(t *testing.T) {
 		})
 	}
 }
+
+func Test_serverService_OnPlayerFieldsUpdate(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	gameService := game.NewGameService()
+	gameService.AddGame(mock.NewMockGame())
+	gameConfig := mock.NewMockGame().GetConfig()
+
+	serverService := NewServerService(mock.NewMockServerRepository(mock.GetMockServers()), gameService, nil, testLogger)
+	serverService.CreateServerData(1, mock.NewMockGame().GetName())
+
+	// Players found through a join broadcast only keep the fields captured by the player list pattern
+	serverService.OnPlayerJoin(1, &refractor.Player{PlayerID: 1, PlayFabID: "AAA", Fields: gameConfig.GetPlayerListFields(
+		map[string]string{"PlayFabID": "AAA", "Date": "2021.01.01-00.00.00", "Team": "1"})})
+	serverService.OnPlayerJoin(1, &refractor.Player{PlayerID: 2, PlayFabID: "BBB"})
+
+	serverData, _ := serverService.GetServerData(1)
+	assert.Equal(t, map[string]string{"PlayFabID": "AAA", "Team": "1"}, serverData.OnlinePlayers["AAA"].Fields)
+	assert.Nil(t, serverData.OnlinePlayers["BBB"].Fields)
+
+	serverService.OnPlayerFieldsUpdate(1, gameConfig, map[string]broadcast.Fields{
+		"BBB": {"PlayFabID": "BBB", "Name": "Player2", "Team": "0"},
+		"CCC": {"PlayFabID": "CCC", "Name": "Player3", "Team": "1"},
+	})
+
+	assert.Equal(t, map[string]string{"PlayFabID": "AAA", "Team": "1"}, serverData.OnlinePlayers["AAA"].Fields,
+		"Players missing from the update should keep their fields")
+	assert.Equal(t, map[string]string{"PlayFabID": "BBB", "Name": "Player2", "Team": "0"},
+		serverData.OnlinePlayers["BBB"].Fields)
+	assert.Nil(t, serverData.OnlinePlayers["CCC"], "Fields should not add players to the server data")
+
+	// Updating a player's details keeps their fields
+	serverService.OnPlayerUpdate(&refractor.Player{PlayerID: 2, PlayFabID: "BBB", Watched: true})
+	assert.True(t, serverData.OnlinePlayers["BBB"].Watched)
+	assert.Equal(t, "0", serverData.OnlinePlayers["BBB"].Fields["Team"])
+}
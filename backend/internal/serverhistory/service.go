@@ -0,0 +1,296 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package serverhistory
+
+import (
+	"fmt"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"math"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// bucketSizes holds the size of each history bucket in seconds. Buckets start on multiples of their size, so day
+// buckets run from midnight to midnight UTC.
+var bucketSizes = map[string]int64{
+	params.HistoryBucketMinute: 60,
+	params.HistoryBucketHour:   60 * 60,
+	params.HistoryBucketDay:    24 * 60 * 60,
+}
+
+type serverHistoryService struct {
+	repo          refractor.ServerHistoryRepository
+	serverService refractor.ServerService
+	log           log.Logger
+
+	// lastSample is the timestamp of the most recent round of samples. It makes sure that a round is never taken
+	// twice within the same sample interval.
+	lastSample     int64
+	lastDownsample time.Time
+	mu             sync.Mutex
+}
+
+func NewServerHistoryService(repo refractor.ServerHistoryRepository, serverService refractor.ServerService,
+	log log.Logger) refractor.ServerHistoryService {
+	return &serverHistoryService{
+		repo:          repo,
+		serverService: serverService,
+		log:           log,
+	}
+}
+
+func (s *serverHistoryService) GetServerHistory(serverID int64, body params.GetServerHistoryParams) (*refractor.ServerHistory, *refractor.ServiceResponse) {
+	if server, _ := s.serverService.GetServerByID(serverID); server == nil {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			Message:    config.MessageInvalidIDProvided,
+		}
+	}
+
+	size := bucketSizes[body.Bucket]
+
+	to := body.To
+	if to == 0 {
+		to = time.Now().Unix()
+	}
+
+	from := body.From
+	if from == 0 {
+		from = to - size*int64(config.ServerHistoryDefaultBuckets)
+	}
+
+	// Start on a bucket boundary so that the first bucket isn't partially cut off
+	from -= from % size
+
+	if (to-from)/size > int64(config.ServerHistoryMaxBuckets) {
+		return nil, &refractor.ServiceResponse{
+			Success:    false,
+			StatusCode: http.StatusBadRequest,
+			ValidationErrors: url.Values{
+				"from": []string{fmt.Sprintf("At most %d %s buckets can be fetched at once",
+					config.ServerHistoryMaxBuckets, body.Bucket)},
+			},
+		}
+	}
+
+	samples, err := s.repo.FindByServerID(serverID, from, to)
+	if err != nil && err != refractor.ErrNotFound {
+		s.log.Error("Could not find status samples of server ID %d. Error: %v", serverID, err)
+		return nil, refractor.InternalErrorResponse
+	}
+
+	history := &refractor.ServerHistory{
+		ServerID: serverID,
+		Bucket:   body.Bucket,
+		From:     from,
+		To:       to,
+		Buckets:  []*refractor.ServerHistoryBucket{},
+	}
+
+	var totalSamples, onlineSamples int
+
+	for _, merged := range mergeSamples(samples, size) {
+		history.Buckets = append(history.Buckets, &refractor.ServerHistoryBucket{
+			Timestamp:  merged.Timestamp,
+			MinPlayers: merged.MinPlayers,
+			MaxPlayers: merged.MaxPlayers,
+			AvgPlayers: round(merged.AvgPlayers),
+			Uptime:     percentage(merged.OnlineSamples, merged.Samples),
+		})
+
+		totalSamples += merged.Samples
+		onlineSamples += merged.OnlineSamples
+	}
+
+	history.Uptime = percentage(onlineSamples, totalSamples)
+
+	return history, &refractor.ServiceResponse{
+		Success:    true,
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("Fetched %d history buckets", len(history.Buckets)),
+	}
+}
+
+// StartSampling samples every server at the start of each sample interval and periodically downsamples samples which
+// are older than the retention period. It should be run in its own goroutine.
+func (s *serverHistoryService) StartSampling() {
+	interval := config.ServerHistorySampleInterval
+
+	for {
+		// Wait until the start of the next interval so that samples line up with bucket boundaries
+		now := time.Now()
+		time.Sleep(now.Truncate(interval).Add(interval).Sub(now))
+
+		now = time.Now()
+		s.SampleServers(now)
+
+		s.mu.Lock()
+		downsampleDue := now.Sub(s.lastDownsample) >= config.ServerHistoryDownsampleInterval
+		s.mu.Unlock()
+
+		if downsampleDue {
+			s.Downsample(now)
+		}
+	}
+}
+
+// SampleServers records whether each server is online and how many players are on it. Servers which are offline or
+// haven't connected yet are recorded as offline.
+func (s *serverHistoryService) SampleServers(now time.Time) {
+	timestamp := now.Truncate(config.ServerHistorySampleInterval).Unix()
+
+	s.mu.Lock()
+	if timestamp <= s.lastSample {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSample = timestamp
+	s.mu.Unlock()
+
+	servers, res := s.serverService.GetAllServers()
+	if !res.Success {
+		s.log.Error("Could not get servers to sample their status")
+		return
+	}
+
+	for _, server := range servers {
+		sample := &refractor.ServerStatusSample{
+			ServerID:  server.ServerID,
+			Timestamp: timestamp,
+			Samples:   1,
+		}
+
+		if serverData, _ := s.serverService.GetServerData(server.ServerID); serverData != nil && serverData.Online {
+			sample.OnlineSamples = 1
+			sample.MinPlayers = serverData.PlayerCount
+			sample.MaxPlayers = serverData.PlayerCount
+			sample.AvgPlayers = float64(serverData.PlayerCount)
+		}
+
+		if err := s.repo.Create(sample); err != nil {
+			s.log.Error("Could not create status sample for server ID %d. Error: %v", server.ServerID, err)
+		}
+	}
+}
+
+// Downsample combines the samples of each server which are older than the retention period into one sample per
+// downsample period. Only whole periods are downsampled so that a period is never split between raw and downsampled
+// samples.
+func (s *serverHistoryService) Downsample(now time.Time) {
+	s.mu.Lock()
+	s.lastDownsample = now
+	s.mu.Unlock()
+
+	period := int64(config.ServerHistoryDownsamplePeriod / time.Second)
+
+	before := now.Add(-config.ServerHistoryRetention).Unix()
+	before -= before % period
+
+	servers, res := s.serverService.GetAllServers()
+	if !res.Success {
+		s.log.Error("Could not get servers to downsample their status history")
+		return
+	}
+
+	for _, server := range servers {
+		samples, err := s.repo.FindByServerID(server.ServerID, 0, before)
+		if err != nil {
+			if err != refractor.ErrNotFound {
+				s.log.Error("Could not find status samples of server ID %d. Error: %v", server.ServerID, err)
+			}
+			continue
+		}
+
+		var raw []*refractor.ServerStatusSample
+		for _, sample := range samples {
+			if !sample.Downsampled {
+				raw = append(raw, sample)
+			}
+		}
+
+		if len(raw) == 0 {
+			continue
+		}
+
+		if err := s.repo.ReplaceSamples(server.ServerID, before, mergeSamples(raw, period)); err != nil {
+			s.log.Error("Could not downsample status samples of server ID %d. Error: %v", server.ServerID, err)
+			continue
+		}
+
+		s.log.Info("Downsampled %d status samples of server ID %d", len(raw), server.ServerID)
+	}
+}
+
+// mergeSamples combines samples, which must be ordered oldest first, into one sample per period
+func mergeSamples(samples []*refractor.ServerStatusSample, period int64) []*refractor.ServerStatusSample {
+	var merged []*refractor.ServerStatusSample
+	var current *refractor.ServerStatusSample
+
+	for _, sample := range samples {
+		start := sample.Timestamp - sample.Timestamp%period
+
+		if current == nil || current.Timestamp != start {
+			current = &refractor.ServerStatusSample{
+				ServerID:    sample.ServerID,
+				Timestamp:   start,
+				Downsampled: true,
+			}
+
+			merged = append(merged, current)
+		}
+
+		// Player counts only cover online samples, so offline samples don't drag the minimum and average down
+		if sample.OnlineSamples > 0 {
+			if current.OnlineSamples == 0 || sample.MinPlayers < current.MinPlayers {
+				current.MinPlayers = sample.MinPlayers
+			}
+
+			if sample.MaxPlayers > current.MaxPlayers {
+				current.MaxPlayers = sample.MaxPlayers
+			}
+
+			current.AvgPlayers = (current.AvgPlayers*float64(current.OnlineSamples) +
+				sample.AvgPlayers*float64(sample.OnlineSamples)) /
+				float64(current.OnlineSamples+sample.OnlineSamples)
+		}
+
+		current.Samples += sample.Samples
+		current.OnlineSamples += sample.OnlineSamples
+	}
+
+	return merged
+}
+
+func percentage(part int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return round(float64(part) / float64(total) * 100)
+}
+
+// round rounds a value to two decimal places
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,240 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package serverhistory
+
+import (
+	"github.com/sniddunc/refractor/internal/game"
+	"github.com/sniddunc/refractor/internal/mock"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/internal/server"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/pkg/log"
+	"github.com/sniddunc/refractor/refractor"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// start is midnight UTC on January 1st 2021, so it lines up with minute, hour and day buckets
+const start int64 = 1609459200
+
+func newTestServerService() refractor.ServerService {
+	testLogger, _ := log.NewLogger(true, false)
+
+	gameService := game.NewGameService()
+	gameService.AddGame(mock.NewMockGame())
+
+	return server.NewServerService(mock.NewMockServerRepository(mock.GetMockServers()), gameService, nil, testLogger)
+}
+
+func Test_serverHistoryService_SampleServers(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	serverService := newTestServerService()
+	serverService.CreateServerData(1, mock.NewMockGame().GetName())
+	serverService.OnServerOnline(1)
+	serverService.OnPlayerListUpdate(1, mock.NewMockGame().GetConfig(), []*refractor.Player{
+		{PlayerID: 1, PlayFabID: "AAA"},
+		{PlayerID: 2, PlayFabID: "BBB"},
+	})
+
+	mockSamples := map[int64]map[int64]*refractor.ServerStatusSample{}
+	serverHistoryService := NewServerHistoryService(mock.NewMockServerHistoryRepository(mockSamples), serverService,
+		testLogger)
+
+	now := time.Unix(start+30, 0)
+	serverHistoryService.SampleServers(now)
+
+	// A second round within the same interval should be skipped
+	serverHistoryService.SampleServers(now.Add(10 * time.Second))
+
+	expected := map[int64]map[int64]*refractor.ServerStatusSample{
+		1: {
+			start: {ServerID: 1, Timestamp: start, Samples: 1, OnlineSamples: 1, MinPlayers: 2, MaxPlayers: 2,
+				AvgPlayers: 2},
+		},
+		2: {
+			start: {ServerID: 2, Timestamp: start, Samples: 1},
+		},
+	}
+
+	assert.Equal(t, expected, mockSamples)
+}
+
+func Test_serverHistoryService_GetServerHistory(t *testing.T) {
+	// Server 1 was online with 2, 4 and 6 players for the first three minutes, offline for the fourth minute and
+	// back online with 8 players in the second hour. The first hour of the previous day was downsampled.
+	samples := []*refractor.ServerStatusSample{
+		{Timestamp: start - 86400, Samples: 60, OnlineSamples: 30, MinPlayers: 1, MaxPlayers: 3, AvgPlayers: 2,
+			Downsampled: true},
+		{Timestamp: start, Samples: 1, OnlineSamples: 1, MinPlayers: 2, MaxPlayers: 2, AvgPlayers: 2},
+		{Timestamp: start + 60, Samples: 1, OnlineSamples: 1, MinPlayers: 4, MaxPlayers: 4, AvgPlayers: 4},
+		{Timestamp: start + 120, Samples: 1, OnlineSamples: 1, MinPlayers: 6, MaxPlayers: 6, AvgPlayers: 6},
+		{Timestamp: start + 180, Samples: 1},
+		{Timestamp: start + 3600, Samples: 1, OnlineSamples: 1, MinPlayers: 8, MaxPlayers: 8, AvgPlayers: 8},
+	}
+
+	tests := []struct {
+		name           string
+		serverID       int64
+		body           params.GetServerHistoryParams
+		wantStatusCode int
+		wantHistory    *refractor.ServerHistory
+	}{
+		{
+			name:           "serverhistory.getserverhistory.1",
+			serverID:       1,
+			body:           params.GetServerHistoryParams{Bucket: params.HistoryBucketMinute, From: start, To: start + 240},
+			wantStatusCode: http.StatusOK,
+			wantHistory: &refractor.ServerHistory{
+				ServerID: 1,
+				Bucket:   params.HistoryBucketMinute,
+				From:     start,
+				To:       start + 240,
+				Uptime:   75,
+				Buckets: []*refractor.ServerHistoryBucket{
+					{Timestamp: start, MinPlayers: 2, MaxPlayers: 2, AvgPlayers: 2, Uptime: 100},
+					{Timestamp: start + 60, MinPlayers: 4, MaxPlayers: 4, AvgPlayers: 4, Uptime: 100},
+					{Timestamp: start + 120, MinPlayers: 6, MaxPlayers: 6, AvgPlayers: 6, Uptime: 100},
+					{Timestamp: start + 180, Uptime: 0},
+				},
+			},
+		},
+		{
+			name:           "serverhistory.getserverhistory.2",
+			serverID:       1,
+			body:           params.GetServerHistoryParams{Bucket: params.HistoryBucketHour, From: start + 1, To: start + 7200},
+			wantStatusCode: http.StatusOK,
+			wantHistory: &refractor.ServerHistory{
+				ServerID: 1,
+				Bucket:   params.HistoryBucketHour,
+				From:     start,
+				To:       start + 7200,
+				Uptime:   80,
+				Buckets: []*refractor.ServerHistoryBucket{
+					{Timestamp: start, MinPlayers: 2, MaxPlayers: 6, AvgPlayers: 4, Uptime: 75},
+					{Timestamp: start + 3600, MinPlayers: 8, MaxPlayers: 8, AvgPlayers: 8, Uptime: 100},
+				},
+			},
+		},
+		{
+			name:           "serverhistory.getserverhistory.3",
+			serverID:       1,
+			body:           params.GetServerHistoryParams{Bucket: params.HistoryBucketDay, From: start - 86400, To: start + 86400},
+			wantStatusCode: http.StatusOK,
+			wantHistory: &refractor.ServerHistory{
+				ServerID: 1,
+				Bucket:   params.HistoryBucketDay,
+				From:     start - 86400,
+				To:       start + 86400,
+				Uptime:   52.31,
+				Buckets: []*refractor.ServerHistoryBucket{
+					{Timestamp: start - 86400, MinPlayers: 1, MaxPlayers: 3, AvgPlayers: 2, Uptime: 50},
+					{Timestamp: start, MinPlayers: 2, MaxPlayers: 8, AvgPlayers: 5, Uptime: 80},
+				},
+			},
+		},
+		{
+			name:           "serverhistory.getserverhistory.4",
+			serverID:       2,
+			body:           params.GetServerHistoryParams{Bucket: params.HistoryBucketMinute, From: start, To: start + 240},
+			wantStatusCode: http.StatusOK,
+			wantHistory: &refractor.ServerHistory{
+				ServerID: 2,
+				Bucket:   params.HistoryBucketMinute,
+				From:     start,
+				To:       start + 240,
+				Buckets:  []*refractor.ServerHistoryBucket{},
+			},
+		},
+		{
+			name:           "serverhistory.getserverhistory.5",
+			serverID:       1,
+			body:           params.GetServerHistoryParams{Bucket: params.HistoryBucketMinute, From: start - 2*86400, To: start},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "serverhistory.getserverhistory.6",
+			serverID:       3,
+			body:           params.GetServerHistoryParams{Bucket: params.HistoryBucketMinute, From: start, To: start + 240},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testLogger, _ := log.NewLogger(true, false)
+
+			mockSamples := map[int64]map[int64]*refractor.ServerStatusSample{1: {}}
+			for _, sample := range samples {
+				created := *sample
+				created.ServerID = 1
+				mockSamples[1][sample.Timestamp] = &created
+			}
+
+			serverHistoryService := NewServerHistoryService(mock.NewMockServerHistoryRepository(mockSamples),
+				newTestServerService(), testLogger)
+
+			history, res := serverHistoryService.GetServerHistory(tt.serverID, tt.body)
+			assert.Equal(t, tt.wantStatusCode, res.StatusCode, "Wrong status code. Message: %s", res.Message)
+			assert.Equal(t, tt.wantHistory, history)
+		})
+	}
+}
+
+func Test_serverHistoryService_Downsample(t *testing.T) {
+	testLogger, _ := log.NewLogger(true, false)
+
+	now := time.Unix(start, 0).Add(config.ServerHistoryRetention).Add(90 * time.Minute)
+
+	// The samples in the first hour are past the retention period and get merged. The sample in the second hour is
+	// past the retention period too, but the rest of its hour isn't yet so it is kept as it is.
+	mockSamples := map[int64]map[int64]*refractor.ServerStatusSample{
+		1: {
+			start - 3600: {ServerID: 1, Timestamp: start - 3600, Samples: 60, OnlineSamples: 60, MinPlayers: 1,
+				MaxPlayers: 9, AvgPlayers: 5, Downsampled: true},
+			start: {ServerID: 1, Timestamp: start, Samples: 1, OnlineSamples: 1, MinPlayers: 4, MaxPlayers: 4,
+				AvgPlayers: 4},
+			start + 60: {ServerID: 1, Timestamp: start + 60, Samples: 1},
+			start + 120: {ServerID: 1, Timestamp: start + 120, Samples: 1, OnlineSamples: 1, MinPlayers: 2,
+				MaxPlayers: 2, AvgPlayers: 2},
+			start + 3600: {ServerID: 1, Timestamp: start + 3600, Samples: 1, OnlineSamples: 1, MinPlayers: 3,
+				MaxPlayers: 3, AvgPlayers: 3},
+			now.Unix() - 60: {ServerID: 1, Timestamp: now.Unix() - 60, Samples: 1},
+		},
+	}
+
+	serverHistoryService := NewServerHistoryService(mock.NewMockServerHistoryRepository(mockSamples),
+		newTestServerService(), testLogger)
+
+	serverHistoryService.Downsample(now)
+
+	expected := map[int64]map[int64]*refractor.ServerStatusSample{
+		1: {
+			start - 3600: {ServerID: 1, Timestamp: start - 3600, Samples: 60, OnlineSamples: 60, MinPlayers: 1,
+				MaxPlayers: 9, AvgPlayers: 5, Downsampled: true},
+			start: {ServerID: 1, Timestamp: start, Samples: 3, OnlineSamples: 2, MinPlayers: 2, MaxPlayers: 4,
+				AvgPlayers: 3, Downsampled: true},
+			start + 3600: {ServerID: 1, Timestamp: start + 3600, Samples: 1, OnlineSamples: 1, MinPlayers: 3,
+				MaxPlayers: 3, AvgPlayers: 3},
+			now.Unix() - 60: {ServerID: 1, Timestamp: now.Unix() - 60, Samples: 1},
+		},
+	}
+
+	assert.Equal(t, expected, mockSamples)
+}
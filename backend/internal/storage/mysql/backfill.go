@@ -0,0 +1,239 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/refractor"
+	"strings"
+)
+
+type backfillRepo struct {
+	db *sql.DB
+}
+
+func NewBackfillRepository(db *sql.DB) refractor.BackfillRepository {
+	return &backfillRepo{
+		db: db,
+	}
+}
+
+// gameIDColumns holds the Players columns which can be used to identify a player by their game ID
+var gameIDColumns = map[string]bool{
+	"PlayFabID": true,
+	"MCUUID":    true,
+	"SteamID":   true,
+}
+
+func (r *backfillRepo) FindPlayerID(gameIDField string, gameID string) (int64, error) {
+	if !gameIDColumns[gameIDField] {
+		return 0, fmt.Errorf("invalid player game ID field: %s", gameIDField)
+	}
+
+	query := fmt.Sprintf("SELECT PlayerID FROM Players WHERE %s = ?;", gameIDField)
+
+	var playerID int64
+	if err := r.db.QueryRow(query, gameID).Scan(&playerID); err != nil {
+		return 0, wrapError(err)
+	}
+
+	return playerID, nil
+}
+
+// CreatePlayer creates a player whose name was first recorded at the given time
+func (r *backfillRepo) CreatePlayer(gameIDField string, gameID string, name string, timestamp int64) (int64, error) {
+	if !gameIDColumns[gameIDField] {
+		return 0, fmt.Errorf("invalid player game ID field: %s", gameIDField)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	query := fmt.Sprintf("INSERT INTO Players (%s, LastSeen) VALUES (?, ?);", gameIDField)
+
+	res, err := tx.Exec(query, gameID, timestamp)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, wrapError(err)
+	}
+
+	playerID, err := res.LastInsertId()
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, wrapError(err)
+	}
+
+	query = "INSERT INTO PlayerNames (PlayerID, Name, DateRecorded) VALUES (?, ?, ?);"
+
+	if _, err := tx.Exec(query, playerID, sanitizeName(name), timestamp); err != nil {
+		_ = tx.Rollback()
+		return 0, wrapError(err)
+	}
+
+	return playerID, tx.Commit()
+}
+
+func (r *backfillRepo) RecordName(playerID int64, name string, timestamp int64) error {
+	query := `INSERT INTO PlayerNames (PlayerID, Name, DateRecorded) VALUES (?, ?, ?)
+			ON DUPLICATE KEY UPDATE DateRecorded = GREATEST(DateRecorded, VALUES(DateRecorded));`
+
+	if _, err := r.db.Exec(query, playerID, sanitizeName(name), timestamp); err != nil {
+		return wrapError(err)
+	}
+
+	return nil
+}
+
+func (r *backfillRepo) RecordSession(session *refractor.DBPlayerSession) (bool, error) {
+	query := `INSERT INTO PlayerSessions (PlayerID, ServerID, JoinTime, QuitTime)
+			SELECT ?, ?, ?, ? FROM DUAL WHERE NOT EXISTS(
+				SELECT 1 FROM PlayerSessions WHERE PlayerID = ? AND ServerID = ? AND JoinTime = ?
+			);`
+
+	res, err := r.db.Exec(query, session.PlayerID, session.ServerID, session.JoinTime, session.QuitTime,
+		session.PlayerID, session.ServerID, session.JoinTime)
+	if err != nil {
+		return false, wrapError(err)
+	}
+
+	created, err := rowsWereAffected(res)
+	if err != nil || created {
+		return created, err
+	}
+
+	// The session already exists, but it may have been closed early by a backfill which ended while the player was
+	// still online
+	query = `UPDATE PlayerSessions SET QuitTime = ?
+			WHERE PlayerID = ? AND ServerID = ? AND JoinTime = ? AND QuitTime < ?;`
+
+	if _, err := r.db.Exec(query, session.QuitTime, session.PlayerID, session.ServerID, session.JoinTime,
+		session.QuitTime); err != nil {
+		return false, wrapError(err)
+	}
+
+	return false, nil
+}
+
+func (r *backfillRepo) ExtendLastSession(playerID int64, serverID int64, quitTime int64) (bool, error) {
+	query := `UPDATE PlayerSessions ps
+			INNER JOIN (
+				SELECT SessionID FROM PlayerSessions
+				WHERE PlayerID = ? AND ServerID = ? AND JoinTime < ?
+				ORDER BY JoinTime DESC
+				LIMIT 1
+			) latest ON latest.SessionID = ps.SessionID
+			SET ps.QuitTime = ?
+			WHERE ps.QuitTime < ?;`
+
+	res, err := r.db.Exec(query, playerID, serverID, quitTime, quitTime, quitTime)
+	if err != nil {
+		return false, wrapError(err)
+	}
+
+	return rowsWereAffected(res)
+}
+
+func (r *backfillRepo) RecordChatMessage(message *refractor.ChatMessage, key string) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, wrapError(err)
+	}
+
+	created, err := r.recordChatMessage(tx, message, key)
+	if err != nil {
+		_ = tx.Rollback()
+		return false, wrapError(err)
+	}
+
+	return created, tx.Commit()
+}
+
+func (r *backfillRepo) recordChatMessage(tx *sql.Tx, message *refractor.ChatMessage, key string) (bool, error) {
+	var exists bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM ChatMessages WHERE BackfillKey = ?);", key).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	if exists {
+		return false, nil
+	}
+
+	// Messages recorded live are stored with the time they were received rather than the time in the log, so the
+	// closest unclaimed message within the match window is taken to be the same message
+	window := int64(config.BackfillChatMatchWindow)
+
+	query := `UPDATE ChatMessages SET BackfillKey = ?
+			WHERE BackfillKey IS NULL AND PlayerID = ? AND ServerID = ? AND Message = ? AND
+				DateRecorded BETWEEN FROM_UNIXTIME(?) AND FROM_UNIXTIME(?)
+			ORDER BY ABS(UNIX_TIMESTAMP(DateRecorded) - ?)
+			LIMIT 1;`
+
+	res, err := tx.Exec(query, key, message.PlayerID, message.ServerID, message.Message,
+		message.DateRecorded-window, message.DateRecorded+window, message.DateRecorded)
+	if err != nil {
+		return false, err
+	}
+
+	if claimed, err := rowsWereAffected(res); err != nil || claimed {
+		return false, err
+	}
+
+	query = `INSERT INTO ChatMessages (PlayerID, ServerID, Message, DateRecorded, Channel, PlayerName, BackfillKey)
+			VALUES (?, ?, ?, FROM_UNIXTIME(?), ?, ?, ?);`
+
+	if _, err := tx.Exec(query, message.PlayerID, message.ServerID, message.Message, message.DateRecorded,
+		message.Channel, message.PlayerName, key); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *backfillRepo) UpdateLastSeen(playerID int64, timestamp int64) error {
+	query := "UPDATE Players SET LastSeen = GREATEST(LastSeen, ?) WHERE PlayerID = ?;"
+
+	if _, err := r.db.Exec(query, timestamp, playerID); err != nil {
+		return wrapError(err)
+	}
+
+	return nil
+}
+
+func rowsWereAffected(res sql.Result) (bool, error) {
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, wrapError(err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// sanitizeName strips invalid unicode characters from a name. If nothing is left, a known string is used instead.
+func sanitizeName(name string) string {
+	name = strings.ToValidUTF8(name, "")
+
+	if name == "" {
+		return "Invalid name"
+	}
+
+	return name
+}
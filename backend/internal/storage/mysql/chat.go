@@ -38,10 +38,14 @@ func (r *chatRepo) Create(message *refractor.ChatMessage) (*refractor.ChatMessag
 		message.DateRecorded = time.Now().Unix()
 	}
 
-	query := `INSERT INTO ChatMessages (PlayerID, ServerID, Message, DateRecorded, Flagged)
-			VALUES (?, ?, ?, FROM_UNIXTIME(?), ?);`
-
-	res, err := r.db.Exec(query, message.PlayerID, message.ServerID, message.Message, message.DateRecorded, message.Flagged)
+	query := `INSERT INTO ChatMessages (PlayerID, UserID, TargetPlayerID, ServerID, Message, DateRecorded, Flagged,
+				Channel, PlayerName)
+			VALUES (?, ?, ?, ?, ?, FROM_UNIXTIME(?), ?, ?, ?);`
+
+	// Messages are sent either by a player or by a staff member, so only one of PlayerID and UserID is stored
+	res, err := r.db.Exec(query, toNullInt64(message.PlayerID), toNullInt64(message.UserID),
+		toNullInt64(message.TargetPlayerID), message.ServerID, message.Message, message.DateRecorded, message.Flagged,
+		message.Channel, message.PlayerName)
 	if err != nil {
 		return nil, wrapError(err)
 	}
@@ -57,12 +61,13 @@ func (r *chatRepo) Create(message *refractor.ChatMessage) (*refractor.ChatMessag
 }
 
 func (r *chatRepo) FindByID(id int64) (*refractor.ChatMessage, error) {
-	query := `SELECT MessageID, PlayerID, ServerID, Message, UNIX_TIMESTAMP(DateRecorded) AS DateRecorded, Flagged
+	query := `SELECT MessageID, PlayerID, ServerID, Message, UNIX_TIMESTAMP(DateRecorded) AS DateRecorded, Flagged,
+				Channel, PlayerName, UserID, TargetPlayerID
 			FROM ChatMessages WHERE MessageID = ?;`
 
 	row := r.db.QueryRow(query, id)
 
-	var message *refractor.ChatMessage
+	message := &refractor.ChatMessage{}
 
 	if err := r.scanRow(row, message); err != nil {
 		return nil, wrapError(err)
@@ -102,25 +107,34 @@ func (r *chatRepo) Search(args refractor.FindArgs, limit int, offset int, getPla
 		       ServerID,
 		       Message,
 		       UNIX_TIMESTAMP(DateRecorded) AS DateRecorded,
-		       Flagged
+		       Flagged,
+		       Channel,
+		       PlayerName,
+		       UserID,
+		       TargetPlayerID
 		FROM ChatMessages cm
 		WHERE
-			(? IS NULL OR cm.PlayerID = ?) AND
+			(? IS NULL OR cm.PlayerID = ? OR cm.TargetPlayerID = ?) AND
 			(? IS NULL OR cm.ServerID = ?) AND
+			(? IS NULL OR cm.Channel = ?) AND
+			(? IS NULL OR cm.PlayerName LIKE CONCAT('%', ?, '%')) AND
 		    (DateRecorded BETWEEN FROM_UNIXTIME(?) AND FROM_UNIXTIME(?)) AND
 			IF(? IS NOT NULL AND ? != '', MATCH(Message) AGAINST(? IN NATURAL LANGUAGE MODE), TRUE)
 		LIMIT ? OFFSET ?;
 	`
 
 	var (
-		playerID  = args["PlayerID"]
-		serverID  = args["ServerID"]
-		message   = args["Message"]
-		startDate = args["StartDate"]
-		endDate   = args["EndDate"]
+		playerID   = args["PlayerID"]
+		serverID   = args["ServerID"]
+		channel    = args["Channel"]
+		playerName = args["PlayerName"]
+		message    = args["Message"]
+		startDate  = args["StartDate"]
+		endDate    = args["EndDate"]
 	)
 
-	rows, err := r.db.Query(query, playerID, playerID, serverID, serverID, startDate, endDate, message, message, message, limit, offset)
+	rows, err := r.db.Query(query, playerID, playerID, playerID, serverID, serverID, channel, channel, playerName, playerName,
+		startDate, endDate, message, message, message, limit, offset)
 	if err != nil {
 		return 0, nil, wrapError(err)
 	}
@@ -134,13 +148,16 @@ func (r *chatRepo) Search(args refractor.FindArgs, limit int, offset int, getPla
 			return 0, nil, wrapError(err)
 		}
 
-		// Get player's name
-		currentName, _, err := getPlayerName(foundMessage.PlayerID)
-		if err != nil {
-			return 0, nil, wrapError(err)
+		// Messages recorded before the sender's name was stored alongside them fall back to the current name
+		if foundMessage.PlayerName == "" && !foundMessage.SentByUser {
+			currentName, _, err := getPlayerName(foundMessage.PlayerID)
+			if err != nil {
+				return 0, nil, wrapError(err)
+			}
+
+			foundMessage.PlayerName = currentName
 		}
 
-		foundMessage.PlayerName = currentName
 		foundMessages = append(foundMessages, foundMessage)
 	}
 
@@ -150,13 +167,16 @@ func (r *chatRepo) Search(args refractor.FindArgs, limit int, offset int, getPla
 			COUNT(1) AS Count
 		FROM ChatMessages cm
 		WHERE
-			(? IS NULL OR cm.PlayerID = ?) AND
+			(? IS NULL OR cm.PlayerID = ? OR cm.TargetPlayerID = ?) AND
 			(? IS NULL OR cm.ServerID = ?) AND
+			(? IS NULL OR cm.Channel = ?) AND
+			(? IS NULL OR cm.PlayerName LIKE CONCAT('%', ?, '%')) AND
 		    (DateRecorded BETWEEN FROM_UNIXTIME(?) AND FROM_UNIXTIME(?)) AND
 			IF(? IS NOT NULL AND ? != '', MATCH(Message) AGAINST(? IN NATURAL LANGUAGE MODE), TRUE)
 	`
 
-	row := r.db.QueryRow(query, playerID, playerID, serverID, serverID, startDate, endDate, message, message, message)
+	row := r.db.QueryRow(query, playerID, playerID, playerID, serverID, serverID, channel, channel, playerName, playerName,
+		startDate, endDate, message, message, message)
 
 	var count int
 	if err := row.Scan(&count); err != nil {
@@ -166,11 +186,232 @@ func (r *chatRepo) Search(args refractor.FindArgs, limit int, offset int, getPla
 	return count, foundMessages, nil
 }
 
+func (r *chatRepo) SetFlagged(id int64, flagged bool) error {
+	query := "UPDATE ChatMessages SET Flagged = ? WHERE MessageID = ?;"
+
+	if _, err := r.db.Exec(query, flagged, id); err != nil {
+		return wrapError(err)
+	}
+
+	return nil
+}
+
+func (r *chatRepo) FindFlagged(limit int, offset int) (int, []*refractor.ChatMessage, error) {
+	query := `SELECT MessageID, PlayerID, ServerID, Message, UNIX_TIMESTAMP(DateRecorded) AS DateRecorded, Flagged,
+				Channel, PlayerName, UserID, TargetPlayerID
+			FROM ChatMessages WHERE Flagged = TRUE ORDER BY DateRecorded ASC, MessageID ASC LIMIT ? OFFSET ?;`
+
+	foundMessages, err := r.queryMessages(query, limit, offset)
+	if err != nil {
+		return 0, nil, wrapError(err)
+	}
+
+	row := r.db.QueryRow("SELECT COUNT(1) FROM ChatMessages WHERE Flagged = TRUE;")
+
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return 0, nil, wrapError(err)
+	}
+
+	return count, foundMessages, nil
+}
+
+// GetContext returns up to before messages sent on the same server before message, message itself and up to after
+// messages sent after it, in the order they were sent.
+func (r *chatRepo) GetContext(message *refractor.ChatMessage, before int, after int) ([]*refractor.ChatMessage, error) {
+	query := `SELECT MessageID, PlayerID, ServerID, Message, UNIX_TIMESTAMP(DateRecorded) AS DateRecorded, Flagged,
+				Channel, PlayerName, UserID, TargetPlayerID
+			FROM ChatMessages
+			WHERE ServerID = ? AND
+				(DateRecorded < FROM_UNIXTIME(?) OR (DateRecorded = FROM_UNIXTIME(?) AND MessageID < ?))
+			ORDER BY DateRecorded DESC, MessageID DESC LIMIT ?;`
+
+	beforeMessages, err := r.queryMessages(query, message.ServerID, message.DateRecorded, message.DateRecorded,
+		message.MessageID, before)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	query = `SELECT MessageID, PlayerID, ServerID, Message, UNIX_TIMESTAMP(DateRecorded) AS DateRecorded, Flagged,
+				Channel, PlayerName, UserID, TargetPlayerID
+			FROM ChatMessages
+			WHERE ServerID = ? AND
+				(DateRecorded > FROM_UNIXTIME(?) OR (DateRecorded = FROM_UNIXTIME(?) AND MessageID > ?))
+			ORDER BY DateRecorded ASC, MessageID ASC LIMIT ?;`
+
+	afterMessages, err := r.queryMessages(query, message.ServerID, message.DateRecorded, message.DateRecorded,
+		message.MessageID, after)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	// beforeMessages is newest first so it's reversed while building the result
+	contextMessages := make([]*refractor.ChatMessage, 0, len(beforeMessages)+len(afterMessages)+1)
+
+	for i := len(beforeMessages) - 1; i >= 0; i-- {
+		contextMessages = append(contextMessages, beforeMessages[i])
+	}
+
+	contextMessages = append(contextMessages, message)
+	contextMessages = append(contextMessages, afterMessages...)
+
+	return contextMessages, nil
+}
+
+func (r *chatRepo) AttachToInfraction(messageID int64, infractionID int64) error {
+	query := "INSERT INTO InfractionChatMessages (InfractionID, MessageID) VALUES (?, ?);"
+
+	if _, err := r.db.Exec(query, infractionID, messageID); err != nil {
+		return wrapError(err)
+	}
+
+	return nil
+}
+
+func (r *chatRepo) FindByInfractionID(infractionID int64) ([]*refractor.ChatMessage, error) {
+	query := `SELECT cm.MessageID, cm.PlayerID, cm.ServerID, cm.Message, UNIX_TIMESTAMP(cm.DateRecorded) AS DateRecorded,
+				cm.Flagged, cm.Channel, cm.PlayerName, cm.UserID,
+				cm.TargetPlayerID
+			FROM InfractionChatMessages icm
+			JOIN ChatMessages cm ON cm.MessageID = icm.MessageID
+			WHERE icm.InfractionID = ?
+			ORDER BY cm.DateRecorded ASC, cm.MessageID ASC;`
+
+	foundMessages, err := r.queryMessages(query, infractionID)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return foundMessages, nil
+}
+
+// StreamTranscript reads a server's chat messages along with join and quit markers taken from player sessions, oldest
+// first, and passes each one to handler as it is read. Player names are the names used at the time of each entry. For
+// chat messages this is the name stored with the message when there is one.
+func (r *chatRepo) StreamTranscript(serverID int64, startDate int64, endDate int64, handler refractor.TranscriptEntryHandler) error {
+	query := `
+		SELECT
+			t.Type,
+			t.Timestamp,
+			COALESCE(t.PlayerID, 0) AS PlayerID,
+			COALESCE(t.UserID, 0) AS UserID,
+			COALESCE(t.TargetPlayerID, 0) AS TargetPlayerID,
+			COALESCE(NULLIF(t.PlayerName, ''), (
+				SELECT pn.Name FROM PlayerNames pn WHERE pn.PlayerID = t.PlayerID
+				ORDER BY pn.DateRecorded <= t.Timestamp DESC,
+					IF(pn.DateRecorded <= t.Timestamp, -pn.DateRecorded, pn.DateRecorded) ASC
+				LIMIT 1
+			), '') AS PlayerName,
+			COALESCE((
+				SELECT pn.Name FROM PlayerNames pn WHERE pn.PlayerID = t.TargetPlayerID
+				ORDER BY pn.DateRecorded DESC
+				LIMIT 1
+			), '') AS TargetName,
+			t.Message
+		FROM (
+			SELECT
+				CASE
+					WHEN TargetPlayerID IS NOT NULL THEN 'WHISPER'
+					WHEN UserID IS NOT NULL THEN 'STAFF_CHAT'
+					ELSE 'CHAT'
+				END AS Type,
+				UNIX_TIMESTAMP(DateRecorded) AS Timestamp,
+				PlayerID, UserID, TargetPlayerID, MessageID AS RefID, Message, PlayerName
+			FROM ChatMessages
+			WHERE ServerID = ? AND DateRecorded BETWEEN FROM_UNIXTIME(?) AND FROM_UNIXTIME(?)
+			UNION ALL
+			SELECT 'JOIN', JoinTime, PlayerID, NULL, NULL, SessionID, '', ''
+			FROM PlayerSessions
+			WHERE ServerID = ? AND JoinTime BETWEEN ? AND ?
+			UNION ALL
+			SELECT 'QUIT', QuitTime, PlayerID, NULL, NULL, SessionID, '', ''
+			FROM PlayerSessions
+			WHERE ServerID = ? AND QuitTime BETWEEN ? AND ?
+		) t
+		ORDER BY t.Timestamp ASC, t.RefID ASC;
+	`
+
+	rows, err := r.db.Query(query, serverID, startDate, endDate, serverID, startDate, endDate, serverID, startDate,
+		endDate)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry := &refractor.TranscriptEntry{}
+
+		if err := rows.Scan(&entry.Type, &entry.Timestamp, &entry.PlayerID, &entry.UserID, &entry.TargetPlayerID,
+			&entry.PlayerName, &entry.TargetName, &entry.Message); err != nil {
+			return wrapError(err)
+		}
+
+		if err := handler(entry); err != nil {
+			return err
+		}
+	}
+
+	return wrapError(rows.Err())
+}
+
+func (r *chatRepo) queryMessages(query string, args ...interface{}) ([]*refractor.ChatMessage, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	foundMessages := []*refractor.ChatMessage{}
+
+	for rows.Next() {
+		message := &refractor.ChatMessage{}
+
+		if err := r.scanRows(rows, message); err != nil {
+			return nil, err
+		}
+
+		foundMessages = append(foundMessages, message)
+	}
+
+	return foundMessages, rows.Err()
+}
+
 // Scan helpers
 func (r *chatRepo) scanRow(row *sql.Row, msg *refractor.ChatMessage) error {
-	return row.Scan(&msg.MessageID, &msg.PlayerID, &msg.ServerID, &msg.Message, &msg.DateRecorded, &msg.Flagged)
+	var playerID, userID, targetPlayerID sql.NullInt64
+
+	if err := row.Scan(&msg.MessageID, &playerID, &msg.ServerID, &msg.Message, &msg.DateRecorded, &msg.Flagged,
+		&msg.Channel, &msg.PlayerName, &userID, &targetPlayerID); err != nil {
+		return err
+	}
+
+	setChatMessageSender(msg, playerID, userID)
+	msg.TargetPlayerID = targetPlayerID.Int64
+	return nil
 }
 
 func (r *chatRepo) scanRows(rows *sql.Rows, msg *refractor.ChatMessage) error {
-	return rows.Scan(&msg.MessageID, &msg.PlayerID, &msg.ServerID, &msg.Message, &msg.DateRecorded, &msg.Flagged)
+	var playerID, userID, targetPlayerID sql.NullInt64
+
+	if err := rows.Scan(&msg.MessageID, &playerID, &msg.ServerID, &msg.Message, &msg.DateRecorded, &msg.Flagged,
+		&msg.Channel, &msg.PlayerName, &userID, &targetPlayerID); err != nil {
+		return err
+	}
+
+	setChatMessageSender(msg, playerID, userID)
+	msg.TargetPlayerID = targetPlayerID.Int64
+	return nil
+}
+
+func setChatMessageSender(msg *refractor.ChatMessage, playerID sql.NullInt64, userID sql.NullInt64) {
+	msg.PlayerID = playerID.Int64
+	msg.UserID = userID.Int64
+	msg.SentByUser = userID.Valid
+}
+
+func toNullInt64(value int64) sql.NullInt64 {
+	return sql.NullInt64{
+		Int64: value,
+		Valid: value != 0,
+	}
 }
@@ -0,0 +1,131 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mysql
+
+import (
+	"database/sql"
+	"github.com/sniddunc/refractor/refractor"
+)
+
+type chatFilterRepo struct {
+	db *sql.DB
+}
+
+func NewChatFilterRepository(db *sql.DB) refractor.ChatFilterRepository {
+	return &chatFilterRepo{
+		db: db,
+	}
+}
+
+func (r *chatFilterRepo) Create(rule *refractor.DBChatFilterRule) (*refractor.ChatFilterRule, error) {
+	query := `INSERT INTO ChatFilterRules (Game, ServerID, Type, Pattern, Action, Reason, Duration, Enabled, CreatedBy)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);`
+
+	res, err := r.db.Exec(query, rule.Game, rule.ServerID, rule.Type, rule.Pattern, rule.Action, rule.Reason,
+		rule.Duration, rule.Enabled, rule.CreatedBy)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	rule.RuleID = id
+
+	return rule.ChatFilterRule(), nil
+}
+
+func (r *chatFilterRepo) FindByID(id int64) (*refractor.ChatFilterRule, error) {
+	query := "SELECT * FROM ChatFilterRules WHERE RuleID = ?;"
+
+	row := r.db.QueryRow(query, id)
+
+	rule := &refractor.DBChatFilterRule{}
+	if err := r.scanRow(row, rule); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return rule.ChatFilterRule(), nil
+}
+
+func (r *chatFilterRepo) FindAll() ([]*refractor.ChatFilterRule, error) {
+	query := "SELECT * FROM ChatFilterRules;"
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var foundRules []*refractor.ChatFilterRule
+
+	for rows.Next() {
+		rule := &refractor.DBChatFilterRule{}
+
+		if err := r.scanRows(rows, rule); err != nil {
+			return nil, wrapError(err)
+		}
+
+		foundRules = append(foundRules, rule.ChatFilterRule())
+	}
+
+	return foundRules, nil
+}
+
+func (r *chatFilterRepo) Update(id int64, args refractor.UpdateArgs) (*refractor.ChatFilterRule, error) {
+	query, values := buildUpdateQuery("ChatFilterRules", id, "RuleID", args)
+
+	if _, err := r.db.Exec(query, values...); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return r.FindByID(id)
+}
+
+func (r *chatFilterRepo) Delete(id int64) error {
+	query := "DELETE FROM ChatFilterRules WHERE RuleID = ?;"
+
+	res, err := r.db.Exec(query, id)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return wrapError(err)
+	}
+
+	if rowsAffected <= 0 {
+		return wrapError(sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// Scan helpers
+func (r *chatFilterRepo) scanRow(row *sql.Row, rule *refractor.DBChatFilterRule) error {
+	return row.Scan(&rule.RuleID, &rule.Game, &rule.ServerID, &rule.Type, &rule.Pattern, &rule.Action, &rule.Reason,
+		&rule.Duration, &rule.Enabled, &rule.CreatedBy)
+}
+
+func (r *chatFilterRepo) scanRows(rows *sql.Rows, rule *refractor.DBChatFilterRule) error {
+	return rows.Scan(&rule.RuleID, &rule.Game, &rule.ServerID, &rule.Type, &rule.Pattern, &rule.Action, &rule.Reason,
+		&rule.Duration, &rule.Enabled, &rule.CreatedBy)
+}
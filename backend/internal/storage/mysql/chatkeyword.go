@@ -0,0 +1,118 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mysql
+
+import (
+	"database/sql"
+	"github.com/sniddunc/refractor/refractor"
+)
+
+type chatKeywordRepo struct {
+	db *sql.DB
+}
+
+func NewChatKeywordRepository(db *sql.DB) refractor.ChatKeywordRepository {
+	return &chatKeywordRepo{
+		db: db,
+	}
+}
+
+func (r *chatKeywordRepo) Create(keyword *refractor.ChatKeyword) (*refractor.ChatKeyword, error) {
+	query := "INSERT INTO ChatKeywords (UserID, Keyword) VALUES (?, ?);"
+
+	res, err := r.db.Exec(query, keyword.UserID, keyword.Keyword)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	keyword.KeywordID = id
+
+	return keyword, nil
+}
+
+func (r *chatKeywordRepo) FindByID(id int64) (*refractor.ChatKeyword, error) {
+	query := "SELECT * FROM ChatKeywords WHERE KeywordID = ?;"
+
+	row := r.db.QueryRow(query, id)
+
+	keyword := &refractor.ChatKeyword{}
+	if err := row.Scan(&keyword.KeywordID, &keyword.UserID, &keyword.Keyword); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return keyword, nil
+}
+
+func (r *chatKeywordRepo) FindByUserID(userID int64) ([]*refractor.ChatKeyword, error) {
+	query := "SELECT * FROM ChatKeywords WHERE UserID = ? ORDER BY Keyword;"
+
+	return r.findMany(query, userID)
+}
+
+func (r *chatKeywordRepo) FindAll() ([]*refractor.ChatKeyword, error) {
+	query := "SELECT * FROM ChatKeywords;"
+
+	return r.findMany(query)
+}
+
+func (r *chatKeywordRepo) Delete(id int64) error {
+	query := "DELETE FROM ChatKeywords WHERE KeywordID = ?;"
+
+	res, err := r.db.Exec(query, id)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return wrapError(err)
+	}
+
+	if rowsAffected <= 0 {
+		return wrapError(sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+func (r *chatKeywordRepo) findMany(query string, args ...interface{}) ([]*refractor.ChatKeyword, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var foundKeywords []*refractor.ChatKeyword
+
+	for rows.Next() {
+		keyword := &refractor.ChatKeyword{}
+
+		if err := rows.Scan(&keyword.KeywordID, &keyword.UserID, &keyword.Keyword); err != nil {
+			return nil, wrapError(err)
+		}
+
+		foundKeywords = append(foundKeywords, keyword)
+	}
+
+	return foundKeywords, nil
+}
@@ -0,0 +1,68 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mysql
+
+import (
+	"database/sql"
+	"github.com/sniddunc/refractor/refractor"
+)
+
+type chatSpamRepo struct {
+	db *sql.DB
+}
+
+func NewChatSpamRepository(db *sql.DB) refractor.ChatSpamRepository {
+	return &chatSpamRepo{
+		db: db,
+	}
+}
+
+func (r *chatSpamRepo) FindByServerID(serverID int64) (*refractor.ChatSpamSettings, error) {
+	query := "SELECT * FROM ChatSpamSettings WHERE ServerID = ?;"
+
+	row := r.db.QueryRow(query, serverID)
+
+	settings := &refractor.ChatSpamSettings{}
+	if err := row.Scan(&settings.ServerID, &settings.Enabled, &settings.FloodMessages, &settings.FloodWindow,
+		&settings.FloodAction, &settings.RepeatCount, &settings.RepeatAction, &settings.CapsPercent,
+		&settings.CapsMinLength, &settings.CapsAction, &settings.MuteDuration, &settings.UpdatedBy); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return settings, nil
+}
+
+// Save stores a server's settings, replacing any settings which were previously stored for it.
+func (r *chatSpamRepo) Save(s *refractor.ChatSpamSettings) error {
+	query := `INSERT INTO ChatSpamSettings (ServerID, Enabled, FloodMessages, FloodWindow, FloodAction, RepeatCount,
+			RepeatAction, CapsPercent, CapsMinLength, CapsAction, MuteDuration, UpdatedBy)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE Enabled = VALUES(Enabled), FloodMessages = VALUES(FloodMessages),
+			FloodWindow = VALUES(FloodWindow), FloodAction = VALUES(FloodAction), RepeatCount = VALUES(RepeatCount),
+			RepeatAction = VALUES(RepeatAction), CapsPercent = VALUES(CapsPercent),
+			CapsMinLength = VALUES(CapsMinLength), CapsAction = VALUES(CapsAction),
+			MuteDuration = VALUES(MuteDuration), UpdatedBy = VALUES(UpdatedBy);`
+
+	if _, err := r.db.Exec(query, s.ServerID, s.Enabled, s.FloodMessages, s.FloodWindow, s.FloodAction,
+		s.RepeatCount, s.RepeatAction, s.CapsPercent, s.CapsMinLength, s.CapsAction, s.MuteDuration,
+		s.UpdatedBy); err != nil {
+		return wrapError(err)
+	}
+
+	return nil
+}
@@ -38,10 +38,12 @@ func (r *infractionRepo) Create(infraction *refractor.DBInfraction) (*refractor.
 		infraction.Timestamp = time.Now().Unix()
 	}
 
-	query := "INSERT INTO Infractions(PlayerID, UserID, ServerID, Type, Reason, Duration, Timestamp, SystemAction) VALUES (?, ?, ?, ?, ?, ?, ?, ?);"
+	query := `INSERT INTO Infractions(PlayerID, UserID, ServerID, Type, Reason, Duration, Timestamp, SystemAction,
+			IssuerPlayerID) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);`
 
-	res, err := r.db.Exec(query, infraction.PlayerID, infraction.UserID, infraction.ServerID, infraction.Type,
-		infraction.Reason, infraction.Duration, infraction.Timestamp, infraction.SystemAction)
+	res, err := r.db.Exec(query, infraction.PlayerID, toNullInt64(infraction.UserID), infraction.ServerID,
+		infraction.Type, infraction.Reason, infraction.Duration, infraction.Timestamp, infraction.SystemAction,
+		toNullInt64(infraction.IssuerPlayerID))
 	if err != nil {
 		return nil, wrapError(err)
 	}
@@ -207,7 +209,8 @@ func (r *infractionRepo) Search(args refractor.FindArgs, limit int, offset int)
 	query := `
 		SELECT
 			res.*,
-			u.Username AS StaffName
+			COALESCE(u.Username, (SELECT Name FROM PlayerNames WHERE PlayerID = res.IssuerPlayerID
+				ORDER BY DateRecorded DESC LIMIT 1), '') AS StaffName
 		FROM (
 			SELECT
 				i.*
@@ -220,7 +223,7 @@ func (r *infractionRepo) Search(args refractor.FindArgs, limit int, offset int)
 				(? IS NULL OR i.ServerID = ?) AND
 				(? IS NULL OR s.Game = ?)
 			) res
-		JOIN Users u ON res.UserID = u.UserID
+		LEFT JOIN Users u ON res.UserID = u.UserID
 		GROUP BY InfractionID
 		LIMIT ? OFFSET ?;
 	`
@@ -244,8 +247,7 @@ func (r *infractionRepo) Search(args refractor.FindArgs, limit int, offset int)
 		dbinfr := &refractor.DBInfraction{}
 
 		var staffName string
-		if err := rows.Scan(&dbinfr.InfractionID, &dbinfr.PlayerID, &dbinfr.UserID, &dbinfr.ServerID,
-			&dbinfr.Type, &dbinfr.Reason, &dbinfr.Duration, &dbinfr.Timestamp, &dbinfr.SystemAction, &staffName); err != nil {
+		if err := scanInfraction(rows, dbinfr, &staffName); err != nil {
 			return 0, nil, wrapError(err)
 		}
 
@@ -297,9 +299,10 @@ func (r *infractionRepo) GetRecent(count int) ([]*refractor.Infraction, error) {
 	query := `
 		SELECT
 			i.*,
-			u.Username AS StaffName
+			COALESCE(u.Username, (SELECT Name FROM PlayerNames WHERE PlayerID = i.IssuerPlayerID
+				ORDER BY DateRecorded DESC LIMIT 1), '') AS StaffName
 		FROM Infractions i
-		INNER JOIN Users u ON u.UserID = i.UserID
+		LEFT JOIN Users u ON u.UserID = i.UserID
 		ORDER BY Timestamp DESC LIMIT ?;
 	`
 
@@ -314,8 +317,7 @@ func (r *infractionRepo) GetRecent(count int) ([]*refractor.Infraction, error) {
 		dbinfr := &refractor.DBInfraction{}
 
 		var staffName string
-		if err := rows.Scan(&dbinfr.InfractionID, &dbinfr.PlayerID, &dbinfr.UserID, &dbinfr.ServerID,
-			&dbinfr.Type, &dbinfr.Reason, &dbinfr.Duration, &dbinfr.Timestamp, &dbinfr.SystemAction, &staffName); err != nil {
+		if err := scanInfraction(rows, dbinfr, &staffName); err != nil {
 			return nil, wrapError(err)
 		}
 
@@ -358,11 +360,31 @@ func (r *infractionRepo) GetCountByPlayerID(playerID int64) (int, error) {
 
 // Scan helpers
 func (r *infractionRepo) scanRow(row *sql.Row, infr *refractor.DBInfraction) error {
-	return row.Scan(&infr.InfractionID, &infr.PlayerID, &infr.UserID, &infr.ServerID, &infr.Type, &infr.Reason,
-		&infr.Duration, &infr.Timestamp, &infr.SystemAction)
+	return scanInfraction(row, infr)
 }
 
 func (r *infractionRepo) scanRows(row *sql.Rows, infr *refractor.DBInfraction) error {
-	return row.Scan(&infr.InfractionID, &infr.PlayerID, &infr.UserID, &infr.ServerID, &infr.Type, &infr.Reason,
-		&infr.Duration, &infr.Timestamp, &infr.SystemAction)
+	return scanInfraction(row, infr)
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanInfraction scans an Infractions row followed by any extra selected columns. UserID and IssuerPlayerID are
+// nullable, so they are scanned as 0 if they are NULL.
+func scanInfraction(row scanner, infr *refractor.DBInfraction, extra ...interface{}) error {
+	var userID, issuerPlayerID sql.NullInt64
+
+	dest := append([]interface{}{&infr.InfractionID, &infr.PlayerID, &userID, &infr.ServerID, &infr.Type,
+		&infr.Reason, &infr.Duration, &infr.Timestamp, &infr.SystemAction, &issuerPlayerID}, extra...)
+
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+
+	infr.UserID = userID.Int64
+	infr.IssuerPlayerID = issuerPlayerID.Int64
+
+	return nil
 }
@@ -0,0 +1,123 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"github.com/sniddunc/refractor/refractor"
+)
+
+type matchStatsRepo struct {
+	db *sql.DB
+}
+
+func NewMatchStatsRepository(db *sql.DB) refractor.MatchStatsRepository {
+	return &matchStatsRepo{
+		db: db,
+	}
+}
+
+// Create stores the stats of every player in a match. Either all of them are stored or none are.
+func (r *matchStatsRepo) Create(stats []*refractor.MatchStats) error {
+	tx, err := r.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	query := `INSERT INTO MatchStats (ServerID, PlayerID, MatchStart, MatchEnd, Kills, Deaths, Teamkills)
+			VALUES (?, ?, ?, ?, ?, ?, ?);`
+
+	for _, s := range stats {
+		res, err := tx.Exec(query, s.ServerID, s.PlayerID, s.MatchStart, s.MatchEnd, s.Kills, s.Deaths, s.Teamkills)
+		if err != nil {
+			_ = tx.Rollback()
+			return wrapError(err)
+		}
+
+		if s.MatchStatsID, err = res.LastInsertId(); err != nil {
+			_ = tx.Rollback()
+			return wrapError(err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindByPlayerID returns a player's most recent match stats, most recent first.
+func (r *matchStatsRepo) FindByPlayerID(playerID int64, limit int) ([]*refractor.MatchStats, error) {
+	query := "SELECT * FROM MatchStats WHERE PlayerID = ? ORDER BY MatchEnd DESC LIMIT ?;"
+
+	rows, err := r.db.Query(query, playerID, limit)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var foundStats []*refractor.MatchStats
+
+	for rows.Next() {
+		stats := &refractor.MatchStats{}
+
+		if err := rows.Scan(&stats.MatchStatsID, &stats.ServerID, &stats.PlayerID, &stats.MatchStart, &stats.MatchEnd,
+			&stats.Kills, &stats.Deaths, &stats.Teamkills); err != nil {
+			return nil, wrapError(err)
+		}
+
+		foundStats = append(foundStats, stats)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, wrapError(err)
+	}
+
+	if len(foundStats) == 0 {
+		return nil, refractor.ErrNotFound
+	}
+
+	return foundStats, nil
+}
+
+func (r *matchStatsRepo) FindTeamkillSettings(serverID int64) (*refractor.TeamkillSettings, error) {
+	query := "SELECT * FROM TeamkillSettings WHERE ServerID = ?;"
+
+	row := r.db.QueryRow(query, serverID)
+
+	settings := &refractor.TeamkillSettings{}
+	if err := row.Scan(&settings.ServerID, &settings.Enabled, &settings.Threshold, &settings.Action,
+		&settings.BanDuration, &settings.UpdatedBy); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return settings, nil
+}
+
+// SaveTeamkillSettings stores a server's settings, replacing any settings which were previously stored for it.
+func (r *matchStatsRepo) SaveTeamkillSettings(s *refractor.TeamkillSettings) error {
+	query := `INSERT INTO TeamkillSettings (ServerID, Enabled, Threshold, Action, BanDuration, UpdatedBy)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE Enabled = VALUES(Enabled), Threshold = VALUES(Threshold), Action = VALUES(Action),
+			BanDuration = VALUES(BanDuration), UpdatedBy = VALUES(UpdatedBy);`
+
+	if _, err := r.db.Exec(query, s.ServerID, s.Enabled, s.Threshold, s.Action, s.BanDuration,
+		s.UpdatedBy); err != nil {
+		return wrapError(err)
+	}
+
+	return nil
+}
@@ -62,6 +62,10 @@ func Setup(db *sql.DB) error {
 			Address VARCHAR(15) NOT NULL,
 		    RCONPort VARCHAR(5) NOT NULL,
 		    RCONPassword VARCHAR(128) NOT NULL,
+			WarnMessageTemplate VARCHAR(256) NOT NULL DEFAULT '',
+			MuteAction VARCHAR(16) NOT NULL DEFAULT '',
+			EventSource VARCHAR(16) NOT NULL DEFAULT '',
+			LogFilePath VARCHAR(256) NOT NULL DEFAULT '',
 			
 			PRIMARY KEY (ServerID)
 		);
@@ -73,6 +77,38 @@ func Setup(db *sql.DB) error {
 		return fmt.Errorf("could not create Servers table. Error: %v", err)
 	}
 
+	if err := addColumnIfNotExists(tx, "Servers", "WarnMessageTemplate", "VARCHAR(256) NOT NULL DEFAULT ''"); err != nil {
+		if err = tx.Rollback(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("could not add WarnMessageTemplate column to Servers table. Error: %v", err)
+	}
+
+	if err := addColumnIfNotExists(tx, "Servers", "MuteAction", "VARCHAR(16) NOT NULL DEFAULT ''"); err != nil {
+		if err = tx.Rollback(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("could not add MuteAction column to Servers table. Error: %v", err)
+	}
+
+	if err := addColumnIfNotExists(tx, "Servers", "EventSource", "VARCHAR(16) NOT NULL DEFAULT ''"); err != nil {
+		if err = tx.Rollback(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("could not add EventSource column to Servers table. Error: %v", err)
+	}
+
+	if err := addColumnIfNotExists(tx, "Servers", "LogFilePath", "VARCHAR(256) NOT NULL DEFAULT ''"); err != nil {
+		if err = tx.Rollback(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("could not add LogFilePath column to Servers table. Error: %v", err)
+	}
+
 	// Create players table
 	if _, err := tx.Exec(`
 		CREATE TABLE IF NOT EXISTS Players(
@@ -81,6 +117,7 @@ func Setup(db *sql.DB) error {
 		    MCUUID VARCHAR(36) UNIQUE,
 			LastSeen BIGINT DEFAULT 0,
 		    Watched BOOLEAN DEFAULT FALSE,
+		    Trusted BOOLEAN DEFAULT FALSE,
 			
 			PRIMARY KEY (PlayerID)
 		);
@@ -92,6 +129,22 @@ func Setup(db *sql.DB) error {
 		return fmt.Errorf("could not create Players table. Error: %v", err)
 	}
 
+	if err := addColumnIfNotExists(tx, "Players", "Trusted", "BOOLEAN DEFAULT FALSE"); err != nil {
+		if err = tx.Rollback(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("could not add Trusted column to Players table. Error: %v", err)
+	}
+
+	if err := addColumnIfNotExists(tx, "Players", "SteamID", "VARCHAR(17) UNIQUE"); err != nil {
+		if err = tx.Rollback(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("could not add SteamID column to Players table. Error: %v", err)
+	}
+
 	// Create player names table
 	if _, err := tx.Exec(`
 		CREATE TABLE IF NOT EXISTS PlayerNames(
@@ -126,18 +179,20 @@ func Setup(db *sql.DB) error {
 		CREATE TABLE IF NOT EXISTS Infractions (
 			InfractionID INT NOT NULL AUTO_INCREMENT,
 			PlayerID INT NOT NULL,
-			UserID INT NOT NULL,
+			UserID INT,
 			ServerID INT NOT NULL,
 			Type ENUM("WARNING", "MUTE", "KICK", "BAN") NOT NULL,
 			Reason TEXT,
 			Duration INT,
 			Timestamp INT UNSIGNED NOT NULL,
 			SystemAction BOOLEAN DEFAULT FALSE,
+			IssuerPlayerID INT,
 			
 			PRIMARY KEY (InfractionID),
 			FOREIGN KEY (PlayerID) REFERENCES Players(PlayerID),
 			FOREIGN KEY (UserID) REFERENCES Users(UserID),
-			FOREIGN KEY (ServerID) REFERENCES Servers(ServerID)
+			FOREIGN KEY (ServerID) REFERENCES Servers(ServerID),
+			FOREIGN KEY (IssuerPlayerID) REFERENCES Players(PlayerID)
 		);
 	`); err != nil {
 		if err = tx.Rollback(); err != nil {
@@ -147,18 +202,45 @@ func Setup(db *sql.DB) error {
 		return fmt.Errorf("could not create Infractions table. Error: %v", err)
 	}
 
+	// Infractions issued by admins in-game have no Refractor user. The in-game admin is linked as a player instead.
+	if _, err := tx.Exec(`
+		ALTER TABLE Infractions MODIFY UserID INT;
+	`); err != nil {
+		if err = tx.Rollback(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("could not alter Infractions table. Error: %v", err)
+	}
+
+	if err := addColumnIfNotExists(tx, "Infractions", "IssuerPlayerID",
+		"INT, ADD FOREIGN KEY (IssuerPlayerID) REFERENCES Players(PlayerID)"); err != nil {
+		if err = tx.Rollback(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("could not add IssuerPlayerID column to Infractions table. Error: %v", err)
+	}
+
 	// Create chat messages table
 	if _, err := tx.Exec(`
 		CREATE TABLE IF NOT EXISTS ChatMessages (
 			MessageID INT NOT NULL AUTO_INCREMENT,
-			PlayerID INT NOT NULL,
+			PlayerID INT,
+			UserID INT,
+			TargetPlayerID INT,
 			ServerID INT NOT NULL,
 			Message TEXT NOT NULL,
 			DateRecorded TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			Flagged BOOLEAN DEFAULT FALSE,
+			Channel VARCHAR(32) NOT NULL DEFAULT '',
+			PlayerName VARCHAR(128) CHARACTER SET utf8mb4 NOT NULL DEFAULT '',
+			BackfillKey CHAR(64) UNIQUE,
 			
 			PRIMARY KEY (MessageID),
 			FOREIGN KEY (PlayerID) REFERENCES Players(PlayerID),
+			FOREIGN KEY (UserID) REFERENCES Users(UserID),
+			FOREIGN KEY (TargetPlayerID) REFERENCES Players(PlayerID),
 			FOREIGN KEY (ServerID) REFERENCES Servers(ServerID),
 		    FULLTEXT (Message)
 		);
@@ -170,9 +252,297 @@ func Setup(db *sql.DB) error {
 		return fmt.Errorf("could not create ChatMessages table. Error: %v", err)
 	}
 
+	// Add columns which were introduced after the chat messages table was first created
+	for _, column := range []struct{ name, definition string }{
+		{"Channel", "VARCHAR(32) NOT NULL DEFAULT ''"},
+		{"PlayerName", "VARCHAR(128) CHARACTER SET utf8mb4 NOT NULL DEFAULT ''"},
+		{"UserID", "INT, ADD FOREIGN KEY (UserID) REFERENCES Users(UserID)"},
+		{"TargetPlayerID", "INT, ADD FOREIGN KEY (TargetPlayerID) REFERENCES Players(PlayerID)"},
+		{"BackfillKey", "CHAR(64) UNIQUE"},
+	} {
+		if err := addColumnIfNotExists(tx, "ChatMessages", column.name, column.definition); err != nil {
+			if err = tx.Rollback(); err != nil {
+				return err
+			}
+
+			return fmt.Errorf("could not add %s column to ChatMessages table. Error: %v", column.name, err)
+		}
+	}
+
+	// Messages sent by staff have a UserID instead of a PlayerID
+	if _, err := tx.Exec(`
+		ALTER TABLE ChatMessages MODIFY PlayerID INT;
+	`); err != nil {
+		if err = tx.Rollback(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("could not alter ChatMessages table. Error: %v", err)
+	}
+
+	// Create player sessions table
+	if _, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS PlayerSessions (
+			SessionID INT NOT NULL AUTO_INCREMENT,
+			PlayerID INT NOT NULL,
+			ServerID INT NOT NULL,
+			JoinTime BIGINT NOT NULL,
+			QuitTime BIGINT,
+
+			PRIMARY KEY (SessionID),
+			FOREIGN KEY (PlayerID) REFERENCES Players(PlayerID),
+			FOREIGN KEY (ServerID) REFERENCES Servers(ServerID) ON DELETE CASCADE,
+			INDEX (ServerID, QuitTime)
+		);
+	`); err != nil {
+		if err = tx.Rollback(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("could not create PlayerSessions table. Error: %v", err)
+	}
+
+	// Create player merges table
+	if _, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS PlayerMerges (
+			MergeID INT NOT NULL AUTO_INCREMENT,
+			SourcePlayerID INT NOT NULL,
+			TargetPlayerID INT NOT NULL,
+			UserID INT NOT NULL,
+			Timestamp BIGINT NOT NULL,
+			Undone BOOLEAN DEFAULT FALSE,
+			Snapshot LONGTEXT NOT NULL,
+			UndoneBy INT,
+			UndoneAt BIGINT,
+
+			PRIMARY KEY (MergeID),
+			FOREIGN KEY (UserID) REFERENCES Users(UserID),
+			FOREIGN KEY (UndoneBy) REFERENCES Users(UserID)
+		);
+	`); err != nil {
+		if err = tx.Rollback(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("could not create PlayerMerges table. Error: %v", err)
+	}
+
+	// Add columns which were introduced after the player merges table was first created
+	for _, column := range []struct{ name, definition string }{
+		{"UndoneBy", "INT, ADD FOREIGN KEY (UndoneBy) REFERENCES Users(UserID)"},
+		{"UndoneAt", "BIGINT"},
+	} {
+		if err := addColumnIfNotExists(tx, "PlayerMerges", column.name, column.definition); err != nil {
+			if err = tx.Rollback(); err != nil {
+				return err
+			}
+
+			return fmt.Errorf("could not add %s column to PlayerMerges table. Error: %v", column.name, err)
+		}
+	}
+
+	// Create player data requests table
+	if _, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS PlayerDataRequests (
+			RequestID INT NOT NULL AUTO_INCREMENT,
+			PlayerID INT NOT NULL,
+			UserID INT NOT NULL,
+			Type ENUM("EXPORT", "ERASURE") NOT NULL,
+			Timestamp BIGINT NOT NULL,
+
+			PRIMARY KEY (RequestID),
+			FOREIGN KEY (UserID) REFERENCES Users(UserID)
+		);
+	`); err != nil {
+		if err = tx.Rollback(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("could not create PlayerDataRequests table. Error: %v", err)
+	}
+
+	// Create chat filter rules table
+	if _, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS ChatFilterRules (
+			RuleID INT NOT NULL AUTO_INCREMENT,
+			Game VARCHAR(32) NOT NULL DEFAULT "",
+			ServerID INT,
+			Type ENUM("REGEX", "WORDLIST", "LEETSPEAK") NOT NULL,
+			Pattern TEXT NOT NULL,
+			Action ENUM("FLAG", "ALERT", "WARN", "MUTE") NOT NULL,
+			Reason TEXT NOT NULL,
+			Duration INT DEFAULT 0,
+			Enabled BOOLEAN DEFAULT TRUE,
+			CreatedBy INT NOT NULL,
+
+			PRIMARY KEY (RuleID),
+			FOREIGN KEY (ServerID) REFERENCES Servers(ServerID) ON DELETE CASCADE,
+			FOREIGN KEY (CreatedBy) REFERENCES Users(UserID)
+		);
+	`); err != nil {
+		if err = tx.Rollback(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("could not create ChatFilterRules table. Error: %v", err)
+	}
+
+	// Create chat spam settings table
+	if _, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS ChatSpamSettings (
+			ServerID INT NOT NULL,
+			Enabled BOOLEAN DEFAULT TRUE,
+			FloodMessages INT NOT NULL,
+			FloodWindow INT NOT NULL,
+			FloodAction ENUM("NONE", "ALERT", "WARN", "MUTE", "KICK") NOT NULL,
+			RepeatCount INT NOT NULL,
+			RepeatAction ENUM("NONE", "ALERT", "WARN", "MUTE", "KICK") NOT NULL,
+			CapsPercent INT NOT NULL,
+			CapsMinLength INT NOT NULL,
+			CapsAction ENUM("NONE", "ALERT", "WARN", "MUTE", "KICK") NOT NULL,
+			MuteDuration INT DEFAULT 0,
+			UpdatedBy INT NOT NULL,
+
+			PRIMARY KEY (ServerID),
+			FOREIGN KEY (ServerID) REFERENCES Servers(ServerID) ON DELETE CASCADE,
+			FOREIGN KEY (UpdatedBy) REFERENCES Users(UserID)
+		);
+	`); err != nil {
+		if err = tx.Rollback(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("could not create ChatSpamSettings table. Error: %v", err)
+	}
+
+	// Create chat keywords table
+	if _, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS ChatKeywords (
+			KeywordID INT NOT NULL AUTO_INCREMENT,
+			UserID INT NOT NULL,
+			Keyword VARCHAR(64) CHARACTER SET utf8mb4 NOT NULL,
+
+			PRIMARY KEY (KeywordID),
+			UNIQUE (UserID, Keyword),
+			FOREIGN KEY (UserID) REFERENCES Users(UserID) ON DELETE CASCADE
+		);
+	`); err != nil {
+		if err = tx.Rollback(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("could not create ChatKeywords table. Error: %v", err)
+	}
+
+	// Create infraction chat messages table
+	if _, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS InfractionChatMessages (
+			InfractionID INT NOT NULL,
+			MessageID INT NOT NULL,
+
+			PRIMARY KEY (InfractionID, MessageID),
+			FOREIGN KEY (InfractionID) REFERENCES Infractions(InfractionID) ON DELETE CASCADE,
+			FOREIGN KEY (MessageID) REFERENCES ChatMessages(MessageID) ON DELETE CASCADE
+		);
+	`); err != nil {
+		if err = tx.Rollback(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("could not create InfractionChatMessages table. Error: %v", err)
+	}
+
+	// Create match stats table
+	if _, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS MatchStats (
+			MatchStatsID INT NOT NULL AUTO_INCREMENT,
+			ServerID INT NOT NULL,
+			PlayerID INT NOT NULL,
+			MatchStart BIGINT NOT NULL,
+			MatchEnd BIGINT NOT NULL,
+			Kills INT DEFAULT 0,
+			Deaths INT DEFAULT 0,
+			Teamkills INT DEFAULT 0,
+
+			PRIMARY KEY (MatchStatsID),
+			FOREIGN KEY (ServerID) REFERENCES Servers(ServerID) ON DELETE CASCADE,
+			FOREIGN KEY (PlayerID) REFERENCES Players(PlayerID),
+			INDEX (PlayerID, MatchEnd)
+		);
+	`); err != nil {
+		if err = tx.Rollback(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("could not create MatchStats table. Error: %v", err)
+	}
+
+	// Create teamkill settings table
+	if _, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS TeamkillSettings (
+			ServerID INT NOT NULL,
+			Enabled BOOLEAN DEFAULT TRUE,
+			Threshold INT NOT NULL,
+			Action ENUM("ALERT", "WARN", "KICK", "BAN") NOT NULL,
+			BanDuration INT DEFAULT 0,
+			UpdatedBy INT NOT NULL,
+
+			PRIMARY KEY (ServerID),
+			FOREIGN KEY (ServerID) REFERENCES Servers(ServerID) ON DELETE CASCADE,
+			FOREIGN KEY (UpdatedBy) REFERENCES Users(UserID)
+		);
+	`); err != nil {
+		if err = tx.Rollback(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("could not create TeamkillSettings table. Error: %v", err)
+	}
+
+	// Create server status samples table
+	if _, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS ServerStatusSamples (
+			ServerID INT NOT NULL,
+			Timestamp BIGINT NOT NULL,
+			Samples INT NOT NULL,
+			OnlineSamples INT NOT NULL,
+			MinPlayers INT DEFAULT 0,
+			MaxPlayers INT DEFAULT 0,
+			AvgPlayers DOUBLE DEFAULT 0,
+			Downsampled BOOLEAN DEFAULT FALSE,
+
+			PRIMARY KEY (ServerID, Timestamp),
+			FOREIGN KEY (ServerID) REFERENCES Servers(ServerID) ON DELETE CASCADE
+		);
+	`); err != nil {
+		if err = tx.Rollback(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("could not create ServerStatusSamples table. Error: %v", err)
+	}
+
 	return tx.Commit()
 }
 
+// addColumnIfNotExists adds a column to an existing table if the table does not have it yet.
+func addColumnIfNotExists(tx *sql.Tx, table string, column string, definition string) error {
+	query := `SELECT EXISTS(SELECT 1 FROM information_schema.COLUMNS
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?);`
+
+	var exists bool
+	if err := tx.QueryRow(query, table, column).Scan(&exists); err != nil {
+		return err
+	}
+
+	if exists {
+		return nil
+	}
+
+	_, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", table, column, definition))
+	return err
+}
+
 // MySQL query builder and helper functions
 func wrapError(err error) error {
 	switch err {
@@ -217,6 +587,25 @@ func buildFindQuery(table string, args map[string]interface{}) (string, []interf
 	return query, values
 }
 
+// buildInPlaceholders returns a comma separated list of placeholders, one per ID, for use in an IN clause along with
+// the IDs converted to a slice of interface{} values.
+func buildInPlaceholders(ids []int64) (string, []interface{}) {
+	var placeholders string
+	var values []interface{}
+
+	for _, id := range ids {
+		placeholders += "?, "
+		values = append(values, id)
+	}
+
+	// Cut off trailing comma and space
+	if len(placeholders) > 0 {
+		placeholders = placeholders[:len(placeholders)-2]
+	}
+
+	return placeholders, values
+}
+
 func buildUpdateQuery(table string, id int64, idName string, args map[string]interface{}) (string, []interface{}) {
 	var query = fmt.Sprintf("UPDATE %s SET ", table)
 	var values []interface{}
@@ -39,9 +39,9 @@ func NewPlayerRepository(db *sql.DB) refractor.PlayerRepository {
 // The following values must be present on the passed in Player reference for Create to function properly:
 // PlayFabID, LastSeen and CurrentName.
 func (r *playerRepo) Create(player *refractor.DBPlayer) error {
-	query := "INSERT INTO Players (PlayFabID, MCUUID, LastSeen) VALUES (?, ?, ?);"
+	query := "INSERT INTO Players (PlayFabID, MCUUID, SteamID, LastSeen) VALUES (?, ?, ?, ?);"
 
-	res, err := r.db.Exec(query, player.PlayFabID, player.MCUUID, player.LastSeen)
+	res, err := r.db.Exec(query, player.PlayFabID, player.MCUUID, player.SteamID, player.LastSeen)
 	if err != nil {
 		return wrapError(err)
 	}
@@ -241,21 +241,66 @@ func (r *playerRepo) Update(id int64, args refractor.UpdateArgs) (*refractor.Pla
 	return updatedPlayer.Player(), nil
 }
 
-func (r *playerRepo) SearchByName(name string, limit int, offset int) (int, []*refractor.Player, error) {
+// FindNameCandidates returns names (current and historical) which could be a match for the provided name. Candidates
+// are names containing the search term, names which sound similar and names sharing the first two characters of the
+// term. Exact and substring matches are returned before the looser matches so that they are never cut off by max,
+// regardless of when the player was last seen. The caller is responsible for scoring and ranking the candidates.
+func (r *playerRepo) FindNameCandidates(name string, max int) ([]*refractor.PlayerNameMatch, error) {
 	query := `
 		SELECT
-			res.*
-		FROM (
-			SELECT p.* FROM PlayerNames pn
-			INNER JOIN Players p ON p.PlayerID = pn.PlayerID
-			WHERE pn.Name LIKE CONCAT('%', ?, '%')
-			LIMIT ? OFFSET ?
-		) res
-		GROUP BY PlayerID
-		ORDER BY LastSeen DESC
+			pn.PlayerID,
+			pn.Name,
+			pn.DateRecorded = (SELECT MAX(DateRecorded) FROM PlayerNames WHERE PlayerID = pn.PlayerID) AS Current,
+			p.LastSeen
+		FROM PlayerNames pn
+		INNER JOIN Players p ON p.PlayerID = pn.PlayerID
+		WHERE
+			pn.Name LIKE CONCAT('%', ?, '%') OR
+			SOUNDEX(pn.Name) = SOUNDEX(?) OR
+			pn.Name LIKE CONCAT(LEFT(?, 2), '%')
+		ORDER BY
+			pn.Name = ? DESC,
+			pn.Name LIKE CONCAT('%', ?, '%') DESC,
+			SOUNDEX(pn.Name) = SOUNDEX(?) DESC,
+			p.LastSeen DESC
+		LIMIT ?;
 	`
 
-	rows, err := r.db.Query(query, name, limit, offset)
+	rows, err := r.db.Query(query, name, name, name, name, name, name, max)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	var candidates []*refractor.PlayerNameMatch
+
+	for rows.Next() {
+		candidate := &refractor.PlayerNameMatch{}
+
+		if err := rows.Scan(&candidate.PlayerID, &candidate.Name, &candidate.Current, &candidate.LastSeen); err != nil {
+			return nil, wrapError(err)
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, nil
+}
+
+// SearchByGameID returns players whose game identifier contains the provided term.
+// field must be the name of a player game ID column: PlayFabID, MCUUID or SteamID.
+func (r *playerRepo) SearchByGameID(field string, term string, limit int, offset int) (int, []*refractor.Player, error) {
+	if field != "PlayFabID" && field != "MCUUID" && field != "SteamID" {
+		return 0, nil, fmt.Errorf("invalid player game ID field: %s", field)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT * FROM Players
+		WHERE %s LIKE CONCAT('%%', ?, '%%')
+		ORDER BY LastSeen DESC
+		LIMIT ? OFFSET ?;
+	`, field)
+
+	rows, err := r.db.Query(query, term, limit, offset)
 	if err != nil {
 		return 0, nil, wrapError(err)
 	}
@@ -283,17 +328,9 @@ func (r *playerRepo) SearchByName(name string, limit int, offset int) (int, []*r
 	}
 
 	// Get number of possible matches
-	query = `
-		SELECT
-			COUNT(1) AS MatchCount
-		FROM (
-			SELECT 1 FROM PlayerNames pn
-			WHERE pn.Name LIKE CONCAT('%', ?, '%')
-		    GROUP BY PlayerID
-		) AS matches;
-	`
+	query = fmt.Sprintf("SELECT COUNT(1) FROM Players WHERE %s LIKE CONCAT('%%', ?, '%%');", field)
 
-	row := r.db.QueryRow(query, name)
+	row := r.db.QueryRow(query, term)
 
 	var count int
 
@@ -333,11 +370,34 @@ func (r *playerRepo) GetPlayerNames(playerID int64) (string, []string, error) {
 	return names[0], names[1:], nil
 }
 
+// GetNameAt returns the name a player was using at the given unix timestamp. This is the most recently recorded name
+// at or before the timestamp. If every name was recorded after it, the earliest name is used instead. Since a name's
+// DateRecorded is bumped whenever the player uses it again, this is a best effort for players who switch back and
+// forth between names.
+func (r *playerRepo) GetNameAt(playerID int64, timestamp int64) (string, error) {
+	query := `
+		SELECT Name FROM PlayerNames WHERE PlayerID = ?
+		ORDER BY DateRecorded <= ? DESC, IF(DateRecorded <= ?, -DateRecorded, DateRecorded) ASC
+		LIMIT 1;
+	`
+
+	row := r.db.QueryRow(query, playerID, timestamp, timestamp)
+
+	var name string
+	if err := row.Scan(&name); err != nil {
+		return "", wrapError(err)
+	}
+
+	return name, nil
+}
+
 // Scan helpers
 func (r *playerRepo) scanRow(row *sql.Row, player *refractor.DBPlayer) error {
-	return row.Scan(&player.PlayerID, &player.PlayFabID, &player.MCUUID, &player.LastSeen, &player.Watched)
+	return row.Scan(&player.PlayerID, &player.PlayFabID, &player.MCUUID, &player.LastSeen, &player.Watched,
+		&player.Trusted, &player.SteamID)
 }
 
 func (r *playerRepo) scanRows(rows *sql.Rows, player *refractor.DBPlayer) error {
-	return rows.Scan(&player.PlayerID, &player.PlayFabID, &player.MCUUID, &player.LastSeen, &player.Watched)
+	return rows.Scan(&player.PlayerID, &player.PlayFabID, &player.MCUUID, &player.LastSeen, &player.Watched,
+		&player.Trusted, &player.SteamID)
 }
@@ -0,0 +1,255 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"github.com/sniddunc/refractor/pkg/config"
+	"github.com/sniddunc/refractor/refractor"
+	"time"
+)
+
+type playerDataRepo struct {
+	db *sql.DB
+}
+
+func NewPlayerDataRepository(db *sql.DB) refractor.PlayerDataRepository {
+	return &playerDataRepo{
+		db: db,
+	}
+}
+
+// Export collects everything stored about a player. It is read inside a single transaction so the archive is
+// consistent even if the player is active while it is being built.
+func (r *playerDataRepo) Export(playerID int64) (*refractor.PlayerDataExport, error) {
+	tx, err := r.db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer tx.Rollback()
+
+	player := &refractor.DBPlayer{}
+	if err := scanPlayerRow(tx.QueryRow("SELECT * FROM Players WHERE PlayerID = ?;", playerID), player); err != nil {
+		return nil, wrapError(err)
+	}
+
+	export := &refractor.PlayerDataExport{
+		ExportedAt: time.Now().Unix(),
+		PlayerID:   player.PlayerID,
+		PlayFabID:  player.PlayFabID.String,
+		MCUUID:     player.MCUUID.String,
+		SteamID:    player.SteamID.String,
+		LastSeen:   player.LastSeen,
+	}
+
+	if export.Names, err = selectNameRecords(tx, playerID); err != nil {
+		return nil, wrapError(err)
+	}
+
+	if export.ChatMessages, err = r.selectChatMessages(tx, playerID); err != nil {
+		return nil, wrapError(err)
+	}
+
+	if export.Infractions, err = r.selectInfractions(tx, playerID); err != nil {
+		return nil, wrapError(err)
+	}
+
+	if export.Sessions, err = r.selectSessions(tx, playerID); err != nil {
+		return nil, wrapError(err)
+	}
+
+	if export.MatchStats, err = r.selectMatchStats(tx, playerID); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return export, nil
+}
+
+// Erase anonymizes a player. Their identifiers are removed, their name history is replaced with the pseudonym and
+// the text of their chat messages and of any staff messages whispered to them is blanked out. Infractions and sessions
+// are kept and remain linked to the player ID, which no longer points to anything identifying once the rest is gone.
+// Merge snapshots involving the player are also cleared since they contain copies of the player's identifiers and
+// names.
+func (r *playerDataRepo) Erase(playerID int64, pseudonym string) error {
+	tx, err := r.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	if err := r.erase(tx, playerID, pseudonym); err != nil {
+		_ = tx.Rollback()
+		return wrapError(err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *playerDataRepo) erase(tx *sql.Tx, playerID int64, pseudonym string) error {
+	query := `UPDATE Players SET PlayFabID = NULL, MCUUID = NULL, SteamID = NULL, Watched = FALSE, Trusted = FALSE
+			WHERE PlayerID = ?;`
+	if _, err := tx.Exec(query, playerID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM PlayerNames WHERE PlayerID = ?;", playerID); err != nil {
+		return err
+	}
+
+	query = "INSERT INTO PlayerNames (PlayerID, Name, DateRecorded) VALUES (?, ?, ?);"
+	if _, err := tx.Exec(query, playerID, pseudonym, time.Now().Unix()); err != nil {
+		return err
+	}
+
+	query = "UPDATE ChatMessages SET Message = ?, PlayerName = ? WHERE PlayerID = ?;"
+	if _, err := tx.Exec(query, config.ErasedChatMessage, pseudonym, playerID); err != nil {
+		return err
+	}
+
+	query = "UPDATE ChatMessages SET Message = ? WHERE TargetPlayerID = ?;"
+	if _, err := tx.Exec(query, config.ErasedChatMessage, playerID); err != nil {
+		return err
+	}
+
+	query = "UPDATE PlayerMerges SET Snapshot = '{}' WHERE SourcePlayerID = ? OR TargetPlayerID = ?;"
+	if _, err := tx.Exec(query, playerID, playerID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *playerDataRepo) LogRequest(request *refractor.PlayerDataRequest) error {
+	query := "INSERT INTO PlayerDataRequests (PlayerID, UserID, Type, Timestamp) VALUES (?, ?, ?, ?);"
+
+	res, err := r.db.Exec(query, request.PlayerID, request.UserID, request.Type, request.Timestamp)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return wrapError(err)
+	}
+
+	request.RequestID = id
+
+	return nil
+}
+
+// Helpers
+
+// selectChatMessages returns the messages sent by the player as well as staff messages whispered to them.
+func (r *playerDataRepo) selectChatMessages(tx *sql.Tx, playerID int64) ([]*refractor.ChatMessage, error) {
+	query := `SELECT MessageID, PlayerID, ServerID, Message, UNIX_TIMESTAMP(DateRecorded) AS DateRecorded, Flagged,
+				Channel, PlayerName, UserID, TargetPlayerID
+			FROM ChatMessages WHERE PlayerID = ? OR TargetPlayerID = ? ORDER BY DateRecorded ASC;`
+
+	rows, err := tx.Query(query, playerID, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []*refractor.ChatMessage{}
+
+	for rows.Next() {
+		msg := &refractor.ChatMessage{}
+		var senderID, userID, targetPlayerID sql.NullInt64
+
+		if err := rows.Scan(&msg.MessageID, &senderID, &msg.ServerID, &msg.Message, &msg.DateRecorded,
+			&msg.Flagged, &msg.Channel, &msg.PlayerName, &userID, &targetPlayerID); err != nil {
+			return nil, err
+		}
+
+		setChatMessageSender(msg, senderID, userID)
+		msg.TargetPlayerID = targetPlayerID.Int64
+
+		messages = append(messages, msg)
+	}
+
+	return messages, rows.Err()
+}
+
+func (r *playerDataRepo) selectInfractions(tx *sql.Tx, playerID int64) ([]*refractor.Infraction, error) {
+	rows, err := tx.Query("SELECT * FROM Infractions WHERE PlayerID = ? ORDER BY Timestamp ASC;", playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	infractions := []*refractor.Infraction{}
+
+	for rows.Next() {
+		dbinfr := &refractor.DBInfraction{}
+
+		if err := scanInfraction(rows, dbinfr); err != nil {
+			return nil, err
+		}
+
+		infractions = append(infractions, dbinfr.Infraction())
+	}
+
+	return infractions, rows.Err()
+}
+
+func (r *playerDataRepo) selectSessions(tx *sql.Tx, playerID int64) ([]*refractor.PlayerSession, error) {
+	rows, err := tx.Query("SELECT * FROM PlayerSessions WHERE PlayerID = ? ORDER BY JoinTime ASC;", playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*refractor.PlayerSession{}
+
+	for rows.Next() {
+		session := &refractor.DBPlayerSession{}
+
+		if err := rows.Scan(&session.SessionID, &session.PlayerID, &session.ServerID, &session.JoinTime,
+			&session.QuitTime); err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, session.PlayerSession())
+	}
+
+	return sessions, rows.Err()
+}
+
+func (r *playerDataRepo) selectMatchStats(tx *sql.Tx, playerID int64) ([]*refractor.MatchStats, error) {
+	rows, err := tx.Query("SELECT * FROM MatchStats WHERE PlayerID = ? ORDER BY MatchStart ASC;", playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matchStats := []*refractor.MatchStats{}
+
+	for rows.Next() {
+		stats := &refractor.MatchStats{}
+
+		if err := rows.Scan(&stats.MatchStatsID, &stats.ServerID, &stats.PlayerID, &stats.MatchStart, &stats.MatchEnd,
+			&stats.Kills, &stats.Deaths, &stats.Teamkills); err != nil {
+			return nil, err
+		}
+
+		matchStats = append(matchStats, stats)
+	}
+
+	return matchStats, rows.Err()
+}
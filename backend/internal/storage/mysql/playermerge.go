@@ -0,0 +1,417 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/sniddunc/refractor/refractor"
+	"time"
+)
+
+type playerMergeRepo struct {
+	db *sql.DB
+}
+
+func NewPlayerMergeRepository(db *sql.DB) refractor.PlayerMergeRepository {
+	return &playerMergeRepo{
+		db: db,
+	}
+}
+
+// Merge moves all infractions, chat messages (including whispers sent to the player), sessions and names from the
+// source player to the target player and deletes the source player. Identifiers the target is missing are copied over
+// from the source. Everything happens in a single transaction, and a snapshot of what was moved is stored with the
+// audit record so the merge can be undone.
+func (r *playerMergeRepo) Merge(sourceID int64, targetID int64, userID int64) (*refractor.PlayerMerge, error) {
+	tx, err := r.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	merge, err := r.merge(tx, sourceID, targetID, userID)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, wrapError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return merge, nil
+}
+
+func (r *playerMergeRepo) merge(tx *sql.Tx, sourceID int64, targetID int64, userID int64) (*refractor.PlayerMerge, error) {
+	snapshot := &refractor.PlayerMergeSnapshot{}
+
+	// Lock both players for the duration of the merge
+	query := "SELECT * FROM Players WHERE PlayerID = ? FOR UPDATE;"
+
+	if err := scanPlayerRow(tx.QueryRow(query, sourceID), &snapshot.Source); err != nil {
+		return nil, err
+	}
+
+	if err := scanPlayerRow(tx.QueryRow(query, targetID), &snapshot.TargetBefore); err != nil {
+		return nil, err
+	}
+
+	// Record the IDs of everything which will be moved
+	var err error
+
+	if snapshot.InfractionIDs, err = selectIDs(tx, "SELECT InfractionID FROM Infractions WHERE PlayerID = ?;", sourceID); err != nil {
+		return nil, err
+	}
+
+	if snapshot.ChatMessageIDs, err = selectIDs(tx, "SELECT MessageID FROM ChatMessages WHERE PlayerID = ?;", sourceID); err != nil {
+		return nil, err
+	}
+
+	if snapshot.TargetChatMessageIDs, err = selectIDs(tx, "SELECT MessageID FROM ChatMessages WHERE TargetPlayerID = ?;",
+		sourceID); err != nil {
+		return nil, err
+	}
+
+	if snapshot.SessionIDs, err = selectIDs(tx, "SELECT SessionID FROM PlayerSessions WHERE PlayerID = ?;", sourceID); err != nil {
+		return nil, err
+	}
+
+	if snapshot.MatchStatsIDs, err = selectIDs(tx, "SELECT MatchStatsID FROM MatchStats WHERE PlayerID = ?;", sourceID); err != nil {
+		return nil, err
+	}
+
+	if snapshot.IssuedInfractionIDs, err = selectIDs(tx, "SELECT InfractionID FROM Infractions WHERE IssuerPlayerID = ?;",
+		sourceID); err != nil {
+		return nil, err
+	}
+
+	// Move records over to the target
+	for _, table := range []string{"Infractions", "ChatMessages", "PlayerSessions", "MatchStats"} {
+		query := fmt.Sprintf("UPDATE %s SET PlayerID = ? WHERE PlayerID = ?;", table)
+
+		if _, err := tx.Exec(query, targetID, sourceID); err != nil {
+			return nil, err
+		}
+	}
+
+	query = "UPDATE ChatMessages SET TargetPlayerID = ? WHERE TargetPlayerID = ?;"
+	if _, err := tx.Exec(query, targetID, sourceID); err != nil {
+		return nil, err
+	}
+
+	query = "UPDATE Infractions SET IssuerPlayerID = ? WHERE IssuerPlayerID = ?;"
+	if _, err := tx.Exec(query, targetID, sourceID); err != nil {
+		return nil, err
+	}
+
+	// Move names the target does not already have. Names both players share are kept on the target.
+	if snapshot.SourceNames, err = selectNameRecords(tx, sourceID); err != nil {
+		return nil, err
+	}
+
+	targetNames, err := selectNameRecords(tx, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	hasName := map[string]bool{}
+	for _, record := range targetNames {
+		hasName[record.Name] = true
+	}
+
+	for _, record := range snapshot.SourceNames {
+		if hasName[record.Name] {
+			continue
+		}
+
+		query := "UPDATE PlayerNames SET PlayerID = ? WHERE PlayerID = ? AND Name = ?;"
+		if _, err := tx.Exec(query, targetID, sourceID, record.Name); err != nil {
+			return nil, err
+		}
+
+		snapshot.MovedNames = append(snapshot.MovedNames, record.Name)
+	}
+
+	if _, err := tx.Exec("DELETE FROM PlayerNames WHERE PlayerID = ?;", sourceID); err != nil {
+		return nil, err
+	}
+
+	// The source player must be deleted before its identifiers can be given to the target since they are unique
+	if _, err := tx.Exec("DELETE FROM Players WHERE PlayerID = ?;", sourceID); err != nil {
+		return nil, err
+	}
+
+	target := snapshot.TargetBefore
+	source := snapshot.Source
+
+	if !target.PlayFabID.Valid {
+		target.PlayFabID = source.PlayFabID
+	}
+
+	if !target.MCUUID.Valid {
+		target.MCUUID = source.MCUUID
+	}
+
+	if !target.SteamID.Valid {
+		target.SteamID = source.SteamID
+	}
+
+	if source.LastSeen > target.LastSeen {
+		target.LastSeen = source.LastSeen
+	}
+
+	target.Watched = target.Watched || source.Watched
+	target.Trusted = target.Trusted || source.Trusted
+
+	query = `UPDATE Players SET PlayFabID = ?, MCUUID = ?, SteamID = ?, LastSeen = ?, Watched = ?, Trusted = ?
+			WHERE PlayerID = ?;`
+	if _, err := tx.Exec(query, target.PlayFabID, target.MCUUID, target.SteamID, target.LastSeen, target.Watched,
+		target.Trusted, targetID); err != nil {
+		return nil, err
+	}
+
+	// Store the audit record
+	snapshotJSON, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	merge := &refractor.PlayerMerge{
+		SourcePlayerID: sourceID,
+		TargetPlayerID: targetID,
+		UserID:         userID,
+		Timestamp:      time.Now().Unix(),
+		Snapshot:       snapshot,
+	}
+
+	query = `INSERT INTO PlayerMerges (SourcePlayerID, TargetPlayerID, UserID, Timestamp, Snapshot)
+			VALUES (?, ?, ?, ?, ?);`
+
+	res, err := tx.Exec(query, sourceID, targetID, userID, merge.Timestamp, string(snapshotJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	if merge.MergeID, err = res.LastInsertId(); err != nil {
+		return nil, err
+	}
+
+	return merge, nil
+}
+
+const selectMergeQuery = `SELECT MergeID, SourcePlayerID, TargetPlayerID, UserID, Timestamp, Undone, UndoneBy,
+		UndoneAt, Snapshot FROM PlayerMerges WHERE MergeID = ?`
+
+func (r *playerMergeRepo) FindByID(id int64) (*refractor.PlayerMerge, error) {
+	return scanMergeRow(r.db.QueryRow(selectMergeQuery+";", id))
+}
+
+// Undo reverses a merge using its stored snapshot. The source player is recreated with its original ID and all moved
+// records are given back to it. The merge is locked while it is undone so that it can't be undone twice at once, and
+// ErrMergeUndone is returned if it was already undone.
+func (r *playerMergeRepo) Undo(id int64, userID int64) (*refractor.PlayerMerge, error) {
+	tx, err := r.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	merge, err := scanMergeRow(tx.QueryRow(selectMergeQuery+" FOR UPDATE;", id))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	if merge.Undone {
+		_ = tx.Rollback()
+		return nil, refractor.ErrMergeUndone
+	}
+
+	merge.Undone = true
+	merge.UndoneBy = userID
+	merge.UndoneAt = time.Now().Unix()
+
+	if err := r.undo(tx, merge); err != nil {
+		_ = tx.Rollback()
+		return nil, wrapError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return merge, nil
+}
+
+func (r *playerMergeRepo) undo(tx *sql.Tx, merge *refractor.PlayerMerge) error {
+	snapshot := merge.Snapshot
+	source := snapshot.Source
+	target := snapshot.TargetBefore
+
+	// Restore the target's identifiers first so that the source's identifiers are free again
+	query := `UPDATE Players SET PlayFabID = ?, MCUUID = ?, SteamID = ?, LastSeen = ?, Watched = ?, Trusted = ?
+			WHERE PlayerID = ?;`
+	if _, err := tx.Exec(query, target.PlayFabID, target.MCUUID, target.SteamID, target.LastSeen, target.Watched,
+		target.Trusted, merge.TargetPlayerID); err != nil {
+		return err
+	}
+
+	query = `INSERT INTO Players (PlayerID, PlayFabID, MCUUID, SteamID, LastSeen, Watched, Trusted)
+			VALUES (?, ?, ?, ?, ?, ?, ?);`
+	if _, err := tx.Exec(query, source.PlayerID, source.PlayFabID, source.MCUUID, source.SteamID, source.LastSeen,
+		source.Watched, source.Trusted); err != nil {
+		return err
+	}
+
+	// Give back names
+	for _, name := range snapshot.MovedNames {
+		query := "DELETE FROM PlayerNames WHERE PlayerID = ? AND Name = ?;"
+		if _, err := tx.Exec(query, merge.TargetPlayerID, name); err != nil {
+			return err
+		}
+	}
+
+	for _, record := range snapshot.SourceNames {
+		query := "INSERT INTO PlayerNames (PlayerID, Name, DateRecorded) VALUES (?, ?, ?);"
+		if _, err := tx.Exec(query, merge.SourcePlayerID, record.Name, record.DateRecorded); err != nil {
+			return err
+		}
+	}
+
+	// Give back moved records
+	moved := []struct {
+		table   string
+		idField string
+		ids     []int64
+	}{
+		{"Infractions", "InfractionID", snapshot.InfractionIDs},
+		{"ChatMessages", "MessageID", snapshot.ChatMessageIDs},
+		{"PlayerSessions", "SessionID", snapshot.SessionIDs},
+		{"MatchStats", "MatchStatsID", snapshot.MatchStatsIDs},
+	}
+
+	for _, m := range moved {
+		if len(m.ids) == 0 {
+			continue
+		}
+
+		placeholders, values := buildInPlaceholders(m.ids)
+		query := fmt.Sprintf("UPDATE %s SET PlayerID = ? WHERE %s IN (%s);", m.table, m.idField, placeholders)
+
+		if _, err := tx.Exec(query, append([]interface{}{merge.SourcePlayerID}, values...)...); err != nil {
+			return err
+		}
+	}
+
+	if len(snapshot.TargetChatMessageIDs) > 0 {
+		placeholders, values := buildInPlaceholders(snapshot.TargetChatMessageIDs)
+		query := fmt.Sprintf("UPDATE ChatMessages SET TargetPlayerID = ? WHERE MessageID IN (%s);", placeholders)
+
+		if _, err := tx.Exec(query, append([]interface{}{merge.SourcePlayerID}, values...)...); err != nil {
+			return err
+		}
+	}
+
+	if len(snapshot.IssuedInfractionIDs) > 0 {
+		placeholders, values := buildInPlaceholders(snapshot.IssuedInfractionIDs)
+		query := fmt.Sprintf("UPDATE Infractions SET IssuerPlayerID = ? WHERE InfractionID IN (%s);", placeholders)
+
+		if _, err := tx.Exec(query, append([]interface{}{merge.SourcePlayerID}, values...)...); err != nil {
+			return err
+		}
+	}
+
+	query = "UPDATE PlayerMerges SET Undone = TRUE, UndoneBy = ?, UndoneAt = ? WHERE MergeID = ?;"
+	if _, err := tx.Exec(query, merge.UndoneBy, merge.UndoneAt, merge.MergeID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Helpers
+func scanMergeRow(row *sql.Row) (*refractor.PlayerMerge, error) {
+	merge := &refractor.PlayerMerge{}
+	var undoneBy, undoneAt sql.NullInt64
+	var snapshotJSON string
+
+	if err := row.Scan(&merge.MergeID, &merge.SourcePlayerID, &merge.TargetPlayerID, &merge.UserID,
+		&merge.Timestamp, &merge.Undone, &undoneBy, &undoneAt, &snapshotJSON); err != nil {
+		return nil, wrapError(err)
+	}
+
+	merge.UndoneBy = undoneBy.Int64
+	merge.UndoneAt = undoneAt.Int64
+
+	merge.Snapshot = &refractor.PlayerMergeSnapshot{}
+	if err := json.Unmarshal([]byte(snapshotJSON), merge.Snapshot); err != nil {
+		return nil, err
+	}
+
+	return merge, nil
+}
+
+func scanPlayerRow(row *sql.Row, player *refractor.DBPlayer) error {
+	return row.Scan(&player.PlayerID, &player.PlayFabID, &player.MCUUID, &player.LastSeen, &player.Watched,
+		&player.Trusted, &player.SteamID)
+}
+
+func selectIDs(tx *sql.Tx, query string, args ...interface{}) ([]int64, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+
+	for rows.Next() {
+		var id int64
+
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func selectNameRecords(tx *sql.Tx, playerID int64) ([]refractor.PlayerNameRecord, error) {
+	rows, err := tx.Query("SELECT Name, DateRecorded FROM PlayerNames WHERE PlayerID = ?;", playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []refractor.PlayerNameRecord
+
+	for rows.Next() {
+		record := refractor.PlayerNameRecord{}
+
+		if err := rows.Scan(&record.Name, &record.DateRecorded); err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
@@ -33,9 +33,11 @@ func NewServerRepository(db *sql.DB) refractor.ServerRepository {
 }
 
 func (r *serverRepo) Create(server *refractor.Server) error {
-	query := "INSERT INTO Servers (Game, Name, Address, RCONPort, RCONPassword) VALUES (?, ?, ?, ?, ?);"
+	query := `INSERT INTO Servers (Game, Name, Address, RCONPort, RCONPassword, WarnMessageTemplate, MuteAction,
+			EventSource, LogFilePath) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);`
 
-	res, err := r.db.Exec(query, server.Game, server.Name, server.Address, server.RCONPort, server.RCONPassword)
+	res, err := r.db.Exec(query, server.Game, server.Name, server.Address, server.RCONPort, server.RCONPassword,
+		server.WarnMessageTemplate, server.MuteAction, server.EventSource, server.LogFilePath)
 	if err != nil {
 		return wrapError(err)
 	}
@@ -153,9 +155,11 @@ func (r *serverRepo) Delete(id int64) error {
 
 // Scan helpers
 func (r *serverRepo) scanRow(row *sql.Row, server *refractor.Server) error {
-	return row.Scan(&server.ServerID, &server.Game, &server.Name, &server.Address, &server.RCONPort, &server.RCONPassword)
+	return row.Scan(&server.ServerID, &server.Game, &server.Name, &server.Address, &server.RCONPort, &server.RCONPassword,
+		&server.WarnMessageTemplate, &server.MuteAction, &server.EventSource, &server.LogFilePath)
 }
 
 func (r *serverRepo) scanRows(rows *sql.Rows, server *refractor.Server) error {
-	return rows.Scan(&server.ServerID, &server.Game, &server.Name, &server.Address, &server.RCONPort, &server.RCONPassword)
+	return rows.Scan(&server.ServerID, &server.Game, &server.Name, &server.Address, &server.RCONPort, &server.RCONPassword,
+		&server.WarnMessageTemplate, &server.MuteAction, &server.EventSource, &server.LogFilePath)
 }
@@ -0,0 +1,121 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"github.com/sniddunc/refractor/refractor"
+)
+
+type serverHistoryRepo struct {
+	db *sql.DB
+}
+
+func NewServerHistoryRepository(db *sql.DB) refractor.ServerHistoryRepository {
+	return &serverHistoryRepo{
+		db: db,
+	}
+}
+
+func (r *serverHistoryRepo) Create(s *refractor.ServerStatusSample) error {
+	query := `INSERT INTO ServerStatusSamples (ServerID, Timestamp, Samples, OnlineSamples, MinPlayers, MaxPlayers,
+			AvgPlayers, Downsampled) VALUES (?, ?, ?, ?, ?, ?, ?, ?);`
+
+	if _, err := r.db.Exec(query, s.ServerID, s.Timestamp, s.Samples, s.OnlineSamples, s.MinPlayers, s.MaxPlayers,
+		s.AvgPlayers, s.Downsampled); err != nil {
+		return wrapError(err)
+	}
+
+	return nil
+}
+
+func (r *serverHistoryRepo) FindByServerID(serverID int64, from int64, to int64) ([]*refractor.ServerStatusSample, error) {
+	query := `SELECT * FROM ServerStatusSamples WHERE ServerID = ? AND Timestamp >= ? AND Timestamp < ?
+			ORDER BY Timestamp ASC;`
+
+	rows, err := r.db.Query(query, serverID, from, to)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var foundSamples []*refractor.ServerStatusSample
+
+	for rows.Next() {
+		sample := &refractor.ServerStatusSample{}
+
+		if err := rows.Scan(&sample.ServerID, &sample.Timestamp, &sample.Samples, &sample.OnlineSamples,
+			&sample.MinPlayers, &sample.MaxPlayers, &sample.AvgPlayers, &sample.Downsampled); err != nil {
+			return nil, wrapError(err)
+		}
+
+		foundSamples = append(foundSamples, sample)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, wrapError(err)
+	}
+
+	if len(foundSamples) == 0 {
+		return nil, refractor.ErrNotFound
+	}
+
+	return foundSamples, nil
+}
+
+// ReplaceSamples deletes the samples being downsampled and stores the downsampled ones in a single transaction. If a
+// downsampled sample already exists for the same period, the two are combined.
+func (r *serverHistoryRepo) ReplaceSamples(serverID int64, before int64, downsampled []*refractor.ServerStatusSample) error {
+	tx, err := r.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	deleteQuery := "DELETE FROM ServerStatusSamples WHERE ServerID = ? AND Timestamp < ? AND Downsampled = FALSE;"
+
+	if _, err := tx.Exec(deleteQuery, serverID, before); err != nil {
+		_ = tx.Rollback()
+		return wrapError(err)
+	}
+
+	// The columns are updated in order, so the averages and extremes have to be combined before the sample counts
+	// they depend on are.
+	insertQuery := `INSERT INTO ServerStatusSamples (ServerID, Timestamp, Samples, OnlineSamples, MinPlayers,
+			MaxPlayers, AvgPlayers, Downsampled) VALUES (?, ?, ?, ?, ?, ?, ?, TRUE)
+			ON DUPLICATE KEY UPDATE
+			AvgPlayers = IF(OnlineSamples + VALUES(OnlineSamples) = 0, 0,
+				(AvgPlayers * OnlineSamples + VALUES(AvgPlayers) * VALUES(OnlineSamples)) /
+				(OnlineSamples + VALUES(OnlineSamples))),
+			MinPlayers = IF(OnlineSamples = 0, VALUES(MinPlayers),
+				IF(VALUES(OnlineSamples) = 0, MinPlayers, LEAST(MinPlayers, VALUES(MinPlayers)))),
+			MaxPlayers = GREATEST(MaxPlayers, VALUES(MaxPlayers)),
+			OnlineSamples = OnlineSamples + VALUES(OnlineSamples),
+			Samples = Samples + VALUES(Samples),
+			Downsampled = TRUE;`
+
+	for _, s := range downsampled {
+		if _, err := tx.Exec(insertQuery, serverID, s.Timestamp, s.Samples, s.OnlineSamples, s.MinPlayers,
+			s.MaxPlayers, s.AvgPlayers); err != nil {
+			_ = tx.Rollback()
+			return wrapError(err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,146 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mysql
+
+import (
+	"database/sql"
+	"github.com/sniddunc/refractor/refractor"
+	"time"
+)
+
+type playerSessionRepo struct {
+	db *sql.DB
+}
+
+func NewPlayerSessionRepository(db *sql.DB) refractor.PlayerSessionRepository {
+	return &playerSessionRepo{
+		db: db,
+	}
+}
+
+func (r *playerSessionRepo) Create(session *refractor.DBPlayerSession) (*refractor.PlayerSession, error) {
+	if session.JoinTime == 0 {
+		session.JoinTime = time.Now().Unix()
+	}
+
+	query := "INSERT INTO PlayerSessions (PlayerID, ServerID, JoinTime, QuitTime) VALUES (?, ?, ?, ?);"
+
+	res, err := r.db.Exec(query, session.PlayerID, session.ServerID, session.JoinTime, session.QuitTime)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	session.SessionID = id
+
+	return session.PlayerSession(), nil
+}
+
+// FindOpen returns the most recent session of a player on a server which has not been closed yet.
+func (r *playerSessionRepo) FindOpen(playerID int64, serverID int64) (*refractor.PlayerSession, error) {
+	query := `SELECT * FROM PlayerSessions WHERE PlayerID = ? AND ServerID = ? AND QuitTime IS NULL
+			ORDER BY JoinTime DESC LIMIT 1;`
+
+	row := r.db.QueryRow(query, playerID, serverID)
+
+	foundSession := &refractor.DBPlayerSession{}
+	if err := r.scanRow(row, foundSession); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return foundSession.PlayerSession(), nil
+}
+
+func (r *playerSessionRepo) Close(sessionID int64, quitTime int64) error {
+	query := "UPDATE PlayerSessions SET QuitTime = ? WHERE SessionID = ?;"
+
+	res, err := r.db.Exec(query, quitTime, sessionID)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return wrapError(err)
+	}
+
+	if rowsAffected <= 0 {
+		return wrapError(sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// GetRecent returns the latest closed session for each player and server pair, most recent first.
+// Supported args: ServerID, Since and Until.
+func (r *playerSessionRepo) GetRecent(args refractor.FindArgs, limit int) ([]*refractor.PlayerSession, error) {
+	query := `
+		SELECT
+			MAX(SessionID) AS SessionID,
+			PlayerID,
+			ServerID,
+			MAX(JoinTime) AS JoinTime,
+			MAX(QuitTime) AS QuitTime
+		FROM PlayerSessions
+		WHERE
+			QuitTime IS NOT NULL AND
+			(? IS NULL OR ServerID = ?) AND
+			(QuitTime BETWEEN ? AND ?)
+		GROUP BY PlayerID, ServerID
+		ORDER BY QuitTime DESC
+		LIMIT ?;
+	`
+
+	var (
+		serverID = args["ServerID"]
+		since    = args["Since"]
+		until    = args["Until"]
+	)
+
+	rows, err := r.db.Query(query, serverID, serverID, since, until, limit)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	var foundSessions []*refractor.PlayerSession
+
+	for rows.Next() {
+		session := &refractor.DBPlayerSession{}
+
+		if err := r.scanRows(rows, session); err != nil {
+			return nil, wrapError(err)
+		}
+
+		foundSessions = append(foundSessions, session.PlayerSession())
+	}
+
+	return foundSessions, nil
+}
+
+// Scan helpers
+func (r *playerSessionRepo) scanRow(row *sql.Row, session *refractor.DBPlayerSession) error {
+	return row.Scan(&session.SessionID, &session.PlayerID, &session.ServerID, &session.JoinTime, &session.QuitTime)
+}
+
+func (r *playerSessionRepo) scanRows(rows *sql.Rows, session *refractor.DBPlayerSession) error {
+	return rows.Scan(&session.SessionID, &session.PlayerID, &session.ServerID, &session.JoinTime, &session.QuitTime)
+}
@@ -26,14 +26,16 @@ import (
 type summaryService struct {
 	playerService     refractor.PlayerService
 	infractionService refractor.InfractionService
+	matchStatsService refractor.MatchStatsService
 	log               log.Logger
 }
 
 func NewSummaryService(playerService refractor.PlayerService, infractionService refractor.InfractionService,
-	log log.Logger) refractor.SummaryService {
+	matchStatsService refractor.MatchStatsService, log log.Logger) refractor.SummaryService {
 	return &summaryService{
 		playerService:     playerService,
 		infractionService: infractionService,
+		matchStatsService: matchStatsService,
 		log:               log,
 	}
 }
@@ -76,13 +78,19 @@ func (s *summaryService) GetPlayerSummary(playerID int64) (*refractor.PlayerSumm
 		}
 	}
 
+	matchStats, res := s.matchStatsService.GetPlayerMatchStats(playerID)
+	if !res.Success {
+		return nil, res
+	}
+
 	// Build player summary
 	playerSummary := &refractor.PlayerSummary{
-		Warnings: warnings,
-		Mutes:    mutes,
-		Kicks:    kicks,
-		Bans:     bans,
-		Player:   player,
+		Warnings:   warnings,
+		Mutes:      mutes,
+		Kicks:      kicks,
+		Bans:       bans,
+		MatchStats: matchStats,
+		Player:     player,
 	}
 
 	return playerSummary, &refractor.ServiceResponse{
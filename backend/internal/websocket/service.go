@@ -32,6 +32,7 @@ type websocketService struct {
 	playerInfractionService refractor.PlayerInfractionService
 	log                     log.Logger
 	chatSendSubscribers     []refractor.ChatSendSubscriber
+	whisperSendSubscribers  []refractor.WhisperSendSubscriber
 }
 
 func NewWebsocketService(playerRepo refractor.PlayerRepository, userRepo refractor.UserRepository,
@@ -43,6 +44,7 @@ func NewWebsocketService(playerRepo refractor.PlayerRepository, userRepo refract
 		userRepo:                userRepo,
 		log:                     log,
 		chatSendSubscribers:     []refractor.ChatSendSubscriber{},
+		whisperSendSubscribers:  []refractor.WhisperSendSubscriber{},
 	}
 }
 
@@ -50,8 +52,16 @@ func (s *websocketService) Broadcast(message *refractor.WebsocketMessage) {
 	s.pool.Broadcast <- message
 }
 
+// SendDirect sends a message to every client the user is connected with
+func (s *websocketService) SendDirect(userID int64, message *refractor.WebsocketMessage) {
+	s.pool.SendToUser <- &refractor.WebsocketUserMessage{
+		UserID:  userID,
+		Message: message,
+	}
+}
+
 func (s *websocketService) CreateClient(userID int64, conn net.Conn) {
-	client := websocket.NewClient(userID, conn, s.pool, s.log, s.sendChatHandler)
+	client := websocket.NewClient(userID, conn, s.pool, s.log, s.sendChatHandler, s.sendWhisperHandler)
 
 	s.pool.Register <- client
 	client.Read()
@@ -67,6 +77,7 @@ func (s *websocketService) sendChatHandler(msgBody *websocket.SendChatBody) {
 
 	transformed := &refractor.ChatSendBody{
 		ServerID:   msgBody.ServerID,
+		UserID:     msgBody.UserID,
 		Message:    msgBody.Message,
 		Sender:     user.Username,
 		SentByUser: true,
@@ -77,6 +88,19 @@ func (s *websocketService) sendChatHandler(msgBody *websocket.SendChatBody) {
 	}
 }
 
+func (s *websocketService) sendWhisperHandler(msgBody *websocket.SendWhisperBody) {
+	transformed := &refractor.WhisperSendBody{
+		ServerID: msgBody.ServerID,
+		PlayerID: msgBody.PlayerID,
+		UserID:   msgBody.UserID,
+		Message:  msgBody.Message,
+	}
+
+	for _, sub := range s.whisperSendSubscribers {
+		sub(transformed)
+	}
+}
+
 func (s *websocketService) StartPool() {
 	s.pool.Start()
 }
@@ -88,6 +112,9 @@ type playerJoinQuitData struct {
 	Name            string `json:"name"`
 	InfractionCount int    `json:"infractionCount,omitempty"`
 	Watched         bool   `json:"watched"`
+
+	// Fields holds the player list fields of a joining player (e.g. ping and team) if they are known
+	Fields map[string]string `json:"fields,omitempty"`
 }
 
 func (s *websocketService) OnPlayerJoin(fields broadcast.Fields, serverID int64, gameConfig *refractor.GameConfig) {
@@ -113,6 +140,7 @@ func (s *websocketService) OnPlayerJoin(fields broadcast.Fields, serverID int64,
 			Name:            player.CurrentName,
 			InfractionCount: count,
 			Watched:         player.Watched,
+			Fields:          gameConfig.GetPlayerListFields(fields),
 		},
 	})
 }
@@ -159,6 +187,22 @@ func (s *websocketService) OnServerOffline(serverID int64) {
 	})
 }
 
+type serverInfoBody struct {
+	ServerID int64                     `json:"serverId"`
+	Info     *refractor.LiveServerInfo `json:"info"`
+}
+
+// OnServerInfoUpdate lets clients know that the polled info of a server (e.g. its map) changed
+func (s *websocketService) OnServerInfoUpdate(serverID int64, info *refractor.LiveServerInfo) {
+	s.Broadcast(&refractor.WebsocketMessage{
+		Type: "server-info",
+		Body: &serverInfoBody{
+			ServerID: serverID,
+			Info:     info,
+		},
+	})
+}
+
 type infractionCreateBody struct {
 	InfractionID int64  `json:"id"`
 	PlayerID     int64  `json:"playerId"`
@@ -206,3 +250,7 @@ func (s *websocketService) OnInfractionCreate(infraction *refractor.Infraction)
 func (s *websocketService) SubscribeChatSend(subscriber refractor.ChatSendSubscriber) {
 	s.chatSendSubscribers = append(s.chatSendSubscribers, subscriber)
 }
+
+func (s *websocketService) SubscribeWhisperSend(subscriber refractor.WhisperSendSubscriber) {
+	s.whisperSendSubscribers = append(s.whisperSendSubscribers, subscriber)
+}
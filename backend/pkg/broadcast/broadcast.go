@@ -30,9 +30,12 @@ type Broadcast struct {
 }
 
 const (
-	TYPE_JOIN = "JOIN"
-	TYPE_QUIT = "QUIT"
-	TYPE_CHAT = "CHAT"
+	TYPE_JOIN       = "JOIN"
+	TYPE_QUIT       = "QUIT"
+	TYPE_CHAT       = "CHAT"
+	TYPE_PUNISHMENT = "PUNISHMENT"
+	TYPE_MATCHSTATE = "MATCHSTATE"
+	TYPE_KILL       = "KILL"
 )
 
 func GetBroadcastType(broadcast string, patterns map[string]*regexp.Regexp) *Broadcast {
@@ -49,3 +52,31 @@ func GetBroadcastType(broadcast string, patterns map[string]*regexp.Regexp) *Bro
 
 	return nil
 }
+
+// MatchLogLine strips a log line prefix from a line and matches the rest of it against the broadcast patterns. Any
+// named groups captured by the prefix (such as a timestamp) are included in the broadcast's fields. The prefix is
+// optional and may be nil. If the line doesn't match a broadcast pattern, nil is returned.
+func MatchLogLine(line string, prefix *regexp.Regexp, patterns map[string]*regexp.Regexp) *Broadcast {
+	var prefixFields map[string]string
+
+	if prefix != nil {
+		loc := prefix.FindStringIndex(line)
+		if loc != nil && loc[0] == 0 {
+			prefixFields = regexutils.MapNamedMatches(prefix, line[:loc[1]])
+			line = line[loc[1]:]
+		}
+	}
+
+	bcast := GetBroadcastType(line, patterns)
+	if bcast == nil {
+		return nil
+	}
+
+	for name, value := range prefixFields {
+		if name != "" && bcast.Fields[name] == "" {
+			bcast.Fields[name] = value
+		}
+	}
+
+	return bcast
+}
@@ -26,6 +26,7 @@ import (
 var (
 	mordhauJoinPattern = regexp.MustCompile("^Login: (?P<date>[0-9\\.-]+): (?P<name>.+) \\((?P<playfabid>[0-9a-fA-F]+)\\) logged in$")
 	mordhauQuitPattern = regexp.MustCompile("^Login: (?P<date>[0-9\\.-]+): (?P<name>.+) \\((?P<playfabid>[0-9a-fA-F]+)\\) logged out$")
+	mordhauLogPrefix   = regexp.MustCompile("^\\[(?P<timestamp>[0-9\\.-]+)(?::\\d+)?\\]\\[\\s*\\d+\\]\\w+: (?:Display: )?")
 )
 
 func TestGetBroadcastType(t *testing.T) {
@@ -94,3 +95,82 @@ func TestGetBroadcastType(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchLogLine(t *testing.T) {
+	patterns := map[string]*regexp.Regexp{
+		TYPE_JOIN: mordhauJoinPattern,
+		TYPE_QUIT: mordhauQuitPattern,
+	}
+
+	type args struct {
+		line   string
+		prefix *regexp.Regexp
+	}
+	tests := []struct {
+		name string
+		args args
+		want *Broadcast
+	}{
+		{
+			name: "broadcast.matchlogline.1",
+			args: args{
+				line:   "[2021.01.01-00.00.05:349][ 52]LogMordhauGameMode: Display: Login: 2021.01.01-00.00.05: Test (52DAB212C79F5EC) logged in",
+				prefix: mordhauLogPrefix,
+			},
+			want: &Broadcast{
+				Type: TYPE_JOIN,
+				Fields: map[string]string{
+					"date":      "2021.01.01-00.00.05",
+					"name":      "Test",
+					"playfabid": "52DAB212C79F5EC",
+					"timestamp": "2021.01.01-00.00.05",
+				},
+			},
+		},
+		{
+			name: "broadcast.matchlogline.2",
+			args: args{
+				line:   "Login: 2021.01.01-00.00.05: Test (52DAB212C79F5EC) logged out",
+				prefix: mordhauLogPrefix,
+			},
+			want: &Broadcast{
+				Type: TYPE_QUIT,
+				Fields: map[string]string{
+					"date":      "2021.01.01-00.00.05",
+					"name":      "Test",
+					"playfabid": "52DAB212C79F5EC",
+				},
+			},
+		},
+		{
+			name: "broadcast.matchlogline.3",
+			args: args{
+				line:   "Login: 2021.01.01-00.00.05: Test (52DAB212C79F5EC) logged out",
+				prefix: nil,
+			},
+			want: &Broadcast{
+				Type: TYPE_QUIT,
+				Fields: map[string]string{
+					"date":      "2021.01.01-00.00.05",
+					"name":      "Test",
+					"playfabid": "52DAB212C79F5EC",
+				},
+			},
+		},
+		{
+			name: "broadcast.matchlogline.4",
+			args: args{
+				line:   "[2021.01.01-00.00.05:349][ 52]LogNet: Client netspeed is 10000",
+				prefix: mordhauLogPrefix,
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchLogLine(tt.args.line, tt.args.prefix, patterns); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MatchLogLine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chatfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Rule types
+const (
+	TYPE_REGEX     = "REGEX"
+	TYPE_WORDLIST  = "WORDLIST"
+	TYPE_LEETSPEAK = "LEETSPEAK"
+)
+
+var Types = []string{TYPE_REGEX, TYPE_WORDLIST, TYPE_LEETSPEAK}
+
+// Rule actions, ordered from least to most severe
+const (
+	ACTION_FLAG  = "FLAG"
+	ACTION_ALERT = "ALERT"
+	ACTION_WARN  = "WARN"
+	ACTION_MUTE  = "MUTE"
+)
+
+var Actions = []string{ACTION_FLAG, ACTION_ALERT, ACTION_WARN, ACTION_MUTE}
+
+// Severity returns the position of action in Actions. Higher values are more severe. Unknown actions return -1.
+func Severity(action string) int {
+	for i, a := range Actions {
+		if a == action {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Matcher checks chat messages against a single compiled filter rule.
+type Matcher struct {
+	regex *regexp.Regexp
+	words map[string]bool
+	leet  [][]string
+}
+
+// Compile builds a Matcher for a rule of the given type. For TYPE_REGEX the pattern is a regular expression. For
+// TYPE_WORDLIST and TYPE_LEETSPEAK it is a list of words separated by commas or new lines. TYPE_LEETSPEAK entries only
+// match whole words and may be phrases, in which case the words must appear next to each other.
+func Compile(ruleType string, pattern string) (*Matcher, error) {
+	m := &Matcher{}
+
+	switch ruleType {
+	case TYPE_REGEX:
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		m.regex = regex
+	case TYPE_WORDLIST:
+		m.words = map[string]bool{}
+
+		for _, word := range splitWords(pattern) {
+			m.words[strings.ToLower(word)] = true
+		}
+	case TYPE_LEETSPEAK:
+		for _, word := range splitWords(pattern) {
+			var phrase []string
+
+			for _, field := range strings.Fields(word) {
+				if letters := substitute(field); letters != "" {
+					phrase = append(phrase, letters)
+				}
+			}
+
+			if len(phrase) > 0 {
+				m.leet = append(m.leet, phrase)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown rule type: %s", ruleType)
+	}
+
+	return m, nil
+}
+
+// Match returns true if message matches the rule the Matcher was compiled from.
+func (m *Matcher) Match(message string) bool {
+	switch {
+	case m.regex != nil:
+		return m.regex.MatchString(message)
+	case m.words != nil:
+		for _, word := range strings.FieldsFunc(strings.ToLower(message), isNotWordRune) {
+			if m.words[word] {
+				return true
+			}
+		}
+	case m.leet != nil:
+		tokens := leetTokens(message)
+
+		for _, phrase := range m.leet {
+			if matchPhrase(tokens, phrase) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// leetToken holds the ways a single word of a message can be read. The first form keeps every character so that
+// words such as "@$$" are read in full, and the second has the punctuation around the word trimmed so that a trailing
+// "!" isn't read as an i.
+type leetToken []string
+
+// leetTokens splits message into words and substitutes leetspeak in each of them. Runs of single letter words are
+// joined back together so that spaced out words such as "i d i o t" are still read as one word.
+func leetTokens(message string) []leetToken {
+	var tokens []leetToken
+	var letters strings.Builder
+
+	flushLetters := func() {
+		if letters.Len() > 0 {
+			tokens = append(tokens, leetToken{letters.String()})
+			letters.Reset()
+		}
+	}
+
+	for _, field := range strings.Fields(message) {
+		var token leetToken
+
+		for _, form := range []string{field, strings.TrimFunc(field, isNotWordRune)} {
+			if letters := substitute(form); letters != "" {
+				token = append(token, letters)
+			}
+		}
+
+		if len(token) == 0 {
+			continue
+		}
+
+		if single := singleLetter(token); single != "" {
+			letters.WriteString(single)
+			continue
+		}
+
+		flushLetters()
+		tokens = append(tokens, token)
+	}
+
+	flushLetters()
+
+	return tokens
+}
+
+// singleLetter returns the form of token which is a single (possibly repeated) letter, or an empty string if there
+// isn't one.
+func singleLetter(token leetToken) string {
+	for _, form := range token {
+		if len([]rune(Normalize(form))) == 1 {
+			return form
+		}
+	}
+
+	return ""
+}
+
+// matchPhrase returns true if tokens contains the words of phrase in order and next to each other.
+func matchPhrase(tokens []leetToken, phrase []string) bool {
+	for start := 0; start+len(phrase) <= len(tokens); start++ {
+		matched := true
+
+		for i, word := range phrase {
+			if !matchToken(tokens[start+i], word) {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchToken(token leetToken, word string) bool {
+	for _, form := range token {
+		if stretches(form, word) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stretches returns true if s is word with zero or more of its letters repeated, such as "fuuuck" for "fuck". Letters
+// may only be added, so "as" does not match "ass".
+func stretches(s string, word string) bool {
+	sRunes, wordRunes := []rune(s), []rune(word)
+	i, j := 0, 0
+
+	for j < len(wordRunes) {
+		if i >= len(sRunes) || sRunes[i] != wordRunes[j] {
+			return false
+		}
+
+		// Count the run of this letter in both strings
+		letter := wordRunes[j]
+		sCount, wordCount := 0, 0
+
+		for ; i < len(sRunes) && sRunes[i] == letter; i++ {
+			sCount++
+		}
+
+		for ; j < len(wordRunes) && wordRunes[j] == letter; j++ {
+			wordCount++
+		}
+
+		if sCount < wordCount {
+			return false
+		}
+	}
+
+	return i == len(sRunes)
+}
+
+var leetReplacements = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'!': 'i',
+	'|': 'i',
+	'3': 'e',
+	'4': 'a',
+	'@': 'a',
+	'5': 's',
+	'$': 's',
+	'7': 't',
+	'+': 't',
+	'8': 'b',
+	'9': 'g',
+}
+
+// Normalize lowercases s, replaces common leetspeak substitutions with the letters they stand in for and drops
+// everything which isn't a letter. Runs of the same letter are collapsed so that stretched out words still match,
+// meaning "F u_u_u C k" and "fuck" both normalize to "fuck".
+func Normalize(s string) string {
+	var b strings.Builder
+	var last rune
+
+	for _, r := range substitute(s) {
+		if r == last {
+			continue
+		}
+
+		b.WriteRune(r)
+		last = r
+	}
+
+	return b.String()
+}
+
+// substitute lowercases s, replaces common leetspeak substitutions with the letters they stand in for and drops
+// everything which isn't a letter. Unlike Normalize, repeated letters are kept.
+func substitute(s string) string {
+	var b strings.Builder
+
+	for _, r := range strings.ToLower(s) {
+		if replacement, ok := leetReplacements[r]; ok {
+			r = replacement
+		}
+
+		if unicode.IsLetter(r) {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+func splitWords(pattern string) []string {
+	var words []string
+
+	for _, word := range strings.FieldsFunc(pattern, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if word = strings.TrimSpace(word); word != "" {
+			words = append(words, word)
+		}
+	}
+
+	return words
+}
+
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+}
@@ -0,0 +1,96 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chatfilter
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"hello", "helo"},
+		{"H3LL0", "helo"},
+		{"n00b", "nob"},
+		{"@$$", "as"},
+		{"F u_u_u C k", "fuck"},
+		{"1337", "iet"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.want, Normalize(tt.input))
+		})
+	}
+}
+
+func TestMatcher_Match(t *testing.T) {
+	tests := []struct {
+		name     string
+		ruleType string
+		pattern  string
+		message  string
+		want     bool
+	}{
+		{"chatfilter.match.1", TYPE_REGEX, "(?i)discord\\.gg/\\w+", "join Discord.gg/abc now", true},
+		{"chatfilter.match.2", TYPE_REGEX, "(?i)discord\\.gg/\\w+", "join our discord", false},
+		{"chatfilter.match.3", TYPE_WORDLIST, "noob, idiot", "you are an IDIOT!", true},
+		{"chatfilter.match.4", TYPE_WORDLIST, "noob, idiot", "idiotic play", false},
+		{"chatfilter.match.5", TYPE_WORDLIST, "noob\nidiot", "what a noob", true},
+		{"chatfilter.match.6", TYPE_LEETSPEAK, "idiot", "you 1d10t", true},
+		{"chatfilter.match.7", TYPE_LEETSPEAK, "idiot", "i d i o t", true},
+		{"chatfilter.match.8", TYPE_LEETSPEAK, "idiot", "good game", false},
+		{"chatfilter.match.9", TYPE_LEETSPEAK, "ass", "you @$$", true},
+		{"chatfilter.match.10", TYPE_LEETSPEAK, "ass", "a s s", true},
+		{"chatfilter.match.11", TYPE_LEETSPEAK, "ass", "what an aaassss", true},
+		{"chatfilter.match.12", TYPE_LEETSPEAK, "idiot", "you idiot!", true},
+		{"chatfilter.match.13", TYPE_LEETSPEAK, "kill yourself", "just k1ll y0urself", true},
+		{"chatfilter.match.14", TYPE_LEETSPEAK, "kill yourself", "kill the guy yourself", false},
+		{"chatfilter.match.15", TYPE_LEETSPEAK, "ass", "was it you", false},
+		{"chatfilter.match.16", TYPE_LEETSPEAK, "ass", "as if", false},
+		{"chatfilter.match.17", TYPE_LEETSPEAK, "ass", "nice pass", false},
+		{"chatfilter.match.18", TYPE_LEETSPEAK, "ass", "class", false},
+		{"chatfilter.match.19", TYPE_LEETSPEAK, "kill", "skilled player", false},
+		{"chatfilter.match.20", TYPE_LEETSPEAK, "kill", "ok i'll go", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Compile(tt.ruleType, tt.pattern)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, m.Match(tt.message))
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	_, err := Compile(TYPE_REGEX, "([a-z")
+	assert.NotNil(t, err)
+
+	_, err = Compile("UNKNOWN", "test")
+	assert.NotNil(t, err)
+}
+
+func TestSeverity(t *testing.T) {
+	assert.True(t, Severity(ACTION_MUTE) > Severity(ACTION_WARN))
+	assert.True(t, Severity(ACTION_WARN) > Severity(ACTION_ALERT))
+	assert.True(t, Severity(ACTION_ALERT) > Severity(ACTION_FLAG))
+	assert.Equal(t, -1, Severity("UNKNOWN"))
+}
@@ -0,0 +1,195 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chatspam
+
+import (
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Detection types
+const (
+	DETECTION_FLOOD  = "FLOOD"
+	DETECTION_REPEAT = "REPEAT"
+	DETECTION_CAPS   = "CAPS"
+)
+
+// Detection actions, ordered from least to most severe
+const (
+	ACTION_NONE  = "NONE"
+	ACTION_ALERT = "ALERT"
+	ACTION_WARN  = "WARN"
+	ACTION_MUTE  = "MUTE"
+	ACTION_KICK  = "KICK"
+)
+
+var Actions = []string{ACTION_NONE, ACTION_ALERT, ACTION_WARN, ACTION_MUTE, ACTION_KICK}
+
+// Severity returns the position of action in Actions. Higher values are more severe. Unknown actions return -1.
+func Severity(action string) int {
+	for i, a := range Actions {
+		if a == action {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Settings holds the thresholds used when checking a message. A zero value disables the corresponding check.
+type Settings struct {
+	// FloodMessages is the number of messages a player can send within FloodWindow before it's considered a flood.
+	FloodMessages int
+	FloodWindow   time.Duration
+
+	// RepeatCount is the number of identical messages in a row which are considered repeat spam.
+	RepeatCount int
+
+	// CapsPercent is the percentage of upper case letters at or above which a message is considered all-caps spam.
+	// Messages with fewer than CapsMinLength letters are ignored.
+	CapsPercent   int
+	CapsMinLength int
+}
+
+// Detector keeps track of recent messages per player on each server.
+type Detector struct {
+	servers map[int64]map[string]*history
+	mu      sync.Mutex
+}
+
+type history struct {
+	times   []time.Time
+	last    string
+	repeats int
+}
+
+func NewDetector() *Detector {
+	return &Detector{
+		servers: map[int64]map[string]*history{},
+	}
+}
+
+// Check records a message sent by a player on a server at the given time and returns the types of spam it was
+// detected as. Once a flood or repeat is detected the relevant counter is reset so that a single burst of spam is only
+// reported once.
+func (d *Detector) Check(serverID int64, player string, message string, now time.Time, settings Settings) []string {
+	var detections []string
+
+	if IsCaps(message, settings.CapsPercent, settings.CapsMinLength) {
+		detections = append(detections, DETECTION_CAPS)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	players := d.servers[serverID]
+	if players == nil {
+		players = map[string]*history{}
+		d.servers[serverID] = players
+	}
+
+	h := players[player]
+	if h == nil {
+		h = &history{}
+		players[player] = h
+	}
+
+	// Flood detection
+	if settings.FloodMessages > 0 && settings.FloodWindow > 0 {
+		cutoff := now.Add(-settings.FloodWindow)
+
+		var recent []time.Time
+		for _, t := range h.times {
+			if t.After(cutoff) {
+				recent = append(recent, t)
+			}
+		}
+
+		h.times = append(recent, now)
+
+		if len(h.times) > settings.FloodMessages {
+			detections = append(detections, DETECTION_FLOOD)
+			h.times = nil
+		}
+	}
+
+	// Repeat detection
+	normalized := strings.Join(strings.Fields(strings.ToLower(message)), " ")
+	if normalized == h.last {
+		h.repeats++
+	} else {
+		h.last = normalized
+		h.repeats = 1
+	}
+
+	if settings.RepeatCount > 1 && h.repeats >= settings.RepeatCount {
+		detections = append(detections, DETECTION_REPEAT)
+		h.repeats = 0
+	}
+
+	return detections
+}
+
+// Forget clears the history of a player on a server.
+func (d *Detector) Forget(serverID int64, player string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if players := d.servers[serverID]; players != nil {
+		delete(players, player)
+	}
+}
+
+// ForgetServer clears the history of every player on a server.
+func (d *Detector) ForgetServer(serverID int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.servers, serverID)
+}
+
+// IsCaps returns true if at least percent percent of the letters in message are upper case. Messages containing fewer
+// than minLength letters always return false, as does a percent of zero.
+func IsCaps(message string, percent int, minLength int) bool {
+	if percent <= 0 {
+		return false
+	}
+
+	letters := 0
+	upper := 0
+
+	for _, r := range message {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+
+		letters++
+
+		if unicode.IsUpper(r) {
+			upper++
+		}
+	}
+
+	if letters == 0 || letters < minLength {
+		return false
+	}
+
+	return upper*100/letters >= percent
+}
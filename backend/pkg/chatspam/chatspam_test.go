@@ -0,0 +1,158 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package chatspam
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestIsCaps(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		percent   int
+		minLength int
+		want      bool
+	}{
+		{"chatspam.iscaps.1", "WHY IS EVERYONE SO BAD", 80, 5, true},
+		{"chatspam.iscaps.2", "why is everyone so bad", 80, 5, false},
+		{"chatspam.iscaps.3", "GG", 80, 5, false},
+		{"chatspam.iscaps.4", "GG WP EVERYONE!!!", 80, 5, true},
+		{"chatspam.iscaps.5", "Mostly Normal Message", 80, 5, false},
+		{"chatspam.iscaps.6", "WHY IS EVERYONE SO BAD", 0, 5, false},
+		{"chatspam.iscaps.7", "12345 !!!", 80, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsCaps(tt.message, tt.percent, tt.minLength))
+		})
+	}
+}
+
+func TestDetector_Check(t *testing.T) {
+	settings := Settings{
+		FloodMessages: 3,
+		FloodWindow:   5 * time.Second,
+		RepeatCount:   3,
+		CapsPercent:   80,
+		CapsMinLength: 5,
+	}
+
+	start := time.Unix(1000, 0)
+
+	type message struct {
+		serverID int64
+		player   string
+		message  string
+		offset   time.Duration
+	}
+
+	tests := []struct {
+		name     string
+		messages []message
+		want     [][]string
+	}{
+		{
+			name: "chatspam.check.flood",
+			messages: []message{
+				{1, "a", "one", 0},
+				{1, "a", "two", time.Second},
+				{1, "a", "three", 2 * time.Second},
+				{1, "a", "four", 3 * time.Second},
+				{1, "a", "five", 4 * time.Second},
+			},
+			want: [][]string{nil, nil, nil, {DETECTION_FLOOD}, nil},
+		},
+		{
+			name: "chatspam.check.slow",
+			messages: []message{
+				{1, "a", "one", 0},
+				{1, "a", "two", 2 * time.Second},
+				{1, "a", "three", 4 * time.Second},
+				{1, "a", "four", 6 * time.Second},
+				{1, "a", "five", 8 * time.Second},
+			},
+			want: [][]string{nil, nil, nil, nil, nil},
+		},
+		{
+			name: "chatspam.check.perserver",
+			messages: []message{
+				{1, "a", "one", 0},
+				{2, "a", "two", 0},
+				{1, "a", "three", 0},
+				{2, "a", "four", 0},
+				{1, "b", "five", 0},
+			},
+			want: [][]string{nil, nil, nil, nil, nil},
+		},
+		{
+			name: "chatspam.check.repeat",
+			messages: []message{
+				{1, "a", "buy gold", 0},
+				{1, "a", "Buy  Gold", 10 * time.Second},
+				{1, "a", "buy gold", 20 * time.Second},
+				{1, "a", "buy gold", 30 * time.Second},
+			},
+			want: [][]string{nil, nil, {DETECTION_REPEAT}, nil},
+		},
+		{
+			name: "chatspam.check.repeatbroken",
+			messages: []message{
+				{1, "a", "buy gold", 0},
+				{1, "a", "buy gold", 10 * time.Second},
+				{1, "a", "hello", 20 * time.Second},
+				{1, "a", "buy gold", 30 * time.Second},
+			},
+			want: [][]string{nil, nil, nil, nil},
+		},
+		{
+			name: "chatspam.check.caps",
+			messages: []message{
+				{1, "a", "STOP TEAMKILLING", 0},
+				{1, "a", "ok", 10 * time.Second},
+			},
+			want: [][]string{{DETECTION_CAPS}, nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := NewDetector()
+
+			var got [][]string
+			for _, m := range tt.messages {
+				got = append(got, detector.Check(m.serverID, m.player, m.message, start.Add(m.offset), settings))
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDetector_Forget(t *testing.T) {
+	settings := Settings{RepeatCount: 2}
+	now := time.Unix(1000, 0)
+
+	detector := NewDetector()
+	detector.Check(1, "a", "hello", now, settings)
+	detector.Forget(1, "a")
+
+	assert.Nil(t, detector.Check(1, "a", "hello", now, settings))
+	assert.Equal(t, []string{DETECTION_REPEAT}, detector.Check(1, "a", "hello", now, settings))
+}
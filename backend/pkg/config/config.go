@@ -17,7 +17,10 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 
 package config
 
-import "math"
+import (
+	"math"
+	"time"
+)
 
 var (
 	// Auth
@@ -33,6 +36,16 @@ var (
 	ServerGameMaxLen     = 32
 	ServerPasswordMinLen = 1
 	ServerPasswordMaxLen = 64
+	ServerLogPathMaxLen  = 256
+
+	// Log file event source
+	LogTailPollInterval = time.Millisecond * 500
+
+	// Backfills from archived log files
+	BackfillMaxFiles         = 500
+	BackfillProgressInterval = 5000 // lines read between progress updates
+	BackfillMaxLineLength    = 1024 * 1024
+	BackfillChatMatchWindow  = 60 // seconds between a log line and a chat message recorded live for them to match
 
 	// Infractions
 	InfractionReasonMinLen       = 1
@@ -48,6 +61,84 @@ var (
 	SearchLimitMin   = 1
 	SearchLimitMax   = 100
 
+	// Chat messages
+	ChatChannelMaxLen = 32
+	WhisperMaxLen     = 256
+
+	// In-game warnings. The template placeholders are {player}, {reason} and {count}.
+	WarnMessageTemplateMaxLen  = 256
+	DefaultWarnMessageTemplate = "{player}, you have been warned: {reason} (warning #{count})"
+
+	// Fuzzy player name search
+	SearchNameCandidateMax  = 500
+	SearchNameMinSimilarity = 0.6
+	SearchNameCurrentBonus  = 0.1
+	SearchNameRecencyBonus  = 0.05
+	SearchNameRecencyWindow = int64(60 * 60 * 24 * 30) // 30 days in seconds
+
 	// Players
-	RecentPlayersMaxSize = 22
+	RecentPlayersMaxSize  = 22
+	PlayerMergeUndoWindow = int64(60 * 60 * 24 * 7) // 7 days in seconds
+
+	// Chat filters
+	ChatFilterPatternMaxLen = 2048
+	ChatFilterDefaultReason = "Inappropriate chat message"
+
+	// Mute enforcement for games without native mute support
+	MuteReminderMessage     = "{player}, you are muted ({reason}). Please do not use chat until your mute expires"
+	MuteKickReason          = "Chatting while muted"
+	MuteEscalationThreshold = 3 // messages sent while muted before a player is kicked
+
+	// Punishments issued by admins in-game
+	InGamePunishmentDefaultReason = "Issued in-game"
+	InGamePunishmentDedupWindow   = int64(60) // seconds
+
+	// Chat keyword alerts
+	ChatKeywordMinLen     = 2
+	ChatKeywordMaxLen     = 64
+	ChatKeywordMaxPerUser = 50
+
+	// Chat spam detection. The defaults are used for servers which haven't had their spam settings changed.
+	ChatSpamDefaultFloodMessages = 5
+	ChatSpamDefaultFloodWindow   = 5 // seconds
+	ChatSpamDefaultRepeatCount   = 3
+	ChatSpamDefaultCapsPercent   = 80
+	ChatSpamDefaultCapsMinLength = 8
+	ChatSpamDefaultMuteDuration  = 10 // minutes
+	ChatSpamFloodWindowMax       = 300
+	ChatSpamThresholdMax         = 100
+	ChatSpamFloodReason          = "Chat flooding"
+	ChatSpamRepeatReason         = "Repeated chat messages"
+	ChatSpamCapsReason           = "Excessive use of capital letters"
+
+	// Teamkill detection. The defaults are used for servers which haven't had their teamkill settings changed.
+	TeamkillDefaultThreshold   = 3
+	TeamkillDefaultBanDuration = 60 // minutes
+	TeamkillThresholdMax       = 100
+	TeamkillReason             = "Excessive teamkilling"
+
+	// Match stats. Player teams are looked up through the player list command to tell teamkills apart from kills.
+	MatchTeamCacheTTL            = 30 * time.Second
+	MatchTeamMinRefreshInterval  = 5 * time.Second
+	PlayerSummaryMatchStatsLimit = 10
+
+	// Server status history. Samples older than the retention period are downsampled into one sample per hour.
+	ServerHistorySampleInterval     = time.Minute
+	ServerHistoryRetention          = 7 * 24 * time.Hour
+	ServerHistoryDownsamplePeriod   = time.Hour
+	ServerHistoryDownsampleInterval = time.Hour // how often old samples are checked for downsampling
+	ServerHistoryDefaultBuckets     = 60
+	ServerHistoryMaxBuckets         = 2000
+
+	// Chat review and context
+	ChatReviewContextSize  = 3 // messages shown before and after a flagged message
+	ChatContextDefaultSize = 10
+	ChatContextMaxSize     = 100
+
+	// Chat transcripts
+	ChatTranscriptChunkSize = 32 * 1024 // bytes buffered before a chunk of the transcript is sent
+
+	// Player data erasure
+	ErasedPlayerNamePrefix = "Erased-"
+	ErasedChatMessage      = "[erased]"
 )
@@ -0,0 +1,132 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package fuzzy
+
+import "strings"
+
+// Levenshtein returns the edit distance between a and b, counted in runes.
+func Levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+
+	if len(ar) == 0 {
+		return len(br)
+	}
+
+	if len(br) == 0 {
+		return len(ar)
+	}
+
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(br)]
+}
+
+// Similarity returns a score between 0 and 1 describing how closely candidate matches term. Matching is case
+// insensitive. Exact matches score 1, prefix matches 0.9 and substring matches 0.8. Anything else is scored by edit
+// distance against the whole candidate and against every term-sized window of it, so a typo in part of a longer name
+// still scores well.
+func Similarity(term, candidate string) float64 {
+	term = strings.ToLower(term)
+	candidate = strings.ToLower(candidate)
+
+	switch {
+	case term == "" || candidate == "":
+		return 0
+	case term == candidate:
+		return 1
+	case strings.HasPrefix(candidate, term):
+		return 0.9
+	case strings.Contains(candidate, term):
+		return 0.8
+	}
+
+	termLen := len([]rune(term))
+	candidateRunes := []rune(candidate)
+
+	best := ratio(Levenshtein(term, candidate), max(termLen, len(candidateRunes)))
+
+	// Compare against windows of the candidate which are roughly the same length as the term
+	for size := termLen - 1; size <= termLen+1; size++ {
+		if size < 1 || size > len(candidateRunes) {
+			continue
+		}
+
+		for start := 0; start+size <= len(candidateRunes); start++ {
+			window := string(candidateRunes[start : start+size])
+
+			if score := ratio(Levenshtein(term, window), max(termLen, size)); score > best {
+				best = score
+			}
+		}
+	}
+
+	// Cap fuzzy scores below substring matches so that exact substrings always rank first
+	if best > 0.75 {
+		best = 0.75
+	}
+
+	return best
+}
+
+func ratio(distance int, length int) float64 {
+	if length == 0 {
+		return 0
+	}
+
+	return 1 - float64(distance)/float64(length)
+}
+
+func min(values ...int) int {
+	m := values[0]
+
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
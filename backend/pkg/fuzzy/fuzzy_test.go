@@ -0,0 +1,57 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package fuzzy
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want int
+	}{
+		{name: "fuzzy.levenshtein.1", a: "", b: "", want: 0},
+		{name: "fuzzy.levenshtein.2", a: "abc", b: "", want: 3},
+		{name: "fuzzy.levenshtein.3", a: "kitten", b: "sitting", want: 3},
+		{name: "fuzzy.levenshtein.4", a: "flaw", b: "lawn", want: 2},
+		{name: "fuzzy.levenshtein.5", a: "jörg", b: "jorg", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Levenshtein(tt.a, tt.b))
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, Similarity("Player", "player"))
+	assert.Equal(t, 0.9, Similarity("play", "Player123"))
+	assert.Equal(t, 0.8, Similarity("yer", "Player123"))
+	assert.Equal(t, 0.0, Similarity("", "Player123"))
+
+	// A single typo inside a longer name should still score well, but below a substring match
+	typo := Similarity("plyer", "xXplayerXx")
+	assert.True(t, typo >= 0.6 && typo < 0.8, "typo score was %v", typo)
+
+	// Completely different strings should score poorly
+	assert.True(t, Similarity("abcdef", "zyxwvu") < 0.5)
+}
@@ -0,0 +1,179 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package logtail
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Tailer follows a log file and passes each line appended to it to a handler. Rotated files (a new file created at
+// the same path) and truncated files (copytruncate style rotation) are both detected, in which case the new file is
+// read from the beginning.
+type Tailer struct {
+	path     string
+	interval time.Duration
+
+	file    *os.File
+	info    os.FileInfo
+	reader  *bufio.Reader
+	offset  int64
+	partial string
+}
+
+func NewTailer(path string, pollInterval time.Duration) *Tailer {
+	return &Tailer{
+		path:     path,
+		interval: pollInterval,
+	}
+}
+
+// Follow reads lines appended to the file after Follow was called until stop is closed. Lines are passed to the
+// handler without their line endings. If the file can't be opened when Follow is called (e.g. in the middle of a
+// rotation), opening it is retried on each poll and the file is read from the beginning once it appears.
+func (t *Tailer) Follow(stop <-chan struct{}, handler func(line string)) {
+	opened := t.open(true) == nil
+
+	defer func() {
+		if t.file != nil {
+			_ = t.file.Close()
+		}
+	}()
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		if !opened {
+			opened = t.open(false) == nil
+		}
+
+		if opened {
+			t.readLines(handler)
+			t.checkRotation(handler)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// open opens the file at the tailer's path. If seekEnd is true, reading starts at the current end of the file.
+func (t *Tailer) open(seekEnd bool) error {
+	file, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	var offset int64
+	if seekEnd {
+		offset, err = file.Seek(0, io.SeekEnd)
+		if err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+
+	t.file = file
+	t.info = info
+	t.reader = bufio.NewReader(file)
+	t.offset = offset
+	t.partial = ""
+
+	return nil
+}
+
+// readLines reads all complete lines currently available. An incomplete trailing line is held until the rest of it
+// has been written.
+func (t *Tailer) readLines(handler func(line string)) {
+	for {
+		line, err := t.reader.ReadString('\n')
+		t.offset += int64(len(line))
+
+		if err != nil {
+			t.partial += line
+			return
+		}
+
+		line = strings.TrimRight(t.partial+line, "\r\n")
+		t.partial = ""
+
+		handler(line)
+	}
+}
+
+// checkRotation checks if the file at the tailer's path was replaced or truncated and if so, starts reading the new
+// contents from the beginning. If the path does not currently exist (e.g. in the middle of a rotation), it is checked
+// again on the next poll.
+func (t *Tailer) checkRotation(handler func(line string)) {
+	info, err := os.Stat(t.path)
+	if err != nil {
+		return
+	}
+
+	if !os.SameFile(info, t.info) {
+		// Read anything written to the old file before it was rotated
+		t.readLines(handler)
+		t.flushPartial(handler)
+
+		oldFile := t.file
+		if err := t.open(false); err != nil {
+			return
+		}
+
+		_ = oldFile.Close()
+		t.readLines(handler)
+		return
+	}
+
+	if info.Size() < t.offset {
+		if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+			return
+		}
+
+		t.reader.Reset(t.file)
+		t.offset = 0
+		t.partial = ""
+		t.readLines(handler)
+	}
+}
+
+// flushPartial passes a held incomplete line to the handler. It is used when a file is rotated since nothing more
+// will be written to the end of it.
+func (t *Tailer) flushPartial(handler func(line string)) {
+	if t.partial == "" {
+		return
+	}
+
+	line := strings.TrimRight(t.partial, "\r\n")
+	t.partial = ""
+
+	handler(line)
+}
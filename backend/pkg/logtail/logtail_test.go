@@ -0,0 +1,166 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package logtail
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testInterval = time.Millisecond * 10
+
+// startTailer starts following path and returns a channel which receives each line read along with a function to
+// stop the tailer.
+func startTailer(t *testing.T, path string) (chan string, func()) {
+	lines := make(chan string, 100)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	tailer := NewTailer(path, testInterval)
+
+	go func() {
+		defer close(done)
+
+		tailer.Follow(stop, func(line string) {
+			lines <- line
+		})
+	}()
+
+	// Give the tailer time to open the file before anything is written
+	time.Sleep(testInterval * 3)
+
+	return lines, func() {
+		close(stop)
+		<-done
+	}
+}
+
+func collectLines(lines chan string, count int) []string {
+	var collected []string
+
+	timeout := time.After(time.Second * 2)
+
+	for len(collected) < count {
+		select {
+		case line := <-lines:
+			collected = append(collected, line)
+		case <-timeout:
+			return collected
+		}
+	}
+
+	return collected
+}
+
+func appendToFile(t *testing.T, path string, data string) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	assert.Nil(t, err)
+
+	_, err = file.WriteString(data)
+	assert.Nil(t, err)
+
+	assert.Nil(t, file.Close())
+}
+
+func TestTailer_Follow(t *testing.T) {
+	tests := []struct {
+		name    string
+		initial string
+		steps   func(t *testing.T, path string)
+		want    []string
+	}{
+		{
+			name:    "logtail.follow.1",
+			initial: "old line\n",
+			steps: func(t *testing.T, path string) {
+				appendToFile(t, path, "line one\nline two\r\n")
+			},
+			want: []string{"line one", "line two"},
+		},
+		{
+			name:    "logtail.follow.2",
+			initial: "",
+			steps: func(t *testing.T, path string) {
+				appendToFile(t, path, "partial")
+				time.Sleep(testInterval * 3)
+				appendToFile(t, path, " line\n")
+			},
+			want: []string{"partial line"},
+		},
+		{
+			name:    "logtail.follow.3",
+			initial: "old line\n",
+			steps: func(t *testing.T, path string) {
+				appendToFile(t, path, "before rotation\n")
+				time.Sleep(testInterval * 3)
+				assert.Nil(t, os.Rename(path, path+".1"))
+				appendToFile(t, path, "after rotation\n")
+			},
+			want: []string{"before rotation", "after rotation"},
+		},
+		{
+			name:    "logtail.follow.4",
+			initial: "a fairly long line which was written before truncation\n",
+			steps: func(t *testing.T, path string) {
+				assert.Nil(t, os.Truncate(path, 0))
+				time.Sleep(testInterval * 3)
+				appendToFile(t, path, "after truncation\n")
+			},
+			want: []string{"after truncation"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "logtail")
+			assert.Nil(t, err)
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "server.log")
+			assert.Nil(t, ioutil.WriteFile(path, []byte(tt.initial), 0644))
+
+			lines, stop := startTailer(t, path)
+			tt.steps(t, path)
+
+			got := collectLines(lines, len(tt.want))
+			stop()
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTailer_FollowMissingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logtail")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "server.log")
+
+	// The file is created after the tailer starts, so everything in it is new
+	lines, stop := startTailer(t, path)
+	appendToFile(t, path, "first line\nsecond line\n")
+
+	got := collectLines(lines, 2)
+	stop()
+
+	assert.Equal(t, []string{"first line", "second line"}, got)
+}
@@ -0,0 +1,138 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package matchstats
+
+import (
+	"sync"
+	"time"
+)
+
+// Actions taken when a player crosses the teamkill threshold in a match
+const (
+	ACTION_ALERT = "ALERT"
+	ACTION_WARN  = "WARN"
+	ACTION_KICK  = "KICK"
+	ACTION_BAN   = "BAN"
+)
+
+var Actions = []string{ACTION_ALERT, ACTION_WARN, ACTION_KICK, ACTION_BAN}
+
+// PlayerStats holds a player's kills, deaths and teamkills in a single match. Teamkills are not counted as kills.
+type PlayerStats struct {
+	Kills     int
+	Deaths    int
+	Teamkills int
+}
+
+// Match holds the stats of every player who killed or died in a match, keyed by their game ID.
+type Match struct {
+	Start   time.Time
+	End     time.Time
+	Players map[string]*PlayerStats
+}
+
+// Tracker keeps track of the current match on each server.
+type Tracker struct {
+	matches map[int64]*Match
+	mu      sync.Mutex
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{
+		matches: map[int64]*Match{},
+	}
+}
+
+// StartMatch starts a new match on a server. If a match was already in progress, it is ended and returned.
+func (t *Tracker) StartMatch(serverID int64, now time.Time) *Match {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ended := t.end(serverID, now)
+
+	t.matches[serverID] = newMatch(now)
+
+	return ended
+}
+
+// EndMatch ends the match in progress on a server and returns it. If no match was in progress, nil is returned.
+func (t *Tracker) EndMatch(serverID int64, now time.Time) *Match {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.end(serverID, now)
+}
+
+// RecordKill records a kill in the match in progress on a server. A match is started if none is in progress so that
+// kills are still tracked if the start of the match was missed. Kills without a killer and kills where the killer is
+// the victim only count as a death. The killer's stats after the kill are returned.
+func (t *Tracker) RecordKill(serverID int64, killer string, victim string, teamkill bool, now time.Time) PlayerStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	match := t.matches[serverID]
+	if match == nil {
+		match = newMatch(now)
+		t.matches[serverID] = match
+	}
+
+	match.getPlayer(victim).Deaths++
+
+	if killer == "" || killer == victim {
+		return PlayerStats{}
+	}
+
+	killerStats := match.getPlayer(killer)
+
+	if teamkill {
+		killerStats.Teamkills++
+	} else {
+		killerStats.Kills++
+	}
+
+	return *killerStats
+}
+
+func (t *Tracker) end(serverID int64, now time.Time) *Match {
+	match := t.matches[serverID]
+	if match == nil {
+		return nil
+	}
+
+	delete(t.matches, serverID)
+	match.End = now
+
+	return match
+}
+
+func newMatch(start time.Time) *Match {
+	return &Match{
+		Start:   start,
+		Players: map[string]*PlayerStats{},
+	}
+}
+
+func (m *Match) getPlayer(playerGameID string) *PlayerStats {
+	stats := m.Players[playerGameID]
+	if stats == nil {
+		stats = &PlayerStats{}
+		m.Players[playerGameID] = stats
+	}
+
+	return stats
+}
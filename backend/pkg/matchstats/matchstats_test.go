@@ -0,0 +1,108 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package matchstats
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTracker_RecordKill(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	type kill struct {
+		killer   string
+		victim   string
+		teamkill bool
+	}
+
+	tests := []struct {
+		name        string
+		kills       []kill
+		wantKiller  PlayerStats
+		wantPlayers map[string]*PlayerStats
+	}{
+		{
+			name:       "matchstats.recordkill.1",
+			kills:      []kill{{"A", "B", false}, {"A", "C", false}},
+			wantKiller: PlayerStats{Kills: 2},
+			wantPlayers: map[string]*PlayerStats{
+				"A": {Kills: 2},
+				"B": {Deaths: 1},
+				"C": {Deaths: 1},
+			},
+		},
+		{
+			name:       "matchstats.recordkill.2",
+			kills:      []kill{{"A", "B", true}, {"B", "A", false}, {"A", "C", true}},
+			wantKiller: PlayerStats{Deaths: 1, Teamkills: 2},
+			wantPlayers: map[string]*PlayerStats{
+				"A": {Deaths: 1, Teamkills: 2},
+				"B": {Kills: 1, Deaths: 1},
+				"C": {Deaths: 1},
+			},
+		},
+		{
+			name:       "matchstats.recordkill.3",
+			kills:      []kill{{"A", "A", false}, {"", "B", false}},
+			wantKiller: PlayerStats{},
+			wantPlayers: map[string]*PlayerStats{
+				"A": {Deaths: 1},
+				"B": {Deaths: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewTracker()
+
+			var killer PlayerStats
+			for _, k := range tt.kills {
+				killer = tracker.RecordKill(1, k.killer, k.victim, k.teamkill, start)
+			}
+
+			match := tracker.EndMatch(1, start.Add(time.Minute))
+
+			assert.Equal(t, tt.wantKiller, killer)
+			assert.Equal(t, tt.wantPlayers, match.Players)
+			assert.Equal(t, start, match.Start)
+			assert.Equal(t, start.Add(time.Minute), match.End)
+		})
+	}
+}
+
+func TestTracker_StartMatch(t *testing.T) {
+	start := time.Unix(1000, 0)
+	tracker := NewTracker()
+
+	assert.Nil(t, tracker.StartMatch(1, start), "No match should have been in progress")
+
+	tracker.RecordKill(1, "A", "B", false, start)
+	tracker.RecordKill(2, "C", "D", false, start)
+
+	ended := tracker.StartMatch(1, start.Add(time.Hour))
+	assert.NotNil(t, ended, "The previous match should have been ended")
+	assert.Equal(t, 2, len(ended.Players))
+	assert.Equal(t, start.Add(time.Hour), ended.End)
+
+	// The new match on server 1 should be empty and server 2's match should be untouched
+	assert.Equal(t, 0, len(tracker.EndMatch(1, start.Add(2*time.Hour)).Players))
+	assert.Equal(t, 2, len(tracker.EndMatch(2, start.Add(2*time.Hour)).Players))
+	assert.Nil(t, tracker.EndMatch(2, start.Add(2*time.Hour)))
+}
@@ -0,0 +1,94 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package settingscache
+
+import (
+	"sync"
+)
+
+// Cache holds the settings of each server. Settings are loaded the first time they are needed and kept until they are
+// updated through the cache.
+type Cache struct {
+	load     func(serverID int64) (interface{}, error)
+	settings map[int64]interface{}
+	mu       sync.RWMutex
+}
+
+// New creates a Cache which loads a server's settings using load. load should return the default settings for servers
+// without stored settings.
+func New(load func(serverID int64) (interface{}, error)) *Cache {
+	return &Cache{
+		load:     load,
+		settings: map[int64]interface{}{},
+	}
+}
+
+// Get returns a server's settings, loading them if they aren't cached. The returned settings are shared and must not
+// be modified. Use Update to change them.
+func (c *Cache) Get(serverID int64) (interface{}, error) {
+	c.mu.RLock()
+	settings := c.settings[serverID]
+	c.mu.RUnlock()
+
+	if settings != nil {
+		return settings, nil
+	}
+
+	settings, err := c.load(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Another caller may have loaded or updated the settings in the meantime
+	if cached := c.settings[serverID]; cached != nil {
+		return cached, nil
+	}
+
+	c.settings[serverID] = settings
+
+	return settings, nil
+}
+
+// Update replaces a server's settings with the result of update, which is passed the current settings. update must
+// work on a copy of the settings it is passed and should save the new settings before returning them, so that the
+// cached settings are left untouched if saving fails. Updates of the same cache are run one at a time.
+func (c *Cache) Update(serverID int64, update func(current interface{}) (interface{}, error)) (interface{}, error) {
+	current, err := c.Get(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached := c.settings[serverID]; cached != nil {
+		current = cached
+	}
+
+	updated, err := update(current)
+	if err != nil {
+		return nil, err
+	}
+
+	c.settings[serverID] = updated
+
+	return updated, nil
+}
@@ -0,0 +1,79 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package settingscache
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testSettings struct {
+	ServerID int64
+	Value    int
+}
+
+func TestCache(t *testing.T) {
+	loads := 0
+
+	cache := New(func(serverID int64) (interface{}, error) {
+		loads++
+
+		if serverID == 0 {
+			return nil, errors.New("invalid server ID")
+		}
+
+		return &testSettings{ServerID: serverID, Value: 1}, nil
+	})
+
+	// Settings are only loaded once
+	settings, err := cache.Get(1)
+	assert.Nil(t, err)
+	assert.Equal(t, &testSettings{ServerID: 1, Value: 1}, settings)
+
+	_, _ = cache.Get(1)
+	assert.Equal(t, 1, loads)
+
+	_, err = cache.Get(0)
+	assert.NotNil(t, err)
+
+	// A failed update leaves the cached settings untouched
+	_, err = cache.Update(1, func(current interface{}) (interface{}, error) {
+		updated := *current.(*testSettings)
+		updated.Value = 2
+
+		return nil, errors.New("could not save")
+	})
+	assert.NotNil(t, err)
+
+	settings, _ = cache.Get(1)
+	assert.Equal(t, &testSettings{ServerID: 1, Value: 1}, settings)
+
+	updated, err := cache.Update(1, func(current interface{}) (interface{}, error) {
+		updated := *current.(*testSettings)
+		updated.Value = 3
+
+		return &updated, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &testSettings{ServerID: 1, Value: 3}, updated)
+
+	settings, _ = cache.Get(1)
+	assert.Equal(t, &testSettings{ServerID: 1, Value: 3}, settings)
+	assert.Equal(t, 2, loads)
+}
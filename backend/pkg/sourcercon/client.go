@@ -0,0 +1,332 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package sourcercon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+var (
+	ErrAuthFailed   = errors.New("RCON authentication failed")
+	ErrNotConnected = errors.New("not connected")
+	ErrTimeout      = errors.New("timed out waiting for a response")
+)
+
+const (
+	defaultTimeout           = 10 * time.Second
+	defaultHeartbeatInterval = 30 * time.Second
+
+	// broadcastQueueSize is how many broadcasts can wait for the broadcast handler before reading packets stalls
+	broadcastQueueSize = 256
+)
+
+// ClientConfig holds the settings of a Client. Host, Port and Password are required.
+type ClientConfig struct {
+	Host     string
+	Port     uint16
+	Password string
+
+	// Timeout is how long connecting and running a command may take. Default: 10 seconds.
+	Timeout time.Duration
+
+	// SendHeartbeat enables an empty packet being sent every HeartbeatInterval to keep the connection alive.
+	// Default interval: 30 seconds.
+	SendHeartbeat     bool
+	HeartbeatInterval time.Duration
+
+	// BroadcastHandler is called with the body of every packet the server sends without being asked, such as
+	// Squad's chat messages. It is only called once ListenForBroadcasts has been called. It runs on its own goroutine,
+	// so it may run commands.
+	EnableBroadcasts bool
+	BroadcastHandler func(message string)
+
+	// DisconnectHandler is called once when the connection is closed. Expected is true if the connection was closed
+	// by a call to Disconnect.
+	DisconnectHandler func(err error, expected bool)
+}
+
+// Client is a Source RCON client. Commands are run one at a time over a single connection, which broadcasts are also
+// received on. Responses split across several packets are joined back together.
+type Client struct {
+	address string
+	config  *ClientConfig
+
+	conn       net.Conn
+	writeMu    sync.Mutex
+	execMu     sync.Mutex
+	nextID     int32
+	responses  chan *Packet
+	broadcasts chan string
+	closed     chan struct{}
+
+	mu           sync.Mutex
+	listening    bool
+	disconnected bool
+}
+
+func NewClient(config *ClientConfig) *Client {
+	if config.Timeout == 0 {
+		config.Timeout = defaultTimeout
+	}
+
+	if config.HeartbeatInterval == 0 {
+		config.HeartbeatInterval = defaultHeartbeatInterval
+	}
+
+	return &Client{
+		address: net.JoinHostPort(config.Host, fmt.Sprintf("%d", config.Port)),
+		config:  config,
+	}
+}
+
+// Connect opens a connection to the server and authenticates with it
+func (c *Client) Connect() error {
+	conn, err := net.DialTimeout("tcp", c.address, c.config.Timeout)
+	if err != nil {
+		return err
+	}
+
+	if err := c.authenticate(conn); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	c.conn = conn
+	c.responses = make(chan *Packet, 64)
+	c.broadcasts = make(chan string, broadcastQueueSize)
+	c.closed = make(chan struct{})
+
+	go c.readPackets()
+	go c.dispatchBroadcasts()
+
+	if c.config.SendHeartbeat {
+		go c.sendHeartbeats()
+	}
+
+	return nil
+}
+
+// authenticate sends the password and waits for the auth response. Servers send an empty response value packet
+// before the auth response, which is skipped. An ID of -1 in the auth response means the password was wrong.
+func (c *Client) authenticate(conn net.Conn) error {
+	id := c.newID()
+
+	if err := conn.SetDeadline(time.Now().Add(c.config.Timeout)); err != nil {
+		return err
+	}
+
+	if err := WritePacket(conn, &Packet{ID: id, Type: TypeAuth, Body: c.config.Password}); err != nil {
+		return err
+	}
+
+	for {
+		packet, err := ReadPacket(conn)
+		if err != nil {
+			return err
+		}
+
+		if packet.Type != TypeAuthResponse {
+			continue
+		}
+
+		if packet.ID != id {
+			return ErrAuthFailed
+		}
+
+		return conn.SetDeadline(time.Time{})
+	}
+}
+
+// ExecCommand runs a command and returns its output. To know when a response split across several packets is
+// complete, an empty response value packet is sent right after the command. Servers answer packets in order, so once
+// that packet is mirrored back every part of the command's response has been received.
+func (c *Client) ExecCommand(command string) (string, error) {
+	if c.conn == nil {
+		return "", ErrNotConnected
+	}
+
+	c.execMu.Lock()
+	defer c.execMu.Unlock()
+
+	select {
+	case <-c.closed:
+		return "", ErrNotConnected
+	default:
+	}
+
+	id := c.newID()
+	endID := c.newID()
+
+	if err := c.write(&Packet{ID: id, Type: TypeExecCommand, Body: command}); err != nil {
+		return "", err
+	}
+
+	if err := c.write(&Packet{ID: endID, Type: TypeResponseValue}); err != nil {
+		return "", err
+	}
+
+	var output string
+
+	timeout := time.NewTimer(c.config.Timeout)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case packet := <-c.responses:
+			switch packet.ID {
+			case id:
+				output += packet.Body
+			case endID:
+				return output, nil
+			}
+
+			// Anything else is left over from an earlier command (e.g. the extra packet some servers send after
+			// mirroring the end packet) or a heartbeat, so it is ignored.
+		case <-c.closed:
+			return "", ErrNotConnected
+		case <-timeout.C:
+			return "", ErrTimeout
+		}
+	}
+}
+
+// ListenForBroadcasts starts passing broadcasts to the broadcast handler. Source RCON has no broadcast channels, so
+// every broadcast is passed on. Nothing is done if broadcasts aren't enabled.
+func (c *Client) ListenForBroadcasts(channels []string, errs chan error) {
+	if !c.config.EnableBroadcasts {
+		return
+	}
+
+	c.mu.Lock()
+	c.listening = true
+	c.mu.Unlock()
+}
+
+// Disconnect closes the connection to the server
+func (c *Client) Disconnect() error {
+	if c.conn == nil {
+		return ErrNotConnected
+	}
+
+	c.mu.Lock()
+	c.disconnected = true
+	c.mu.Unlock()
+
+	return c.conn.Close()
+}
+
+// readPackets reads packets until the connection is closed. Broadcasts are queued for the broadcast handler and
+// everything else is passed to the command waiting for it. The handler isn't called from here since commands it runs
+// would wait on responses which only this goroutine reads.
+func (c *Client) readPackets() {
+	for {
+		packet, err := ReadPacket(c.conn)
+		if err != nil {
+			close(c.broadcasts)
+			close(c.closed)
+			_ = c.conn.Close()
+
+			c.mu.Lock()
+			expected := c.disconnected
+			c.mu.Unlock()
+
+			if c.config.DisconnectHandler != nil {
+				if expected {
+					err = nil
+				}
+
+				c.config.DisconnectHandler(err, expected)
+			}
+
+			return
+		}
+
+		if packet.Type == TypeBroadcast {
+			c.mu.Lock()
+			listening := c.listening
+			c.mu.Unlock()
+
+			if listening && c.config.BroadcastHandler != nil {
+				c.broadcasts <- packet.Body
+			}
+
+			continue
+		}
+
+		select {
+		case c.responses <- packet:
+		default:
+			// Nobody is reading responses, so the oldest one is dropped to make room
+			select {
+			case <-c.responses:
+			default:
+			}
+			c.responses <- packet
+		}
+	}
+}
+
+// dispatchBroadcasts passes queued broadcasts to the broadcast handler in the order they were received until the
+// connection is closed.
+func (c *Client) dispatchBroadcasts() {
+	for message := range c.broadcasts {
+		c.config.BroadcastHandler(message)
+	}
+}
+
+// sendHeartbeats sends an empty response value packet every heartbeat interval. Servers mirror it back, and the
+// mirrored packet is ignored since no command is waiting for its ID.
+func (c *Client) sendHeartbeats() {
+	ticker := time.NewTicker(c.config.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.write(&Packet{ID: c.newID(), Type: TypeResponseValue}); err != nil {
+				return
+			}
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+func (c *Client) write(packet *Packet) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	return WritePacket(c.conn, packet)
+}
+
+// newID returns the next packet ID. IDs are always positive since -1 is used by servers to signal failed auth.
+func (c *Client) newID() int32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	if c.nextID < 1 {
+		c.nextID = 1
+	}
+
+	return c.nextID
+}
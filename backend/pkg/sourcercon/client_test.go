@@ -0,0 +1,206 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package sourcercon
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPassword = "rconpassword"
+
+func newTestClient(t *testing.T, server *FakeServer, config *ClientConfig) *Client {
+	config.Host = server.Host()
+	config.Port = server.Port()
+	config.Timeout = time.Second
+
+	if config.Password == "" {
+		config.Password = testPassword
+	}
+
+	return NewClient(config)
+}
+
+func TestClient_Connect(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+	}{
+		{
+			name:     "sourcercon.connect.1",
+			password: testPassword,
+			wantErr:  nil,
+		},
+		{
+			name:     "sourcercon.connect.2",
+			password: "wrongpassword",
+			wantErr:  ErrAuthFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, err := NewFakeServer(testPassword, map[string]string{})
+			assert.Nil(t, err)
+			defer server.Close()
+
+			client := newTestClient(t, server, &ClientConfig{Password: tt.password})
+
+			assert.Equal(t, tt.wantErr, client.Connect())
+		})
+	}
+}
+
+func TestClient_ExecCommand(t *testing.T) {
+	longResponse := strings.Repeat("ID: 0 | SteamID: 76561198000000000 | Name: Player\n", 250)
+
+	tests := []struct {
+		name     string
+		commands []string
+		want     []string
+	}{
+		{
+			name:     "sourcercon.execcommand.1",
+			commands: []string{"ShowCurrentMap"},
+			want:     []string{"Current level is Narva, layer is Narva RAAS v1"},
+		},
+		{
+			name:     "sourcercon.execcommand.2",
+			commands: []string{"ListPlayers", "ShowCurrentMap"},
+			want:     []string{longResponse, "Current level is Narva, layer is Narva RAAS v1"},
+		},
+		{
+			name:     "sourcercon.execcommand.3",
+			commands: []string{"Unknown", "Unknown"},
+			want:     []string{"", ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, err := NewFakeServer(testPassword, map[string]string{
+				"ShowCurrentMap": "Current level is Narva, layer is Narva RAAS v1",
+				"ListPlayers":    longResponse,
+			})
+			assert.Nil(t, err)
+			defer server.Close()
+
+			client := newTestClient(t, server, &ClientConfig{})
+			assert.Nil(t, client.Connect())
+
+			var got []string
+			for _, command := range tt.commands {
+				output, err := client.ExecCommand(command)
+				assert.Nil(t, err)
+
+				got = append(got, output)
+			}
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.commands, server.Commands())
+		})
+	}
+}
+
+func TestClient_Broadcasts(t *testing.T) {
+	server, err := NewFakeServer(testPassword, map[string]string{})
+	assert.Nil(t, err)
+	defer server.Close()
+
+	broadcasts := make(chan string, 1)
+	disconnects := make(chan bool, 1)
+
+	client := newTestClient(t, server, &ClientConfig{
+		EnableBroadcasts: true,
+		BroadcastHandler: func(message string) {
+			broadcasts <- message
+		},
+		DisconnectHandler: func(err error, expected bool) {
+			disconnects <- expected
+		},
+	})
+	assert.Nil(t, client.Connect())
+
+	client.ListenForBroadcasts(nil, nil)
+
+	// Broadcasts received while a command is running shouldn't end up in its output
+	assert.Nil(t, server.Broadcast("[ChatAll] [SteamID:76561198000000000] Player : hello"))
+
+	output, err := client.ExecCommand("Unknown")
+	assert.Nil(t, err)
+	assert.Equal(t, "", output)
+
+	select {
+	case message := <-broadcasts:
+		assert.Equal(t, "[ChatAll] [SteamID:76561198000000000] Player : hello", message)
+	case <-time.After(time.Second):
+		t.Fatal("Broadcast was not received")
+	}
+
+	// Closing the server should be reported as an unexpected disconnect
+	assert.Nil(t, server.Close())
+
+	select {
+	case expected := <-disconnects:
+		assert.False(t, expected)
+	case <-time.After(time.Second):
+		t.Fatal("Disconnect was not reported")
+	}
+
+	_, err = client.ExecCommand("Unknown")
+	assert.Equal(t, ErrNotConnected, err)
+}
+
+func TestClient_BroadcastHandlerExecCommand(t *testing.T) {
+	server, err := NewFakeServer(testPassword, map[string]string{
+		"ShowCurrentMap": "Current level is Narva, layer is Narva RAAS v1",
+	})
+	assert.Nil(t, err)
+	defer server.Close()
+
+	type result struct {
+		output string
+		err    error
+	}
+
+	results := make(chan result, 1)
+
+	// Handlers run commands in response to broadcasts, e.g. to kick a player for what they said in chat
+	var client *Client
+	client = newTestClient(t, server, &ClientConfig{
+		EnableBroadcasts: true,
+		BroadcastHandler: func(message string) {
+			output, err := client.ExecCommand("ShowCurrentMap")
+			results <- result{output, err}
+		},
+	})
+	assert.Nil(t, client.Connect())
+
+	client.ListenForBroadcasts(nil, nil)
+
+	assert.Nil(t, server.Broadcast("[ChatAll] [SteamID:76561198000000000] Player : hello"))
+
+	select {
+	case res := <-results:
+		assert.Nil(t, res.err)
+		assert.Equal(t, "Current level is Narva, layer is Narva RAAS v1", res.output)
+	case <-time.After(time.Second * 2):
+		t.Fatal("Broadcast handler did not finish")
+	}
+}
@@ -0,0 +1,171 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package sourcercon
+
+import (
+	"net"
+	"strconv"
+	"sync"
+)
+
+// FakeServer is a local Source RCON server for testing clients and the games which use them. It behaves like a
+// Source dedicated server: responses longer than MaxBodySize are split across several packets, and empty response
+// value packets are mirrored back followed by an extra packet with the body 0x00 0x01 0x00 0x00.
+type FakeServer struct {
+	password  string
+	listener  net.Listener
+	responses map[string]string
+
+	mu       sync.Mutex
+	conns    []net.Conn
+	commands []string
+}
+
+// NewFakeServer starts a fake server listening on a random local port. Commands with an entry in responses are
+// answered with it, and every other command gets an empty response.
+func NewFakeServer(password string, responses map[string]string) (*FakeServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FakeServer{
+		password:  password,
+		listener:  listener,
+		responses: responses,
+	}
+
+	go s.accept()
+
+	return s, nil
+}
+
+// Host returns the host the server is listening on
+func (s *FakeServer) Host() string {
+	host, _, _ := net.SplitHostPort(s.listener.Addr().String())
+	return host
+}
+
+// Port returns the port the server is listening on
+func (s *FakeServer) Port() uint16 {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	portNum, _ := strconv.ParseUint(port, 10, 16)
+	return uint16(portNum)
+}
+
+// Commands returns the commands the server has received so far
+func (s *FakeServer) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.commands...)
+}
+
+// Broadcast sends a broadcast packet to every authenticated connection
+func (s *FakeServer) Broadcast(message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, conn := range s.conns {
+		if err := WritePacket(conn, &Packet{Type: TypeBroadcast, Body: message}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close stops the server and closes every connection to it
+func (s *FakeServer) Close() error {
+	s.mu.Lock()
+	for _, conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+
+	return s.listener.Close()
+}
+
+func (s *FakeServer) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go s.handle(conn)
+	}
+}
+
+func (s *FakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		packet, err := ReadPacket(conn)
+		if err != nil {
+			return
+		}
+
+		switch packet.Type {
+		case TypeAuth:
+			id := packet.ID
+			if packet.Body != s.password {
+				id = -1
+			}
+
+			s.mu.Lock()
+			err = WritePacket(conn, &Packet{ID: packet.ID, Type: TypeResponseValue})
+			if err == nil {
+				err = WritePacket(conn, &Packet{ID: id, Type: TypeAuthResponse})
+			}
+			if err == nil && id != -1 {
+				s.conns = append(s.conns, conn)
+			}
+			s.mu.Unlock()
+		case TypeExecCommand:
+			s.mu.Lock()
+			s.commands = append(s.commands, packet.Body)
+			response := s.responses[packet.Body]
+
+			for {
+				chunk := response
+				if len(chunk) > MaxBodySize {
+					chunk = chunk[:MaxBodySize]
+				}
+				response = response[len(chunk):]
+
+				if err = WritePacket(conn, &Packet{ID: packet.ID, Type: TypeResponseValue, Body: chunk}); err != nil ||
+					response == "" {
+					break
+				}
+			}
+			s.mu.Unlock()
+		case TypeResponseValue:
+			s.mu.Lock()
+			err = WritePacket(conn, &Packet{ID: packet.ID, Type: TypeResponseValue})
+			if err == nil {
+				err = WritePacket(conn, &Packet{ID: packet.ID, Type: TypeResponseValue, Body: "\x00\x01\x00\x00"})
+			}
+			s.mu.Unlock()
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,97 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package sourcercon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Packet types of the Source RCON protocol. Exec commands and auth responses share the same value, so the direction
+// a packet travels in decides its meaning.
+const (
+	TypeResponseValue = 0
+	TypeBroadcast     = 1 // sent by some servers (e.g. Squad) for chat and other events without a request
+	TypeExecCommand   = 2
+	TypeAuthResponse  = 2
+	TypeAuth          = 3
+)
+
+const (
+	// packetHeaderSize is the size of a packet's ID and type plus the two null bytes ending its body
+	packetHeaderSize = 10
+
+	// MaxBodySize is the largest body servers put in a single response packet. Longer responses are split across
+	// several packets.
+	MaxBodySize = 4096
+
+	// maxPacketSize is the largest packet accepted. It is well above what servers send so that servers which don't
+	// split their responses still work.
+	maxPacketSize = 1024 * 1024
+)
+
+// Packet is a single Source RCON packet
+type Packet struct {
+	ID   int32
+	Type int32
+	Body string
+}
+
+// WritePacket writes a packet in the Source RCON wire format: its size, ID and type as little endian 32 bit integers
+// followed by its null terminated body and an empty null terminated string.
+func WritePacket(w io.Writer, packet *Packet) error {
+	buf := &bytes.Buffer{}
+
+	size := int32(len(packet.Body) + packetHeaderSize)
+	for _, value := range []int32{size, packet.ID, packet.Type} {
+		if err := binary.Write(buf, binary.LittleEndian, value); err != nil {
+			return err
+		}
+	}
+
+	buf.WriteString(packet.Body)
+	buf.Write([]byte{0, 0})
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// ReadPacket reads a single packet in the Source RCON wire format
+func ReadPacket(r io.Reader) (*Packet, error) {
+	var size int32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return nil, err
+	}
+
+	if size < packetHeaderSize || size > maxPacketSize {
+		return nil, fmt.Errorf("invalid packet size: %d", size)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	return &Packet{
+		ID:   int32(binary.LittleEndian.Uint32(data[0:4])),
+		Type: int32(binary.LittleEndian.Uint32(data[4:8])),
+		Body: string(bytes.TrimRight(data[8:], "\x00")),
+	}, nil
+}
@@ -30,6 +30,7 @@ type Pool struct {
 	Unregister chan *Client
 	Broadcast  chan *refractor.WebsocketMessage
 	SendDirect chan *refractor.WebsocketDirectMessage
+	SendToUser chan *refractor.WebsocketUserMessage
 	log        log.Logger
 }
 
@@ -40,6 +41,7 @@ func NewPool(log log.Logger) *Pool {
 		Unregister: make(chan *Client),
 		Broadcast:  make(chan *refractor.WebsocketMessage),
 		SendDirect: make(chan *refractor.WebsocketDirectMessage),
+		SendToUser: make(chan *refractor.WebsocketUserMessage),
 		log:        log,
 	}
 }
@@ -80,12 +82,35 @@ func (pool *Pool) Start() {
 				continue
 			}
 
+			// The client may have disconnected since the message was queued
 			client := pool.Clients[sendParams.ClientID]
+			if client == nil {
+				continue
+			}
 
 			if err := wsutil.WriteServerText(client.Conn, msgBytes); err != nil {
 				pool.log.Warn("Could not send direct message to client ID %d. Error: %v\n", client.ID, err)
 			}
 
+			break
+		case sendParams := <-pool.SendToUser:
+			msgBytes, err := json.Marshal(sendParams.Message)
+			if err != nil {
+				pool.log.Error("Could not marshal user message. Error: %v\n", err)
+				continue
+			}
+
+			// Clients are resolved here since the pool is the only place they can safely be read from
+			for _, client := range pool.Clients {
+				if client == nil || client.UserID != sendParams.UserID {
+					continue
+				}
+
+				if err := wsutil.WriteServerText(client.Conn, msgBytes); err != nil {
+					pool.log.Warn("Could not send user message to client ID %d. Error: %v\n", client.ID, err)
+				}
+			}
+
 			break
 		}
 	}
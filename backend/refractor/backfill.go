@@ -0,0 +1,84 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package refractor
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/sniddunc/refractor/internal/params"
+)
+
+// BackfillProgress describes a backfill of a server's history from archived log files. Counts of created records
+// exclude records which already existed, so running a backfill over the same files again creates nothing new.
+type BackfillProgress struct {
+	ServerID        int64    `json:"serverId"`
+	Running         bool     `json:"running"`
+	Files           []string `json:"files"`
+	FilesDone       int      `json:"filesDone"`
+	CurrentFile     string   `json:"currentFile"`
+	BytesTotal      int64    `json:"bytesTotal"`
+	BytesRead       int64    `json:"bytesRead"`
+	LinesRead       int64    `json:"linesRead"`
+	PlayersCreated  int      `json:"playersCreated"`
+	SessionsCreated int      `json:"sessionsCreated"`
+	MessagesCreated int      `json:"messagesCreated"`
+	Duplicates      int      `json:"duplicates"`
+	StartedBy       int64    `json:"startedBy"`
+	StartedAt       int64    `json:"startedAt"`
+	FinishedAt      int64    `json:"finishedAt"`
+	Error           string   `json:"error,omitempty"`
+}
+
+// BackfillRepository records historical events. Each method only creates records which don't already exist so that
+// backfills are idempotent.
+type BackfillRepository interface {
+	// FindPlayerID returns the ID of the player with the given game ID. ErrNotFound is returned if they don't exist.
+	FindPlayerID(gameIDField string, gameID string) (int64, error)
+	CreatePlayer(gameIDField string, gameID string, name string, timestamp int64) (int64, error)
+
+	// RecordName records that a player used a name at the given time. If the name was already recorded, the later
+	// of the two times is kept.
+	RecordName(playerID int64, name string, timestamp int64) error
+
+	// RecordSession creates a closed session. It returns false if a session with the same player, server and join
+	// time already exists, in which case the existing session's quit time is moved to the later of the two.
+	RecordSession(session *DBPlayerSession) (bool, error)
+
+	// ExtendLastSession moves the quit time of the player's latest session on the server which started before
+	// quitTime to quitTime if it ended earlier. It is used when a player's session was closed at the end of a previous
+	// backfill because they were still online. It returns false if there was no session to extend.
+	ExtendLastSession(playerID int64, serverID int64, quitTime int64) (bool, error)
+
+	// RecordChatMessage creates a chat message identified by key, which must be unique to the log line the message
+	// was read from. It returns false if a message with the same key already exists or if a matching message which
+	// was recorded live (sent by the same player with the same text around the same time) exists. In the latter case
+	// the live message is given the key so that it is only matched once.
+	RecordChatMessage(message *ChatMessage, key string) (bool, error)
+
+	// UpdateLastSeen sets a player's last seen time if it is later than the one currently stored.
+	UpdateLastSeen(playerID int64, timestamp int64) error
+}
+
+type BackfillService interface {
+	StartBackfill(serverID int64, body params.StartBackfillParams) (*BackfillProgress, *ServiceResponse)
+	GetProgress(serverID int64) (*BackfillProgress, *ServiceResponse)
+}
+
+type BackfillHandler interface {
+	StartBackfill(c echo.Context) error
+	GetProgress(c echo.Context) error
+}
@@ -17,33 +17,128 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 
 package refractor
 
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/sniddunc/refractor/internal/params"
+	"io"
+)
+
 type ChatReceiveBody struct {
 	ServerID     int64  `json:"serverId"`
 	PlayerGameID string `json:"playerGameID"`
 	Name         string `json:"name"`
 	Message      string `json:"message"`
+	Channel      string `json:"channel,omitempty"`
 	SentByUser   bool   `json:"sentByUser"`
 }
 
+// ChatMessage is a chat message sent on a server. Messages are either sent by a player in-game or by a staff member
+// through Refractor, in which case UserID is set instead of PlayerID. Whispers sent by staff to a single player have
+// TargetPlayerID set.
 type ChatMessage struct {
-	MessageID    int64  `json:"id"`
-	PlayerID     int64  `json:"playerId"`
-	ServerID     int64  `json:"serverId"`
-	Message      string `json:"message"`
-	DateRecorded int64  `json:"timestamp"`
-	Flagged      bool   `json:"flagged"`
-	PlayerName   string `json:"playerName,omitempty"` // not a db field
+	MessageID      int64  `json:"id"`
+	PlayerID       int64  `json:"playerId,omitempty"`
+	UserID         int64  `json:"userId,omitempty"`
+	TargetPlayerID int64  `json:"targetPlayerId,omitempty"`
+	ServerID       int64  `json:"serverId"`
+	Message        string `json:"message"`
+	DateRecorded   int64  `json:"timestamp"`
+	Flagged        bool   `json:"flagged"`
+	Channel        string `json:"channel,omitempty"`
+	PlayerName     string `json:"playerName,omitempty"` // the name used when the message was sent
+	SentByUser     bool   `json:"sentByUser"`           // not a db field
+	IsTarget       bool   `json:"isTarget,omitempty"`   // not a db field
+}
+
+// FlaggedChatMessage is an entry in the flagged chat review queue.
+type FlaggedChatMessage struct {
+	Message         *ChatMessage   `json:"message"`
+	Context         []*ChatMessage `json:"context"`
+	InfractionCount int            `json:"infractionCount"`
+}
+
+const (
+	TRANSCRIPT_ENTRY_CHAT       = "CHAT"
+	TRANSCRIPT_ENTRY_STAFF_CHAT = "STAFF_CHAT"
+	TRANSCRIPT_ENTRY_WHISPER    = "WHISPER"
+	TRANSCRIPT_ENTRY_JOIN       = "JOIN"
+	TRANSCRIPT_ENTRY_QUIT       = "QUIT"
+)
+
+// TranscriptEntry is a single line of a server's chat transcript. Chat entries have a Message, join and quit entries
+// do not. Staff chat and whisper entries have a UserID instead of a PlayerID and PlayerName holds the staff member's
+// username. Whisper entries also hold the player the whisper was sent to.
+type TranscriptEntry struct {
+	Type           string `json:"type"`
+	Timestamp      int64  `json:"timestamp"`
+	PlayerID       int64  `json:"playerId,omitempty"`
+	UserID         int64  `json:"userId,omitempty"`
+	TargetPlayerID int64  `json:"targetPlayerId,omitempty"`
+	PlayerName     string `json:"playerName"`
+	TargetName     string `json:"targetName,omitempty"`
+	Message        string `json:"message,omitempty"`
 }
 
+// ChatKeyword is a word or phrase a user wants to be alerted about whenever it is said in chat.
+type ChatKeyword struct {
+	KeywordID int64  `json:"id"`
+	UserID    int64  `json:"userId"`
+	Keyword   string `json:"keyword"`
+}
+
+// TranscriptEntryHandler is called for each entry of a transcript as it is read.
+type TranscriptEntryHandler func(entry *TranscriptEntry) error
+
 type ChatRepository interface {
 	Create(message *ChatMessage) (*ChatMessage, error)
 	FindByID(id int64) (*ChatMessage, error)
 	FindMany(args FindArgs) ([]*ChatMessage, error)
 	Search(args FindArgs, limit int, offset int, getPlayerName PlayerNameGetter) (int, []*ChatMessage, error)
+	SetFlagged(id int64, flagged bool) error
+	FindFlagged(limit int, offset int) (int, []*ChatMessage, error)
+	GetContext(message *ChatMessage, before int, after int) ([]*ChatMessage, error)
+	AttachToInfraction(messageID int64, infractionID int64) error
+	FindByInfractionID(infractionID int64) ([]*ChatMessage, error)
+	StreamTranscript(serverID int64, startDate int64, endDate int64, handler TranscriptEntryHandler) error
+}
+
+type ChatKeywordRepository interface {
+	Create(keyword *ChatKeyword) (*ChatKeyword, error)
+	FindByID(id int64) (*ChatKeyword, error)
+	FindByUserID(userID int64) ([]*ChatKeyword, error)
+	FindAll() ([]*ChatKeyword, error)
+	Delete(id int64) error
 }
 
 type ChatService interface {
 	OnChatReceive(msgBody *ChatReceiveBody, serverID int64, gameConfig *GameConfig)
 	OnUserSendChat(msgBody *ChatSendBody)
+	OnUserSendWhisper(msgBody *WhisperSendBody)
+	OnInfractionCreate(infraction *Infraction)
+	SendWhisper(body params.SendWhisperParams) (*ChatMessage, *ServiceResponse)
 	LogMessage(message *ChatMessage) (*ChatMessage, *ServiceResponse)
+	SetMessageFlagged(id int64, flagged bool, user params.UserMeta) *ServiceResponse
+	GetFlaggedMessages(body params.GetFlaggedMessagesParams) (int, []*FlaggedChatMessage, *ServiceResponse)
+	DismissFlaggedMessage(id int64, user params.UserMeta) *ServiceResponse
+	CreateInfractionFromMessage(id int64, body params.CreateChatInfractionParams) (*Infraction, *ServiceResponse)
+	GetInfractionMessages(infractionID int64) ([]*ChatMessage, *ServiceResponse)
+	GetMessageContext(id int64, body params.GetChatContextParams) ([]*ChatMessage, *ServiceResponse)
+	ExportTranscript(body params.ExportTranscriptParams, w io.Writer) *ServiceResponse
+	AddKeyword(body params.AddChatKeywordParams) (*ChatKeyword, *ServiceResponse)
+	GetUserKeywords(userID int64) ([]*ChatKeyword, *ServiceResponse)
+	DeleteKeyword(id int64, user params.UserMeta) *ServiceResponse
+}
+
+type ChatHandler interface {
+	SwitchMessageFlag(flagged bool) echo.HandlerFunc
+	GetFlaggedMessages(c echo.Context) error
+	DismissFlaggedMessage(c echo.Context) error
+	CreateInfractionFromMessage(c echo.Context) error
+	GetInfractionMessages(c echo.Context) error
+	GetMessageContext(c echo.Context) error
+	AddKeyword(c echo.Context) error
+	GetKeywords(c echo.Context) error
+	DeleteKeyword(c echo.Context) error
+	ExportTranscript(c echo.Context) error
+	SendWhisper(c echo.Context) error
 }
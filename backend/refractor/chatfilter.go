@@ -0,0 +1,92 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package refractor
+
+import (
+	"database/sql"
+	"github.com/labstack/echo/v4"
+	"github.com/sniddunc/refractor/internal/params"
+)
+
+// ChatFilterRule is an automated chat moderation rule. A rule with a ServerID only applies to that server. A rule
+// with a Game but no ServerID applies to every server running that game, and a rule with neither applies everywhere.
+type ChatFilterRule struct {
+	RuleID    int64  `json:"id"`
+	Game      string `json:"game"`
+	ServerID  int64  `json:"serverId"`
+	Type      string `json:"type"`
+	Pattern   string `json:"pattern"`
+	Action    string `json:"action"`
+	Reason    string `json:"reason"`
+	Duration  int    `json:"duration"`
+	Enabled   bool   `json:"enabled"`
+	CreatedBy int64  `json:"createdBy"`
+}
+
+type DBChatFilterRule struct {
+	RuleID    int64
+	Game      string
+	ServerID  sql.NullInt64
+	Type      string
+	Pattern   string
+	Action    string
+	Reason    string
+	Duration  int
+	Enabled   bool
+	CreatedBy int64
+}
+
+// ChatFilterRule builds a ChatFilterRule instance from the DBChatFilterRule it was called upon.
+func (dbr *DBChatFilterRule) ChatFilterRule() *ChatFilterRule {
+	return &ChatFilterRule{
+		RuleID:    dbr.RuleID,
+		Game:      dbr.Game,
+		ServerID:  dbr.ServerID.Int64,
+		Type:      dbr.Type,
+		Pattern:   dbr.Pattern,
+		Action:    dbr.Action,
+		Reason:    dbr.Reason,
+		Duration:  dbr.Duration,
+		Enabled:   dbr.Enabled,
+		CreatedBy: dbr.CreatedBy,
+	}
+}
+
+type ChatFilterRepository interface {
+	Create(rule *DBChatFilterRule) (*ChatFilterRule, error)
+	FindByID(id int64) (*ChatFilterRule, error)
+	FindAll() ([]*ChatFilterRule, error)
+	Update(id int64, args UpdateArgs) (*ChatFilterRule, error)
+	Delete(id int64) error
+}
+
+type ChatFilterService interface {
+	CreateRule(body params.CreateChatFilterRuleParams) (*ChatFilterRule, *ServiceResponse)
+	GetAllRules() ([]*ChatFilterRule, *ServiceResponse)
+	UpdateRule(id int64, body params.UpdateChatFilterRuleParams) (*ChatFilterRule, *ServiceResponse)
+	DeleteRule(id int64) *ServiceResponse
+	CheckMessage(serverID int64, message string) []*ChatFilterRule
+	HandleMatches(message *ChatMessage, playerGameID string, matches []*ChatFilterRule)
+}
+
+type ChatFilterHandler interface {
+	CreateRule(c echo.Context) error
+	GetAllRules(c echo.Context) error
+	UpdateRule(c echo.Context) error
+	DeleteRule(c echo.Context) error
+}
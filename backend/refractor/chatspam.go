@@ -0,0 +1,59 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package refractor
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/broadcast"
+)
+
+// ChatSpamSettings holds a server's chat spam detection settings. Each kind of spam has its own threshold and action.
+// FloodWindow is in seconds and MuteDuration is in minutes.
+type ChatSpamSettings struct {
+	ServerID      int64  `json:"serverId"`
+	Enabled       bool   `json:"enabled"`
+	FloodMessages int    `json:"floodMessages"`
+	FloodWindow   int    `json:"floodWindow"`
+	FloodAction   string `json:"floodAction"`
+	RepeatCount   int    `json:"repeatCount"`
+	RepeatAction  string `json:"repeatAction"`
+	CapsPercent   int    `json:"capsPercent"`
+	CapsMinLength int    `json:"capsMinLength"`
+	CapsAction    string `json:"capsAction"`
+	MuteDuration  int    `json:"muteDuration"`
+	UpdatedBy     int64  `json:"updatedBy"`
+}
+
+type ChatSpamRepository interface {
+	FindByServerID(serverID int64) (*ChatSpamSettings, error)
+	Save(settings *ChatSpamSettings) error
+}
+
+type ChatSpamService interface {
+	GetSettings(serverID int64) (*ChatSpamSettings, *ServiceResponse)
+	UpdateSettings(serverID int64, body params.UpdateChatSpamSettingsParams) (*ChatSpamSettings, *ServiceResponse)
+	CheckMessage(message *ChatMessage, player *Player, playerGameID string) []string
+	OnPlayerQuit(fields broadcast.Fields, serverID int64, gameConfig *GameConfig)
+	OnServerOffline(serverID int64)
+}
+
+type ChatSpamHandler interface {
+	GetSettings(c echo.Context) error
+	UpdateSettings(c echo.Context) error
+}
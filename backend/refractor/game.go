@@ -30,31 +30,102 @@ type Game interface {
 }
 
 type GameConfig struct {
-	UseRCON           bool
+	UseRCON bool
+
+	// RCONTransport creates the transport used to connect to the game's servers
+	RCONTransport RCONTransportFactory
+
+	// MaxRCONPort is the highest RCON port the game's transport can connect to. Any port can be used if it is 0.
+	MaxRCONPort uint16
+
 	SendAlivePing     bool
 	AlivePingInterval time.Duration
 	EnableBroadcasts  bool
 	BroadcastPatterns map[string]*regexp.Regexp
 	CmdOutputPatterns map[string]*regexp.Regexp
 
+	// BroadcastChannels are the RCON broadcast channels listened to if EnableBroadcasts is set to true
+	BroadcastChannels []string
+
 	// Not all games will have support for live chat. If a game does, this should be set to true.
 	EnableChat bool
 
+	// SupportsNativeMute should be set to true if the game has a mute command. For games without one, Refractor
+	// enforces mutes itself by acting on chat messages sent by muted players.
+	SupportsNativeMute bool
+
+	// PollPlayerList should be set to true for games which broadcast events such as chat but not player joins and
+	// quits. Joins and quits are then detected by polling the player list while broadcasts are still received.
+	PollPlayerList bool
+
 	// If EnableBroadcasts is set to false, we will use polling for the playerlist instead of broadcasts.
 	// Alternatively, if EnableBroadcasts is set to true this duration is used for the player refresh polling routine
 	// to keep the player list in sync for games which support broadcasts.
 	PlayerListPollingInterval time.Duration
 
+	// PlayerFieldsPollingInterval is the interval at which the player list is polled to refresh player list fields
+	// such as ping and team for games which detect joins and quits without polling. It is usually much shorter than
+	// PlayerListPollingInterval. Player list fields are not refreshed in between refresh polls if it is 0.
+	PlayerFieldsPollingInterval time.Duration
+
+	// ChatPollingInterval is the interval at which chat is polled for games which relay chat through a polled
+	// command rather than through broadcasts. Chat polling is only used if GetChatPollCommand returns a command.
+	ChatPollingInterval time.Duration
+
+	// ServerInfoPollingInterval is the interval at which the server info command is polled. Server info polling is
+	// only used if GetServerInfoCommand returns a command and the game has a ServerInfo output pattern. The pattern's
+	// named groups (ServerName, Map, GameMode and MaxPlayers) are collected from every match in the command's output.
+	ServerInfoPollingInterval time.Duration
+
+	// LogLinePrefix is an optional pattern matching the prefix the game writes before each line of its log file.
+	// It is stripped from log lines before they are matched against BroadcastPatterns so that the same patterns can
+	// be used for RCON broadcasts and log files.
+	LogLinePrefix *regexp.Regexp
+
+	// LogTimestampLayout is the time layout of the Timestamp named group captured by LogLinePrefix. It is used to
+	// find out when events in archived log files happened. Log timestamps are assumed to be in UTC.
+	LogTimestampLayout string
+
+	// MatchInProgressState is the State field of the match state broadcast sent when a match starts. Match state
+	// broadcasts with any other state end the current match.
+	MatchInProgressState string
+
 	// PlayerGameIDField holds the name of the regex named properly containing the player's unique identifier for a game.
-	// Using Mordhau as an example, it would be "PlayFabID".
+	// Using Mordhau as an example, it would be "PlayFabID". It must be the name of a game ID field of Player and
+	// DBPlayer: PlayFabID, MCUUID or SteamID.
 	PlayerGameIDField string
 }
 
+// GetPlayerListFields returns the fields which are named groups of the game's player list pattern. Fields captured by
+// other patterns (e.g. the date of a join broadcast) are left out. If the game has no player list pattern, nil is
+// returned.
+func (c *GameConfig) GetPlayerListFields(fields map[string]string) map[string]string {
+	playerListPattern := c.CmdOutputPatterns["PlayerList"]
+	if playerListPattern == nil {
+		return nil
+	}
+
+	playerListFields := map[string]string{}
+
+	for _, name := range playerListPattern.SubexpNames() {
+		if value, ok := fields[name]; ok && name != "" {
+			playerListFields[name] = value
+		}
+	}
+
+	if len(playerListFields) == 0 {
+		return nil
+	}
+
+	return playerListFields
+}
+
 // CommandArgs is a struct used to supply a game's command builders with the data they need.
 type CommandArgs struct {
 	PlayerID string
 	Reason   string
 	Duration int
+	Message  string
 }
 
 type GameCommands interface {
@@ -63,6 +134,23 @@ type GameCommands interface {
 	GetKickCommand(args CommandArgs) string
 	GetBanCommand(args CommandArgs) string
 	GetPlayerListCommand() string
+
+	// GetWhisperCommand returns a command which privately messages a single player. Games which cannot message a
+	// single player should return an empty string.
+	GetWhisperCommand(args CommandArgs) string
+
+	// GetBroadcastCommand returns a command which sends a message to every player on the server. It is also used to
+	// reach a single player in games which cannot message one privately, so every game must have one.
+	GetBroadcastCommand(args CommandArgs) string
+
+	// GetChatPollCommand returns a command which fetches chat messages sent after the provided cursor. An empty
+	// cursor means no messages have been fetched yet. Games which do not relay chat through polling should return
+	// an empty string.
+	GetChatPollCommand(cursor string) string
+
+	// GetServerInfoCommand returns a command which outputs details about the server such as its current map. Games
+	// without such a command should return an empty string.
+	GetServerInfoCommand() string
 }
 
 type GameService interface {
@@ -21,6 +21,7 @@ import (
 	"database/sql"
 	"github.com/labstack/echo/v4"
 	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/broadcast"
 )
 
 const (
@@ -44,6 +45,10 @@ type Infraction struct {
 	SystemAction bool   `json:"systemAction"`
 	StaffName    string `json:"staffName"`  // not a database field
 	PlayerName   string `json:"playerName"` // not a database field
+
+	// IssuerPlayerID is set for infractions which were issued by an admin in-game rather than through Refractor.
+	// It is the player ID of the in-game admin. UserID is 0 for these infractions.
+	IssuerPlayerID int64 `json:"issuerPlayerId,omitempty"`
 }
 
 type DBInfraction struct {
@@ -56,6 +61,9 @@ type DBInfraction struct {
 	Duration     sql.NullInt32
 	Timestamp    int64
 	SystemAction bool
+
+	// UserID and IssuerPlayerID are stored as NULL when they are 0
+	IssuerPlayerID int64
 }
 
 // Infraction builds a Infraction instance from the DBInstance it was called upon.
@@ -70,7 +78,30 @@ func (dbi *DBInfraction) Infraction() *Infraction {
 		Type:         dbi.Type,
 		Timestamp:    dbi.Timestamp,
 		SystemAction: dbi.SystemAction,
+
+		IssuerPlayerID: dbi.IssuerPlayerID,
+	}
+}
+
+// ExpiresAt returns the unix time a mute or ban expires at. Durations are in minutes and mutes and bans with a duration
+// of 0 are permanent, in which case 0 is returned.
+func (i *Infraction) ExpiresAt() int64 {
+	if i.Duration == 0 {
+		return 0
 	}
+
+	return i.Timestamp + int64(i.Duration)*60
+}
+
+// IsActive returns true if the infraction is a mute or ban which has not expired at the given unix time.
+func (i *Infraction) IsActive(now int64) bool {
+	if i.Type != INFRACTION_TYPE_MUTE && i.Type != INFRACTION_TYPE_BAN {
+		return false
+	}
+
+	expiresAt := i.ExpiresAt()
+
+	return expiresAt == 0 || expiresAt > now
 }
 
 type InfractionRepository interface {
@@ -95,11 +126,15 @@ type InfractionService interface {
 	CreateMute(userID int64, body params.CreateMuteParams) (*Infraction, *ServiceResponse)
 	CreateKick(userID int64, body params.CreateKickParams) (*Infraction, *ServiceResponse)
 	CreateBan(userID int64, body params.CreateBanParams) (*Infraction, *ServiceResponse)
+	CreateSystemInfraction(userID int64, playerID int64, serverID int64, infractionType string, reason string,
+		duration int) (*Infraction, *ServiceResponse)
+	OnPunishment(fields broadcast.Fields, serverID int64, gameConfig *GameConfig)
 	DeleteInfraction(id int64, user params.UserMeta) *ServiceResponse
 	UpdateInfraction(id int64, body params.UpdateInfractionParams) (*Infraction, *ServiceResponse)
 	GetPlayerInfractionsType(infractionType string, playerID int64) ([]*Infraction, *ServiceResponse)
 	GetPlayerInfractions(playerID int64) ([]*Infraction, *ServiceResponse)
 	GetRecentInfractions(count int) ([]*Infraction, *ServiceResponse)
+	GetActiveMute(playerID int64, serverID int64) (*Infraction, *ServiceResponse)
 	SubscribeInfractionCreate(subscriber InfractionCreateSubscriber)
 }
 
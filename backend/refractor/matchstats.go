@@ -0,0 +1,68 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package refractor
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/sniddunc/refractor/internal/params"
+	"github.com/sniddunc/refractor/pkg/broadcast"
+)
+
+// MatchStats holds a player's kills, deaths and teamkills in a single match on a server.
+type MatchStats struct {
+	MatchStatsID int64 `json:"id"`
+	ServerID     int64 `json:"serverId"`
+	PlayerID     int64 `json:"playerId"`
+	MatchStart   int64 `json:"matchStart"`
+	MatchEnd     int64 `json:"matchEnd"`
+	Kills        int   `json:"kills"`
+	Deaths       int   `json:"deaths"`
+	Teamkills    int   `json:"teamkills"`
+}
+
+// TeamkillSettings holds a server's teamkill detection settings. Action is taken once per match when a player's
+// teamkills reach Threshold. BanDuration is in minutes.
+type TeamkillSettings struct {
+	ServerID    int64  `json:"serverId"`
+	Enabled     bool   `json:"enabled"`
+	Threshold   int    `json:"threshold"`
+	Action      string `json:"action"`
+	BanDuration int    `json:"banDuration"`
+	UpdatedBy   int64  `json:"updatedBy"`
+}
+
+type MatchStatsRepository interface {
+	Create(stats []*MatchStats) error
+	FindByPlayerID(playerID int64, limit int) ([]*MatchStats, error)
+	FindTeamkillSettings(serverID int64) (*TeamkillSettings, error)
+	SaveTeamkillSettings(settings *TeamkillSettings) error
+}
+
+type MatchStatsService interface {
+	GetPlayerMatchStats(playerID int64) ([]*MatchStats, *ServiceResponse)
+	GetTeamkillSettings(serverID int64) (*TeamkillSettings, *ServiceResponse)
+	UpdateTeamkillSettings(serverID int64, body params.UpdateTeamkillSettingsParams) (*TeamkillSettings, *ServiceResponse)
+	OnMatchState(fields broadcast.Fields, serverID int64, gameConfig *GameConfig)
+	OnKill(fields broadcast.Fields, serverID int64, gameConfig *GameConfig)
+	OnServerOffline(serverID int64)
+}
+
+type MatchStatsHandler interface {
+	GetTeamkillSettings(c echo.Context) error
+	UpdateTeamkillSettings(c echo.Context) error
+}
@@ -20,6 +20,7 @@ package refractor
 import (
 	"database/sql"
 	"github.com/labstack/echo/v4"
+	"github.com/sniddunc/refractor/internal/params"
 	"github.com/sniddunc/refractor/pkg/broadcast"
 )
 
@@ -27,21 +28,30 @@ type Player struct {
 	PlayerID        int64    `json:"id"`
 	PlayFabID       string   `json:"playFabId"`
 	MCUUID          string   `json:"mcuuid"`
+	SteamID         string   `json:"steamId"`
 	LastSeen        int64    `json:"lastSeen"`
 	CurrentName     string   `json:"currentName"`
 	PreviousNames   []string `json:"previousNames,omitempty"`
 	Watched         bool     `json:"watched"`
+	Trusted         bool     `json:"trusted"`
 	InfractionCount *int     `json:"infractionCount,omitempty"` // not a db field
+	MatchedName     string   `json:"matchedName,omitempty"`     // not a db field
+
+	// Fields holds the named groups captured for an online player by their game's player list pattern (e.g. Ping
+	// and Team). It is only set on players in ServerData.
+	Fields map[string]string `json:"fields,omitempty"` // not a db field
 }
 
 type DBPlayer struct {
 	PlayerID      int64
 	PlayFabID     sql.NullString
 	MCUUID        sql.NullString
+	SteamID       sql.NullString
 	LastSeen      int64
 	CurrentName   string
 	PreviousNames []string
 	Watched       bool `json:"watched"`
+	Trusted       bool `json:"trusted"`
 }
 
 func (dbp DBPlayer) Player() *Player {
@@ -49,13 +59,23 @@ func (dbp DBPlayer) Player() *Player {
 		PlayerID:      dbp.PlayerID,
 		PlayFabID:     dbp.PlayFabID.String,
 		MCUUID:        dbp.MCUUID.String,
+		SteamID:       dbp.SteamID.String,
 		LastSeen:      dbp.LastSeen,
 		CurrentName:   dbp.CurrentName,
 		PreviousNames: dbp.PreviousNames,
 		Watched:       dbp.Watched,
+		Trusted:       dbp.Trusted,
 	}
 }
 
+// PlayerNameMatch is a candidate result of a name search. Current is true if Name is the player's current name.
+type PlayerNameMatch struct {
+	PlayerID int64
+	Name     string
+	Current  bool
+	LastSeen int64
+}
+
 type PlayerUpdateSubscriber func(updated *Player)
 type PlayerNameGetter func(id int64) (string, []string, error)
 
@@ -68,16 +88,19 @@ type PlayerRepository interface {
 	Exists(args FindArgs) (bool, error)
 	UpdateName(player *Player, currentName string) error
 	Update(id int64, args UpdateArgs) (*Player, error)
-	SearchByName(name string, limit int, offset int) (int, []*Player, error)
+	FindNameCandidates(name string, max int) ([]*PlayerNameMatch, error)
+	SearchByGameID(field string, term string, limit int, offset int) (int, []*Player, error)
 	GetPlayerNames(id int64) (string, []string, error)
+	GetNameAt(playerID int64, timestamp int64) (string, error)
 }
 
 type PlayerService interface {
 	CreatePlayer(newPlayer *DBPlayer) (*Player, *ServiceResponse)
 	GetPlayerByID(id int64) (*Player, *ServiceResponse)
 	GetPlayer(args FindArgs) (*Player, *ServiceResponse)
-	GetRecentPlayers() ([]*Player, *ServiceResponse)
+	GetRecentPlayers(body params.GetRecentPlayersParams) ([]*RecentPlayer, *ServiceResponse)
 	SetPlayerWatch(id int64, watch bool) *ServiceResponse
+	SetPlayerTrusted(id int64, trusted bool) *ServiceResponse
 	OnPlayerJoin(serverID int64, playerGameID string, currentName string, gameConfig *GameConfig) (*Player, *ServiceResponse)
 	OnPlayerQuit(serverID int64, playerGameID string, gameConfig *GameConfig) (*Player, *ServiceResponse)
 	SubscribeUpdate(subscriber PlayerUpdateSubscriber)
@@ -86,6 +109,7 @@ type PlayerService interface {
 type PlayerHandler interface {
 	GetRecentPlayers(c echo.Context) error
 	SwitchPlayerWatch(watch bool) echo.HandlerFunc
+	SwitchPlayerTrusted(trusted bool) echo.HandlerFunc
 	OnPlayerJoin(fields broadcast.Fields, serverID int64, gameConfig *GameConfig)
 	OnPlayerQuit(fields broadcast.Fields, serverID int64, gameConfig *GameConfig)
 }
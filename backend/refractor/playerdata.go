@@ -0,0 +1,68 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package refractor
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/sniddunc/refractor/internal/params"
+)
+
+const (
+	PLAYER_DATA_REQUEST_EXPORT  = "EXPORT"
+	PLAYER_DATA_REQUEST_ERASURE = "ERASURE"
+)
+
+// PlayerDataExport is an archive of everything stored about a single player.
+type PlayerDataExport struct {
+	ExportedAt   int64              `json:"exportedAt"`
+	PlayerID     int64              `json:"playerId"`
+	PlayFabID    string             `json:"playFabId,omitempty"`
+	MCUUID       string             `json:"mcuuid,omitempty"`
+	SteamID      string             `json:"steamId,omitempty"`
+	LastSeen     int64              `json:"lastSeen"`
+	Names        []PlayerNameRecord `json:"names"`
+	ChatMessages []*ChatMessage     `json:"chatMessages"`
+	Infractions  []*Infraction      `json:"infractions"`
+	Sessions     []*PlayerSession   `json:"sessions"`
+	MatchStats   []*MatchStats      `json:"matchStats"`
+}
+
+// PlayerDataRequest is a log entry for an export or erasure of a player's data.
+type PlayerDataRequest struct {
+	RequestID int64  `json:"id"`
+	PlayerID  int64  `json:"playerId"`
+	UserID    int64  `json:"userId"`
+	Type      string `json:"type"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+type PlayerDataRepository interface {
+	Export(playerID int64) (*PlayerDataExport, error)
+	Erase(playerID int64, pseudonym string) error
+	LogRequest(request *PlayerDataRequest) error
+}
+
+type PlayerDataService interface {
+	ExportPlayerData(playerID int64, user params.UserMeta) (*PlayerDataExport, *ServiceResponse)
+	ErasePlayerData(playerID int64, user params.UserMeta) *ServiceResponse
+}
+
+type PlayerDataHandler interface {
+	ExportPlayerData(c echo.Context) error
+	ErasePlayerData(c echo.Context) error
+}
@@ -0,0 +1,81 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package refractor
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"github.com/sniddunc/refractor/internal/params"
+)
+
+// PlayerMerge is an audit record of one player (the source) being merged into another (the target).
+type PlayerMerge struct {
+	MergeID        int64                `json:"id"`
+	SourcePlayerID int64                `json:"sourcePlayerId"`
+	TargetPlayerID int64                `json:"targetPlayerId"`
+	UserID         int64                `json:"userId"`
+	Timestamp      int64                `json:"timestamp"`
+	Undone         bool                 `json:"undone"`
+	UndoneBy       int64                `json:"undoneBy"`
+	UndoneAt       int64                `json:"undoneAt"`
+	Snapshot       *PlayerMergeSnapshot `json:"-"`
+}
+
+// PlayerMergeSnapshot holds everything needed to undo a merge. It is stored alongside the audit record.
+type PlayerMergeSnapshot struct {
+	Source         DBPlayer           `json:"source"`
+	TargetBefore   DBPlayer           `json:"targetBefore"`
+	SourceNames    []PlayerNameRecord `json:"sourceNames"`
+	MovedNames     []string           `json:"movedNames"`
+	InfractionIDs  []int64            `json:"infractionIds"`
+	ChatMessageIDs []int64            `json:"chatMessageIds"`
+	SessionIDs     []int64            `json:"sessionIds"`
+
+	// TargetChatMessageIDs holds the staff messages which were whispered to the source player
+	TargetChatMessageIDs []int64 `json:"targetChatMessageIds"`
+
+	// IssuedInfractionIDs holds the infractions the source player issued as an in-game admin
+	IssuedInfractionIDs []int64 `json:"issuedInfractionIds"`
+
+	MatchStatsIDs []int64 `json:"matchStatsIds"`
+}
+
+// PlayerNameRecord is a single row of a player's name history.
+type PlayerNameRecord struct {
+	Name         string `json:"name"`
+	DateRecorded int64  `json:"dateRecorded"`
+}
+
+// ErrMergeUndone is returned when undoing a merge which has already been undone
+var ErrMergeUndone = errors.New("merge has already been undone")
+
+type PlayerMergeRepository interface {
+	Merge(sourceID int64, targetID int64, userID int64) (*PlayerMerge, error)
+	FindByID(id int64) (*PlayerMerge, error)
+	Undo(id int64, userID int64) (*PlayerMerge, error)
+}
+
+type PlayerMergeService interface {
+	MergePlayers(body params.MergePlayersParams) (*PlayerMerge, *ServiceResponse)
+	UndoMerge(id int64, user params.UserMeta) (*PlayerMerge, *ServiceResponse)
+}
+
+type PlayerMergeHandler interface {
+	MergePlayers(c echo.Context) error
+	UndoMerge(c echo.Context) error
+}
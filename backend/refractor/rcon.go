@@ -18,30 +18,79 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package refractor
 
 import (
-	rcon "github.com/sniddunc/mordhau-rcon"
 	"github.com/sniddunc/refractor/pkg/broadcast"
+	"time"
 )
 
-// RCONClient wraps around a mordhau-rcon Client and has an extra field containing the server
+// RCONClient wraps around the RCON transport of a server and has an extra field containing the server
 type RCONClient struct {
 	Server *Server
-	*rcon.Client
+	RCONTransport
 }
 
+// RCONTransport is a connection to a server's RCON interface. Each RCON dialect (e.g. Mordhau's or Valve's Source
+// RCON) has its own transport, and each game picks the transport which speaks its dialect.
+type RCONTransport interface {
+	// Connect opens a connection to the server and authenticates with it
+	Connect() error
+
+	// ExecCommand runs a command on the server and returns its output
+	ExecCommand(command string) (string, error)
+
+	// ListenForBroadcasts starts passing broadcasts from the given channels to the configured broadcast handler.
+	// Errors which stop broadcasts from being received are sent to errors. Transports of dialects without broadcast
+	// channels pass every broadcast the server sends to the handler.
+	ListenForBroadcasts(channels []string, errors chan error)
+
+	// Disconnect closes the connection to the server
+	Disconnect() error
+}
+
+// RCONTransportConfig holds the settings a transport is created with
+type RCONTransportConfig struct {
+	Host     string
+	Port     uint16
+	Password string
+
+	// SendHeartbeat enables a command being sent to the server every HeartbeatInterval to keep the connection alive
+	SendHeartbeat     bool
+	HeartbeatInterval time.Duration
+
+	EnableBroadcasts bool
+	BroadcastHandler func(message string)
+
+	// DisconnectHandler is called when the connection to the server is lost. Expected is true if the disconnect was
+	// caused by a call to Disconnect.
+	DisconnectHandler func(err error, expected bool)
+}
+
+// RCONTransportFactory creates a transport which isn't connected yet
+type RCONTransportFactory func(config *RCONTransportConfig) RCONTransport
+
 type BroadcastSubscriber func(fields broadcast.Fields, serverID int64, gameConfig *GameConfig)
 type ChatReceiveSubscriber func(msgBody *ChatReceiveBody, serverID int64, gameConfig *GameConfig)
 type PlayerListPollSubscriber func(serverID int64, gameConfig *GameConfig, players []*Player)
+
+// PlayerFieldsSubscriber receives the player list fields of every online player on a server, keyed by player game ID
+type PlayerFieldsSubscriber func(serverID int64, gameConfig *GameConfig, fields map[string]broadcast.Fields)
 type StatusSubscriber func(serverID int64)
+type ServerInfoSubscriber func(serverID int64, info *LiveServerInfo)
 
 type RCONService interface {
 	CreateClient(*Server) error
 	GetClients() map[int64]*RCONClient
 	DeleteClient(serverID int64)
 	SendChatMessage(msgBody *ChatSendBody)
+	ExecCommand(serverID int64, command string) (string, error)
 	SubscribeJoin(subscriber BroadcastSubscriber)
 	SubscribeQuit(subscriber BroadcastSubscriber)
 	SubscribeOnline(subscriber StatusSubscriber)
 	SubscribeOffline(subscriber StatusSubscriber)
 	SubscribeChat(subscriber ChatReceiveSubscriber)
 	SubscribePlayerListPoll(subscriber PlayerListPollSubscriber)
+	SubscribePlayerFields(subscriber PlayerFieldsSubscriber)
+	SubscribeServerInfo(subscriber ServerInfoSubscriber)
+	SubscribePunishment(subscriber BroadcastSubscriber)
+	SubscribeMatchState(subscriber BroadcastSubscriber)
+	SubscribeKill(subscriber BroadcastSubscriber)
 }
@@ -30,6 +30,22 @@ type Server struct {
 	Address      string `json:"address"`
 	RCONPort     string `json:"rconPort"`
 	RCONPassword string `json:"rconPassword"`
+
+	// WarnMessageTemplate is the message used to tell a player about a warning in-game. If it is empty,
+	// config.DefaultWarnMessageTemplate is used.
+	WarnMessageTemplate string `json:"warnMessageTemplate"`
+
+	// MuteAction is the action taken when a muted player chats on a server running a game without native mute
+	// support. It is one of the params.MuteAction values. If it is empty, params.MuteActionRemind is used.
+	MuteAction string `json:"muteAction"`
+
+	// EventSource is where player join, quit and chat events for this server come from. It is one of the
+	// params.EventSource values. If it is empty, RCON broadcasts are used if the game supports them and the player
+	// list is polled otherwise.
+	EventSource string `json:"eventSource"`
+
+	// LogFilePath is the path of the server's log file which is followed if EventSource is params.EventSourceLog.
+	LogFilePath string `json:"logFilePath"`
 }
 
 type ServerInfo struct {
@@ -39,6 +55,14 @@ type ServerInfo struct {
 	Address  string `json:"address"`
 }
 
+// LiveServerInfo holds the details a server reports about itself through its game's server info command
+type LiveServerInfo struct {
+	Name       string `json:"name"`
+	Map        string `json:"map"`
+	GameMode   string `json:"gameMode"`
+	MaxPlayers int    `json:"maxPlayers"`
+}
+
 // ServerData is used to keep track of server data (player counts, levels, etc)
 type ServerData struct {
 	NeedsUpdate   bool
@@ -47,6 +71,10 @@ type ServerData struct {
 	Online        bool
 	PlayerCount   int
 	OnlinePlayers map[string]*Player
+
+	// Info is the latest server info polled from the server. It is nil if the game has no server info command or
+	// the server hasn't been polled yet.
+	Info *LiveServerInfo
 }
 
 type ServerRepository interface {
@@ -74,6 +102,9 @@ type ServerService interface {
 	OnServerOffline(serverID int64)
 	OnPlayerUpdate(updated *Player)
 	OnPlayerListUpdate(serverID int64, gameConfig *GameConfig, players []*Player)
+	OnPlayerFieldsUpdate(serverID int64, gameConfig *GameConfig, fields map[string]broadcast.Fields)
+	OnServerInfoUpdate(serverID int64, info *LiveServerInfo)
+	GetOnlinePlayerGameID(serverID int64, playerID int64) string
 }
 
 type ServerHandler interface {
@@ -0,0 +1,81 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package refractor
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/sniddunc/refractor/internal/params"
+	"time"
+)
+
+// ServerStatusSample holds a server's status over a period starting at Timestamp. Recent samples each cover a single
+// sample interval, while downsampled samples combine every sample taken in a period into one. Player counts only
+// cover the samples taken while the server was online.
+type ServerStatusSample struct {
+	ServerID      int64   `json:"serverId"`
+	Timestamp     int64   `json:"timestamp"`
+	Samples       int     `json:"samples"`
+	OnlineSamples int     `json:"onlineSamples"`
+	MinPlayers    int     `json:"minPlayers"`
+	MaxPlayers    int     `json:"maxPlayers"`
+	AvgPlayers    float64 `json:"avgPlayers"`
+	Downsampled   bool    `json:"downsampled"`
+}
+
+// ServerHistoryBucket holds a server's player counts and uptime over a single bucket of its history. Uptime is the
+// percentage of samples in which the server was online.
+type ServerHistoryBucket struct {
+	Timestamp  int64   `json:"timestamp"`
+	MinPlayers int     `json:"minPlayers"`
+	MaxPlayers int     `json:"maxPlayers"`
+	AvgPlayers float64 `json:"avgPlayers"`
+	Uptime     float64 `json:"uptime"`
+}
+
+// ServerHistory holds a server's status history between From and To grouped into buckets. Buckets without any
+// samples are left out. Uptime is the percentage of all samples in the range in which the server was online.
+type ServerHistory struct {
+	ServerID int64                  `json:"serverId"`
+	Bucket   string                 `json:"bucket"`
+	From     int64                  `json:"from"`
+	To       int64                  `json:"to"`
+	Uptime   float64                `json:"uptime"`
+	Buckets  []*ServerHistoryBucket `json:"buckets"`
+}
+
+type ServerHistoryRepository interface {
+	Create(sample *ServerStatusSample) error
+
+	// FindByServerID returns a server's samples with a timestamp in the range [from, to), oldest first
+	FindByServerID(serverID int64, from int64, to int64) ([]*ServerStatusSample, error)
+
+	// ReplaceSamples replaces a server's samples which haven't been downsampled yet and are older than before with
+	// the given downsampled samples.
+	ReplaceSamples(serverID int64, before int64, downsampled []*ServerStatusSample) error
+}
+
+type ServerHistoryService interface {
+	GetServerHistory(serverID int64, body params.GetServerHistoryParams) (*ServerHistory, *ServiceResponse)
+	StartSampling()
+	SampleServers(now time.Time)
+	Downsample(now time.Time)
+}
+
+type ServerHistoryHandler interface {
+	GetServerHistory(c echo.Context) error
+}
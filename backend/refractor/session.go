@@ -0,0 +1,62 @@
+/*
+This file is part of Refractor.
+
+Refractor is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package refractor
+
+import "database/sql"
+
+// PlayerSession represents a single stay of a player on a server. A QuitTime of 0 means the session is still open.
+type PlayerSession struct {
+	SessionID int64 `json:"id"`
+	PlayerID  int64 `json:"playerId"`
+	ServerID  int64 `json:"serverId"`
+	JoinTime  int64 `json:"joinTime"`
+	QuitTime  int64 `json:"quitTime"`
+}
+
+type DBPlayerSession struct {
+	SessionID int64
+	PlayerID  int64
+	ServerID  int64
+	JoinTime  int64
+	QuitTime  sql.NullInt64
+}
+
+// PlayerSession builds a PlayerSession instance from the DBPlayerSession it was called upon.
+func (dbs *DBPlayerSession) PlayerSession() *PlayerSession {
+	return &PlayerSession{
+		SessionID: dbs.SessionID,
+		PlayerID:  dbs.PlayerID,
+		ServerID:  dbs.ServerID,
+		JoinTime:  dbs.JoinTime,
+		QuitTime:  dbs.QuitTime.Int64,
+	}
+}
+
+// RecentPlayer is a player who recently left a server along with the server they left and when they left it.
+type RecentPlayer struct {
+	ServerID int64 `json:"serverId"`
+	QuitTime int64 `json:"quitTime"`
+	*Player
+}
+
+type PlayerSessionRepository interface {
+	Create(session *DBPlayerSession) (*PlayerSession, error)
+	FindOpen(playerID int64, serverID int64) (*PlayerSession, error)
+	Close(sessionID int64, quitTime int64) error
+	GetRecent(args FindArgs, limit int) ([]*PlayerSession, error)
+}
@@ -24,6 +24,9 @@ type PlayerSummary struct {
 	Mutes    []*Infraction `json:"mutes"`
 	Kicks    []*Infraction `json:"kicks"`
 	Bans     []*Infraction `json:"bans"`
+
+	// MatchStats holds the player's most recent matches, most recent first
+	MatchStats []*MatchStats `json:"matchStats"`
 	*Player
 }
 
@@ -32,10 +32,17 @@ type WebsocketDirectMessage struct {
 	Message  *WebsocketMessage
 }
 
+// WebsocketUserMessage is a message for every client a user is connected with.
+type WebsocketUserMessage struct {
+	UserID  int64
+	Message *WebsocketMessage
+}
+
 type ChatSendSubscriber func(msgBody *ChatSendBody)
 
 type ChatSendBody struct {
 	ServerID int64  `json:"serverId"`
+	UserID   int64  `json:"userId"`
 	Message  string `json:"message"`
 	Sender   string `json:"sender"`
 
@@ -43,14 +50,27 @@ type ChatSendBody struct {
 	SentByUser bool `json:"sendByUser"`
 }
 
+type WhisperSendSubscriber func(msgBody *WhisperSendBody)
+
+// WhisperSendBody is a private message sent by a Refractor user to a single player
+type WhisperSendBody struct {
+	ServerID int64  `json:"serverId"`
+	PlayerID int64  `json:"playerId"`
+	UserID   int64  `json:"userId"`
+	Message  string `json:"message"`
+}
+
 type WebsocketService interface {
 	Broadcast(message *WebsocketMessage)
+	SendDirect(userID int64, message *WebsocketMessage)
 	CreateClient(userID int64, conn net.Conn)
 	StartPool()
 	OnPlayerJoin(fields broadcast.Fields, serverID int64, gameConfig *GameConfig)
 	OnPlayerQuit(fields broadcast.Fields, serverID int64, gameConfig *GameConfig)
 	OnServerOnline(serverID int64)
 	OnServerOffline(serverID int64)
+	OnServerInfoUpdate(serverID int64, info *LiveServerInfo)
 	OnInfractionCreate(infraction *Infraction)
 	SubscribeChatSend(subscriber ChatSendSubscriber)
+	SubscribeWhisperSend(subscriber WhisperSendSubscriber)
 }